@@ -0,0 +1,23 @@
+/**
+ * flowmind 命令行入口
+ *
+ * 不依赖 Wails 窗口，可在服务器或 CI 中运行后台服务，或在脚本中查询本地数据
+ */
+
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/chenyang-zz/flowmind/internal/cli"
+)
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	code := cli.Run(ctx, os.Args[1:], os.Stdout, os.Stderr)
+	stop()
+	os.Exit(code)
+}
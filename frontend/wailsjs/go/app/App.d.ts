@@ -1,14 +1,26 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
+import {services} from '../models';
+import {events} from '../models';
 import {context} from '../models';
 
-export function CreateAutomation(arg1:Record<string, any>):Promise<Record<string, any>>;
+export function CreateAutomation(arg1:services.CreateAutomationRequest):Promise<services.AutomationView>;
 
-export function GetDashboardData():Promise<Record<string, any>>;
+export function GetAIUsage(arg1:number):Promise<services.UsageReport>;
 
-export function GetEvents(arg1:number):Promise<Array<Record<string, any>>>;
+export function GetDashboardData():Promise<services.DashboardData>;
 
-export function GetPatterns():Promise<Array<Record<string, any>>>;
+export function GetEventBusStats():Promise<Array<events.SubscriberStats>>;
+
+export function GetEvents(arg1:number):Promise<Array<events.Event>>;
+
+export function GetPatterns():Promise<Array<services.PatternView>>;
+
+export function IsMonitoringPaused():Promise<boolean>;
+
+export function PauseMonitoring(arg1:number):Promise<void>;
+
+export function ResumeMonitoring():Promise<void>;
 
 export function Shutdown():Promise<void>;
 
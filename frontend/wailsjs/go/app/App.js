@@ -6,10 +6,18 @@ export function CreateAutomation(arg1) {
   return window['go']['app']['App']['CreateAutomation'](arg1);
 }
 
+export function GetAIUsage(arg1) {
+  return window['go']['app']['App']['GetAIUsage'](arg1);
+}
+
 export function GetDashboardData() {
   return window['go']['app']['App']['GetDashboardData']();
 }
 
+export function GetEventBusStats() {
+  return window['go']['app']['App']['GetEventBusStats']();
+}
+
 export function GetEvents(arg1) {
   return window['go']['app']['App']['GetEvents'](arg1);
 }
@@ -18,6 +26,18 @@ export function GetPatterns() {
   return window['go']['app']['App']['GetPatterns']();
 }
 
+export function IsMonitoringPaused() {
+  return window['go']['app']['App']['IsMonitoringPaused']();
+}
+
+export function PauseMonitoring(arg1) {
+  return window['go']['app']['App']['PauseMonitoring'](arg1);
+}
+
+export function ResumeMonitoring() {
+  return window['go']['app']['App']['ResumeMonitoring']();
+}
+
 export function Shutdown() {
   return window['go']['app']['App']['Shutdown']();
 }
@@ -0,0 +1,416 @@
+export namespace events {
+	
+	export class EventContext {
+	    application?: string;
+	    bundle_id?: string;
+	    window_title?: string;
+	    file_path?: string;
+	    selection?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new EventContext(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.application = source["application"];
+	        this.bundle_id = source["bundle_id"];
+	        this.window_title = source["window_title"];
+	        this.file_path = source["file_path"];
+	        this.selection = source["selection"];
+	    }
+	}
+	export class Event {
+	    id: string;
+	    type: string;
+	    schema_version?: number;
+	    timestamp: any;
+	    data: {[key: string]: any};
+	    metadata?: {[key: string]: string};
+	    context?: EventContext;
+	    correlation_id?: string;
+	    causation_id?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new Event(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.type = source["type"];
+	        this.schema_version = source["schema_version"];
+	        this.timestamp = this.convertValues(source["timestamp"], null);
+	        this.data = source["data"];
+	        this.metadata = source["metadata"];
+	        this.context = this.convertValues(source["context"], EventContext);
+	        this.correlation_id = source["correlation_id"];
+	        this.causation_id = source["causation_id"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class SubscriberStats {
+	    id: string;
+	    name: string;
+	    topic: string;
+	    policy: string;
+	    buffer_size: number;
+	    delivered: number;
+	    dropped: number;
+	    coalesced: number;
+	    lagging: number;
+	    retried: number;
+	    dead_lettered: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SubscriberStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.topic = source["topic"];
+	        this.policy = source["policy"];
+	        this.buffer_size = source["buffer_size"];
+	        this.delivered = source["delivered"];
+	        this.dropped = source["dropped"];
+	        this.coalesced = source["coalesced"];
+	        this.lagging = source["lagging"];
+	        this.retried = source["retried"];
+	        this.dead_lettered = source["dead_lettered"];
+	    }
+	}
+}
+
+export namespace models {
+	
+	export class AIAnalysis {
+	    ShouldAutomate: boolean;
+	    Reason: string;
+	    EstimatedTimeSaving: number;
+	    Complexity: string;
+	    SuggestedName: string;
+	    SuggestedSteps: string[];
+	    AnalyzedAt: any;
+	    PromptVersion: string;
+	    Provider: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new AIAnalysis(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ShouldAutomate = source["ShouldAutomate"];
+	        this.Reason = source["Reason"];
+	        this.EstimatedTimeSaving = source["EstimatedTimeSaving"];
+	        this.Complexity = source["Complexity"];
+	        this.SuggestedName = source["SuggestedName"];
+	        this.SuggestedSteps = source["SuggestedSteps"];
+	        this.AnalyzedAt = this.convertValues(source["AnalyzedAt"], null);
+	        this.PromptVersion = source["PromptVersion"];
+	        this.Provider = source["Provider"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+}
+
+export namespace services {
+	
+	export class AutomationView {
+	    id: string;
+	    name: string;
+	    description?: string;
+	    pattern_id?: string;
+	    steps: string[];
+	    enabled: boolean;
+	    created_at: any;
+	    updated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new AutomationView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.pattern_id = source["pattern_id"];
+	        this.steps = source["steps"];
+	        this.enabled = source["enabled"];
+	        this.created_at = this.convertValues(source["created_at"], null);
+	        this.updated_at = this.convertValues(source["updated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class BudgetStatus {
+	    daily_limit_usd: number;
+	    daily_spent_usd: number;
+	    monthly_limit_usd: number;
+	    monthly_spent_usd: number;
+	    exceeded: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new BudgetStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.daily_limit_usd = source["daily_limit_usd"];
+	        this.daily_spent_usd = source["daily_spent_usd"];
+	        this.monthly_limit_usd = source["monthly_limit_usd"];
+	        this.monthly_spent_usd = source["monthly_spent_usd"];
+	        this.exceeded = source["exceeded"];
+	    }
+	}
+	export class CreateAutomationRequest {
+	    name: string;
+	    description?: string;
+	    pattern_id?: string;
+	    steps?: string[];
+	    enabled?: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new CreateAutomationRequest(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.description = source["description"];
+	        this.pattern_id = source["pattern_id"];
+	        this.steps = source["steps"];
+	        this.enabled = source["enabled"];
+	    }
+	}
+	export class DashboardData {
+	    total_events: number;
+	    events_by_type: {[key: string]: number};
+	    oldest_event?: any;
+	    newest_event?: any;
+	    total_patterns: number;
+	    analyzed_patterns: number;
+	    automatable_patterns: number;
+	    total_automations: number;
+	    estimated_time_saving: number;
+	    recent_events: events.Event[];
+	    generated_at: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new DashboardData(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.total_events = source["total_events"];
+	        this.events_by_type = source["events_by_type"];
+	        this.oldest_event = this.convertValues(source["oldest_event"], null);
+	        this.newest_event = this.convertValues(source["newest_event"], null);
+	        this.total_patterns = source["total_patterns"];
+	        this.analyzed_patterns = source["analyzed_patterns"];
+	        this.automatable_patterns = source["automatable_patterns"];
+	        this.total_automations = source["total_automations"];
+	        this.estimated_time_saving = source["estimated_time_saving"];
+	        this.recent_events = this.convertValues(source["recent_events"], events.Event);
+	        this.generated_at = this.convertValues(source["generated_at"], null);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class StepView {
+	    type: string;
+	    action?: string;
+	    application?: string;
+	    value?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new StepView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.action = source["action"];
+	        this.application = source["application"];
+	        this.value = source["value"];
+	    }
+	}
+	export class PatternView {
+	    id: string;
+	    description?: string;
+	    support_count: number;
+	    confidence: number;
+	    frequency_per_hour: number;
+	    first_seen: any;
+	    last_seen: any;
+	    is_automated: boolean;
+	    steps: StepView[];
+	    ai_analysis?: models.AIAnalysis;
+	
+	    static createFrom(source: any = {}) {
+	        return new PatternView(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.description = source["description"];
+	        this.support_count = source["support_count"];
+	        this.confidence = source["confidence"];
+	        this.frequency_per_hour = source["frequency_per_hour"];
+	        this.first_seen = this.convertValues(source["first_seen"], null);
+	        this.last_seen = this.convertValues(source["last_seen"], null);
+	        this.is_automated = source["is_automated"];
+	        this.steps = this.convertValues(source["steps"], StepView);
+	        this.ai_analysis = this.convertValues(source["ai_analysis"], models.AIAnalysis);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class UsageTotal {
+	    key: string;
+	    calls: number;
+	    input_tokens: number;
+	    output_tokens: number;
+	    cost_usd: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new UsageTotal(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.key = source["key"];
+	        this.calls = source["calls"];
+	        this.input_tokens = source["input_tokens"];
+	        this.output_tokens = source["output_tokens"];
+	        this.cost_usd = source["cost_usd"];
+	    }
+	}
+	export class UsageReport {
+	    from: any;
+	    to: any;
+	    total: UsageTotal;
+	    by_day: UsageTotal[];
+	    by_provider: UsageTotal[];
+	    budget: BudgetStatus;
+	
+	    static createFrom(source: any = {}) {
+	        return new UsageReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.from = this.convertValues(source["from"], null);
+	        this.to = this.convertValues(source["to"], null);
+	        this.total = this.convertValues(source["total"], UsageTotal);
+	        this.by_day = this.convertValues(source["by_day"], UsageTotal);
+	        this.by_provider = this.convertValues(source["by_provider"], UsageTotal);
+	        this.budget = this.convertValues(source["budget"], BudgetStatus);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+}
+
@@ -0,0 +1,348 @@
+/**
+ * Package api 提供本地 HTTP/JSON API
+ *
+ * 与 Wails 绑定暴露相同的操作（仪表板、模式、事件、创建自动化、AI 花费），
+ * 并通过 Server-Sent Events 推送实时事件流，供编辑器插件、脚本和仪表板集成。
+ * 只允许监听本机回环地址，所有请求都需要携带访问令牌
+ */
+
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/services"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+/**
+ * DefaultAddr API 的默认监听地址
+ */
+const DefaultAddr = "127.0.0.1:9465"
+
+/**
+ * maxRequestBody 请求体大小上限
+ */
+const maxRequestBody = 1 << 20
+
+/**
+ * Server 本地 HTTP API 服务
+ */
+type Server struct {
+	addr     string
+	token    string
+	query    *services.QueryService
+	usage    *services.UsageService
+	bus      *events.EventBus
+	server   *http.Server
+	listener net.Listener
+
+	// baseCtx 所有请求的父上下文，停止时取消以结束事件流连接
+	baseCtx    context.Context
+	cancelBase context.CancelFunc
+
+	// heartbeat 事件流的心跳间隔
+	heartbeat time.Duration
+}
+
+/**
+ * NewServer 创建本地 HTTP API 服务
+ *
+ * Parameters:
+ *   - addr: 监听地址，为空时使用 DefaultAddr，必须是回环地址
+ *   - token: 访问令牌，不能为空
+ *   - query: 查询服务
+ *   - usage: AI 用量服务，为 nil 时 AI 花费接口返回 404
+ *   - bus: 事件总线，用于实时事件流
+ *
+ * Returns:
+ *   - *Server: 服务实例
+ *   - error: 地址不是回环地址或令牌为空时返回错误
+ */
+func NewServer(addr, token string, query *services.QueryService, usage *services.UsageService,
+	bus *events.EventBus) (*Server, error) {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	if token == "" {
+		return nil, fmt.Errorf("API 访问令牌不能为空")
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("解析 API 监听地址失败: %w", err)
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("API 只能监听本机回环地址: %s", addr)
+		}
+	}
+
+	s := &Server{
+		addr:      addr,
+		token:     token,
+		query:     query,
+		usage:     usage,
+		bus:       bus,
+		heartbeat: 15 * time.Second,
+	}
+	s.baseCtx, s.cancelBase = context.WithCancel(context.Background())
+	s.server = &http.Server{
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+		BaseContext: func(net.Listener) context.Context {
+			return s.baseCtx
+		},
+	}
+	return s, nil
+}
+
+/**
+ * Handler 返回带令牌校验的 HTTP 处理器
+ *
+ * Returns:
+ *   - http.Handler: 处理器
+ */
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/dashboard", s.handleDashboard)
+	mux.HandleFunc("GET /api/patterns", s.handlePatterns)
+	mux.HandleFunc("GET /api/patterns/{id}", s.handlePattern)
+	mux.HandleFunc("GET /api/events", s.handleEvents)
+	mux.HandleFunc("GET /api/automations", s.handleAutomations)
+	mux.HandleFunc("POST /api/automations", s.handleCreateAutomation)
+	mux.HandleFunc("GET /api/ai/usage", s.handleAIUsage)
+	mux.HandleFunc("GET /api/stream", s.handleStream)
+	return s.authenticate(mux)
+}
+
+/**
+ * Start 开始监听并在后台提供服务
+ *
+ * Returns:
+ *   - error: 监听失败时返回错误
+ */
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("监听 API 端口失败: %w", err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("API 服务异常退出",
+				zap.String("component", "api"),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	logger.Info("本地 API 已启动",
+		zap.String("component", "api"),
+		zap.String("addr", listener.Addr().String()),
+	)
+	return nil
+}
+
+/**
+ * Addr 获取实际监听地址
+ *
+ * Returns:
+ *   - string: 监听地址，未启动时为配置的地址
+ */
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+/**
+ * Stop 停止服务
+ *
+ * 先取消所有请求的上下文，让事件流连接退出，再等待其余请求完成
+ *
+ * Parameters:
+ *   - ctx: 上下文，用于限制等待时间
+ *
+ * Returns:
+ *   - error: 关闭失败时返回错误
+ */
+func (s *Server) Stop(ctx context.Context) error {
+	s.cancelBase()
+	return s.server.Shutdown(ctx)
+}
+
+/**
+ * authenticate 校验访问令牌
+ *
+ * 令牌通过 Authorization: Bearer 头传递；浏览器的 EventSource 无法设置请求头，
+ * 因此也接受 token 查询参数
+ */
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := r.URL.Query().Get("token")
+		if header := r.Header.Get("Authorization"); header != "" {
+			bearer, ok := strings.CutPrefix(header, "Bearer ")
+			if !ok {
+				bearer = ""
+			}
+			token = bearer
+		}
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="flowmind"`)
+			writeError(w, http.StatusUnauthorized, errors.New("访问令牌无效"))
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
+	data, err := s.query.Dashboard()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, data)
+}
+
+func (s *Server) handlePatterns(w http.ResponseWriter, r *http.Request) {
+	patterns, err := s.query.Patterns()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, patterns)
+}
+
+func (s *Server) handlePattern(w http.ResponseWriter, r *http.Request) {
+	pattern, err := s.query.Pattern(r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, pattern)
+}
+
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	limit := 0
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("limit 必须是整数: %q", value))
+			return
+		}
+		limit = parsed
+	}
+
+	list, err := s.query.RecentEvents(limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleAutomations(w http.ResponseWriter, r *http.Request) {
+	automations, err := s.query.Automations()
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, automations)
+}
+
+func (s *Server) handleCreateAutomation(w http.ResponseWriter, r *http.Request) {
+	var req services.CreateAutomationRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("解析请求体失败: %w", err))
+		return
+	}
+
+	automation, err := s.query.CreateAutomation(req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusCreated, automation)
+}
+
+func (s *Server) handleAIUsage(w http.ResponseWriter, r *http.Request) {
+	if s.usage == nil {
+		writeError(w, http.StatusNotFound, errors.New("AI 用量统计不可用"))
+		return
+	}
+
+	days := 0
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("days 必须是整数: %q", value))
+			return
+		}
+		days = parsed
+	}
+
+	report, err := s.usage.Report(days)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, report)
+}
+
+/**
+ * errorResponse 错误响应
+ */
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+/**
+ * writeServiceError 按查询服务的错误类型选择状态码
+ */
+func writeServiceError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, services.ErrInvalidRequest):
+		writeError(w, http.StatusBadRequest, err)
+	case errors.Is(err, services.ErrNotFound):
+		writeError(w, http.StatusNotFound, err)
+	default:
+		logger.Warn("处理 API 请求失败",
+			zap.String("component", "api"),
+			zap.Error(err),
+		)
+		writeError(w, http.StatusInternalServerError, err)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, value interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		logger.Debug("写入 API 响应失败",
+			zap.String("component", "api"),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,237 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/internal/services"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testToken = "secret-token"
+
+// newTestServer 创建使用临时数据库的 API 服务
+func newTestServer(t *testing.T) (*Server, *events.EventBus) {
+	t.Helper()
+
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/test.db"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, storage.RunMigrations(db))
+
+	eventRepo := storage.NewSQLiteEventRepository(db)
+	patternRepo := storage.NewSQLitePatternRepository(db)
+	require.NoError(t, eventRepo.Save(*events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"content": "hello"})))
+	require.NoError(t, patternRepo.Save(&models.Pattern{
+		ID:           "pattern-copy",
+		Sequence:     []models.EventStep{{Type: events.EventTypeClipboard, Action: "clipboard_copy"}},
+		SupportCount: 5,
+		FirstSeen:    time.Now().Add(-time.Hour),
+		LastSeen:     time.Now(),
+		AIAnalysis:   &models.AIAnalysis{ShouldAutomate: true, SuggestedName: "复制"},
+	}))
+
+	bus := events.NewEventBus()
+	t.Cleanup(func() { bus.Stop(time.Second) })
+
+	usageRepo := storage.NewSQLiteAIUsageRepository(db)
+	require.NoError(t, usageRepo.Save(&models.AIUsage{
+		Provider:     "openai",
+		Model:        "gpt-4o-mini",
+		PatternID:    "pattern-copy",
+		Purpose:      "pattern_analysis",
+		InputTokens:  1000,
+		OutputTokens: 100,
+		CostUSD:      0.0002,
+		CreatedAt:    time.Now().Add(-time.Minute),
+	}))
+
+	query := services.NewQueryService(eventRepo, patternRepo, storage.NewSQLiteAutomationRepository(db))
+	usage := services.NewUsageService(usageRepo, nil, services.BudgetConfig{DailyUSD: 1})
+	server, err := NewServer("127.0.0.1:0", testToken, query, usage, bus)
+	require.NoError(t, err)
+	return server, bus
+}
+
+// do 发送带令牌的请求并解析 JSON 响应
+func do(t *testing.T, handler http.Handler, method, path, body string, out interface{}) int {
+	t.Helper()
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if out != nil {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), out), rec.Body.String())
+	}
+	return rec.Code
+}
+
+// TestNewServer_Validation 测试只允许回环地址且必须有令牌
+func TestNewServer_Validation(t *testing.T) {
+	for _, addr := range []string{"", "127.0.0.1:0", "localhost:9465", "[::1]:9465"} {
+		_, err := NewServer(addr, testToken, nil, nil, nil)
+		assert.NoError(t, err, addr)
+	}
+	for _, addr := range []string{"0.0.0.0:9465", "192.168.1.2:9465", "example.com:80", "9465"} {
+		_, err := NewServer(addr, testToken, nil, nil, nil)
+		assert.Error(t, err, addr)
+	}
+	_, err := NewServer("", "", nil, nil, nil)
+	assert.Error(t, err)
+}
+
+// TestServer_Auth 测试令牌校验
+func TestServer_Auth(t *testing.T) {
+	server, _ := newTestServer(t)
+	handler := server.Handler()
+
+	for _, header := range []string{"", "Bearer wrong", testToken} {
+		req := httptest.NewRequest(http.MethodGet, "/api/dashboard", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, http.StatusUnauthorized, rec.Code, header)
+		assert.Contains(t, rec.Body.String(), "访问令牌无效")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/dashboard?token="+testToken, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusOK, rec.Code)
+}
+
+// TestServer_Endpoints 测试与 Wails 绑定对应的接口
+func TestServer_Endpoints(t *testing.T) {
+	server, _ := newTestServer(t)
+	handler := server.Handler()
+
+	var dashboard services.DashboardData
+	require.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/api/dashboard", "", &dashboard))
+	assert.Equal(t, int64(1), dashboard.TotalEvents)
+	assert.Equal(t, 1, dashboard.TotalPatterns)
+
+	var patterns []services.PatternView
+	require.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/api/patterns", "", &patterns))
+	require.Len(t, patterns, 1)
+	assert.Equal(t, "pattern-copy", patterns[0].ID)
+
+	var errResp errorResponse
+	assert.Equal(t, http.StatusNotFound, do(t, handler, http.MethodGet, "/api/patterns/missing", "", &errResp))
+	assert.NotEmpty(t, errResp.Error)
+
+	var list []events.Event
+	require.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/api/events?limit=10", "", &list))
+	require.Len(t, list, 1)
+	assert.Equal(t, "hello", list[0].Data["content"])
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodGet, "/api/events?limit=abc", "", nil))
+
+	var created services.AutomationView
+	require.Equal(t, http.StatusCreated, do(t, handler, http.MethodPost, "/api/automations",
+		`{"pattern_id":"pattern-copy","steps":["复制","粘贴"]}`, &created))
+	assert.Equal(t, "复制", created.Name)
+	assert.Equal(t, "pattern-copy", created.PatternID)
+
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodPost, "/api/automations", `{}`, nil))
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodPost, "/api/automations", `{"nmae":"typo"}`, nil))
+	assert.Equal(t, http.StatusNotFound, do(t, handler, http.MethodPost, "/api/automations",
+		`{"name":"x","pattern_id":"missing"}`, nil))
+
+	var automations []services.AutomationView
+	require.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/api/automations", "", &automations))
+	assert.Len(t, automations, 1)
+
+	var pattern services.PatternView
+	require.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/api/patterns/pattern-copy", "", &pattern))
+	assert.True(t, pattern.IsAutomated)
+
+	var report services.UsageReport
+	require.Equal(t, http.StatusOK, do(t, handler, http.MethodGet, "/api/ai/usage?days=7", "", &report))
+	assert.Equal(t, 1, report.Total.Calls)
+	require.Len(t, report.ByProvider, 1)
+	assert.Equal(t, "openai", report.ByProvider[0].Key)
+	assert.Equal(t, 1.0, report.Budget.DailyLimitUSD)
+	assert.Equal(t, http.StatusBadRequest, do(t, handler, http.MethodGet, "/api/ai/usage?days=x", "", nil))
+
+	assert.Equal(t, http.StatusMethodNotAllowed, do(t, handler, http.MethodDelete, "/api/patterns", "", nil))
+}
+
+// TestServer_Stream 测试 Server-Sent Events 实时事件流
+func TestServer_Stream(t *testing.T) {
+	server, bus := newTestServer(t)
+	require.NoError(t, server.Start())
+
+	req, err := http.NewRequest(http.MethodGet, "http://"+server.Addr()+"/api/stream?types=clipboard", nil)
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+testToken)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	line, err := reader.ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, ": connected\n", line)
+
+	keyboard := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"keycode": 1})
+	clipboard := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"content": "streamed"})
+	require.NoError(t, bus.Publish(string(keyboard.Type), *keyboard))
+	require.NoError(t, bus.Publish(string(clipboard.Type), *clipboard))
+
+	// 只收到订阅类型的事件
+	var received []string
+	for len(received) < 3 {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		if strings.HasPrefix(line, "id: ") || strings.HasPrefix(line, "event: ") || strings.HasPrefix(line, "data: ") {
+			received = append(received, strings.TrimSpace(line))
+		}
+	}
+	assert.Equal(t, "id: "+clipboard.ID, received[0])
+	assert.Equal(t, "event: clipboard", received[1])
+	assert.Contains(t, received[2], `"content":"streamed"`)
+
+	// 停止服务时事件流连接随之结束
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, server.Stop(ctx))
+	_, err = reader.ReadString('\n')
+	for err == nil {
+		_, err = reader.ReadString('\n')
+	}
+}
+
+// TestLoadOrCreateToken 测试生成并复用令牌文件
+func TestLoadOrCreateToken(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "api_token")
+
+	token, err := LoadOrCreateToken(path)
+	require.NoError(t, err)
+	assert.Len(t, token, 64)
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	again, err := LoadOrCreateToken(path)
+	require.NoError(t, err)
+	assert.Equal(t, token, again)
+
+	_, err = LoadOrCreateToken("")
+	assert.Error(t, err)
+}
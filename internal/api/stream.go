@@ -0,0 +1,107 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+/**
+ * handleStream 以 Server-Sent Events 推送实时事件
+ *
+ * 每个事件输出为 id（事件 ID）、event（事件类型）和 data（事件 JSON）三行，
+ * 空闲时定期输出注释行作为心跳。types 查询参数可以用逗号分隔只订阅部分事件类型。
+ * 客户端读取过慢时由事件总线丢弃最旧的事件，不影响其他订阅者
+ */
+func (s *Server) handleStream(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, errors.New("连接不支持流式输出"))
+		return
+	}
+	if s.bus == nil {
+		writeError(w, http.StatusServiceUnavailable, errors.New("事件总线不可用"))
+		return
+	}
+
+	var opts []events.SubscribeOption
+	opts = append(opts, events.WithDropOldest(), events.WithName("api_stream"))
+	if types := parseTypes(r.URL.Query().Get("types")); len(types) > 0 {
+		opts = append(opts, events.WithFilter(func(event events.Event) bool {
+			return types[string(event.Type)]
+		}))
+	}
+
+	ctx := r.Context()
+	stream := make(chan events.Event, 16)
+	subscriberID := s.bus.SubscribeWithOptions("*", func(event events.Event) error {
+		select {
+		case stream <- event:
+		case <-ctx.Done():
+		}
+		return nil
+	}, opts...)
+	defer s.bus.Unsubscribe(subscriberID)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprint(w, ": connected\n\n")
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(s.heartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case event := <-stream:
+			if err := writeStreamEvent(w, event); err != nil {
+				logger.Debug("写入事件流失败",
+					zap.String("component", "api"),
+					zap.Error(err),
+				)
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+/**
+ * writeStreamEvent 按 SSE 格式写入一个事件
+ */
+func writeStreamEvent(w http.ResponseWriter, event events.Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
+
+/**
+ * parseTypes 解析逗号分隔的事件类型列表
+ */
+func parseTypes(value string) map[string]bool {
+	types := make(map[string]bool)
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			types[item] = true
+		}
+	}
+	return types
+}
@@ -0,0 +1,53 @@
+package api
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+/**
+ * LoadOrCreateToken 读取访问令牌文件，不存在时生成新令牌并保存
+ *
+ * 令牌文件只有当前用户可读写，脚本和插件可以从同一文件读取令牌
+ *
+ * Parameters:
+ *   - path: 令牌文件路径，支持环境变量（如 ${HOME}）
+ *
+ * Returns:
+ *   - string: 访问令牌
+ *   - error: 读取或写入失败时返回错误
+ */
+func LoadOrCreateToken(path string) (string, error) {
+	path = os.ExpandEnv(path)
+	if path == "" {
+		return "", errors.New("未配置令牌文件路径")
+	}
+
+	data, err := os.ReadFile(path)
+	if err == nil {
+		if token := strings.TrimSpace(string(data)); token != "" {
+			return token, nil
+		}
+	} else if !errors.Is(err, os.ErrNotExist) {
+		return "", fmt.Errorf("读取令牌文件失败: %w", err)
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成访问令牌失败: %w", err)
+	}
+	token := hex.EncodeToString(buf)
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("创建令牌目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("保存令牌文件失败: %w", err)
+	}
+	return token, nil
+}
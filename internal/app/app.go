@@ -13,11 +13,13 @@ package app
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
 	"github.com/chenyang-zz/flowmind/internal/domain/monitor"
 	"github.com/chenyang-zz/flowmind/pkg/events"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/services"
 	"github.com/wailsapp/wails/v2/pkg/runtime"
 	"go.uber.org/zap"
 )
@@ -42,8 +44,12 @@ type App struct {
 	eventBus *events.EventBus
 
 	// monitorEngine 是监控引擎
-	// 负责键盘、剪贴板、应用切换等监控
-	monitorEngine monitor.Monitor
+	// 负责键盘、剪贴板、应用切换等监控，支持暂停和恢复
+	monitorEngine *monitor.Engine
+
+	// services 后台服务集合
+	// 与命令行工具共用同一套装配逻辑，包括存储、批量写入器、分析引擎和指标端点
+	services *Services
 
 	// ========== 依赖注入的服务 ==========
 	//
@@ -76,21 +82,32 @@ type App struct {
  * New 创建一个新的 App 实例
  *
  * 这是 App 的构造函数，负责初始化应用实例
+ * 配置加载失败时使用默认配置，监控引擎按配置中的 monitor 段创建
  * 后续会使用 Wire 进行依赖注入
  *
  * Returns:
  *   - *App: 初始化好的 App 实例
  */
 func New() *App {
-	// 初始化事件总线
-	eventBus := events.NewEventBus()
+	// 加载配置
+	cfg, err := config.Load()
+	if err != nil {
+		logger.Warn("加载配置失败，使用默认配置", zap.Error(err))
+		cfg, _ = config.LoadDefault()
+	}
 
-	// 初始化监控引擎
-	monitorEngine := monitor.NewEngine(eventBus)
+	// 装配服务，数据库不可用时事件只在内存中流转
+	services, err := NewServices(cfg)
+	if err != nil {
+		logger.Error("初始化存储失败，事件不会持久化", zap.Error(err))
+		services = newServicesWithoutStorage(cfg)
+	}
 
 	return &App{
-		eventBus:      eventBus,
-		monitorEngine: monitorEngine,
+		config:        cfg,
+		eventBus:      services.EventBus,
+		monitorEngine: services.MonitorEngine,
+		services:      services,
 	}
 }
 
@@ -115,11 +132,8 @@ func (a *App) Startup(ctx context.Context) error {
 	// 保存上下文
 	a.ctx = ctx
 
-	// TODO: 加载配置
-	// a.config = config.Load()
-
-	// 启动监控引擎
-	if err := a.monitorEngine.Start(); err != nil {
+	// 启动后台服务（持久化、监控引擎、分析引擎、指标端点）
+	if err := a.services.Start(); err != nil {
 		logger.Error("启动监控引擎失败", zap.Error(err))
 		return fmt.Errorf("failed to start monitor engine: %w", err)
 	}
@@ -144,9 +158,9 @@ func (a *App) Startup(ctx context.Context) error {
 func (a *App) Shutdown() {
 	logger.Info("应用关闭中")
 
-	// 停止监控引擎
-	if a.monitorEngine != nil {
-		_ = a.monitorEngine.Stop()
+	// 停止后台服务，写入缓冲区中的事件并关闭数据库
+	if a.services != nil {
+		a.services.Close()
 	}
 
 	// TODO: 保存应用状态
@@ -163,62 +177,56 @@ func (a *App) Shutdown() {
  * GetDashboardData 获取仪表板数据
  *
  * 这是一个导出方法，前端可以直接调用
- * 用于获取仪表板显示的统计数据和图表数据
+ * 用于获取仪表板显示的事件、模式和自动化统计以及最近事件
  *
  * Returns:
- *   - map[string]interface{}: 仪表板数据
- *   - error: 错误信息
+ *   - *services.DashboardData: 仪表板数据
+ *   - error: 存储不可用或查询失败时返回错误
  */
-func (a *App) GetDashboardData() (map[string]interface{}, error) {
-	// TODO: 实现获取仪表板数据的逻辑
-	// return a.analyzerSvc.GetDashboardData(context.Background())
-
-	// 临时返回模拟数据
-	return map[string]interface{}{
-		"totalEvents":      0,
-		"totalPatterns":    0,
-		"totalAutomations": 0,
-	}, nil
+func (a *App) GetDashboardData() (*services.DashboardData, error) {
+	query, err := a.query()
+	if err != nil {
+		return nil, err
+	}
+	return query.Dashboard()
 }
 
 /**
  * CreateAutomation 创建自动化
  *
  * 这是一个导出方法，前端可以直接调用
- * 根据用户需求创建新的自动化脚本
+ * 根据模式或用户填写的步骤创建自动化，关联的模式会被标记为已自动化
  *
  * Parameters:
  *   - req: 创建自动化的请求
  *
  * Returns:
- *   - map[string]interface{}: 创建的自动化对象
- *   - error: 错误信息
+ *   - *services.AutomationView: 创建的自动化
+ *   - error: 参数无效、模式不存在或存储不可用时返回错误
  */
-func (a *App) CreateAutomation(req map[string]interface{}) (map[string]interface{}, error) {
-	// TODO: 实现创建自动化的逻辑
-	// return a.autoSvc.CreateAutomation(context.Background(), req)
-
-	// 临时返回模拟数据
-	return map[string]interface{}{
-		"id":   "1",
-		"name": req["name"],
-	}, nil
+func (a *App) CreateAutomation(req services.CreateAutomationRequest) (*services.AutomationView, error) {
+	query, err := a.query()
+	if err != nil {
+		return nil, err
+	}
+	return query.CreateAutomation(req)
 }
 
 /**
  * GetPatterns 获取已识别的模式列表
  *
- * 前端可以直接调用此方法获取所有已识别的工作流模式
+ * 前端可以直接调用此方法获取所有已识别的工作流模式，按出现次数降序
  *
  * Returns:
- *   - []map[string]interface{}: 模式列表
- *   - error: 错误信息
+ *   - []services.PatternView: 模式列表
+ *   - error: 存储不可用或查询失败时返回错误
  */
-func (a *App) GetPatterns() ([]map[string]interface{}, error) {
-	// TODO: 实现获取模式列表的逻辑
-	// return a.analyzerSvc.GetPatterns(context.Background())
-
-	return []map[string]interface{}{}, nil
+func (a *App) GetPatterns() ([]services.PatternView, error) {
+	query, err := a.query()
+	if err != nil {
+		return nil, err
+	}
+	return query.Patterns()
 }
 
 /**
@@ -227,21 +235,102 @@ func (a *App) GetPatterns() ([]map[string]interface{}, error) {
  * 前端可以直接调用此方法获取系统事件
  *
  * Parameters:
- *   - limit: 返回的最大事件数量
+ *   - limit: 返回的最大事件数量，小于等于 0 时返回最近 100 条
  *
  * Returns:
- *   - []map[string]interface{}: 事件列表
- *   - error: 错误信息
+ *   - []events.Event: 按时间升序排列的事件列表
+ *   - error: 存储不可用或查询失败时返回错误
  */
-func (a *App) GetEvents(limit int) ([]map[string]interface{}, error) {
-	// TODO: 实现获取事件的逻辑
-	// return a.monitorSvc.GetEvents(context.Background(), limit)
+func (a *App) GetEvents(limit int) ([]events.Event, error) {
+	query, err := a.query()
+	if err != nil {
+		return nil, err
+	}
+	return query.RecentEvents(limit)
+}
 
-	return []map[string]interface{}{}, nil
+/**
+ * GetAIUsage 获取 AI 调用的花费
+ *
+ * 前端可以直接调用此方法查看按天和按提供商统计的 token 用量、费用和预算使用情况
+ *
+ * Parameters:
+ *   - days: 包含今天在内的统计天数，小于等于 0 时统计最近 30 天
+ *
+ * Returns:
+ *   - *services.UsageReport: 花费统计
+ *   - error: 存储不可用或查询失败时返回错误
+ */
+func (a *App) GetAIUsage(days int) (*services.UsageReport, error) {
+	if a.services == nil || a.services.Usage == nil {
+		return nil, fmt.Errorf("存储不可用，无法查询数据")
+	}
+	return a.services.Usage.Report(days)
+}
+
+/**
+ * PauseMonitoring 暂停监控
+ *
+ * 前端可以直接调用此方法暂停监控（隐私保护），到期后自动恢复
+ *
+ * Parameters:
+ *   - minutes: 暂停的分钟数，小于等于 0 表示一直暂停直到手动恢复
+ *
+ * Returns:
+ *   - error: 监控引擎未运行时返回错误
+ */
+func (a *App) PauseMonitoring(minutes int) error {
+	return a.monitorEngine.Pause(time.Duration(minutes) * time.Minute)
+}
+
+/**
+ * ResumeMonitoring 恢复监控
+ *
+ * Returns:
+ *   - error: 监控未暂停时返回错误
+ */
+func (a *App) ResumeMonitoring() error {
+	return a.monitorEngine.Resume()
+}
+
+/**
+ * IsMonitoringPaused 查询监控是否已暂停
+ *
+ * Returns:
+ *   - bool: true 表示监控已暂停
+ */
+func (a *App) IsMonitoringPaused() bool {
+	return a.monitorEngine.IsPaused()
+}
+
+/**
+ * GetEventBusStats 获取事件总线各订阅者的交付统计
+ *
+ * 包括已交付、丢弃、合并和积压的事件数，用于观察事件丢失情况
+ *
+ * Returns:
+ *   - []events.SubscriberStats: 订阅者统计列表
+ */
+func (a *App) GetEventBusStats() []events.SubscriberStats {
+	return a.eventBus.Stats()
 }
 
 // ========== 私有方法 ==========
 
+/**
+ * query 获取查询服务
+ *
+ * Returns:
+ *   - *services.QueryService: 查询服务
+ *   - error: 数据库不可用时返回错误
+ */
+func (a *App) query() (*services.QueryService, error) {
+	if a.services == nil || a.services.Query == nil {
+		return nil, fmt.Errorf("存储不可用，无法查询数据")
+	}
+	return a.services.Query, nil
+}
+
 /**
  * forwardEvents 转发后端事件到前端
  *
@@ -251,8 +340,8 @@ func (a *App) GetEvents(limit int) ([]map[string]interface{}, error) {
 func (a *App) forwardEvents() {
 	logger.Info("启动事件转发服务")
 
-	// 订阅所有事件
-	subscriberID := a.eventBus.Subscribe("*", func(event events.Event) error {
+	// 订阅所有事件，前端只关心最新状态，积压时丢弃最旧的事件
+	subscriberID := a.eventBus.SubscribeWithOptions("*", func(event events.Event) error {
 		logger.Debug("转发事件到前端",
 			zap.String("type", string(event.Type)),
 		)
@@ -260,7 +349,7 @@ func (a *App) forwardEvents() {
 		// 将事件推送到前端
 		runtime.EventsEmit(a.ctx, string(event.Type), event)
 		return nil
-	})
+	}, events.WithDropOldest())
 
 	// 保持订阅活跃
 	<-a.ctx.Done()
@@ -0,0 +1,795 @@
+package app
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/api"
+	"github.com/chenyang-zz/flowmind/internal/domain/analyzer"
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/domain/monitor"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/ai"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/cache"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/metrics"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/internal/mcp"
+	persistence "github.com/chenyang-zz/flowmind/internal/monitor"
+	"github.com/chenyang-zz/flowmind/internal/services"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+/**
+ * Services 应用服务集合
+ *
+ * 负责创建和连接后台服务：事件总线、监控引擎、存储、批量写入器、分析引擎、指标端点、本地 API 和 MCP 服务。
+ * Wails 应用和命令行工具共用同一套装配逻辑
+ */
+type Services struct {
+	// Config 应用配置
+	Config *config.Config
+
+	// EventBus 事件总线
+	EventBus *events.EventBus
+
+	// MonitorEngine 监控引擎
+	MonitorEngine *monitor.Engine
+
+	// DB 数据库连接，存储不可用时为 nil
+	DB *sql.DB
+
+	// EventRepo 事件仓储
+	EventRepo *storage.SQLiteEventRepository
+
+	// SessionRepo 会话仓储
+	SessionRepo *storage.SQLiteSessionRepository
+
+	// PatternRepo 模式仓储
+	PatternRepo *storage.SQLitePatternRepository
+
+	// AutomationRepo 自动化仓储
+	AutomationRepo *storage.SQLiteAutomationRepository
+
+	// AIUsageRepo AI 用量仓储
+	AIUsageRepo *storage.SQLiteAIUsageRepository
+
+	// DeadLetters 死信存储
+	DeadLetters *storage.SQLiteDeadLetterStore
+
+	// BatchWriter 批量写入器
+	BatchWriter *storage.BatchWriter
+
+	// Analyzer 分析引擎
+	Analyzer *analyzer.AnalyzerEngine
+
+	// Query 查询服务，存储不可用时为 nil
+	Query *services.QueryService
+
+	// Usage AI 用量和预算服务，存储不可用时为 nil
+	Usage *services.UsageService
+
+	// MetricsServer 指标端点，未启用时为 nil
+	MetricsServer *metrics.Server
+
+	// APIServer 本地 HTTP API，未启用或存储不可用时为 nil
+	APIServer *api.Server
+
+	// MCPServer MCP 的本机 HTTP 传输，未启用或存储不可用时为 nil
+	MCPServer *mcp.HTTPServer
+
+	// persistenceSub 持久化订阅者 ID
+	persistenceSub string
+
+	// started 是否已启动后台服务
+	started bool
+}
+
+/**
+ * NewServices 创建应用服务集合
+ *
+ * 打开数据库并执行迁移，失败时返回错误
+ *
+ * Parameters:
+ *   - cfg: 应用配置
+ *
+ * Returns:
+ *   - *Services: 服务集合
+ *   - error: 打开数据库失败时返回错误
+ */
+func NewServices(cfg *config.Config) (*Services, error) {
+	db, err := openDatabase(cfg.Storage.SQLite)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildServices(cfg, db, true), nil
+}
+
+/**
+ * NewQueryServices 创建不含网络服务的服务集合
+ *
+ * 供命令行查询等一次性命令使用，不创建指标端点、本地 API 和 MCP 服务，
+ * 因此不会生成令牌文件
+ *
+ * Parameters:
+ *   - cfg: 应用配置
+ *
+ * Returns:
+ *   - *Services: 服务集合
+ *   - error: 打开数据库失败时返回错误
+ */
+func NewQueryServices(cfg *config.Config) (*Services, error) {
+	db, err := openDatabase(cfg.Storage.SQLite)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildServices(cfg, db, false), nil
+}
+
+/**
+ * newServicesWithoutStorage 创建不带存储的服务集合
+ *
+ * 数据库不可用时使用，只有事件总线和监控引擎，事件不会持久化
+ *
+ * Parameters:
+ *   - cfg: 应用配置
+ *
+ * Returns:
+ *   - *Services: 服务集合
+ */
+func newServicesWithoutStorage(cfg *config.Config) *Services {
+	return buildServices(cfg, nil, true)
+}
+
+/**
+ * buildServices 按配置装配服务
+ *
+ * Parameters:
+ *   - cfg: 应用配置
+ *   - db: 数据库连接，为 nil 时跳过存储相关服务
+ *   - withServers: 是否创建指标端点、本地 API 和 MCP 服务
+ *
+ * Returns:
+ *   - *Services: 服务集合
+ */
+func buildServices(cfg *config.Config, db *sql.DB, withServers bool) *Services {
+	s := &Services{Config: cfg, DB: db}
+
+	// 事件总线
+	busOpts := []events.Option{events.WithObserver(metrics.BusObserver{})}
+	if cfg.Monitor.EventBufferSize > 0 {
+		busOpts = append(busOpts, events.WithAsyncBufferSize(cfg.Monitor.EventBufferSize))
+	}
+	if db != nil {
+		s.DeadLetters = storage.NewSQLiteDeadLetterStore(db)
+		busOpts = append(busOpts, events.WithDeadLetterStore(s.DeadLetters))
+	}
+	s.EventBus = events.NewEventBus(busOpts...)
+
+	// 监控引擎
+	s.MonitorEngine = monitor.NewEngineWithConfig(s.EventBus, cfg, nil)
+
+	// 存储和分析
+	if db != nil {
+		s.EventRepo = storage.NewSQLiteEventRepository(db)
+		s.SessionRepo = storage.NewSQLiteSessionRepository(db)
+		s.PatternRepo = storage.NewSQLitePatternRepository(db)
+		s.AutomationRepo = storage.NewSQLiteAutomationRepository(db)
+		s.AIUsageRepo = storage.NewSQLiteAIUsageRepository(db)
+		s.Query = services.NewQueryService(s.EventRepo, s.PatternRepo, s.AutomationRepo)
+		s.Usage = newUsageService(cfg.AI.Budget, s.AIUsageRepo)
+		s.BatchWriter = storage.NewBatchWriter(s.EventRepo, storage.DefaultBatchWriterConfig())
+
+		analyzerEngine, err := analyzer.NewAnalyzerEngine(analyzerConfig(cfg, s.EventBus, s.Usage, db),
+			s.EventRepo, s.PatternRepo, s.SessionRepo, s.EventBus)
+		if err != nil {
+			logger.Warn("创建分析引擎失败", zap.Error(err))
+		} else {
+			s.Analyzer = analyzerEngine
+		}
+	}
+
+	// 指标
+	metrics.Default.Register(metrics.BusCollector(s.EventBus))
+	metrics.Default.Register(metrics.DroppedCollector(s.MonitorEngine.Gate().DroppedCounts))
+	if s.BatchWriter != nil {
+		metrics.Default.Register(metrics.BatchWriterCollector(s.BatchWriter))
+	}
+	if s.Analyzer != nil {
+		if stats := s.Analyzer.CacheStats(); stats != nil {
+			metrics.Default.Register(metrics.CacheCollector("ai_pattern_analysis", stats))
+		}
+	}
+	if !withServers {
+		return s
+	}
+	if cfg.Metrics.Enabled {
+		server, err := metrics.NewServer(cfg.Metrics.Addr, metrics.Default)
+		if err != nil {
+			logger.Warn("指标端点配置无效，已禁用", zap.Error(err))
+		} else {
+			s.MetricsServer = server
+		}
+	}
+
+	// 本地 API
+	if cfg.API.Enabled && s.Query != nil {
+		server, err := newAPIServer(cfg.API, s.Query, s.Usage, s.EventBus)
+		if err != nil {
+			logger.Warn("本地 API 配置无效，已禁用", zap.Error(err))
+		} else {
+			s.APIServer = server
+		}
+	}
+
+	// MCP
+	if cfg.MCP.Enabled && db != nil {
+		server, err := s.NewMCPHTTPServer("")
+		if err != nil {
+			logger.Warn("MCP 配置无效，已禁用", zap.Error(err))
+		} else {
+			s.MCPServer = server
+		}
+	}
+
+	return s
+}
+
+/**
+ * Start 启动后台服务
+ *
+ * 依次启动批量写入器和持久化订阅者、监控引擎、分析引擎、指标端点、本地 API 和 MCP 服务。
+ * 只有监控引擎启动失败会返回错误，其余服务失败时记录日志后继续
+ *
+ * Returns:
+ *   - error: 监控引擎启动失败时返回错误
+ */
+func (s *Services) Start() error {
+	if s.BatchWriter != nil {
+		s.BatchWriter.Start()
+		_, s.persistenceSub = persistence.SubscribePersistence(
+			s.EventBus, s.BatchWriter, persistence.DefaultPersistenceConfig())
+	}
+
+	if err := s.MonitorEngine.Start(); err != nil {
+		s.stopStorage()
+		return fmt.Errorf("启动监控引擎失败: %w", err)
+	}
+	s.started = true
+
+	if s.Analyzer != nil {
+		if err := s.Analyzer.Start(); err != nil {
+			logger.Warn("启动分析引擎失败", zap.Error(err))
+		}
+	}
+
+	if s.MetricsServer != nil {
+		if err := s.MetricsServer.Start(); err != nil {
+			logger.Warn("启动指标端点失败", zap.Error(err))
+			s.MetricsServer = nil
+		}
+	}
+
+	if s.APIServer != nil {
+		if err := s.APIServer.Start(); err != nil {
+			logger.Warn("启动本地 API 失败", zap.Error(err))
+			s.APIServer = nil
+		}
+	}
+
+	if s.MCPServer != nil {
+		if err := s.MCPServer.Start(); err != nil {
+			logger.Warn("启动 MCP 服务失败", zap.Error(err))
+			s.MCPServer = nil
+		}
+	}
+
+	return nil
+}
+
+/**
+ * Close 停止后台服务并关闭数据库
+ *
+ * 按启动的相反顺序停止，批量写入器停止前会把缓冲区写入数据库
+ */
+func (s *Services) Close() {
+	if s.started {
+		if s.Analyzer != nil && s.Analyzer.IsRunning() {
+			_ = s.Analyzer.Stop()
+		}
+		_ = s.MonitorEngine.Stop()
+		s.started = false
+	}
+
+	if s.MCPServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_ = s.MCPServer.Stop(ctx)
+		cancel()
+	}
+
+	if s.APIServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_ = s.APIServer.Stop(ctx)
+		cancel()
+	}
+
+	if s.MetricsServer != nil {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+		_ = s.MetricsServer.Stop(ctx)
+		cancel()
+	}
+
+	s.stopStorage()
+
+	if s.Analyzer != nil {
+		_ = s.Analyzer.Close()
+	}
+	if s.DB != nil {
+		if err := s.DB.Close(); err != nil {
+			logger.Warn("关闭数据库失败", zap.Error(err))
+		}
+	}
+}
+
+/**
+ * NewMCP 创建基于本地数据库的 MCP 服务
+ *
+ * 使用事件、模式和会话仓储，按监控引擎的隐私策略过滤，按配置授予权限范围
+ *
+ * Returns:
+ *   - *mcp.Server: MCP 服务
+ *   - error: 存储不可用或权限范围配置无效时返回错误
+ */
+func (s *Services) NewMCP() (*mcp.Server, error) {
+	if s.DB == nil {
+		return nil, fmt.Errorf("存储不可用，无法提供 MCP 服务")
+	}
+	scopes, err := mcp.ParseScopes(s.Config.MCP.Scopes)
+	if err != nil {
+		return nil, err
+	}
+
+	deps := mcp.Deps{
+		Events:   s.EventRepo,
+		Patterns: s.PatternRepo,
+		Sessions: s.SessionRepo,
+		Policy:   s.MonitorEngine.Policy(),
+	}
+	return mcp.NewServer(deps, scopes, s.Config.Application.Version), nil
+}
+
+/**
+ * NewMCPHTTPServer 创建 MCP 的本机 HTTP 传输
+ *
+ * 未配置令牌时从令牌文件读取，文件不存在则生成新令牌
+ *
+ * Parameters:
+ *   - addr: 监听地址，为空时使用配置中的地址
+ *
+ * Returns:
+ *   - *mcp.HTTPServer: HTTP 传输
+ *   - error: 存储不可用、令牌不可用、权限范围或地址无效时返回错误
+ */
+func (s *Services) NewMCPHTTPServer(addr string) (*mcp.HTTPServer, error) {
+	if addr == "" {
+		addr = s.Config.MCP.Addr
+	}
+	server, err := s.NewMCP()
+	if err != nil {
+		return nil, err
+	}
+	token, err := resolveToken(s.Config.MCP.Token, s.Config.MCP.TokenFile, "mcp_token")
+	if err != nil {
+		return nil, err
+	}
+	return mcp.NewHTTPServer(addr, token, server)
+}
+
+/**
+ * stopStorage 取消持久化订阅并停止批量写入器
+ */
+func (s *Services) stopStorage() {
+	if s.persistenceSub != "" {
+		s.EventBus.Unsubscribe(s.persistenceSub)
+		s.persistenceSub = ""
+	}
+	if s.BatchWriter != nil {
+		s.BatchWriter.Stop()
+	}
+}
+
+/**
+ * openDatabase 打开 SQLite 数据库并执行迁移
+ *
+ * 路径支持环境变量（如 ${HOME}），为空时使用 ~/.flowmind/flowmind.db
+ *
+ * Parameters:
+ *   - cfg: SQLite 配置
+ *
+ * Returns:
+ *   - *sql.DB: 数据库连接
+ *   - error: 打开或迁移失败时返回错误
+ */
+func openDatabase(cfg config.SQLiteConfig) (*sql.DB, error) {
+	path := os.ExpandEnv(cfg.Path)
+	if path == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("获取用户主目录失败: %w", err)
+		}
+		path = filepath.Join(homeDir, ".flowmind", "flowmind.db")
+	}
+	if path != ":memory:" {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return nil, fmt.Errorf("创建数据目录失败: %w", err)
+		}
+	}
+
+	sqliteConfig := storage.SQLiteConfig{
+		Path:            path,
+		MaxOpenConns:    cfg.MaxOpenConns,
+		MaxIdleConns:    cfg.MaxIdleConns,
+		ConnMaxLifetime: 5 * time.Minute,
+	}
+	if sqliteConfig.MaxOpenConns <= 0 {
+		sqliteConfig.MaxOpenConns = 25
+	}
+	if sqliteConfig.MaxIdleConns <= 0 {
+		sqliteConfig.MaxIdleConns = 5
+	}
+	if lifetime, err := time.ParseDuration(cfg.ConnMaxLifetime); err == nil {
+		sqliteConfig.ConnMaxLifetime = lifetime
+	}
+
+	db, err := storage.NewSQLiteDB(sqliteConfig)
+	if err != nil {
+		return nil, err
+	}
+	if err := storage.RunMigrations(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("执行数据库迁移失败: %w", err)
+	}
+
+	return db, nil
+}
+
+/**
+ * newAPIServer 按配置创建本地 HTTP API
+ *
+ * 未配置令牌时从令牌文件读取，文件不存在则生成新令牌
+ *
+ * Parameters:
+ *   - cfg: API 配置
+ *   - query: 查询服务
+ *   - usage: AI 用量服务
+ *   - bus: 事件总线
+ *
+ * Returns:
+ *   - *api.Server: API 服务
+ *   - error: 令牌不可用或地址无效时返回错误
+ */
+func newAPIServer(cfg config.APIConfig, query *services.QueryService, usage *services.UsageService,
+	bus *events.EventBus) (*api.Server, error) {
+	token, err := resolveToken(cfg.Token, cfg.TokenFile, "api_token")
+	if err != nil {
+		return nil, err
+	}
+	return api.NewServer(cfg.Addr, token, query, usage, bus)
+}
+
+/**
+ * newUsageService 按预算配置创建 AI 用量服务
+ *
+ * Parameters:
+ *   - cfg: 预算配置
+ *   - repo: AI 用量仓储
+ *
+ * Returns:
+ *   - *services.UsageService: 用量服务
+ */
+func newUsageService(cfg config.BudgetConfig, repo models.AIUsageRepository) *services.UsageService {
+	prices := make(map[string]ai.ModelPrice, len(cfg.Prices))
+	for model, price := range cfg.Prices {
+		prices[model] = ai.ModelPrice{Input: price.Input, Output: price.Output}
+	}
+	return services.NewUsageService(repo, ai.NewPriceTable(prices), services.BudgetConfig{
+		DailyUSD:   cfg.DailyUSD,
+		MonthlyUSD: cfg.MonthlyUSD,
+	})
+}
+
+/**
+ * resolveToken 获取访问令牌
+ *
+ * 优先使用配置的令牌；否则从令牌文件读取，文件不存在则生成新令牌。
+ * 未配置令牌文件时使用 ~/.flowmind 下的默认文件
+ *
+ * Parameters:
+ *   - token: 配置的令牌，支持环境变量
+ *   - tokenFile: 令牌文件路径
+ *   - defaultName: 默认令牌文件名
+ *
+ * Returns:
+ *   - string: 访问令牌
+ *   - error: 读取或生成令牌失败时返回错误
+ */
+func resolveToken(token, tokenFile, defaultName string) (string, error) {
+	if token = os.ExpandEnv(token); token != "" {
+		return token, nil
+	}
+	if tokenFile == "" {
+		homeDir, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("获取用户主目录失败: %w", err)
+		}
+		tokenFile = filepath.Join(homeDir, ".flowmind", defaultName)
+	}
+	return api.LoadOrCreateToken(tokenFile)
+}
+
+/**
+ * analyzerConfig 根据应用配置生成分析引擎配置
+ *
+ * 无法创建 AI 模型（如未配置 API Key）时关闭 AI 分析，只做模式挖掘；
+ * 同时按配置选择所有模型共用的提示词模板
+ *
+ * Parameters:
+ *   - cfg: 应用配置
+ *   - bus: 事件总线，用于发布 AI 提供商的健康状态
+ *   - usage: AI 用量服务，用于检查花费预算，为 nil 时不限制
+ *   - db: 数据库连接，ai.cache.persistent 开启时用于持久化分析结果，为 nil 时使用内存缓存
+ *
+ * Returns:
+ *   - analyzer.AnalyzerEngineConfig: 分析引擎配置
+ */
+func analyzerConfig(cfg *config.Config, bus *events.EventBus, usage *services.UsageService, db *sql.DB) analyzer.AnalyzerEngineConfig {
+	engineConfig := analyzer.DefaultAnalyzerEngineConfig()
+
+	if _, err := ai.ConfigurePrompts(ai.PromptConfig{
+		TemplatesDir: os.ExpandEnv(cfg.AI.TemplatesDir),
+		Language:     cfg.AI.PromptLanguage,
+		Version:      cfg.AI.PromptVersion,
+	}); err != nil {
+		logger.Warn("加载提示词模板失败，使用内置模板", zap.Error(err))
+	}
+
+	var recorder ai.UsageRecorder
+	if usage != nil {
+		recorder = usage.Record
+	}
+	aiModel, err := newAIModel(cfg.AI, bus, recorder)
+	if err != nil {
+		logger.Info("AI 模型不可用，仅进行模式挖掘", zap.Error(err))
+		engineConfig.EnableAIAnalysis = false
+		return engineConfig
+	}
+
+	engineConfig.AIPatternFilter.AIModel = aiModel
+	engineConfig.AIPatternFilter.CacheEnabled = cfg.AI.Cache.Enabled
+	if cfg.AI.Cache.TTL != "" {
+		ttl, err := time.ParseDuration(cfg.AI.Cache.TTL)
+		if err != nil {
+			logger.Warn("解析 ai.cache.ttl 失败，使用默认值", zap.Error(err))
+		} else {
+			engineConfig.AIPatternFilter.CacheTTL = ttl
+		}
+	}
+	if cfg.AI.Cache.Enabled && cfg.AI.Cache.Persistent && db != nil {
+		// 按提示词版本和首选模型划分命名空间，更换提示词或模型后不会读到旧的分析结果
+		engineConfig.AIPatternFilter.Cache = cache.NewSQLiteCache(db, cache.SQLiteCacheConfig{
+			Namespace:       ai.ActivePrompt().ID() + "/" + primaryModelID(cfg.AI),
+			MaxSize:         cfg.AI.Cache.MaxSize,
+			CleanupInterval: 10 * time.Minute,
+			NewValue:        func() interface{} { return &models.AIAnalysis{} },
+		})
+	}
+	if usage != nil {
+		// 登记配置的模型，设置了预算时没有价格的付费模型从一开始就按超出预算处理
+		for _, provider := range append([]string{cfg.AI.Provider}, cfg.AI.Fallback...) {
+			if model := configuredModel(cfg.AI, provider); model != "" {
+				if provider == "" {
+					provider = "claude"
+				}
+				usage.RegisterModel(provider, model)
+			}
+		}
+		engineConfig.AIPatternFilter.Budget = usage
+	}
+	return engineConfig
+}
+
+/**
+ * primaryModelID 获取首选提供商和模型的标识
+ *
+ * Parameters:
+ *   - cfg: AI 配置
+ *
+ * Returns: string - 形如 claude:claude-3-5-sonnet 的标识，未配置模型时只有提供商名称
+ */
+func primaryModelID(cfg config.AIConfig) string {
+	provider := cfg.Provider
+	if provider == "" {
+		provider = "claude"
+	}
+	if provider == "replay" && cfg.Replay.RecordFrom != "" {
+		return provider + ":" + cfg.Replay.RecordFrom
+	}
+	if model := configuredModel(cfg, provider); model != "" {
+		return provider + ":" + model
+	}
+	return provider
+}
+
+/**
+ * configuredModel 获取配置中指定的提供商模型名称
+ *
+ * Parameters:
+ *   - cfg: AI 配置
+ *   - provider: 提供商名称
+ *
+ * Returns: string - 模型名称，未配置时为空，由客户端使用默认模型
+ */
+func configuredModel(cfg config.AIConfig, provider string) string {
+	switch provider {
+	case "", "claude":
+		return cfg.Claude.Model
+	case "openai":
+		return cfg.OpenAI.Model
+	case "ollama":
+		return cfg.Ollama.Model
+	}
+	return ""
+}
+
+/**
+ * newAIModel 根据应用配置创建 AI 模型
+ *
+ * 配置了 ai.fallback 时按 provider、fallback 的顺序组成降级链，无法创建的提供商
+ * （如缺少 API Key）会被跳过；熔断状态变化作为 status 事件发布到事件总线
+ *
+ * Parameters:
+ *   - cfg: AI 配置
+ *   - bus: 事件总线，为 nil 时不发布健康状态
+ *   - recorder: 用量回调，为 nil 时不记录用量
+ *
+ * Returns:
+ *   - ai.AIModel: AI 模型
+ *   - error: 没有可用的提供商时返回错误
+ */
+func newAIModel(cfg config.AIConfig, bus *events.EventBus, recorder ai.UsageRecorder) (ai.AIModel, error) {
+	if len(cfg.Fallback) == 0 {
+		return newProviderModel(cfg, cfg.Provider, recorder)
+	}
+
+	primary := cfg.Provider
+	if primary == "" {
+		primary = "claude"
+	}
+	seen := make(map[string]bool)
+	var providers []ai.FallbackProvider
+	var errs []error
+	for _, name := range append([]string{primary}, cfg.Fallback...) {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		model, err := newProviderModel(cfg, name, recorder)
+		if err != nil {
+			logger.Info("AI 提供商不可用，已从降级链中跳过",
+				zap.String("provider", name),
+				zap.Error(err))
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		providers = append(providers, ai.FallbackProvider{Name: name, Model: model})
+	}
+	if len(providers) == 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	fallbackConfig := ai.FallbackConfig{
+		MaxRetries:       cfg.Resilience.MaxRetries,
+		FailureThreshold: cfg.Resilience.FailureThreshold,
+	}
+	if cfg.Resilience.OpenTimeout != "" {
+		openTimeout, err := time.ParseDuration(cfg.Resilience.OpenTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("解析 ai.resilience.open_timeout 失败: %w", err)
+		}
+		fallbackConfig.OpenTimeout = openTimeout
+	}
+	if bus != nil {
+		fallbackConfig.OnHealthChange = func(health ai.ProviderHealth) {
+			publishAIHealth(bus, health)
+		}
+	}
+	return ai.NewFallbackModel(providers, fallbackConfig)
+}
+
+/**
+ * newProviderModel 创建单个提供商的 AI 模型
+ *
+ * 配置中未设置的字段由 ai.AIConfig.LoadFromEnv 从环境变量补齐
+ *
+ * Parameters:
+ *   - cfg: AI 配置
+ *   - provider: 提供商名称
+ *   - recorder: 用量回调，为 nil 时不记录用量
+ *
+ * Returns:
+ *   - ai.AIModel: AI 模型
+ *   - error: 配置无效或创建失败时返回错误
+ */
+func newProviderModel(cfg config.AIConfig, provider string, recorder ai.UsageRecorder) (ai.AIModel, error) {
+	aiConfig := &ai.AIConfig{Provider: provider, MaxRepairs: cfg.MaxRepairs, UsageRecorder: recorder}
+	switch provider {
+	case "", "claude":
+		aiConfig.APIKey = os.ExpandEnv(cfg.Claude.APIKey)
+		aiConfig.Model = cfg.Claude.Model
+		aiConfig.MaxTokens = cfg.Claude.MaxTokens
+	case "openai":
+		aiConfig.APIKey = os.ExpandEnv(cfg.OpenAI.APIKey)
+		aiConfig.Model = cfg.OpenAI.Model
+		aiConfig.MaxTokens = cfg.OpenAI.MaxTokens
+		aiConfig.JSONMode = cfg.OpenAI.JSONMode
+		if baseURL := os.ExpandEnv(cfg.OpenAI.BaseURL); baseURL != "" {
+			aiConfig.BaseURL = &baseURL
+		}
+	case "ollama":
+		aiConfig.Model = cfg.Ollama.Model
+		aiConfig.MaxTokens = cfg.Ollama.MaxTokens
+		aiConfig.AutoPull = cfg.Ollama.AutoPull
+		aiConfig.JSONMode = cfg.Ollama.JSONMode
+		if baseURL := os.ExpandEnv(cfg.Ollama.BaseURL); baseURL != "" {
+			aiConfig.BaseURL = &baseURL
+		}
+	case "replay":
+		aiConfig.ReplayDir = os.ExpandEnv(cfg.Replay.Dir)
+		if cfg.Replay.RecordFrom == "" || cfg.Replay.RecordFrom == "replay" {
+			return ai.NewAIModel(aiConfig)
+		}
+		upstream, err := newProviderModel(cfg, cfg.Replay.RecordFrom, recorder)
+		if err != nil {
+			return nil, fmt.Errorf("创建录制用的 AI 模型失败: %w", err)
+		}
+		return ai.NewReplayModelFromConfig(aiConfig, upstream)
+	}
+	return ai.NewAIModel(aiConfig)
+}
+
+/**
+ * publishAIHealth 发布 AI 提供商健康状态事件
+ *
+ * Parameters:
+ *   - bus: 事件总线
+ *   - health: 提供商健康状态
+ */
+func publishAIHealth(bus *events.EventBus, health ai.ProviderHealth) {
+	data := map[string]interface{}{
+		"type":                 "ai_provider_health",
+		"provider":             health.Name,
+		"state":                string(health.State),
+		"previous_state":       string(health.PreviousState),
+		"consecutive_failures": health.ConsecutiveFailures,
+	}
+	if health.LastError != "" {
+		data["last_error"] = health.LastError
+	}
+	if !health.RetryAt.IsZero() {
+		data["retry_at"] = health.RetryAt.Format(time.RFC3339)
+	}
+
+	statusEvent := events.NewEvent(events.EventTypeStatus, data)
+	if err := bus.Publish(string(events.EventTypeStatus), *statusEvent); err != nil {
+		logger.Error("发布 AI 健康状态事件失败", zap.Error(err))
+	}
+}
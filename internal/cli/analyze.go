@@ -0,0 +1,85 @@
+package cli
+
+import (
+	"fmt"
+	"time"
+)
+
+/**
+ * analyzeView 分析结果的 JSON 输出结构
+ */
+type analyzeView struct {
+	From             time.Time `json:"from"`
+	To               time.Time `json:"to"`
+	EventCount       int       `json:"event_count"`
+	SessionCount     int       `json:"session_count"`
+	PatternCount     int       `json:"pattern_count"`
+	AnalyzedPatterns int       `json:"analyzed_patterns"`
+	ValuablePatterns int       `json:"valuable_patterns"`
+	DurationMillis   int64     `json:"duration_ms"`
+}
+
+/**
+ * runAnalyze 分析指定时间范围的事件
+ *
+ * 划分会话、挖掘模式并保存到数据库；配置了 AI 模型时对模式做 AI 分析
+ *
+ * Parameters:
+ *   - e: 运行环境
+ *   - args: 子命令参数
+ *
+ * Returns:
+ *   - error: 分析失败时返回错误
+ */
+func runAnalyze(e *env, args []string) error {
+	flags := newFlagSet(e, "analyze", "[-from 时间] [-to 时间] [-json]")
+	from := flags.String("from", "", "开始时间（默认 24h 前），支持 RFC3339、2006-01-02、24h、7d")
+	to := flags.String("to", "", "结束时间（默认现在）")
+	asJSON := flags.Bool("json", false, "输出 JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	start, end, err := parseRange(*from, *to, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	services, err := e.openServices()
+	if err != nil {
+		return err
+	}
+	defer services.Close()
+
+	if services.Analyzer == nil {
+		return fmt.Errorf("分析引擎不可用")
+	}
+
+	result, err := services.Analyzer.AnalyzeRange(e.ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	view := analyzeView{
+		From:             start,
+		To:               end,
+		EventCount:       result.EventCount,
+		SessionCount:     result.SessionCount,
+		PatternCount:     result.PatternCount,
+		AnalyzedPatterns: result.AnalyzedPatterns,
+		ValuablePatterns: result.ValuablePatterns,
+		DurationMillis:   result.Duration.Milliseconds(),
+	}
+	if *asJSON {
+		return printJSON(e.stdout, view)
+	}
+
+	fmt.Fprintf(e.stdout, "时间范围:   %s ~ %s\n", formatTime(start), formatTime(end))
+	fmt.Fprintf(e.stdout, "事件:       %d\n", view.EventCount)
+	fmt.Fprintf(e.stdout, "会话:       %d\n", view.SessionCount)
+	fmt.Fprintf(e.stdout, "模式:       %d\n", view.PatternCount)
+	fmt.Fprintf(e.stdout, "AI 分析:    %d\n", view.AnalyzedPatterns)
+	fmt.Fprintf(e.stdout, "值得自动化: %d\n", view.ValuablePatterns)
+	fmt.Fprintf(e.stdout, "耗时:       %s\n", result.Duration.Round(time.Millisecond))
+	return nil
+}
@@ -0,0 +1,345 @@
+/**
+ * Package cli 提供 flowmind 命令行工具
+ *
+ * 不启动 Wails 窗口即可运行后台服务（daemon），或查询本地数据库中的事件、模式和统计。
+ * 所有子命令与 Wails 应用共用 app.Services 的装配逻辑
+ */
+
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/app"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+)
+
+/**
+ * errUsage 参数错误
+ *
+ * 子命令返回该错误时打印用法并以退出码 2 结束
+ */
+var errUsage = errors.New("usage error")
+
+/**
+ * command 子命令
+ */
+type command struct {
+	// name 子命令名称
+	name string
+
+	// summary 一行说明
+	summary string
+
+	// run 执行函数，args 不含子命令名称
+	run func(env *env, args []string) error
+}
+
+/**
+ * env 子命令运行环境
+ */
+type env struct {
+	ctx    context.Context
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+
+	// configPath 配置文件路径，为空时使用 ~/.flowmind/config.yaml
+	configPath string
+
+	// dbPath 数据库路径，覆盖配置文件中的 storage.sqlite.path
+	dbPath string
+}
+
+/**
+ * commands 所有子命令
+ */
+var commands = []command{
+	{name: "daemon", summary: "在后台运行监控、持久化和分析，不打开窗口", run: runDaemon},
+	{name: "events", summary: "查看事件（tail、query）", run: runEvents},
+	{name: "patterns", summary: "查看识别出的模式（list、show）", run: runPatterns},
+	{name: "analyze", summary: "分析指定时间范围的事件", run: runAnalyze},
+	{name: "stats", summary: "查看事件、模式、死信和 AI 花费统计", run: runStats},
+	{name: "mcp", summary: "运行 MCP 服务，让编码智能体查询工作流历史", run: runMCP},
+}
+
+/**
+ * Run 执行命令行
+ *
+ * Parameters:
+ *   - ctx: 上下文，取消时 daemon 和 events tail -f 退出
+ *   - args: 命令行参数（不含程序名）
+ *   - stdout: 命令结果输出
+ *   - stderr: 错误和用法输出
+ *
+ * Returns:
+ *   - int: 退出码，0 成功，1 执行失败，2 参数错误
+ */
+func Run(ctx context.Context, args []string, stdout, stderr io.Writer) int {
+	e := &env{ctx: ctx, stdin: os.Stdin, stdout: stdout, stderr: stderr}
+
+	flags := flag.NewFlagSet("flowmind", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	flags.StringVar(&e.configPath, "config", "", "配置文件路径（默认 ~/.flowmind/config.yaml）")
+	flags.StringVar(&e.dbPath, "db", "", "数据库路径，覆盖配置文件")
+	flags.Usage = func() { printUsage(flags, stderr) }
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return 0
+		}
+		return 2
+	}
+
+	rest := flags.Args()
+	if len(rest) == 0 {
+		printUsage(flags, stderr)
+		return 2
+	}
+
+	// 命令结果写到标准输出，日志写到标准错误；daemon 需要看到运行日志
+	logLevel := "warn"
+	if rest[0] == "daemon" {
+		logLevel = "info"
+	}
+	logger.InitCLILogger(logLevel)
+	defer logger.Sync()
+
+	for _, cmd := range commands {
+		if cmd.name != rest[0] {
+			continue
+		}
+		err := cmd.run(e, rest[1:])
+		switch {
+		case err == nil:
+			return 0
+		case errors.Is(err, flag.ErrHelp):
+			return 0
+		case errors.Is(err, errUsage):
+			fmt.Fprintf(stderr, "flowmind %s: %v\n", cmd.name, err)
+			return 2
+		default:
+			fmt.Fprintf(stderr, "flowmind %s: %v\n", cmd.name, err)
+			return 1
+		}
+	}
+
+	fmt.Fprintf(stderr, "flowmind: 未知命令 %q\n\n", rest[0])
+	printUsage(flags, stderr)
+	return 2
+}
+
+/**
+ * printUsage 打印总体用法
+ */
+func printUsage(flags *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "用法: flowmind [-config 路径] [-db 路径] <命令> [参数]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "命令:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-10s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "全局参数:")
+	flags.PrintDefaults()
+}
+
+/**
+ * newFlagSet 创建子命令参数集
+ *
+ * Parameters:
+ *   - e: 运行环境
+ *   - name: 子命令名称（含上级命令，如 "events query"）
+ *   - usage: 参数说明
+ *
+ * Returns:
+ *   - *flag.FlagSet: 参数集
+ */
+func newFlagSet(e *env, name, usage string) *flag.FlagSet {
+	flags := flag.NewFlagSet(name, flag.ContinueOnError)
+	flags.SetOutput(e.stderr)
+	flags.Usage = func() {
+		fmt.Fprintf(e.stderr, "用法: flowmind %s %s\n", name, usage)
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+/**
+ * parseFlags 解析子命令参数
+ *
+ * 参数错误时返回 errUsage（flag 包已打印具体原因）
+ */
+func parseFlags(flags *flag.FlagSet, args []string) error {
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return err
+		}
+		return errUsage
+	}
+	return nil
+}
+
+/**
+ * loadConfig 加载配置
+ *
+ * Returns:
+ *   - *config.Config: 配置
+ *   - error: 指定的配置文件无法加载时返回错误
+ */
+func (e *env) loadConfig() (*config.Config, error) {
+	var cfg *config.Config
+	var err error
+	if e.configPath != "" {
+		cfg, err = config.LoadFile(e.configPath)
+		if err != nil {
+			return nil, fmt.Errorf("加载配置失败: %w", err)
+		}
+	} else {
+		cfg, err = config.Load()
+		if err != nil {
+			logger.Warn("加载配置失败，使用默认配置")
+			cfg, _ = config.LoadDefault()
+		}
+	}
+
+	if e.dbPath != "" {
+		cfg.Storage.SQLite.Path = e.dbPath
+	}
+	return cfg, nil
+}
+
+/**
+ * openServices 按配置装配查询用的服务
+ *
+ * 不创建指标端点、本地 API 和 MCP 服务，只读命令不会生成令牌文件
+ *
+ * Returns:
+ *   - *app.Services: 服务集合，调用方负责 Close
+ *   - error: 加载配置或打开数据库失败时返回错误
+ */
+func (e *env) openServices() (*app.Services, error) {
+	cfg, err := e.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return app.NewQueryServices(cfg)
+}
+
+/**
+ * openDaemonServices 按配置装配包含网络服务的完整服务
+ *
+ * Returns:
+ *   - *app.Services: 服务集合，调用方负责 Close
+ *   - error: 加载配置或打开数据库失败时返回错误
+ */
+func (e *env) openDaemonServices() (*app.Services, error) {
+	cfg, err := e.loadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return app.NewServices(cfg)
+}
+
+/**
+ * printJSON 以缩进格式输出 JSON
+ */
+func printJSON(w io.Writer, value interface{}) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(value)
+}
+
+/**
+ * parseTime 解析时间参数
+ *
+ * 支持以下格式：
+ *   - 空字符串：零值
+ *   - now：当前时间
+ *   - 相对时长：24h、30m、7d，表示当前时间之前
+ *   - RFC3339：2026-01-02T15:04:05+08:00
+ *   - 本地时间：2026-01-02 15:04、2026-01-02
+ *
+ * Parameters:
+ *   - value: 参数值
+ *   - now: 当前时间
+ *
+ * Returns:
+ *   - time.Time: 解析结果
+ *   - error: 格式无法识别时返回错误
+ */
+func parseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	switch value {
+	case "":
+		return time.Time{}, nil
+	case "now":
+		return now, nil
+	}
+
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil {
+		return now.Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("%w: 无法识别的时间 %q", errUsage, value)
+}
+
+/**
+ * parseRange 解析 --from/--to 时间范围
+ *
+ * Parameters:
+ *   - from: 开始时间参数，为空时使用 defaultFrom
+ *   - to: 结束时间参数，为空时为当前时间
+ *   - defaultFrom: 默认开始时间（相对当前时间的时长）
+ *
+ * Returns:
+ *   - time.Time: 开始时间
+ *   - time.Time: 结束时间
+ *   - error: 格式错误或开始时间晚于结束时间时返回错误
+ */
+func parseRange(from, to string, defaultFrom time.Duration) (time.Time, time.Time, error) {
+	now := time.Now()
+	start, err := parseTime(from, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if from == "" {
+		start = now.Add(-defaultFrom)
+	}
+
+	end, err := parseTime(to, now)
+	if err != nil {
+		return time.Time{}, time.Time{}, err
+	}
+	if to == "" {
+		end = now
+	}
+
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: 开始时间晚于结束时间", errUsage)
+	}
+	return start, end, nil
+}
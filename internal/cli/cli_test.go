@@ -0,0 +1,352 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/app"
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/internal/services"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupDB 创建临时数据库并写入测试数据，返回数据库路径
+func setupDB(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("AI_API_KEY", "")
+	t.Setenv("CLAUDE_API_KEY", "")
+
+	dbPath := filepath.Join(t.TempDir(), "flowmind.db")
+	cfg, err := config.LoadDefault()
+	require.NoError(t, err)
+	cfg.Storage.SQLite.Path = dbPath
+
+	services, err := app.NewServices(cfg)
+	require.NoError(t, err)
+	defer services.Close()
+
+	now := time.Now()
+	var eventList []events.Event
+	for i := 0; i < 3; i++ {
+		event := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"keycode": i})
+		event.Timestamp = now.Add(time.Duration(i-3) * time.Minute)
+		event.WithContext(&events.EventContext{Application: "Terminal"})
+		eventList = append(eventList, *event)
+	}
+	clipboard := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"content": "hello"})
+	clipboard.Timestamp = now.Add(-30 * time.Second)
+	clipboard.WithContext(&events.EventContext{Application: "Safari"})
+	eventList = append(eventList, *clipboard)
+	require.NoError(t, services.EventRepo.SaveBatch(eventList))
+
+	require.NoError(t, services.PatternRepo.Save(&models.Pattern{
+		ID: "pattern-copy",
+		Sequence: []models.EventStep{
+			{Type: events.EventTypeKeyboard, Action: "keypress", Context: &models.StepContext{Application: "Terminal"}},
+			{Type: events.EventTypeClipboard, Action: "clipboard_copy"},
+		},
+		SupportCount: 12,
+		Confidence:   0.8,
+		FirstSeen:    now.Add(-2 * time.Hour),
+		LastSeen:     now,
+		AIAnalysis: &models.AIAnalysis{
+			ShouldAutomate: true,
+			SuggestedName:  "复制终端输出",
+			Reason:         "重复次数多",
+			AnalyzedAt:     now,
+		},
+	}))
+	require.NoError(t, services.PatternRepo.Save(&models.Pattern{
+		ID:           "pattern-rare",
+		Sequence:     []models.EventStep{{Type: events.EventTypeAppSwitch, Action: "switch"}},
+		SupportCount: 3,
+		Confidence:   0.4,
+		FirstSeen:    now.Add(-time.Hour),
+		LastSeen:     now,
+	}))
+
+	return dbPath
+}
+
+// run 执行命令行并返回退出码和输出
+func run(t *testing.T, dbPath string, args ...string) (int, string, string) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	code := Run(context.Background(), append([]string{"-db", dbPath}, args...), &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// TestRun_Usage 测试缺少或未知命令时打印用法
+func TestRun_Usage(t *testing.T) {
+	var stdout, stderr bytes.Buffer
+	assert.Equal(t, 2, Run(context.Background(), nil, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), "daemon")
+	assert.Contains(t, stderr.String(), "patterns")
+
+	stderr.Reset()
+	assert.Equal(t, 2, Run(context.Background(), []string{"unknown"}, &stdout, &stderr))
+	assert.Contains(t, stderr.String(), `未知命令 "unknown"`)
+
+	dbPath := setupDB(t)
+	code, _, errOut := run(t, dbPath, "events")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "tail 或 query")
+
+	code, _, _ = run(t, dbPath, "events", "query", "-from", "yesterday-ish")
+	assert.Equal(t, 2, code)
+}
+
+// TestEventsQuery 测试按时间范围、类型和应用查询事件
+func TestEventsQuery(t *testing.T) {
+	dbPath := setupDB(t)
+
+	code, out, _ := run(t, dbPath, "events", "query", "-from", "1h")
+	require.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 4)
+	assert.Contains(t, lines[0], "keyboard")
+	assert.Contains(t, lines[0], "Terminal")
+	assert.Contains(t, lines[3], "clipboard")
+
+	code, out, _ = run(t, dbPath, "events", "query", "-type", "clipboard", "-json")
+	require.Equal(t, 0, code)
+	var event events.Event
+	require.NoError(t, json.Unmarshal([]byte(strings.TrimSpace(out)), &event))
+	assert.Equal(t, events.EventTypeClipboard, event.Type)
+	assert.Equal(t, "hello", event.Data["content"])
+
+	code, out, _ = run(t, dbPath, "events", "query", "-app", "Terminal", "-limit", "2")
+	require.Equal(t, 0, code)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 2)
+
+	code, out, _ = run(t, dbPath, "events", "query", "-from", "10s")
+	require.Equal(t, 0, code)
+	assert.Empty(t, out)
+}
+
+// TestEventsTail 测试输出最近的事件，-f 时持续输出新事件
+func TestEventsTail(t *testing.T) {
+	dbPath := setupDB(t)
+
+	code, out, _ := run(t, dbPath, "events", "tail", "-n", "2")
+	require.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[1], "clipboard")
+
+	code, out, _ = run(t, dbPath, "events", "tail", "-type", "keyboard", "-n", "10")
+	require.Equal(t, 0, code)
+	assert.Len(t, strings.Split(strings.TrimSpace(out), "\n"), 3)
+	assert.NotContains(t, out, "clipboard")
+
+	// -f 持续输出之后写入的事件，直到上下文取消
+	ctx, cancel := context.WithCancel(context.Background())
+	stdout := &syncBuffer{}
+	done := make(chan int)
+	go func() {
+		done <- Run(ctx, []string{"-db", dbPath, "events", "tail", "-n", "0", "-f", "-interval", "20ms", "-json"}, stdout, &bytes.Buffer{})
+	}()
+
+	// 等待 tail 记录起始游标后再写入
+	time.Sleep(200 * time.Millisecond)
+
+	cfg, err := config.LoadDefault()
+	require.NoError(t, err)
+	cfg.Storage.SQLite.Path = dbPath
+	services, err := app.NewServices(cfg)
+	require.NoError(t, err)
+	followed := events.NewEvent(events.EventTypeAppSwitch, map[string]interface{}{"to": "Xcode"})
+	require.NoError(t, services.EventRepo.Save(*followed))
+	services.Close()
+
+	require.Eventually(t, func() bool {
+		return strings.Contains(stdout.String(), followed.ID)
+	}, 2*time.Second, 20*time.Millisecond)
+	cancel()
+	require.Equal(t, 0, <-done)
+	assert.Equal(t, 1, strings.Count(stdout.String(), "\n"))
+}
+
+// syncBuffer 并发安全的输出缓冲区
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// TestPatterns 测试模式列表和详情
+func TestPatterns(t *testing.T) {
+	dbPath := setupDB(t)
+
+	code, out, _ := run(t, dbPath, "patterns", "list")
+	require.Equal(t, 0, code)
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[1], "pattern-copy")
+	assert.Contains(t, lines[1], "复制终端输出")
+	assert.Contains(t, lines[2], "pattern-rare")
+
+	code, out, _ = run(t, dbPath, "patterns", "list", "-automate", "-json")
+	require.Equal(t, 0, code)
+	var views []services.PatternView
+	require.NoError(t, json.Unmarshal([]byte(out), &views))
+	require.Len(t, views, 1)
+	assert.Equal(t, "pattern-copy", views[0].ID)
+	assert.Len(t, views[0].Steps, 2)
+
+	code, out, _ = run(t, dbPath, "patterns", "show", "pattern-copy")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "keyboard/keypress @Terminal")
+	assert.Contains(t, out, "值得自动化: true")
+
+	code, _, errOut := run(t, dbPath, "patterns", "show", "missing")
+	assert.Equal(t, 1, code)
+	assert.NotEmpty(t, errOut)
+
+	code, _, _ = run(t, dbPath, "patterns", "show")
+	assert.Equal(t, 2, code)
+}
+
+// TestStats 测试统计输出
+func TestStats(t *testing.T) {
+	dbPath := setupDB(t)
+
+	code, out, _ := run(t, dbPath, "stats", "-json")
+	require.Equal(t, 0, code)
+
+	var view statsView
+	require.NoError(t, json.Unmarshal([]byte(out), &view))
+	assert.Equal(t, int64(4), view.TotalEvents)
+	assert.Equal(t, int64(3), view.EventsByType["keyboard"])
+	assert.Equal(t, 2, view.Patterns)
+	assert.Equal(t, 1, view.AnalyzedPatterns)
+	assert.Equal(t, 1, view.ValuablePatterns)
+	assert.Equal(t, 0, view.DeadLetters)
+	assert.Zero(t, view.AISpentTodayUSD)
+
+	code, out, _ = run(t, dbPath, "stats")
+	require.Equal(t, 0, code)
+	assert.Contains(t, out, "事件总数")
+	assert.Contains(t, out, "AI 花费")
+}
+
+// TestStats_NoNetworkServers 测试只读命令不创建网络服务和令牌文件
+func TestStats_NoNetworkServers(t *testing.T) {
+	dbPath := setupDB(t)
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "config.yaml")
+	require.NoError(t, os.WriteFile(configPath, []byte(`
+api:
+  enabled: true
+  addr: 127.0.0.1:0
+  token_file: `+filepath.Join(dir, "api_token")+`
+mcp:
+  enabled: true
+  addr: 127.0.0.1:0
+  token_file: `+filepath.Join(dir, "mcp_token")+`
+`), 0o600))
+
+	code, _, _ := run(t, dbPath, "-config", configPath, "stats")
+	require.Equal(t, 0, code)
+	assert.NoFileExists(t, filepath.Join(dir, "api_token"))
+	assert.NoFileExists(t, filepath.Join(dir, "mcp_token"))
+}
+
+// TestAnalyze 测试在没有 AI 模型时只做模式挖掘
+func TestAnalyze(t *testing.T) {
+	dbPath := setupDB(t)
+
+	code, out, _ := run(t, dbPath, "analyze", "-from", "1h", "-json")
+	require.Equal(t, 0, code)
+
+	var view analyzeView
+	require.NoError(t, json.Unmarshal([]byte(out), &view))
+	assert.Equal(t, 4, view.EventCount)
+	assert.Equal(t, 0, view.AnalyzedPatterns)
+
+	code, _, errOut := run(t, dbPath, "analyze", "-from", "now", "-to", "1h")
+	assert.Equal(t, 2, code)
+	assert.Contains(t, errOut, "开始时间晚于结束时间")
+}
+
+// TestParseTime 测试时间参数的各种格式
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		value string
+		want  time.Time
+	}{
+		{"", time.Time{}},
+		{"now", now},
+		{"90m", now.Add(-90 * time.Minute)},
+		{"7d", now.AddDate(0, 0, -7)},
+		{"2026-03-01", time.Date(2026, 3, 1, 0, 0, 0, 0, time.Local)},
+		{"2026-03-01 08:30", time.Date(2026, 3, 1, 8, 30, 0, 0, time.Local)},
+		{"2026-03-01T08:30:00Z", time.Date(2026, 3, 1, 8, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := parseTime(tt.value, now)
+		require.NoError(t, err, tt.value)
+		assert.True(t, tt.want.Equal(got), "%s: got %v", tt.value, got)
+	}
+
+	_, err := parseTime("last tuesday", now)
+	assert.ErrorIs(t, err, errUsage)
+}
+
+// TestMCP_Stdio 测试通过标准输入输出调用 MCP 工具
+func TestMCP_Stdio(t *testing.T) {
+	dbPath := setupDB(t)
+
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	require.NoError(t, err)
+	_, err = stdin.WriteString(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"search_events","arguments":{"app":"Terminal"}}}` + "\n")
+	require.NoError(t, err)
+	_, err = stdin.Seek(0, 0)
+	require.NoError(t, err)
+	defer stdin.Close()
+
+	origStdin := os.Stdin
+	os.Stdin = stdin
+	defer func() { os.Stdin = origStdin }()
+
+	code, out, _ := run(t, dbPath, "mcp")
+	require.Equal(t, 0, code)
+
+	var resp struct {
+		Result struct {
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"result"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(out), &resp))
+	require.Len(t, resp.Result.Content, 1)
+	assert.Contains(t, resp.Result.Content[0].Text, `"matched": 3`)
+
+	code, _, _ = run(t, dbPath, "mcp", "extra")
+	assert.Equal(t, 2, code)
+}
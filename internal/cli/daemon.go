@@ -0,0 +1,54 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * runDaemon 运行后台服务
+ *
+ * 启动监控引擎、批量写入器、分析引擎，以及配置启用的指标端点、本地 API 和 MCP 服务，直到收到中断信号
+ *
+ * Parameters:
+ *   - e: 运行环境
+ *   - args: 子命令参数
+ *
+ * Returns:
+ *   - error: 启动失败时返回错误
+ */
+func runDaemon(e *env, args []string) error {
+	flags := newFlagSet(e, "daemon", "")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%w: 多余的参数 %v", errUsage, flags.Args())
+	}
+
+	services, err := e.openDaemonServices()
+	if err != nil {
+		return err
+	}
+	defer services.Close()
+
+	if err := services.Start(); err != nil {
+		return err
+	}
+
+	logger.Info("FlowMind 后台服务已启动",
+		zap.String("component", "daemon"),
+		zap.Strings("monitors", services.MonitorEngine.StartedMonitors()),
+		zap.Bool("analyzer", services.Analyzer != nil),
+		zap.Bool("metrics", services.MetricsServer != nil),
+		zap.Bool("api", services.APIServer != nil),
+		zap.Bool("mcp", services.MCPServer != nil),
+	)
+
+	<-e.ctx.Done()
+
+	logger.Info("FlowMind 后台服务正在停止", zap.String("component", "daemon"))
+	return nil
+}
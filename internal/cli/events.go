@@ -0,0 +1,243 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+)
+
+/**
+ * tailPollInterval events tail -f 的默认轮询间隔
+ */
+const tailPollInterval = time.Second
+
+/**
+ * runEvents 事件子命令
+ *
+ * Parameters:
+ *   - e: 运行环境
+ *   - args: 子命令参数，第一个是 tail 或 query
+ *
+ * Returns:
+ *   - error: 执行失败时返回错误
+ */
+func runEvents(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: 需要子命令 tail 或 query", errUsage)
+	}
+
+	switch args[0] {
+	case "tail":
+		return runEventsTail(e, args[1:])
+	case "query":
+		return runEventsQuery(e, args[1:])
+	default:
+		return fmt.Errorf("%w: 未知子命令 %q，可用 tail、query", errUsage, args[0])
+	}
+}
+
+/**
+ * runEventsTail 输出最近的事件，-f 时持续输出新写入的事件
+ */
+func runEventsTail(e *env, args []string) error {
+	flags := newFlagSet(e, "events tail", "[-n 数量] [-f] [-type 类型] [-json]")
+	count := flags.Int("n", 20, "输出最近的事件数")
+	follow := flags.Bool("f", false, "持续输出新写入的事件")
+	interval := flags.Duration("interval", tailPollInterval, "-f 时的轮询间隔")
+	eventType := flags.String("type", "", "只输出指定类型的事件")
+	asJSON := flags.Bool("json", false, "每行输出一个 JSON 事件")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	services, err := e.openServices()
+	if err != nil {
+		return err
+	}
+	defer services.Close()
+	repo := services.EventRepo
+
+	// 先记录游标，保证 -f 不会漏掉输出最近事件期间写入的事件
+	cursor, err := repo.LatestCursor()
+	if err != nil {
+		return err
+	}
+
+	var recent []events.Event
+	if *eventType != "" {
+		recent, err = repo.FindByType(events.EventType(*eventType), *count)
+		reverseEvents(recent)
+	} else {
+		recent, err = repo.FindRecent(*count)
+	}
+	if err != nil {
+		return err
+	}
+	for _, event := range recent {
+		if err := writeEvent(e.stdout, event, *asJSON); err != nil {
+			return err
+		}
+	}
+
+	if !*follow {
+		return nil
+	}
+
+	ticker := time.NewTicker(*interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-e.ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		for {
+			page, err := repo.FindAfterCursor(cursor, time.Time{}, time.Time{}, 500)
+			if err != nil {
+				return err
+			}
+			for _, stored := range page {
+				cursor = stored.Cursor
+				if *eventType != "" && string(stored.Event.Type) != *eventType {
+					continue
+				}
+				if err := writeEvent(e.stdout, stored.Event, *asJSON); err != nil {
+					return err
+				}
+			}
+			if len(page) < 500 {
+				break
+			}
+		}
+	}
+}
+
+/**
+ * runEventsQuery 按时间范围和类型查询事件
+ */
+func runEventsQuery(e *env, args []string) error {
+	flags := newFlagSet(e, "events query", "[-from 时间] [-to 时间] [-type 类型] [-limit 数量] [-json]")
+	from := flags.String("from", "", "开始时间（默认 24h 前），支持 RFC3339、2006-01-02、24h、7d")
+	to := flags.String("to", "", "结束时间（默认现在）")
+	eventType := flags.String("type", "", "只查询指定类型的事件")
+	app := flags.String("app", "", "只查询指定应用的事件")
+	limit := flags.Int("limit", 100, "最多输出的事件数，0 表示不限")
+	asJSON := flags.Bool("json", false, "每行输出一个 JSON 事件")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	start, end, err := parseRange(*from, *to, 24*time.Hour)
+	if err != nil {
+		return err
+	}
+
+	services, err := e.openServices()
+	if err != nil {
+		return err
+	}
+	defer services.Close()
+
+	found, err := queryEvents(services.EventRepo, start, end, *eventType, *app, *limit)
+	if err != nil {
+		return err
+	}
+	for _, event := range found {
+		if err := writeEvent(e.stdout, event, *asJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * queryEvents 查询时间范围内的事件并按类型和应用过滤
+ *
+ * Parameters:
+ *   - repo: 事件仓储
+ *   - start: 开始时间
+ *   - end: 结束时间
+ *   - eventType: 事件类型，为空时不过滤
+ *   - app: 应用名称，为空时不过滤
+ *   - limit: 数量上限，0 表示不限
+ *
+ * Returns:
+ *   - []events.Event: 按时间升序排列的事件
+ *   - error: 查询失败时返回错误
+ */
+func queryEvents(repo storage.EventRepository, start, end time.Time, eventType, app string, limit int) ([]events.Event, error) {
+	all, err := repo.FindByTimeRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []events.Event
+	for _, event := range all {
+		if eventType != "" && string(event.Type) != eventType {
+			continue
+		}
+		if app != "" && (event.Context == nil || event.Context.Application != app) {
+			continue
+		}
+		found = append(found, event)
+		if limit > 0 && len(found) >= limit {
+			break
+		}
+	}
+	return found, nil
+}
+
+/**
+ * writeEvent 输出一个事件
+ *
+ * 文本格式为：时间 类型 应用 数据
+ *
+ * Parameters:
+ *   - w: 输出目标
+ *   - event: 事件
+ *   - asJSON: 是否输出单行 JSON
+ *
+ * Returns:
+ *   - error: 写入失败时返回错误
+ */
+func writeEvent(w io.Writer, event events.Event, asJSON bool) error {
+	if asJSON {
+		data, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, string(data))
+		return err
+	}
+
+	app := "-"
+	if event.Context != nil && event.Context.Application != "" {
+		app = event.Context.Application
+	}
+	data := ""
+	if len(event.Data) > 0 {
+		encoded, err := json.Marshal(event.Data)
+		if err != nil {
+			return err
+		}
+		data = string(encoded)
+	}
+
+	_, err := fmt.Fprintf(w, "%s  %-12s  %-20s  %s\n",
+		event.Timestamp.Local().Format("2006-01-02 15:04:05"), event.Type, app, data)
+	return err
+}
+
+/**
+ * reverseEvents 原地反转事件顺序
+ */
+func reverseEvents(list []events.Event) {
+	for i, j := 0, len(list)-1; i < j; i, j = i+1, j-1 {
+		list[i], list[j] = list[j], list[i]
+	}
+}
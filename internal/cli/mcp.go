@@ -0,0 +1,62 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+/**
+ * runMCP 运行 MCP 服务
+ *
+ * 默认通过标准输入输出通信，供编码智能体作为子进程启动；
+ * -http 时改为在本机回环地址提供 HTTP 传输，直到收到中断信号。
+ * 只读取数据库，不启动监控
+ *
+ * Parameters:
+ *   - e: 运行环境
+ *   - args: 子命令参数
+ *
+ * Returns:
+ *   - error: 启动失败时返回错误
+ */
+func runMCP(e *env, args []string) error {
+	flags := newFlagSet(e, "mcp", "[-http] [-addr 地址]")
+	useHTTP := flags.Bool("http", false, "使用本机 HTTP 传输（默认 stdio）")
+	addr := flags.String("addr", "", "HTTP 传输的监听地址（默认使用配置中的 mcp.addr）")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() > 0 {
+		return fmt.Errorf("%w: 多余的参数 %v", errUsage, flags.Args())
+	}
+
+	services, err := e.openServices()
+	if err != nil {
+		return err
+	}
+	defer services.Close()
+
+	if !*useHTTP {
+		server, err := services.NewMCP()
+		if err != nil {
+			return err
+		}
+		return server.ServeStdio(e.ctx, e.stdin, e.stdout)
+	}
+
+	server, err := services.NewMCPHTTPServer(*addr)
+	if err != nil {
+		return err
+	}
+	if err := server.Start(); err != nil {
+		return err
+	}
+	fmt.Fprintf(e.stderr, "MCP 服务已启动: http://%s/mcp\n", server.Addr())
+
+	<-e.ctx.Done()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	return server.Stop(ctx)
+}
@@ -0,0 +1,230 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/services"
+)
+
+/**
+ * runPatterns 模式子命令
+ *
+ * Parameters:
+ *   - e: 运行环境
+ *   - args: 子命令参数，第一个是 list 或 show
+ *
+ * Returns:
+ *   - error: 执行失败时返回错误
+ */
+func runPatterns(e *env, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("%w: 需要子命令 list 或 show", errUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		return runPatternsList(e, args[1:])
+	case "show":
+		return runPatternsShow(e, args[1:])
+	default:
+		return fmt.Errorf("%w: 未知子命令 %q，可用 list、show", errUsage, args[0])
+	}
+}
+
+/**
+ * runPatternsList 列出模式，按支持度降序
+ */
+func runPatternsList(e *env, args []string) error {
+	flags := newFlagSet(e, "patterns list", "[-automate] [-limit 数量] [-json]")
+	onlyAutomate := flags.Bool("automate", false, "只列出 AI 判断值得自动化的模式")
+	limit := flags.Int("limit", 0, "最多列出的模式数，0 表示不限")
+	asJSON := flags.Bool("json", false, "输出 JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	svc, err := e.openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	all, err := svc.PatternRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	patterns := make([]*models.Pattern, 0, len(all))
+	for _, pattern := range all {
+		if *onlyAutomate && (pattern.AIAnalysis == nil || !pattern.AIAnalysis.ShouldAutomate) {
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].SupportCount > patterns[j].SupportCount
+	})
+	if *limit > 0 && len(patterns) > *limit {
+		patterns = patterns[:*limit]
+	}
+
+	if *asJSON {
+		views := make([]services.PatternView, 0, len(patterns))
+		for _, pattern := range patterns {
+			views = append(views, services.NewPatternView(pattern))
+		}
+		return printJSON(e.stdout, views)
+	}
+
+	tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\t次数\t置信度\t步骤\t最后出现\t自动化\t描述")
+	for _, pattern := range patterns {
+		fmt.Fprintf(tw, "%s\t%d\t%.2f\t%d\t%s\t%s\t%s\n",
+			pattern.ID,
+			pattern.SupportCount,
+			pattern.Confidence,
+			pattern.Length(),
+			formatTime(pattern.LastSeen),
+			automateLabel(pattern),
+			patternSummary(pattern),
+		)
+	}
+	return tw.Flush()
+}
+
+/**
+ * runPatternsShow 显示单个模式的步骤和 AI 分析
+ */
+func runPatternsShow(e *env, args []string) error {
+	flags := newFlagSet(e, "patterns show", "[-json] <模式 ID>")
+	asJSON := flags.Bool("json", false, "输出 JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("%w: 需要一个模式 ID", errUsage)
+	}
+
+	svc, err := e.openServices()
+	if err != nil {
+		return err
+	}
+	defer svc.Close()
+
+	pattern, err := svc.PatternRepo.FindByID(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	if *asJSON {
+		return printJSON(e.stdout, services.NewPatternView(pattern))
+	}
+	writePattern(e.stdout, pattern)
+	return nil
+}
+
+/**
+ * writePattern 以文本格式输出模式详情
+ */
+func writePattern(w io.Writer, pattern *models.Pattern) {
+	fmt.Fprintf(w, "ID:        %s\n", pattern.ID)
+	if pattern.Description != "" {
+		fmt.Fprintf(w, "描述:      %s\n", pattern.Description)
+	}
+	fmt.Fprintf(w, "出现次数:  %d\n", pattern.SupportCount)
+	fmt.Fprintf(w, "置信度:    %.2f\n", pattern.Confidence)
+	fmt.Fprintf(w, "频率:      %.2f 次/小时\n", pattern.Frequency())
+	fmt.Fprintf(w, "首次出现:  %s\n", formatTime(pattern.FirstSeen))
+	fmt.Fprintf(w, "最后出现:  %s\n", formatTime(pattern.LastSeen))
+
+	fmt.Fprintln(w, "步骤:")
+	for i, step := range pattern.Sequence {
+		fmt.Fprintf(w, "  %d. %s\n", i+1, stepLabel(step))
+	}
+
+	analysis := pattern.AIAnalysis
+	if analysis == nil {
+		fmt.Fprintln(w, "AI 分析:   未分析")
+		return
+	}
+	fmt.Fprintln(w, "AI 分析:")
+	fmt.Fprintf(w, "  值得自动化: %t\n", analysis.ShouldAutomate)
+	if analysis.SuggestedName != "" {
+		fmt.Fprintf(w, "  建议名称:   %s\n", analysis.SuggestedName)
+	}
+	if analysis.Reason != "" {
+		fmt.Fprintf(w, "  原因:       %s\n", analysis.Reason)
+	}
+	if analysis.Complexity != "" {
+		fmt.Fprintf(w, "  复杂度:     %s\n", analysis.Complexity)
+	}
+	if analysis.EstimatedTimeSaving > 0 {
+		fmt.Fprintf(w, "  预计节省:   %d 秒\n", analysis.EstimatedTimeSaving)
+	}
+	for i, step := range analysis.SuggestedSteps {
+		fmt.Fprintf(w, "  建议步骤 %d: %s\n", i+1, step)
+	}
+	if analysis.PromptVersion != "" {
+		fmt.Fprintf(w, "  提示词版本: %s\n", analysis.PromptVersion)
+	}
+}
+
+/**
+ * stepLabel 步骤的简短描述，如 "keyboard/keypress @Terminal (letter)"
+ */
+func stepLabel(step models.EventStep) string {
+	label := string(step.Type)
+	if step.Action != "" {
+		label += "/" + step.Action
+	}
+	if step.Context != nil {
+		if step.Context.Application != "" {
+			label += " @" + step.Context.Application
+		}
+		if step.Context.PatternValue != "" {
+			label += " (" + step.Context.PatternValue + ")"
+		}
+	}
+	return label
+}
+
+/**
+ * patternSummary 列表中显示的模式描述，没有描述时显示步骤
+ */
+func patternSummary(pattern *models.Pattern) string {
+	if pattern.AIAnalysis != nil && pattern.AIAnalysis.SuggestedName != "" {
+		return pattern.AIAnalysis.SuggestedName
+	}
+	if pattern.Description != "" {
+		return pattern.Description
+	}
+	labels := make([]string, 0, len(pattern.Sequence))
+	for _, step := range pattern.Sequence {
+		labels = append(labels, stepLabel(step))
+	}
+	return strings.Join(labels, " → ")
+}
+
+func automateLabel(pattern *models.Pattern) string {
+	switch {
+	case pattern.AIAnalysis == nil:
+		return "-"
+	case pattern.AIAnalysis.ShouldAutomate:
+		return "是"
+	default:
+		return "否"
+	}
+}
+
+func formatTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Local().Format("2006-01-02 15:04")
+}
@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+/**
+ * deadLetterListLimit 统计死信时最多读取的条数
+ */
+const deadLetterListLimit = 10000
+
+/**
+ * statsView 统计的 JSON 输出结构
+ */
+type statsView struct {
+	TotalEvents      int64            `json:"total_events"`
+	EventsByType     map[string]int64 `json:"events_by_type"`
+	OldestEvent      *time.Time       `json:"oldest_event,omitempty"`
+	NewestEvent      *time.Time       `json:"newest_event,omitempty"`
+	Patterns         int              `json:"patterns"`
+	AnalyzedPatterns int              `json:"analyzed_patterns"`
+	ValuablePatterns int              `json:"valuable_patterns"`
+	DeadLetters      int              `json:"dead_letters"`
+	AISpentTodayUSD  float64          `json:"ai_spent_today_usd"`
+	AISpentMonthUSD  float64          `json:"ai_spent_month_usd"`
+}
+
+/**
+ * runStats 输出事件、模式、死信和 AI 花费统计
+ *
+ * Parameters:
+ *   - e: 运行环境
+ *   - args: 子命令参数
+ *
+ * Returns:
+ *   - error: 查询失败时返回错误
+ */
+func runStats(e *env, args []string) error {
+	flags := newFlagSet(e, "stats", "[-json]")
+	asJSON := flags.Bool("json", false, "输出 JSON")
+	if err := parseFlags(flags, args); err != nil {
+		return err
+	}
+
+	services, err := e.openServices()
+	if err != nil {
+		return err
+	}
+	defer services.Close()
+
+	eventStats, err := services.EventRepo.GetStats()
+	if err != nil {
+		return err
+	}
+	patterns, err := services.PatternRepo.FindAll()
+	if err != nil {
+		return err
+	}
+	deadLetters, err := services.DeadLetters.List(deadLetterListLimit)
+	if err != nil {
+		return err
+	}
+	usage, err := services.Usage.Report(1)
+	if err != nil {
+		return err
+	}
+
+	view := statsView{
+		TotalEvents:  eventStats.TotalCount,
+		EventsByType: eventStats.CountByType,
+		OldestEvent:  eventStats.OldestEvent,
+		NewestEvent:  eventStats.NewestEvent,
+		Patterns:     len(patterns),
+		DeadLetters:  len(deadLetters),
+
+		AISpentTodayUSD: usage.Budget.DailySpentUSD,
+		AISpentMonthUSD: usage.Budget.MonthlySpentUSD,
+	}
+	for _, pattern := range patterns {
+		if pattern.AIAnalysis == nil {
+			continue
+		}
+		view.AnalyzedPatterns++
+		if pattern.AIAnalysis.ShouldAutomate {
+			view.ValuablePatterns++
+		}
+	}
+
+	if *asJSON {
+		return printJSON(e.stdout, view)
+	}
+
+	tw := tabwriter.NewWriter(e.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "事件总数\t%d\n", view.TotalEvents)
+
+	types := make([]string, 0, len(view.EventsByType))
+	for eventType := range view.EventsByType {
+		types = append(types, eventType)
+	}
+	sort.Strings(types)
+	for _, eventType := range types {
+		fmt.Fprintf(tw, "  %s\t%d\n", eventType, view.EventsByType[eventType])
+	}
+
+	if view.OldestEvent != nil {
+		fmt.Fprintf(tw, "最早事件\t%s\n", formatTime(*view.OldestEvent))
+	}
+	if view.NewestEvent != nil {
+		fmt.Fprintf(tw, "最新事件\t%s\n", formatTime(*view.NewestEvent))
+	}
+	fmt.Fprintf(tw, "模式\t%d\n", view.Patterns)
+	fmt.Fprintf(tw, "已 AI 分析\t%d\n", view.AnalyzedPatterns)
+	fmt.Fprintf(tw, "值得自动化\t%d\n", view.ValuablePatterns)
+	fmt.Fprintf(tw, "死信\t%d\n", view.DeadLetters)
+	fmt.Fprintf(tw, "AI 花费（今日/本月）\t$%.4f / $%.4f\n", view.AISpentTodayUSD, view.AISpentMonthUSD)
+	return tw.Flush()
+}
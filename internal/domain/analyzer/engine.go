@@ -13,7 +13,9 @@ import (
 	"time"
 
 	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/cache"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/metrics"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
 	"github.com/chenyang-zz/flowmind/pkg/events"
 	"go.uber.org/zap"
@@ -67,6 +69,7 @@ type AnalyzerEngine struct {
 	aiFilter     *AIPatternFilter
 	eventRepo    storage.EventRepository
 	patternRepo  models.PatternRepository
+	sessionRepo  models.SessionRepository
 	eventBus     *events.EventBus
 
 	// 调度相关
@@ -112,6 +115,7 @@ type AnalysisResult struct {
  *   - config: 引擎配置
  *   - eventRepo: 事件仓储
  *   - patternRepo: 模式仓储
+ *   - sessionRepo: 会话仓储
  *   - eventBus: 事件总线
  *
  * Returns: *AnalyzerEngine - 分析引擎实例
@@ -120,6 +124,7 @@ func NewAnalyzerEngine(
 	config AnalyzerEngineConfig,
 	eventRepo storage.EventRepository,
 	patternRepo models.PatternRepository,
+	sessionRepo models.SessionRepository,
 	eventBus *events.EventBus,
 ) (*AnalyzerEngine, error) {
 	if eventRepo == nil {
@@ -128,6 +133,9 @@ func NewAnalyzerEngine(
 	if patternRepo == nil {
 		return nil, fmt.Errorf("模式仓储不能为空")
 	}
+	if sessionRepo == nil {
+		return nil, fmt.Errorf("会话仓储不能为空")
+	}
 	if eventBus == nil {
 		return nil, fmt.Errorf("事件总线不能为空")
 	}
@@ -135,10 +143,14 @@ func NewAnalyzerEngine(
 	// 创建模式挖掘器
 	patternMiner := NewPatternMiner(config.PatternMiner)
 
-	// 创建 AI 过滤器
-	aiFilter, err := NewAIPatternFilter(config.AIPatternFilter)
-	if err != nil {
-		return nil, fmt.Errorf("创建 AI 过滤器失败: %w", err)
+	// 创建 AI 过滤器（未启用 AI 分析时只做模式挖掘，不需要 AI 模型）
+	var aiFilter *AIPatternFilter
+	if config.EnableAIAnalysis {
+		var err error
+		aiFilter, err = NewAIPatternFilter(config.AIPatternFilter)
+		if err != nil {
+			return nil, fmt.Errorf("创建 AI 过滤器失败: %w", err)
+		}
 	}
 
 	return &AnalyzerEngine{
@@ -147,6 +159,7 @@ func NewAnalyzerEngine(
 		aiFilter:      aiFilter,
 		eventRepo:     eventRepo,
 		patternRepo:   patternRepo,
+		sessionRepo:   sessionRepo,
 		eventBus:      eventBus,
 		isRunning:     false,
 		lastAnalyzedAt: time.Time{}, // 初始化为零值，表示分析所有历史事件
@@ -343,9 +356,12 @@ func (e *AnalyzerEngine) AnalyzeNewEvents(ctx context.Context) (*AnalysisResult,
 func (e *AnalyzerEngine) AnalyzeRange(
 	ctx context.Context,
 	start, end time.Time,
-) (*AnalysisResult, error) {
-	result := &AnalysisResult{}
+) (result *AnalysisResult, err error) {
+	result = &AnalysisResult{}
 	startTime := time.Now()
+	defer func() {
+		metrics.ObserveAnalysis(time.Since(startTime), err)
+	}()
 
 	// 1. 读取事件
 	events, err := e.eventRepo.FindByTimeRange(start, end)
@@ -367,6 +383,11 @@ func (e *AnalyzerEngine) AnalyzeRange(
 		return result, nil
 	}
 
+	// 保存会话到数据库
+	if err := e.saveSessions(sessions); err != nil {
+		return nil, err
+	}
+
 	// 3. 挖掘模式
 	patterns, err := e.patternMiner.MineFromSessions(sessions)
 	if err != nil {
@@ -453,6 +474,31 @@ func (e *AnalyzerEngine) analyzePatternsWithAI(
 	return len(unanalyzed), nil
 }
 
+/**
+ * saveSessions 保存划分出的会话
+ *
+ * 分析的是历史事件，会话的结束时间取最后一个事件的时间
+ *
+ * Parameters:
+ *   - sessions: 会话列表
+ *
+ * Returns: error - 错误信息
+ */
+func (e *AnalyzerEngine) saveSessions(sessions []*models.Session) error {
+	for _, session := range sessions {
+		if session.EndTime == nil && len(session.Events) > 0 {
+			endTime := session.Events[len(session.Events)-1].Timestamp
+			session.EndTime = &endTime
+		}
+	}
+
+	if err := e.sessionRepo.SaveBatch(sessions); err != nil {
+		return fmt.Errorf("保存会话失败: %w", err)
+	}
+
+	return nil
+}
+
 /**
  * publishAnalysisResult 发布分析结果到事件总线
  *
@@ -484,6 +530,18 @@ func (e *AnalyzerEngine) GetLastAnalyzedTime() time.Time {
 	return e.lastAnalyzedAt
 }
 
+/**
+ * CacheStats 获取 AI 分析结果缓存的统计信息
+ *
+ * Returns: *cache.CacheStats - 缓存统计，未启用 AI 分析或缓存时为 nil
+ */
+func (e *AnalyzerEngine) CacheStats() *cache.CacheStats {
+	if e.aiFilter == nil {
+		return nil
+	}
+	return e.aiFilter.CacheStats()
+}
+
 /**
  * Close 关闭引擎并释放资源
  */
@@ -151,8 +151,8 @@ func (n *EventNormalizer) determineAction(event events.Event) string {
  * Returns: string - 标准化的动作类型
  */
 func (n *EventNormalizer) normalizeKeyboardAction(event events.Event) string {
-	if keyCode, ok := event.Data["keycode"].(float64); ok {
-		keyCodeInt := int(keyCode)
+	if data, ok := decodeKeyboard(event); ok {
+		keyCodeInt := data.KeyCode
 
 		// 功能键区域
 		if isFunctionKey(keyCodeInt) {
@@ -176,6 +176,27 @@ func (n *EventNormalizer) normalizeKeyboardAction(event events.Event) string {
 	return "keypress"
 }
 
+/**
+ * decodeKeyboard 解码键盘事件数据
+ *
+ * 实时事件的 keycode 是 int，从存储读出的是 float64，统一解码为类型化结构
+ *
+ * Parameters:
+ *   - event: 键盘事件
+ *
+ * Returns: events.KeyboardEventData - 键盘数据, bool - 缺少 keycode 或类型错误时为 false
+ */
+func decodeKeyboard(event events.Event) (events.KeyboardEventData, bool) {
+	var data events.KeyboardEventData
+	if _, ok := event.Data["keycode"]; !ok {
+		return data, false
+	}
+	if err := events.DecodePayload(event, &data); err != nil {
+		return data, false
+	}
+	return data, true
+}
+
 /**
  * normalizeClipboardAction 标准化剪贴板事件动作
  *
@@ -238,8 +259,8 @@ func (n *EventNormalizer) extractPatternValue(event events.Event) string {
 			return n.determineAction(event)
 		}
 		// 返回具体的按键码
-		if keyCode, ok := event.Data["keycode"].(float64); ok {
-			return intToString(int(keyCode))
+		if data, ok := decodeKeyboard(event); ok {
+			return intToString(data.KeyCode)
 		}
 
 	case events.EventTypeClipboard:
@@ -8,6 +8,7 @@ package analyzer
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -31,8 +32,23 @@ type AIPatternFilterConfig struct {
 	// CacheTTL 缓存过期时间
 	CacheTTL time.Duration
 
+	// Cache 分析结果缓存（如 SQLite 持久化缓存），为 nil 时使用内存缓存。
+	// 过滤器关闭时会停止该缓存
+	Cache cache.Cache
+
 	// MaxConcurrent 最大并发数
 	MaxConcurrent int
+
+	// Budget AI 花费预算，为 nil 时不限制
+	Budget BudgetChecker
+}
+
+/**
+ * BudgetChecker AI 花费预算检查
+ */
+type BudgetChecker interface {
+	// CheckBudget 超出预算时返回包装了 ai.ErrBudgetExceeded 的错误
+	CheckBudget(ctx context.Context) error
 }
 
 /**
@@ -80,7 +96,11 @@ func NewAIPatternFilter(config AIPatternFilterConfig) (*AIPatternFilter, error)
 
 	// 创建缓存实例
 	var cacheInstance cache.Cache
-	if config.CacheEnabled {
+	if config.CacheEnabled && config.Cache != nil {
+		cacheInstance = config.Cache
+		logger.Info("AI 分析缓存已启用",
+			zap.Duration("ttl", config.CacheTTL))
+	} else if config.CacheEnabled {
 		// 使用内存缓存，默认24小时TTL
 		cacheInstance = cache.NewMemoryCache(
 			1000,  // 最多1000个缓存项
@@ -116,7 +136,7 @@ func (f *AIPatternFilter) ShouldAutomate(ctx context.Context, pattern *models.Pa
 	if f.cache != nil {
 		cacheKey := f.buildCacheKey(pattern)
 		if cached, found := f.cache.Get(cacheKey); found {
-			if analysis, ok := cached.(*models.AIAnalysis); ok {
+			if analysis, ok := cached.(*models.AIAnalysis); ok && f.cacheable(analysis) {
 				logger.Info("从缓存获取 AI 分析结果",
 					zap.String("pattern_id", pattern.ID))
 				return analysis, nil
@@ -156,8 +176,15 @@ func (f *AIPatternFilter) ShouldAutomate(ctx context.Context, pattern *models.Pa
 		pattern.Description,
 	)
 
+	// 超出预算时只使用免费提供商
+	model, err := f.modelWithinBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// 调用 AI API
-	analysisResult, err := f.aiModel.AnalyzePattern(ctx, patternData)
+	ctx = ai.WithUsageInfo(ctx, pattern.ID, ai.PurposePatternAnalysis)
+	analysisResult, err := model.AnalyzePattern(ctx, patternData)
 	if err != nil {
 		logger.Error("AI 分析失败",
 			zap.String("pattern_id", pattern.ID),
@@ -174,6 +201,8 @@ func (f *AIPatternFilter) ShouldAutomate(ctx context.Context, pattern *models.Pa
 		SuggestedName:       analysisResult.SuggestedName,
 		SuggestedSteps:      analysisResult.SuggestedSteps,
 		AnalyzedAt:          analysisResult.AnalyzedAt,
+		PromptVersion:       analysisResult.PromptVersion,
+		Provider:            analysisResult.Provider,
 	}
 
 	logger.Info("AI 分析完成",
@@ -182,8 +211,12 @@ func (f *AIPatternFilter) ShouldAutomate(ctx context.Context, pattern *models.Pa
 		zap.String("reason", aiAnalysis.Reason),
 		zap.String("complexity", aiAnalysis.Complexity))
 
-	// 保存到缓存
-	if f.cache != nil {
+	// 保存到缓存，备用提供商的结果不缓存，首选提供商恢复后重新分析
+	if f.cache != nil && !f.cacheable(aiAnalysis) {
+		logger.Debug("AI 分析结果来自备用提供商，不缓存",
+			zap.String("pattern_id", pattern.ID),
+			zap.String("provider", aiAnalysis.Provider))
+	} else if f.cache != nil {
 		cacheKey := f.buildCacheKey(pattern)
 		if err := f.cache.Set(cacheKey, aiAnalysis, f.config.CacheTTL); err != nil {
 			logger.Warn("缓存设置失败",
@@ -259,8 +292,14 @@ func (f *AIPatternFilter) ShouldAutomateBatch(ctx context.Context, patterns []*m
 		)
 	}
 
+	// 超出预算时只使用免费提供商
+	model, err := f.modelWithinBudget(ctx)
+	if err != nil {
+		return nil, err
+	}
+
 	// 调用批量分析
-	batchResults, err := f.aiModel.AnalyzePatternBatch(ctx, patternsData)
+	batchResults, err := model.AnalyzePatternBatch(ctx, patternsData)
 	if err != nil {
 		logger.Error("批量 AI 分析失败", zap.Error(err))
 		return nil, fmt.Errorf("批量 AI 分析失败: %w", err)
@@ -277,6 +316,8 @@ func (f *AIPatternFilter) ShouldAutomateBatch(ctx context.Context, patterns []*m
 			SuggestedName:       result.SuggestedName,
 			SuggestedSteps:      result.SuggestedSteps,
 			AnalyzedAt:          result.AnalyzedAt,
+			PromptVersion:       result.PromptVersion,
+			Provider:            result.Provider,
 		}
 		results[pattern.ID] = aiAnalysis
 
@@ -348,15 +389,52 @@ func (f *AIPatternFilter) GetAnalysisSummary(analysis *models.AIAnalysis) string
 		}
 	}
 	summary += fmt.Sprintf("分析时间: %s\n", analysis.AnalyzedAt.Format("2006-01-02 15:04:05"))
+	if analysis.PromptVersion != "" {
+		summary += fmt.Sprintf("提示词版本: %s\n", analysis.PromptVersion)
+	}
 
 	return summary
 }
 
+/**
+ * modelWithinBudget 按预算选择本次分析使用的模型
+ *
+ * 未超出预算时使用配置的模型；超出后降级链只保留免费提供商（如 Ollama），
+ * 没有免费提供商时不再调用。预算查询失败时不阻止分析
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *
+ * Returns: ai.AIModel - 本次使用的模型, error - 超出预算且没有免费提供商时返回 ai.ErrBudgetExceeded
+ */
+func (f *AIPatternFilter) modelWithinBudget(ctx context.Context) (ai.AIModel, error) {
+	if f.config.Budget == nil || !ai.IsPaid(f.aiModel) {
+		return f.aiModel, nil
+	}
+
+	err := f.config.Budget.CheckBudget(ctx)
+	if err == nil {
+		return f.aiModel, nil
+	}
+	if !errors.Is(err, ai.ErrBudgetExceeded) {
+		logger.Warn("检查 AI 预算失败，继续分析", zap.Error(err))
+		return f.aiModel, nil
+	}
+
+	model, freeErr := ai.FreeOnly(f.aiModel)
+	if freeErr != nil {
+		logger.Warn("AI 花费超出预算，停止调用付费提供商", zap.Error(err))
+		return nil, err
+	}
+	logger.Info("AI 花费超出预算，只使用免费提供商", zap.Error(err))
+	return model, nil
+}
+
 /**
  * buildCacheKey 构建缓存键
  *
- * 使用模式序列的字符串表示和支持度作为缓存键，
- * 确保相同的模式序列能够命中缓存
+ * 只使用模式序列的字符串表示作为缓存键，支持度随数据积累增长，
+ * 不参与缓存键，确保相同的模式序列能够命中缓存
  *
  * Parameters:
  *   - pattern: 模式对象
@@ -374,8 +452,37 @@ func (f *AIPatternFilter) buildCacheKey(pattern *models.Pattern) string {
 		sequenceStr += "|"
 	}
 
-	// 使用模式序列和支持度生成缓存键
-	return fmt.Sprintf("pattern_analysis:%s:%d", sequenceStr, pattern.SupportCount)
+	return "pattern_analysis:" + sequenceStr
+}
+
+/**
+ * cacheable 判断分析结果是否可以缓存
+ *
+ * 降级链由备用提供商给出的结果只是临时替代，不写入缓存，也不从缓存返回
+ *
+ * Parameters:
+ *   - analysis: AI 分析结果
+ *
+ * Returns: bool - 结果来自首选提供商（或未经过降级链）时返回 true
+ */
+func (f *AIPatternFilter) cacheable(analysis *models.AIAnalysis) bool {
+	fallback, ok := f.aiModel.(*ai.FallbackModel)
+	if !ok || analysis.Provider == "" {
+		return true
+	}
+	return analysis.Provider == fallback.Primary()
+}
+
+/**
+ * CacheStats 获取分析结果缓存的统计信息
+ *
+ * Returns: *cache.CacheStats - 缓存统计，未启用缓存或缓存不提供统计时为 nil
+ */
+func (f *AIPatternFilter) CacheStats() *cache.CacheStats {
+	if withStats, ok := f.cache.(interface{ GetStats() *cache.CacheStats }); ok {
+		return withStats.GetStats()
+	}
+	return nil
 }
 
 /**
@@ -8,59 +8,22 @@ package analyzer
 
 import (
 	"context"
+	"fmt"
 	"testing"
 	"time"
 
 	"github.com/chenyang-zz/flowmind/internal/domain/models"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/ai"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/cache"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
 	"github.com/chenyang-zz/flowmind/pkg/events"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
-// MockAIModel 模拟 AI 模型（用于测试）
-type MockAIModel struct {
-	analyzeFunc func(context.Context, map[string]interface{}) (*ai.PatternAnalysis, error)
-}
-
-func (m *MockAIModel) AnalyzePattern(ctx context.Context, patternData map[string]interface{}) (*ai.PatternAnalysis, error) {
-	if m.analyzeFunc != nil {
-		return m.analyzeFunc(ctx, patternData)
-	}
-	// 默认返回成功响应
-	return &ai.PatternAnalysis{
-		ShouldAutomate:      true,
-		Reason:              "模拟分析结果",
-		EstimatedTimeSaving: 60,
-		Complexity:          "low",
-		SuggestedName:       "模拟自动化",
-		SuggestedSteps:      []string{"步骤1", "步骤2"},
-		AnalyzedAt:          time.Now(),
-	}, nil
-}
-
-func (m *MockAIModel) AnalyzePatternBatch(ctx context.Context, patterns []map[string]interface{}) ([]*ai.PatternAnalysis, error) {
-	results := make([]*ai.PatternAnalysis, len(patterns))
-	for i := range patterns {
-		analysis, err := m.AnalyzePattern(ctx, patterns[i])
-		if err != nil {
-			return nil, err
-		}
-		results[i] = analysis
-	}
-	return results, nil
-}
-
-func (m *MockAIModel) GetType() ai.ModelType {
-	return "mock"
-}
-
-func (m *MockAIModel) Close() error {
-	return nil
-}
-
 // TestNewAIPatternFilter 测试创建 AI 模式过滤器
 func TestNewAIPatternFilter(t *testing.T) {
-	mockModel := &MockAIModel{}
+	mockModel := ai.NewScriptedModel()
 
 	config := AIPatternFilterConfig{
 		AIModel:      mockModel,
@@ -94,7 +57,7 @@ func TestNewAIPatternFilter_NoAIModel(t *testing.T) {
 
 // TestNewAIPatternFilter_DefaultConfig 测试默认配置
 func TestNewAIPatternFilter_DefaultConfig(t *testing.T) {
-	mockModel := &MockAIModel{}
+	mockModel := ai.NewScriptedModel()
 	config := DefaultAIPatternFilterConfig()
 	config.AIModel = mockModel
 
@@ -109,19 +72,15 @@ func TestNewAIPatternFilter_DefaultConfig(t *testing.T) {
 // TestAIPatternFilter_ShouldAutomate 测试分析模式
 func TestAIPatternFilter_ShouldAutomate(t *testing.T) {
 	// 创建模拟 AI 模型
-	mockModel := &MockAIModel{
-		analyzeFunc: func(ctx context.Context, patternData map[string]interface{}) (*ai.PatternAnalysis, error) {
-			return &ai.PatternAnalysis{
-				ShouldAutomate:      true,
-				Reason:              "高频操作，值得自动化",
-				EstimatedTimeSaving: 45,
-				Complexity:          "medium",
-				SuggestedName:       "快捷操作",
-				SuggestedSteps:      []string{"检测触发", "执行操作"},
-				AnalyzedAt:          time.Now(),
-			}, nil
-		},
-	}
+	mockModel := ai.NewScriptedModel(ai.ScriptStep{Analysis: &ai.PatternAnalysis{
+		ShouldAutomate:      true,
+		Reason:              "高频操作，值得自动化",
+		EstimatedTimeSaving: 45,
+		Complexity:          "medium",
+		SuggestedName:       "快捷操作",
+		SuggestedSteps:      []string{"检测触发", "执行操作"},
+		PromptVersion:       "pattern_analysis.v1.zh",
+	}})
 
 	// 创建过滤器
 	config := AIPatternFilterConfig{AIModel: mockModel}
@@ -153,11 +112,12 @@ func TestAIPatternFilter_ShouldAutomate(t *testing.T) {
 	assert.Equal(t, "medium", analysis.Complexity)
 	assert.Equal(t, "快捷操作", analysis.SuggestedName)
 	assert.Len(t, analysis.SuggestedSteps, 2)
+	assert.Equal(t, "pattern_analysis.v1.zh", analysis.PromptVersion)
 }
 
 // TestAIPatternFilter_ShouldAutomate_AlreadyAnalyzed 测试已分析的模式
 func TestAIPatternFilter_ShouldAutomate_AlreadyAnalyzed(t *testing.T) {
-	mockModel := &MockAIModel{}
+	mockModel := ai.NewScriptedModel()
 
 	config := AIPatternFilterConfig{AIModel: mockModel}
 	filter, err := NewAIPatternFilter(config)
@@ -186,18 +146,12 @@ func TestAIPatternFilter_ShouldAutomate_AlreadyAnalyzed(t *testing.T) {
 
 // TestAIPatternFilter_ShouldAutomateBatch 测试批量分析
 func TestAIPatternFilter_ShouldAutomateBatch(t *testing.T) {
-	// 创建模拟 AI 模型
-	callCount := 0
-	mockModel := &MockAIModel{
-		analyzeFunc: func(ctx context.Context, patternData map[string]interface{}) (*ai.PatternAnalysis, error) {
-			callCount++
-			return &ai.PatternAnalysis{
-				ShouldAutomate: callCount%2 == 0, // 交替返回 true/false
-				Reason:        "批量分析结果",
-				AnalyzedAt:     time.Now(),
-			}, nil
-		},
-	}
+	// 创建模拟 AI 模型，交替返回 false/true
+	mockModel := ai.NewScriptedModel(
+		ai.ScriptStep{Analysis: &ai.PatternAnalysis{ShouldAutomate: false, Reason: "批量分析结果"}},
+		ai.ScriptStep{Analysis: &ai.PatternAnalysis{ShouldAutomate: true, Reason: "批量分析结果"}},
+		ai.ScriptStep{Analysis: &ai.PatternAnalysis{ShouldAutomate: false, Reason: "批量分析结果"}},
+	)
 
 	config := AIPatternFilterConfig{AIModel: mockModel}
 	filter, err := NewAIPatternFilter(config)
@@ -215,7 +169,7 @@ func TestAIPatternFilter_ShouldAutomateBatch(t *testing.T) {
 
 	assert.NoError(t, err)
 	assert.Len(t, results, 3)
-	assert.Equal(t, 3, callCount) // 应该调用3次
+	assert.Equal(t, 3, mockModel.CallCount()) // 应该调用3次
 
 	// 验证每个模式都有结果
 	for patternID, analysis := range results {
@@ -226,24 +180,21 @@ func TestAIPatternFilter_ShouldAutomateBatch(t *testing.T) {
 
 // TestAIPatternFilter_FilterValuablePatterns 测试过滤有价值模式
 func TestAIPatternFilter_FilterValuablePatterns(t *testing.T) {
-	mockModel := &MockAIModel{
-		analyzeFunc: func(ctx context.Context, patternData map[string]interface{}) (*ai.PatternAnalysis, error) {
-			// 处理 support_count 可能是 int 或 float64 的情况
-			var supportCount int
-			switch v := patternData["support_count"].(type) {
-			case int:
-				supportCount = v
-			case float64:
-				supportCount = int(v)
-			}
-			// 支持度>=8的才值得自动化
-			return &ai.PatternAnalysis{
-				ShouldAutomate: supportCount >= 8,
-				Reason:        "根据支持度判断",
-				AnalyzedAt:     time.Now(),
-			}, nil
-		},
-	}
+	mockModel := ai.NewScriptedModelFunc(func(patternData map[string]interface{}) ai.ScriptStep {
+		// 处理 support_count 可能是 int 或 float64 的情况
+		var supportCount int
+		switch v := patternData["support_count"].(type) {
+		case int:
+			supportCount = v
+		case float64:
+			supportCount = int(v)
+		}
+		// 支持度>=8的才值得自动化
+		return ai.ScriptStep{Analysis: &ai.PatternAnalysis{
+			ShouldAutomate: supportCount >= 8,
+			Reason:         "根据支持度判断",
+		}}
+	})
 
 	config := AIPatternFilterConfig{AIModel: mockModel}
 	filter, err := NewAIPatternFilter(config)
@@ -271,7 +222,7 @@ func TestAIPatternFilter_FilterValuablePatterns(t *testing.T) {
 
 // TestAIPatternFilter_GetAnalysisSummary 测试获取分析摘要
 func TestAIPatternFilter_GetAnalysisSummary(t *testing.T) {
-	mockModel := &MockAIModel{}
+	mockModel := ai.NewScriptedModel()
 	config := AIPatternFilterConfig{AIModel: mockModel}
 	filter, err := NewAIPatternFilter(config)
 	assert.NoError(t, err)
@@ -298,3 +249,252 @@ func TestAIPatternFilter_GetAnalysisSummary(t *testing.T) {
 	assert.Contains(t, summary, "步骤3")
 	assert.Contains(t, summary, "2026-01-30")
 }
+
+// TestAIPatternFilter_RecordReplay 测试录制真实模型的响应后离线回放整个分析流程
+func TestAIPatternFilter_RecordReplay(t *testing.T) {
+	dir := t.TempDir()
+	firstSeen := time.Date(2026, 1, 1, 9, 0, 0, 0, time.UTC)
+	newPattern := func() *models.Pattern {
+		return &models.Pattern{
+			ID:           "record-replay",
+			SupportCount: 6,
+			Confidence:   0.9,
+			FirstSeen:    firstSeen,
+			LastSeen:     firstSeen.Add(3 * time.Hour),
+			Sequence: []models.EventStep{
+				{Type: events.EventTypeClipboard, Action: "copy"},
+				{Type: events.EventTypeAppSwitch, Action: "switch"},
+			},
+		}
+	}
+
+	// 录制：用脚本模型代替真实提供商
+	upstream := ai.NewScriptedModel(ai.ScriptStep{Analysis: &ai.PatternAnalysis{
+		ShouldAutomate: true,
+		Reason:         "录制的响应",
+		SuggestedName:  "复制后切换",
+	}})
+	recorder, err := NewAIPatternFilter(AIPatternFilterConfig{AIModel: ai.NewRecordingModel(dir, upstream)})
+	assert.NoError(t, err)
+	_, err = recorder.ShouldAutomate(context.Background(), newPattern())
+	assert.NoError(t, err)
+
+	// 回放：不访问任何提供商
+	replayer, err := NewAIPatternFilter(AIPatternFilterConfig{AIModel: ai.NewReplayModel(dir)})
+	assert.NoError(t, err)
+	analysis, err := replayer.ShouldAutomate(context.Background(), newPattern())
+
+	assert.NoError(t, err)
+	assert.True(t, analysis.ShouldAutomate)
+	assert.Equal(t, "录制的响应", analysis.Reason)
+	assert.Equal(t, "复制后切换", analysis.SuggestedName)
+	assert.Equal(t, 1, upstream.CallCount())
+}
+
+// TestAIPatternFilter_PersistentCache 测试持久化缓存使重启后相同的模式不再重新分析
+func TestAIPatternFilter_PersistentCache(t *testing.T) {
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/cache.db"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, storage.RunMigrations(db))
+
+	newCache := func(namespace string) cache.Cache {
+		return cache.NewSQLiteCache(db, cache.SQLiteCacheConfig{
+			Namespace: namespace,
+			NewValue:  func() interface{} { return &models.AIAnalysis{} },
+		})
+	}
+	newPattern := func() *models.Pattern {
+		return &models.Pattern{
+			ID:           "persistent",
+			SupportCount: 8,
+			Sequence: []models.EventStep{
+				{Type: events.EventTypeClipboard, Action: "copy"},
+				{Type: events.EventTypeKeyboard, Action: "paste"},
+			},
+		}
+	}
+
+	model := ai.NewScriptedModel(ai.ScriptStep{Analysis: &ai.PatternAnalysis{
+		ShouldAutomate: true,
+		Reason:         "首次分析",
+		SuggestedSteps: []string{"复制", "粘贴"},
+	}})
+	filter, err := NewAIPatternFilter(AIPatternFilterConfig{
+		AIModel: model, CacheEnabled: true, Cache: newCache("pattern_analysis.v1.zh"),
+	})
+	require.NoError(t, err)
+	_, err = filter.ShouldAutomate(context.Background(), newPattern())
+	require.NoError(t, err)
+	require.NoError(t, filter.Close())
+
+	// 重启后命中缓存
+	restarted, err := NewAIPatternFilter(AIPatternFilterConfig{
+		AIModel: model, CacheEnabled: true, Cache: newCache("pattern_analysis.v1.zh"),
+	})
+	require.NoError(t, err)
+	defer restarted.Close()
+	analysis, err := restarted.ShouldAutomate(context.Background(), newPattern())
+	require.NoError(t, err)
+	assert.Equal(t, "首次分析", analysis.Reason)
+	assert.Equal(t, []string{"复制", "粘贴"}, analysis.SuggestedSteps)
+	assert.Equal(t, 1, model.CallCount())
+
+	// 支持度增长后相同的序列仍命中缓存
+	grown := newPattern()
+	grown.SupportCount = 20
+	_, err = restarted.ShouldAutomate(context.Background(), grown)
+	require.NoError(t, err)
+	assert.Equal(t, 1, model.CallCount())
+
+	// 提示词版本变化后重新分析
+	upgraded, err := NewAIPatternFilter(AIPatternFilterConfig{
+		AIModel: model, CacheEnabled: true, Cache: newCache("pattern_analysis.v2.zh"),
+	})
+	require.NoError(t, err)
+	defer upgraded.Close()
+	_, err = upgraded.ShouldAutomate(context.Background(), newPattern())
+	require.NoError(t, err)
+	assert.Equal(t, 2, model.CallCount())
+}
+
+// paidModel 把脚本模型标记为付费提供商
+type paidModel struct {
+	*ai.ReplayModel
+}
+
+func (m paidModel) GetType() ai.ModelType { return ai.ModelTypeClaude }
+
+// fakeBudget 可切换是否超出预算的预算检查
+type fakeBudget struct {
+	err error
+}
+
+func (b *fakeBudget) CheckBudget(ctx context.Context) error { return b.err }
+
+// TestAIPatternFilter_Budget 测试超出预算后只使用免费提供商
+func TestAIPatternFilter_Budget(t *testing.T) {
+	pattern := func(id string) *models.Pattern {
+		return &models.Pattern{
+			ID:           id,
+			SupportCount: 5,
+			FirstSeen:    time.Now().Add(-time.Hour),
+			LastSeen:     time.Now(),
+			Sequence:     []models.EventStep{{Type: events.EventTypeClipboard, Action: "copy"}},
+		}
+	}
+	cloud := ai.NewScriptedModel(ai.ScriptStep{Analysis: &ai.PatternAnalysis{Reason: "cloud"}})
+	local := ai.NewScriptedModel(ai.ScriptStep{Analysis: &ai.PatternAnalysis{Reason: "local"}})
+	chain, err := ai.NewFallbackModel([]ai.FallbackProvider{
+		{Name: "claude", Model: paidModel{cloud}},
+		{Name: "ollama", Model: local},
+	}, ai.FallbackConfig{})
+	assert.NoError(t, err)
+
+	budget := &fakeBudget{}
+	filter, err := NewAIPatternFilter(AIPatternFilterConfig{AIModel: chain, Budget: budget})
+	assert.NoError(t, err)
+
+	analysis, err := filter.ShouldAutomate(context.Background(), pattern("p1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cloud", analysis.Reason)
+
+	// 超出预算后跳过付费提供商
+	budget.err = fmt.Errorf("%w: 今日已花费 $1.0000，每日预算 $1.00", ai.ErrBudgetExceeded)
+	analysis, err = filter.ShouldAutomate(context.Background(), pattern("p2"))
+	assert.NoError(t, err)
+	assert.Equal(t, "local", analysis.Reason)
+	results, err := filter.ShouldAutomateBatch(context.Background(), []*models.Pattern{pattern("p3")})
+	assert.NoError(t, err)
+	assert.Equal(t, "local", results["p3"].Reason)
+	assert.Equal(t, 1, cloud.CallCount())
+
+	// 没有免费提供商时不再调用
+	paidOnly, err := NewAIPatternFilter(AIPatternFilterConfig{AIModel: paidModel{cloud}, Budget: budget})
+	assert.NoError(t, err)
+	_, err = paidOnly.ShouldAutomate(context.Background(), pattern("p4"))
+	assert.ErrorIs(t, err, ai.ErrBudgetExceeded)
+	assert.Equal(t, 1, cloud.CallCount())
+
+	// 预算查询失败时不阻止分析
+	budget.err = fmt.Errorf("数据库不可用")
+	analysis, err = paidOnly.ShouldAutomate(context.Background(), pattern("p5"))
+	assert.NoError(t, err)
+	assert.Equal(t, "cloud", analysis.Reason)
+}
+
+// TestAIPatternFilter_FallbackNotCached 测试备用提供商的结果不写入缓存
+func TestAIPatternFilter_FallbackNotCached(t *testing.T) {
+	pattern := func() *models.Pattern {
+		return &models.Pattern{
+			ID:           "fallback",
+			SupportCount: 5,
+			Sequence:     []models.EventStep{{Type: events.EventTypeClipboard, Action: "copy"}},
+		}
+	}
+	cloud := ai.NewScriptedModel(
+		ai.ScriptStep{Err: fmt.Errorf("服务不可用")},
+		ai.ScriptStep{Analysis: &ai.PatternAnalysis{Reason: "cloud"}},
+	)
+	local := ai.NewScriptedModel(ai.ScriptStep{Analysis: &ai.PatternAnalysis{Reason: "local"}})
+	chain, err := ai.NewFallbackModel([]ai.FallbackProvider{
+		{Name: "claude", Model: cloud},
+		{Name: "ollama", Model: local},
+	}, ai.FallbackConfig{})
+	require.NoError(t, err)
+
+	filter, err := NewAIPatternFilter(AIPatternFilterConfig{AIModel: chain, CacheEnabled: true})
+	require.NoError(t, err)
+	defer filter.Close()
+
+	// 主提供商失败，由备用提供商给出结果
+	analysis, err := filter.ShouldAutomate(context.Background(), pattern())
+	require.NoError(t, err)
+	assert.Equal(t, "local", analysis.Reason)
+	assert.Equal(t, "ollama", analysis.Provider)
+
+	// 主提供商恢复后重新分析，并缓存其结果
+	analysis, err = filter.ShouldAutomate(context.Background(), pattern())
+	require.NoError(t, err)
+	assert.Equal(t, "cloud", analysis.Reason)
+	assert.Equal(t, "claude", analysis.Provider)
+
+	analysis, err = filter.ShouldAutomate(context.Background(), pattern())
+	require.NoError(t, err)
+	assert.Equal(t, "cloud", analysis.Reason)
+	assert.Equal(t, 2, cloud.CallCount())
+	assert.Equal(t, 1, local.CallCount())
+}
+
+// TestAIPatternFilter_CacheStats 测试缓存命中和未命中计入统计
+func TestAIPatternFilter_CacheStats(t *testing.T) {
+	mockModel := ai.NewScriptedModel(ai.ScriptStep{Analysis: &ai.PatternAnalysis{
+		ShouldAutomate: true,
+		Reason:         "重复操作",
+	}})
+	filter, err := NewAIPatternFilter(AIPatternFilterConfig{AIModel: mockModel, CacheEnabled: true})
+	require.NoError(t, err)
+	defer filter.Close()
+
+	newPattern := func(id string) *models.Pattern {
+		return &models.Pattern{
+			ID:           id,
+			SupportCount: 5,
+			Sequence: []models.EventStep{
+				{Type: events.EventTypeKeyboard, Action: "keypress"},
+				{Type: events.EventTypeClipboard, Action: "copy"},
+			},
+		}
+	}
+	_, err = filter.ShouldAutomate(context.Background(), newPattern("first"))
+	require.NoError(t, err)
+	_, err = filter.ShouldAutomate(context.Background(), newPattern("second"))
+	require.NoError(t, err)
+
+	stats := filter.CacheStats()
+	require.NotNil(t, stats)
+	hits, misses, sets, _, _ := stats.GetStats()
+	assert.Equal(t, int64(1), hits)
+	assert.Equal(t, int64(1), misses)
+	assert.Equal(t, int64(1), sets)
+}
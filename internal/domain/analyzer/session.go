@@ -95,7 +95,7 @@ func (sd *SessionDivider) Divide(eventList []events.Event) []*models.Session {
 
 			// 创建新会话
 			currentSession = &models.Session{
-				ID:          uuid.New().String(),
+				ID:          sessionID(event, app),
 				StartTime:   event.Timestamp,
 				Application: app,
 				BundleID:    sd.getBundleID(event),
@@ -120,6 +120,25 @@ func (sd *SessionDivider) Divide(eventList []events.Event) []*models.Session {
 	return sessions
 }
 
+/**
+ * sessionID 根据会话的第一个事件和应用生成确定的会话 ID
+ *
+ * 重复分析重叠的时间范围时得到相同的 ID，保存时覆盖已有会话而不是重复插入
+ *
+ * Parameters:
+ *   - first: 会话的第一个事件
+ *   - app: 会话所在的应用
+ *
+ * Returns: string - 会话 ID（UUID 格式）
+ */
+func sessionID(first events.Event, app string) string {
+	key := first.ID
+	if key == "" {
+		key = first.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte("flowmind/session/"+key+"/"+app)).String()
+}
+
 /**
  * shouldStartNewSession 判断是否应该开始新会话
  *
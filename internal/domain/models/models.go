@@ -39,6 +39,9 @@ type Session struct {
 
 	// Events 会话包含的事件列表（按时间顺序）
 	Events []events.Event
+
+	// EventIDs 会话包含的事件ID列表（持久化时保存，从仓储加载时不含完整事件）
+	EventIDs []string
 }
 
 /**
@@ -177,6 +180,75 @@ type AIAnalysis struct {
 
 	// AnalyzedAt 分析时间
 	AnalyzedAt time.Time
+
+	// PromptVersion 生成该结果的提示词模板，用于比较不同版本提示词的效果
+	PromptVersion string
+
+	// Provider 给出该结果的 AI 提供商，未经过降级链时为空
+	Provider string
+}
+
+/**
+ * Automation 自动化
+ *
+ * 由用户根据模式（或手动）创建的自动化流程
+ */
+type Automation struct {
+	// ID 自动化唯一标识
+	ID string
+
+	// Name 自动化名称
+	Name string
+
+	// Description 自动化描述
+	Description string
+
+	// PatternID 来源模式ID（手动创建时为空）
+	PatternID string
+
+	// Steps 自动化步骤
+	Steps []string
+
+	// Enabled 是否启用
+	Enabled bool
+
+	// CreatedAt 创建时间
+	CreatedAt time.Time
+
+	// UpdatedAt 更新时间
+	UpdatedAt time.Time
+}
+
+/**
+ * AIUsage 一次 AI 调用的用量和费用
+ */
+type AIUsage struct {
+	// ID 记录ID
+	ID int64
+
+	// Provider 提供商
+	Provider string
+
+	// Model 模型名称
+	Model string
+
+	// PatternID 被分析的模式ID（与模式无关的调用为空）
+	PatternID string
+
+	// Purpose 调用目的（pattern_analysis、output_repair）
+	Purpose string
+
+	// InputTokens 输入 token 数
+	InputTokens int
+
+	// OutputTokens 输出 token 数
+	OutputTokens int
+
+	// CostUSD 按价格表计算的费用（美元）
+	CostUSD float64
+
+	// CreatedAt 调用时间
+	CreatedAt time.Time
 }
 
 /**
@@ -188,6 +260,9 @@ type SessionRepository interface {
 	// Save 保存会话
 	Save(session *Session) error
 
+	// SaveBatch 批量保存会话
+	SaveBatch(sessions []*Session) error
+
 	// FindByID 根据ID查询会话
 	FindByID(id string) (*Session, error)
 
@@ -228,3 +303,38 @@ type PatternRepository interface {
 	// Delete 删除模式
 	Delete(id string) error
 }
+
+/**
+ * AutomationRepository 自动化仓储接口
+ *
+ * 定义自动化持久化的操作
+ */
+type AutomationRepository interface {
+	// Save 保存自动化
+	Save(automation *Automation) error
+
+	// FindByID 根据ID查询自动化
+	FindByID(id string) (*Automation, error)
+
+	// FindAll 查询所有自动化（按创建时间倒序）
+	FindAll() ([]*Automation, error)
+
+	// Count 统计自动化数量
+	Count() (int, error)
+}
+
+/**
+ * AIUsageRepository AI 用量仓储接口
+ *
+ * 定义 AI 调用用量持久化和花费统计的操作
+ */
+type AIUsageRepository interface {
+	// Save 保存一次调用的用量
+	Save(usage *AIUsage) error
+
+	// FindByTimeRange 按时间范围查询用量（包含 start，不包含 end）
+	FindByTimeRange(start, end time.Time) ([]*AIUsage, error)
+
+	// TotalCost 统计时间范围内的总费用（包含 start，不包含 end）
+	TotalCost(start, end time.Time) (float64, error)
+}
@@ -49,8 +49,8 @@ type AppTracker struct {
 	// sessions 活跃的会话映射（应用名称 → 会话）
 	sessions map[string]*AppSession
 
-	// eventBus 事件总线，用于发布应用会话事件
-	eventBus *events.EventBus
+	// eventBus 事件发布者（事件总线或监控引擎的过滤闸门），用于发布应用会话事件
+	eventBus events.Publisher
 
 	// mu 读写锁，保护并发访问
 	mu sync.RWMutex
@@ -61,10 +61,10 @@ type AppTracker struct {
 // 创建一个新的应用会话追踪器实例。
 //
 // Parameters:
-//   - eventBus: 事件总线实例，用于发布应用会话事件
+//   - eventBus: 事件发布者，用于发布应用会话事件
 //
 // Returns: *AppTracker - 新创建的应用会话追踪器实例
-func NewAppTracker(eventBus *events.EventBus) *AppTracker {
+func NewAppTracker(eventBus events.Publisher) *AppTracker {
 	return &AppTracker{
 		sessions: make(map[string]*AppSession),
 		eventBus: eventBus,
@@ -191,12 +191,12 @@ func (at *AppTracker) publishSessionEvent(session *AppSession) {
 	duration := session.Duration()
 
 	// 构造应用会话事件
-	sessionEvent := events.NewEvent(events.EventTypeAppSession, map[string]interface{}{
-		"app_name":  session.AppName,
-		"bundle_id": session.BundleID,
-		"start":     session.Start,
-		"end":       session.End,
-		"duration":  duration.Seconds(), // 时长（秒）
+	sessionEvent := events.NewPayloadEvent(events.AppSessionEventData{
+		AppName:  session.AppName,
+		BundleID: session.BundleID,
+		Start:    session.Start,
+		End:      session.End,
+		Duration: duration.Seconds(), // 时长（秒）
 	})
 
 	// 发布事件到事件总线
@@ -25,8 +25,8 @@ type ApplicationMonitor struct {
 	// platform 平台层应用切换监控器，负责与操作系统交互
 	platform platform.AppSwitchMonitor
 
-	// eventBus 事件总线，用于发布应用切换事件
-	eventBus *events.EventBus
+	// eventBus 事件发布者（事件总线或监控引擎的过滤闸门），用于发布应用切换事件
+	eventBus events.Publisher
 
 	// contextMgr 上下文管理器，用于获取当前应用信息
 	contextMgr platform.ContextProvider
@@ -46,10 +46,10 @@ type ApplicationMonitor struct {
 // 创建一个新的应用监控器实例，并初始化其依赖的平台层组件、上下文管理器和应用会话追踪器。
 //
 // Parameters:
-//   - eventBus: 事件总线实例，用于发布应用切换事件
+//   - eventBus: 事件发布者，用于发布应用切换事件
 //
 // Returns: Monitor - 新创建的应用监控器实例（返回接口类型）
-func NewApplicationMonitor(eventBus *events.EventBus) Monitor {
+func NewApplicationMonitor(eventBus events.Publisher) Monitor {
 	return &ApplicationMonitor{
 		platform:   platform.NewAppSwitchMonitor(),
 		eventBus:   eventBus,
@@ -147,11 +147,11 @@ func (am *ApplicationMonitor) handlePlatformEvent(event platform.AppSwitchEvent)
 	am.appTracker.SwitchApp(event.From, event.To, event.BundleID)
 
 	// 构造应用切换事件
-	appSwitchEvent := events.NewEvent(events.EventTypeAppSwitch, map[string]interface{}{
-		"from":      event.From,
-		"to":        event.To,
-		"bundle_id": event.BundleID,
-		"window":    event.Window,
+	appSwitchEvent := events.NewPayloadEvent(events.AppSwitchEventData{
+		From:     event.From,
+		To:       event.To,
+		BundleID: event.BundleID,
+		Window:   event.Window,
 	})
 
 	// 添加上下文信息
@@ -3,6 +3,7 @@ package monitor
 import (
 	"sync"
 
+	"github.com/chenyang-zz/flowmind/internal/domain/privacy"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/platform"
 	"github.com/chenyang-zz/flowmind/pkg/events"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
@@ -12,12 +13,12 @@ import (
 // ClipboardMonitor 剪贴板监控器（业务层）
 //
 // 负责剪贴板内容变化的监控和事件处理。本监控器采用分层架构：
-//   - 业务层（本结构体）：处理事件、添加上下文、发布到事件总线
+//   - 业务层（本结构体）：脱敏、处理事件、添加上下文、发布到事件总线
 //   - 平台层（platform字段）：与操作系统交互，监控剪贴板变化
 //
 // 工作流程：
 //   1. 平台层检测到剪贴板内容变化
-//   2. 业务层接收平台事件
+//   2. 业务层接收平台事件，经脱敏流水线处理敏感内容
 //   3. 获取当前应用上下文信息
 //   4. 构造业务事件并附加上下文
 //   5. 发布到事件总线供其他模块消费
@@ -25,12 +26,15 @@ type ClipboardMonitor struct {
 	// platform 平台层剪贴板监控器，负责与操作系统交互
 	platform platform.ClipboardMonitor
 
-	// eventBus 事件总线，用于发布剪贴板事件
-	eventBus *events.EventBus
+	// eventBus 事件发布者（事件总线或监控引擎的过滤闸门），用于发布剪贴板事件
+	eventBus events.Publisher
 
 	// contextMgr 上下文管理器，用于获取当前应用信息
 	contextMgr platform.ContextProvider
 
+	// redactor 脱敏流水线，事件内容和日志预览都经过它处理
+	redactor *privacy.Pipeline
+
 	// isRunning 监控器运行状态标志
 	isRunning bool
 
@@ -44,16 +48,38 @@ type ClipboardMonitor struct {
 // NewClipboardMonitor 创建剪贴板监控器
 //
 // 创建一个新的剪贴板监控器实例，并初始化其依赖的平台层组件和上下文管理器。
+// 使用内置检测器和默认动作的脱敏流水线。
 //
 // Parameters:
-//   - eventBus: 事件总线实例，用于发布剪贴板事件
+//   - eventBus: 事件发布者，用于发布剪贴板事件
 //
 // Returns: Monitor - 新创建的剪贴板监控器实例（返回接口类型）
-func NewClipboardMonitor(eventBus *events.EventBus) Monitor {
+func NewClipboardMonitor(eventBus events.Publisher) Monitor {
+	return newClipboardMonitor(eventBus, platform.NewClipboardMonitor(), privacy.NewDefaultPipeline())
+}
+
+// newClipboardMonitor 使用指定的平台层监控器创建剪贴板监控器
+//
+// Parameters:
+//   - eventBus: 事件发布者，用于发布剪贴板事件
+//   - clipboardMonitor: 平台层剪贴板监控器
+//   - redactor: 脱敏流水线，为 nil 时使用默认流水线
+//
+// Returns: *ClipboardMonitor - 剪贴板监控器实例
+func newClipboardMonitor(
+	eventBus events.Publisher,
+	clipboardMonitor platform.ClipboardMonitor,
+	redactor *privacy.Pipeline,
+) *ClipboardMonitor {
+	if redactor == nil {
+		redactor = privacy.NewDefaultPipeline()
+	}
+
 	return &ClipboardMonitor{
-		platform:   platform.NewClipboardMonitor(),
+		platform:   clipboardMonitor,
 		eventBus:   eventBus,
 		contextMgr: platform.NewContextProvider(),
+		redactor:   redactor,
 	}
 }
 
@@ -135,10 +161,10 @@ func (cm *ClipboardMonitor) IsRunning() bool {
 // 作为平台层监控器的回调函数，接收剪贴板事件并将其转换为业务事件。
 // 处理流程：
 //   1. 检查内容是否与上次相同（去重）
-//   2. 从上下文管理器获取当前应用信息
-//   3. 提取剪贴板事件的关键信息（内容、类型、大小）
-//   4. 构造业务剪贴板事件
-//   5. 附加上下文信息（当前应用）
+//   2. 脱敏：命中 drop 规则时丢弃事件，否则替换敏感片段
+//   3. 从上下文管理器获取当前应用信息
+//   4. 提取剪贴板事件的关键信息（脱敏后的内容、类型、大小）
+//   5. 构造业务剪贴板事件并附加上下文信息（当前应用）
 //   6. 发布到事件总线
 //
 // Parameters:
@@ -155,35 +181,47 @@ func (cm *ClipboardMonitor) handlePlatformEvent(event platform.ClipboardEvent) {
 	cm.lastContent = event.Content
 	cm.mu.Unlock()
 
-	// 记录日志（截取内容以避免日志过长）
-	contentPreview := event.Content
-	if len(contentPreview) > 100 {
-		contentPreview = contentPreview[:100] + "..."
+	// 2. 脱敏
+	redacted := cm.redactor.Redact(event.Content)
+	if redacted.Dropped {
+		logger.Info("剪贴板内容包含敏感信息，已丢弃",
+			zap.String("component", "clipboard"),
+			zap.String("type", event.Type),
+			zap.Int64("size", event.Size),
+			zap.Strings("detectors", redacted.Kinds()),
+		)
+		return
 	}
 
-	logger.Info("检测到剪贴板内容变化",
+	// 记录日志：此时事件尚未经过引擎的暂停、忽略和隐私规则检查，不记录任何内容
+	logger.Debug("检测到剪贴板内容变化",
 		zap.String("component", "clipboard"),
 		zap.String("type", event.Type),
 		zap.Int64("size", event.Size),
-		zap.String("preview", contentPreview),
+		zap.Strings("redacted", redacted.Kinds()),
 	)
 
-	// 2. 获取上下文
+	// 3. 获取上下文
 	context := cm.contextMgr.GetContext()
 
-	// 3. 构造业务事件数据
-	data := map[string]interface{}{
-		"content": event.Content,
-		"type":    event.Type,
-		"size":    event.Size,
-		"length":  len(event.Content),
+	// 4. 构造业务事件数据（长度和大小按脱敏后的内容计算，避免泄露敏感信息的长度）
+	data := events.ClipboardEventData{
+		Content:    redacted.Text,
+		Type:       event.Type,
+		Size:       event.Size,
+		Length:     len(redacted.Text),
+		Redacted:   redacted.Redacted(),
+		Redactions: redacted.Findings,
+	}
+	if data.Redacted {
+		data.Size = int64(len(redacted.Text))
 	}
 
-	// 4. 创建业务事件
-	businessEvent := events.NewEvent(events.EventTypeClipboard, data)
+	// 5. 创建业务事件
+	businessEvent := events.NewPayloadEvent(data)
 	businessEvent.WithContext(context)
 
-	// 5. 发布到事件总线
+	// 6. 发布到事件总线
 	if err := cm.eventBus.Publish(string(events.EventTypeClipboard), *businessEvent); err != nil {
 		logger.Error("发布剪贴板事件失败",
 			zap.String("component", "clipboard"),
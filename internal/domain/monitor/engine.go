@@ -2,8 +2,11 @@ package monitor
 
 import (
 	"fmt"
+	"strings"
 	"sync"
 
+	"github.com/chenyang-zz/flowmind/internal/domain/privacy"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
 	"github.com/chenyang-zz/flowmind/pkg/events"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/platform"
@@ -14,47 +17,119 @@ import (
 // Engine 监控引擎，管理所有监控器
 //
 // 负责统一管理和协调各个监控器的生命周期，是监控系统的核心组件。
-// 引擎维护所有监控器的运行状态，并提供统一的启动/停止接口。
+// 引擎根据配置从注册表中创建启用的监控器，所有监控器通过引擎的发布闸门发布事件，
+// 闸门会在事件进入事件总线前执行 ignore_apps / ignore_window_titles 过滤和按应用的隐私策略，
+// 并在监控暂停期间丢弃除快捷键外的所有事件。
 // 同时负责发布监控引擎的状态变更事件到事件总线。
 type Engine struct {
-	// keyboard 键盘监控器实例
-	keyboard Monitor
+	// registry 监控器注册表
+	registry *Registry
 
-	// clipboard 剪贴板监控器实例
-	clipboard Monitor
-
-	// application 应用监控器实例
-	application Monitor
+	// config 监控配置
+	config config.MonitorConfig
 
 	// eventBus 事件总线，用于发布和订阅事件
 	eventBus *events.EventBus
 
+	// gate 事件发布闸门，监控器通过它发布事件
+	gate *EventGate
+
+	// policy 按应用的隐私策略
+	policy *privacy.Policy
+
+	// monitors 已启动的监控器（按名称）
+	monitors map[string]Monitor
+
+	// started 已启动的监控器名称（按启动顺序）
+	started []string
+
+	// failed 启动失败的监控器及原因
+	failed map[string]string
+
 	// isRunning 引擎运行状态标志
 	isRunning bool
 
 	// mu 读写锁，保护并发访问
 	mu sync.RWMutex
+
+	// pause 暂停状态，使用独立的锁，避免监控器在停止时发布事件与 mu 死锁
+	pause pauseState
+
+	// toggleSub 切换监控快捷键事件的订阅 ID
+	toggleSub string
+
+	// statusSub 引擎状态查询应答者的订阅 ID
+	statusSub string
 }
 
 // NewEngine 创建监控引擎
 //
+// 使用默认配置和内置注册表，启用 keyboard、clipboard 和 application 监控器。
+//
 // Parameters:
 //   - eventBus: 事件总线实例，用于发布监控事件
 //
 // Returns: Monitor - 新创建的监控引擎实例（返回接口类型）
 func NewEngine(eventBus *events.EventBus) Monitor {
-	return &Engine{
+	return NewEngineWithConfig(eventBus, nil, nil)
+}
+
+// NewEngineWithConfig 根据应用配置创建监控引擎
+//
+// Parameters:
+//   - eventBus: 事件总线实例，用于发布监控事件
+//   - cfg: 应用配置，为 nil 时使用默认监控配置
+//   - registry: 监控器注册表，为 nil 时使用内置注册表
+//
+// Returns: *Engine - 新创建的监控引擎实例
+func NewEngineWithConfig(eventBus *events.EventBus, cfg *config.Config, registry *Registry) *Engine {
+	var monitorConfig config.MonitorConfig
+	if cfg != nil {
+		monitorConfig = cfg.Monitor
+	}
+	if registry == nil {
+		registry = NewDefaultRegistry()
+	}
+
+	policy, err := privacy.NewPolicyFromConfig(monitorConfig.Privacy)
+	if err != nil {
+		logger.Error("隐私规则配置无效，使用内置规则",
+			zap.String("component", "engine"),
+			zap.Error(err),
+		)
+		policy = privacy.NewDefaultPolicy()
+	}
+
+	gate := NewEventGate(eventBus)
+
+	engine := &Engine{
+		registry: registry,
+		config:   monitorConfig,
 		eventBus: eventBus,
+		gate:     gate,
+		policy:   policy,
+		monitors: make(map[string]Monitor),
+		failed:   make(map[string]string),
 	}
+
+	gate.AddCheck("pause", engine.pauseCheck)
+	ignoreFilter := NewIgnoreFilter(monitorConfig.Filters)
+	if !ignoreFilter.IsEmpty() {
+		gate.AddCheck("ignore_filter", engine.ignoreCheck(ignoreFilter))
+	}
+	gate.AddCheck("privacy", engine.privacyCheck)
+
+	return engine
 }
 
 // Start 启动监控引擎
 //
-// 初始化并启动所有监控器，包括键盘监控器等。
-// 启动成功后会发布状态事件到事件总线。
-// 如果引擎已经运行，则返回错误。
+// 按配置依次创建并启动启用的监控器，每个监控器启动前检查其所需的系统权限。
+// 非必需监控器启动失败只记录在状态事件中；必需监控器失败或没有任何监控器启动成功时，
+// 已启动的监控器会被停止并返回错误。
+// 启动成功后会发布包含 monitors（已启动）和 failed（失败原因）的状态事件。
 //
-// Returns: error - 启动失败时返回错误，如引擎已运行或监控器启动失败
+// Returns: error - 启动失败时返回错误，如引擎已运行或必需监控器启动失败
 func (e *Engine) Start() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
@@ -66,72 +141,86 @@ func (e *Engine) Start() error {
 
 	logger.Info("启动监控引擎", zap.String("component", "engine"))
 
-	// 权限检查：在启动监控器前验证系统权限
-	logger.Info("检查系统权限", zap.String("component", "engine"))
-	permChecker := platform.NewPermissionChecker()
-	permManager := services.NewPermissionManager(permChecker, e.eventBus)
+	e.monitors = make(map[string]Monitor)
+	e.started = nil
+	e.failed = make(map[string]string)
 
-	// 检查辅助功能权限（键盘监控需要）
-	if err := permManager.EnsurePermission(platform.PermissionAccessibility); err != nil {
-		logger.Error("辅助功能权限检查失败",
-			zap.String("component", "engine"),
-			zap.Error(err),
-		)
+	deps := MonitorDeps{
+		EventBus:  e.eventBus,
+		Publisher: e.gate,
+		Config:    e.config,
+	}
+	permManager := services.NewPermissionManager(platform.NewPermissionChecker(), e.eventBus)
 
-		// 尝试打开系统设置，引导用户授权
-		_ = permManager.OpenSystemSettings(platform.PermissionAccessibility)
+	var fatalErr error
+	for _, name := range e.enabledMonitors() {
+		registration, ok := e.registry.Lookup(name)
+		if !ok {
+			logger.Warn("未注册的监控器，已跳过",
+				zap.String("component", "engine"),
+				zap.String("monitor", name),
+			)
+			e.failed[name] = "未注册的监控器"
+			continue
+		}
 
-		return fmt.Errorf("缺少辅助功能权限，监控器无法启动: %w", err)
+		if err := e.startMonitor(registration, deps, permManager); err != nil {
+			e.failed[name] = err.Error()
+			if registration.Required {
+				fatalErr = fmt.Errorf("启动必需监控器 %s 失败: %w", name, err)
+				break
+			}
+			logger.Warn("监控器启动失败，但引擎继续运行",
+				zap.String("component", "engine"),
+				zap.String("monitor", name),
+				zap.Error(err),
+			)
+		}
 	}
 
-	logger.Info("权限检查通过", zap.String("component", "engine"))
-
-	// 初始化并启动键盘监控器
-	e.keyboard = NewKeyboardMonitor(e.eventBus)
-	if err := e.keyboard.Start(); err != nil {
-		logger.Error("启动键盘监控器失败",
-			zap.String("component", "engine"),
-			zap.Error(err),
-		)
-		return fmt.Errorf("failed to start keyboard monitor: %w", err)
+	if fatalErr == nil && len(e.started) == 0 {
+		fatalErr = fmt.Errorf("没有监控器启动成功")
 	}
 
-	// 初始化并启动剪贴板监控器
-	e.clipboard = NewClipboardMonitor(e.eventBus)
-	if err := e.clipboard.Start(); err != nil {
-		logger.Error("启动剪贴板监控器失败",
-			zap.String("component", "engine"),
-			zap.Error(err),
-		)
-		// 剪贴板监控器启动失败不影响引擎启动，只记录日志
-		logger.Warn("剪贴板监控器启动失败，但引擎继续运行",
+	if fatalErr != nil {
+		logger.Error("监控引擎启动失败",
 			zap.String("component", "engine"),
-			zap.Error(err),
+			zap.Error(fatalErr),
 		)
+		e.stopMonitors()
+
+		statusEvent := events.NewEvent(events.EventTypeStatus, map[string]interface{}{
+			"status": "failed",
+			"error":  fatalErr.Error(),
+			"failed": e.failedSnapshot(),
+		})
+		e.eventBus.Publish(string(events.EventTypeStatus), *statusEvent)
+
+		return fatalErr
 	}
 
-	// 初始化并启动应用监控器
-	e.application = NewApplicationMonitor(e.eventBus)
-	if err := e.application.Start(); err != nil {
-		logger.Error("启动应用监控器失败",
-			zap.String("component", "engine"),
-			zap.Error(err),
-		)
-		// 应用监控器启动失败不影响引擎启动，只记录日志
-		logger.Warn("应用监控器启动失败，但引擎继续运行",
+	e.isRunning = true
+	e.toggleSub = e.eventBus.SubscribeCaused(string(EventTypeHotkeyToggleMonitoring), e.handleToggleMonitoring)
+	if statusSub, err := e.eventBus.Respond(TopicEngineStatus, e.handleStatusRequest); err != nil {
+		logger.Warn("注册引擎状态应答者失败",
 			zap.String("component", "engine"),
 			zap.Error(err),
 		)
+	} else {
+		e.statusSub = statusSub
 	}
 
-	e.isRunning = true
-
-	logger.Info("监控引擎启动成功", zap.String("component", "engine"))
+	logger.Info("监控引擎启动成功",
+		zap.String("component", "engine"),
+		zap.Strings("monitors", e.started),
+		zap.Int("failed", len(e.failed)),
+	)
 
 	// 发布状态事件
 	statusEvent := events.NewEvent(events.EventTypeStatus, map[string]interface{}{
 		"status":   "started",
-		"monitors": []string{"keyboard", "clipboard", "application"},
+		"monitors": append([]string(nil), e.started...),
+		"failed":   e.failedSnapshot(),
 	})
 	e.eventBus.Publish(string(events.EventTypeStatus), *statusEvent)
 
@@ -140,7 +229,7 @@ func (e *Engine) Start() error {
 
 // Stop 停止监控引擎
 //
-// 停止所有正在运行的监控器并释放相关资源。
+// 按启动的相反顺序停止所有监控器并释放相关资源。
 // 停止成功后会发布状态事件到事件总线。
 // 如果引擎未运行，则返回错误。
 //
@@ -156,38 +245,17 @@ func (e *Engine) Stop() error {
 
 	logger.Info("停止监控引擎", zap.String("component", "engine"))
 
-	// 停止应用监控器
-	if e.application != nil {
-		if err := e.application.Stop(); err != nil {
-			logger.Error("停止应用监控器失败",
-				zap.String("component", "engine"),
-				zap.Error(err),
-			)
-			// 继续停止其他监控器
-		}
+	if e.toggleSub != "" {
+		e.eventBus.Unsubscribe(e.toggleSub)
+		e.toggleSub = ""
 	}
-
-	// 停止剪贴板监控器
-	if e.clipboard != nil {
-		if err := e.clipboard.Stop(); err != nil {
-			logger.Error("停止剪贴板监控器失败",
-				zap.String("component", "engine"),
-				zap.Error(err),
-			)
-			// 继续停止其他监控器
-		}
+	if e.statusSub != "" {
+		e.eventBus.Unsubscribe(e.statusSub)
+		e.statusSub = ""
 	}
 
-	// 停止键盘监控器
-	if e.keyboard != nil {
-		if err := e.keyboard.Stop(); err != nil {
-			logger.Error("停止键盘监控器失败",
-				zap.String("component", "engine"),
-				zap.Error(err),
-			)
-			return fmt.Errorf("failed to stop keyboard monitor: %w", err)
-		}
-	}
+	err := e.stopMonitors()
+	e.clearPause()
 
 	e.isRunning = false
 
@@ -199,7 +267,7 @@ func (e *Engine) Stop() error {
 	})
 	e.eventBus.Publish(string(events.EventTypeStatus), *statusEvent)
 
-	return nil
+	return err
 }
 
 // IsRunning 检查运行状态
@@ -213,32 +281,235 @@ func (e *Engine) IsRunning() bool {
 	return e.isRunning
 }
 
+// GetMonitor 获取指定名称的监控器实例
+//
+// Parameters:
+//   - name: 监控器名称
+//
+// Returns: Monitor - 监控器实例，未启动时为 nil
+func (e *Engine) GetMonitor(name string) Monitor {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.monitors[name]
+}
+
 // GetKeyboardMonitor 获取键盘监控器实例
 //
 // 返回引擎管理的键盘监控器实例，可用于直接访问键盘监控器。
-// 注意：返回的实例可能为 nil（在引擎未启动时）。
+// 注意：返回的实例可能为 nil（在引擎未启动或未启用键盘监控时）。
 //
 // Returns: Monitor - 键盘监控器实例，可能为 nil
 func (e *Engine) GetKeyboardMonitor() Monitor {
-	return e.keyboard
+	return e.GetMonitor(MonitorKeyboard)
 }
 
 // GetClipboardMonitor 获取剪贴板监控器实例
 //
 // 返回引擎管理的剪贴板监控器实例，可用于直接访问剪贴板监控器。
-// 注意：返回的实例可能为 nil（在引擎未启动时）。
+// 注意：返回的实例可能为 nil（在引擎未启动或未启用剪贴板监控时）。
 //
 // Returns: Monitor - 剪贴板监控器实例，可能为 nil
 func (e *Engine) GetClipboardMonitor() Monitor {
-	return e.clipboard
+	return e.GetMonitor(MonitorClipboard)
 }
 
 // GetApplicationMonitor 获取应用监控器实例
 //
 // 返回引擎管理的应用监控器实例，可用于直接访问应用监控器。
-// 注意：返回的实例可能为 nil（在引擎未启动时）。
+// 注意：返回的实例可能为 nil（在引擎未启动或未启用应用监控时）。
 //
 // Returns: Monitor - 应用监控器实例，可能为 nil
 func (e *Engine) GetApplicationMonitor() Monitor {
-	return e.application
+	return e.GetMonitor(MonitorApplication)
+}
+
+// StartedMonitors 获取已启动的监控器名称
+//
+// Returns: []string - 按启动顺序排列的监控器名称
+func (e *Engine) StartedMonitors() []string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return append([]string(nil), e.started...)
+}
+
+// FailedMonitors 获取启动失败的监控器及原因
+//
+// Returns: map[string]string - 监控器名称到失败原因的映射
+func (e *Engine) FailedMonitors() map[string]string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.failedSnapshot()
+}
+
+// Policy 获取引擎使用的隐私策略
+//
+// 其他模块（如对外查询接口）可以用它对已存储的事件执行相同的隐私规则。
+//
+// Returns: *privacy.Policy - 隐私策略
+func (e *Engine) Policy() *privacy.Policy {
+	return e.policy
+}
+
+// ignoreCheck 包装发布闸门的忽略过滤检查
+//
+// 命中快捷键的按键不受 ignore_apps / ignore_window_titles 影响，
+// 否则在被忽略的应用中无法用快捷键暂停或恢复监控。
+//
+// Parameters:
+//   - filter: 忽略过滤器
+//
+// Returns: EventCheck - 发布闸门检查函数
+func (e *Engine) ignoreCheck(filter *IgnoreFilter) EventCheck {
+	return func(eventType string, event *events.Event) bool {
+		if e.isHotkeyEvent(eventType, event) {
+			return true
+		}
+		return filter.Check(eventType, event)
+	}
+}
+
+// privacyCheck 发布闸门的隐私策略检查
+//
+// 命中快捷键的按键不受隐私策略影响，保证快捷键在任何应用中都可用。
+//
+// Parameters:
+//   - eventType: 事件类型
+//   - event: 待发布的事件，metadata 级别下会被移除内容
+//
+// Returns: bool - true 表示允许发布
+func (e *Engine) privacyCheck(eventType string, event *events.Event) bool {
+	if e.isHotkeyEvent(eventType, event) {
+		return true
+	}
+	return e.policy.Apply(event)
+}
+
+// Gate 获取事件发布闸门
+//
+// 可用于添加额外的发布前检查或查询过滤统计。
+//
+// Returns: *EventGate - 事件发布闸门
+func (e *Engine) Gate() *EventGate {
+	return e.gate
+}
+
+// startMonitor 检查权限并创建、启动单个监控器
+//
+// Parameters:
+//   - registration: 监控器注册信息
+//   - deps: 监控器依赖
+//   - permManager: 权限管理器
+//
+// Returns: error - 权限缺失、创建或启动失败时返回错误
+func (e *Engine) startMonitor(
+	registration MonitorRegistration,
+	deps MonitorDeps,
+	permManager *services.PermissionManager,
+) error {
+	for _, permission := range registration.Permissions {
+		if err := permManager.EnsurePermission(permission); err != nil {
+			logger.Error("监控器权限检查失败",
+				zap.String("component", "engine"),
+				zap.String("monitor", registration.Name),
+				zap.String("permission", permission.String()),
+				zap.Error(err),
+			)
+
+			// 尝试打开系统设置，引导用户授权
+			_ = permManager.OpenSystemSettings(permission)
+
+			return fmt.Errorf("缺少%s权限: %w", permission.String(), err)
+		}
+	}
+
+	monitor, err := registration.Factory(deps)
+	if err != nil {
+		return fmt.Errorf("创建监控器失败: %w", err)
+	}
+
+	if err := monitor.Start(); err != nil {
+		return fmt.Errorf("启动监控器失败: %w", err)
+	}
+
+	e.monitors[registration.Name] = monitor
+	e.started = append(e.started, registration.Name)
+
+	// 记录快捷键管理器，暂停期间用于放行快捷键按键
+	if provider, ok := monitor.(interface{ GetHotkeyManager() *HotkeyManager }); ok {
+		e.setHotkeyManager(provider.GetHotkeyManager())
+	}
+
+	logger.Info("监控器已启动",
+		zap.String("component", "engine"),
+		zap.String("monitor", registration.Name),
+	)
+	return nil
+}
+
+// stopMonitors 按启动的相反顺序停止所有已启动的监控器
+//
+// Returns: error - 第一个停止失败的错误，其他监控器仍会继续停止
+func (e *Engine) stopMonitors() error {
+	var firstErr error
+
+	for i := len(e.started) - 1; i >= 0; i-- {
+		name := e.started[i]
+		monitor := e.monitors[name]
+		if monitor == nil {
+			continue
+		}
+
+		if err := monitor.Stop(); err != nil {
+			logger.Error("停止监控器失败",
+				zap.String("component", "engine"),
+				zap.String("monitor", name),
+				zap.Error(err),
+			)
+			if firstErr == nil {
+				firstErr = fmt.Errorf("failed to stop %s monitor: %w", name, err)
+			}
+		}
+	}
+
+	e.monitors = make(map[string]Monitor)
+	e.started = nil
+	e.setHotkeyManager(nil)
+
+	return firstErr
+}
+
+// enabledMonitors 获取需要启用的监控器名称
+//
+// 名称会被去除空白、转为小写并去重；未配置时使用 DefaultEnabledMonitors。
+//
+// Returns: []string - 监控器名称列表
+func (e *Engine) enabledMonitors() []string {
+	configured := e.config.EnabledMonitors
+	if len(configured) == 0 {
+		configured = DefaultEnabledMonitors
+	}
+
+	seen := make(map[string]bool, len(configured))
+	names := make([]string, 0, len(configured))
+	for _, name := range configured {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" || seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+
+	return names
+}
+
+// failedSnapshot 复制失败监控器映射
+//
+// Returns: map[string]string - 失败监控器副本
+func (e *Engine) failedSnapshot() map[string]string {
+	failed := make(map[string]string, len(e.failed))
+	for name, reason := range e.failed {
+		failed[name] = reason
+	}
+	return failed
 }
@@ -0,0 +1,292 @@
+package monitor
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+// DefaultHotkeyPauseDuration 通过快捷键暂停监控时的默认时长
+//
+// 超时后自动恢复监控，避免用户忘记恢复导致长时间没有数据。
+const DefaultHotkeyPauseDuration = 30 * time.Minute
+
+// 暂停与恢复的原因，记录在状态事件的 reason 字段中
+const (
+	// PauseReasonManual 通过 API 手动暂停或恢复
+	PauseReasonManual = "manual"
+	// PauseReasonHotkey 通过切换监控快捷键暂停或恢复
+	PauseReasonHotkey = "hotkey"
+	// PauseReasonExpired 定时暂停到期自动恢复
+	PauseReasonExpired = "expired"
+)
+
+// pauseState 监控引擎的暂停状态
+type pauseState struct {
+	// paused 是否处于暂停状态
+	paused bool
+
+	// pausedAt 暂停开始时间
+	pausedAt time.Time
+
+	// until 自动恢复时间，零值表示不会自动恢复
+	until time.Time
+
+	// timer 自动恢复定时器
+	timer *time.Timer
+
+	// generation 暂停代数，每次暂停递增，用于忽略过期的定时器回调
+	generation uint64
+
+	// hotkeys 快捷键管理器，用于在暂停和隐私规则下放行快捷键按键
+	hotkeys *HotkeyManager
+
+	// mu 互斥锁，保护暂停状态
+	mu sync.Mutex
+}
+
+// Pause 暂停监控
+//
+// 暂停期间所有监控器的事件都会被丢弃，只有命中已注册快捷键的按键会被放行，
+// 保证快捷键（包括恢复监控的快捷键）仍然可用。
+// 已暂停时再次调用会以新的时长重新计时。
+// 暂停后会发布 status=paused 的状态事件，时间线据此显示监控空白。
+//
+// Parameters:
+//   - duration: 暂停时长，到期自动恢复；小于等于 0 表示一直暂停直到调用 Resume
+//
+// Returns: error - 引擎未运行时返回错误
+func (e *Engine) Pause(duration time.Duration) error {
+	return e.pauseWithReason(duration, PauseReasonManual, e.eventBus)
+}
+
+// Resume 恢复监控
+//
+// 恢复后会发布 status=resumed 的状态事件，包含本次暂停的时长。
+//
+// Returns: error - 引擎未暂停时返回错误
+func (e *Engine) Resume() error {
+	if !e.resumeWithReason(PauseReasonManual, 0, e.eventBus) {
+		return fmt.Errorf("monitor engine not paused")
+	}
+	return nil
+}
+
+// IsPaused 检查监控是否处于暂停状态
+//
+// Returns: bool - true 表示已暂停
+func (e *Engine) IsPaused() bool {
+	e.pause.mu.Lock()
+	defer e.pause.mu.Unlock()
+	return e.pause.paused
+}
+
+// PausedUntil 获取自动恢复时间
+//
+// Returns: time.Time - 自动恢复时间，未暂停或无限期暂停时为零值
+func (e *Engine) PausedUntil() time.Time {
+	e.pause.mu.Lock()
+	defer e.pause.mu.Unlock()
+	return e.pause.until
+}
+
+// pauseWithReason 暂停监控并记录原因
+//
+// Parameters:
+//   - duration: 暂停时长，小于等于 0 表示无限期
+//   - reason: 暂停原因
+//   - publisher: 状态事件的发布者，由事件触发时状态事件记录为由该事件引起
+//
+// Returns: error - 引擎未运行时返回错误
+func (e *Engine) pauseWithReason(duration time.Duration, reason string, publisher events.Publisher) error {
+	if !e.IsRunning() {
+		return fmt.Errorf("monitor engine not running")
+	}
+
+	e.pause.mu.Lock()
+	now := time.Now()
+	if e.pause.timer != nil {
+		e.pause.timer.Stop()
+		e.pause.timer = nil
+	}
+	if !e.pause.paused {
+		e.pause.pausedAt = now
+	}
+	e.pause.paused = true
+	e.pause.generation++
+	e.pause.until = time.Time{}
+	if duration > 0 {
+		generation := e.pause.generation
+		e.pause.until = now.Add(duration)
+		e.pause.timer = time.AfterFunc(duration, func() {
+			e.resumeWithReason(PauseReasonExpired, generation, e.eventBus)
+		})
+	}
+	pausedAt := e.pause.pausedAt
+	until := e.pause.until
+	e.pause.mu.Unlock()
+
+	logger.Info("监控已暂停",
+		zap.String("component", "engine"),
+		zap.String("reason", reason),
+		zap.Duration("duration", duration),
+	)
+
+	data := map[string]interface{}{
+		"status":    "paused",
+		"reason":    reason,
+		"paused_at": pausedAt,
+	}
+	if !until.IsZero() {
+		data["until"] = until
+		data["duration"] = duration.String()
+	}
+	e.publishStatus(publisher, data)
+
+	return nil
+}
+
+// resumeWithReason 恢复监控并记录原因
+//
+// Parameters:
+//   - reason: 恢复原因
+//   - generation: 定时器所属的暂停代数，0 表示不校验（手动恢复）
+//   - publisher: 状态事件的发布者
+//
+// Returns: bool - true 表示确实从暂停状态恢复
+func (e *Engine) resumeWithReason(reason string, generation uint64, publisher events.Publisher) bool {
+	e.pause.mu.Lock()
+	if !e.pause.paused || (generation != 0 && generation != e.pause.generation) {
+		e.pause.mu.Unlock()
+		return false
+	}
+	if e.pause.timer != nil {
+		e.pause.timer.Stop()
+		e.pause.timer = nil
+	}
+	pausedAt := e.pause.pausedAt
+	e.pause.paused = false
+	e.pause.pausedAt = time.Time{}
+	e.pause.until = time.Time{}
+	e.pause.mu.Unlock()
+
+	pausedFor := time.Since(pausedAt)
+
+	logger.Info("监控已恢复",
+		zap.String("component", "engine"),
+		zap.String("reason", reason),
+		zap.Duration("paused_for", pausedFor),
+	)
+
+	e.publishStatus(publisher, map[string]interface{}{
+		"status":     "resumed",
+		"reason":     reason,
+		"paused_at":  pausedAt,
+		"paused_for": pausedFor.String(),
+	})
+
+	return true
+}
+
+// clearPause 清除暂停状态（引擎停止时调用），不发布状态事件
+func (e *Engine) clearPause() {
+	e.pause.mu.Lock()
+	defer e.pause.mu.Unlock()
+
+	if e.pause.timer != nil {
+		e.pause.timer.Stop()
+		e.pause.timer = nil
+	}
+	e.pause.paused = false
+	e.pause.pausedAt = time.Time{}
+	e.pause.until = time.Time{}
+	e.pause.generation++
+}
+
+// setHotkeyManager 设置用于放行快捷键按键的快捷键管理器
+//
+// Parameters:
+//   - manager: 快捷键管理器，nil 表示清除
+func (e *Engine) setHotkeyManager(manager *HotkeyManager) {
+	e.pause.mu.Lock()
+	defer e.pause.mu.Unlock()
+	e.pause.hotkeys = manager
+}
+
+// pauseCheck 发布闸门的暂停检查
+//
+// 暂停期间只放行命中快捷键的键盘事件，其他事件全部丢弃。
+//
+// Parameters:
+//   - eventType: 事件类型
+//   - event: 待发布的事件
+//
+// Returns: bool - true 表示允许发布
+func (e *Engine) pauseCheck(eventType string, event *events.Event) bool {
+	if !e.IsPaused() {
+		return true
+	}
+
+	return e.isHotkeyEvent(eventType, event)
+}
+
+// isHotkeyEvent 判断事件是否为命中已注册快捷键的按键
+//
+// Parameters:
+//   - eventType: 事件类型
+//   - event: 事件
+//
+// Returns: bool - true 表示是快捷键按键
+func (e *Engine) isHotkeyEvent(eventType string, event *events.Event) bool {
+	if eventType != string(events.EventTypeKeyboard) {
+		return false
+	}
+
+	e.pause.mu.Lock()
+	hotkeys := e.pause.hotkeys
+	e.pause.mu.Unlock()
+
+	return hotkeys != nil && hotkeys.MatchesEvent(*event)
+}
+
+// handleToggleMonitoring 处理切换监控快捷键事件
+//
+// 未暂停时暂停 DefaultHotkeyPauseDuration，已暂停时立即恢复。
+// 发布的状态事件记录为由切换事件引起。
+//
+// Parameters:
+//   - event: 切换监控事件
+//   - publisher: 由切换事件引起的发布者
+//
+// Returns: error - 暂停失败时返回错误
+func (e *Engine) handleToggleMonitoring(event events.Event, publisher *events.CausedPublisher) error {
+	if e.resumeWithReason(PauseReasonHotkey, 0, publisher) {
+		return nil
+	}
+
+	if err := e.pauseWithReason(DefaultHotkeyPauseDuration, PauseReasonHotkey, publisher); err != nil {
+		return fmt.Errorf("暂停监控失败: %w", err)
+	}
+	return nil
+}
+
+// publishStatus 发布监控引擎状态事件
+//
+// 状态事件直接发布到事件总线，不经过发布闸门，暂停期间也能送达。
+//
+// Parameters:
+//   - publisher: 发布者，事件总线或由触发事件引起的发布者
+//   - data: 状态事件数据
+func (e *Engine) publishStatus(publisher events.Publisher, data map[string]interface{}) {
+	statusEvent := events.NewEvent(events.EventTypeStatus, data)
+	if err := publisher.Publish(string(events.EventTypeStatus), *statusEvent); err != nil {
+		logger.Error("发布状态事件失败",
+			zap.String("component", "engine"),
+			zap.Error(err),
+		)
+	}
+}
@@ -0,0 +1,246 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHotkeyMonitor 带快捷键管理器的测试监控器
+type fakeHotkeyMonitor struct {
+	fakeMonitor
+	hotkeys *HotkeyManager
+}
+
+func (m *fakeHotkeyMonitor) GetHotkeyManager() *HotkeyManager {
+	return m.hotkeys
+}
+
+// collectEvents 订阅并收集指定类型的事件
+func collectEvents(bus *events.EventBus, eventType events.EventType) func() []events.Event {
+	var mu sync.Mutex
+	var received []events.Event
+
+	bus.Subscribe(string(eventType), func(event events.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+		return nil
+	})
+
+	return func() []events.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]events.Event(nil), received...)
+	}
+}
+
+// statusesWith 筛选指定 status 的状态事件
+func statusesWith(statuses []events.Event, status string) []events.Event {
+	var matched []events.Event
+	for _, event := range statuses {
+		if event.Data["status"] == status {
+			matched = append(matched, event)
+		}
+	}
+	return matched
+}
+
+// newPauseTestEngine 创建只包含一个带快捷键管理器的测试监控器的引擎
+func newPauseTestEngine(t *testing.T, bus *events.EventBus) (*Engine, *fakeHotkeyMonitor) {
+	t.Helper()
+	return newHotkeyTestEngine(t, bus, config.FilterConfig{})
+}
+
+// newHotkeyTestEngine 创建带过滤配置、只包含一个带快捷键管理器的测试监控器的引擎
+func newHotkeyTestEngine(t *testing.T, bus *events.EventBus, filters config.FilterConfig) (*Engine, *fakeHotkeyMonitor) {
+	t.Helper()
+
+	var created *fakeHotkeyMonitor
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(MonitorRegistration{
+		Name: "fake",
+		Factory: func(deps MonitorDeps) (Monitor, error) {
+			created = &fakeHotkeyMonitor{
+				fakeMonitor: fakeMonitor{publisher: deps.Publisher},
+				hotkeys:     NewHotkeyManager(deps.EventBus),
+			}
+			return created, nil
+		},
+	}))
+
+	cfg := &config.Config{Monitor: config.MonitorConfig{EnabledMonitors: []string{"fake"}, Filters: filters}}
+	engine := NewEngineWithConfig(bus, cfg, registry)
+	require.NoError(t, engine.Start())
+	t.Cleanup(func() { _ = engine.Stop() })
+
+	return engine, created
+}
+
+// TestEngine_PauseResume 测试暂停期间丢弃事件并在恢复后继续发布
+func TestEngine_PauseResume(t *testing.T) {
+	bus := events.NewEventBus()
+	clipboard := collectEvents(bus, events.EventTypeClipboard)
+	statuses := collectEvents(bus, events.EventTypeStatus)
+	engine, m := newPauseTestEngine(t, bus)
+
+	require.NoError(t, engine.Pause(0))
+	assert.True(t, engine.IsPaused())
+	assert.True(t, engine.PausedUntil().IsZero(), "无限期暂停没有自动恢复时间")
+
+	require.NoError(t, m.Publish(events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": 1})))
+
+	require.NoError(t, engine.Resume())
+	assert.False(t, engine.IsPaused())
+	assert.Error(t, engine.Resume(), "未暂停时恢复应该失败")
+
+	require.NoError(t, m.Publish(events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": 2})))
+
+	require.Eventually(t, func() bool {
+		return len(clipboard()) == 1 && len(statusesWith(statuses(), "resumed")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, 2, clipboard()[0].Data["seq"])
+	assert.Equal(t, uint64(1), engine.Gate().DroppedCounts()["pause"])
+
+	paused := statusesWith(statuses(), "paused")
+	require.Len(t, paused, 1)
+	assert.Equal(t, PauseReasonManual, paused[0].Data["reason"])
+	assert.NotContains(t, paused[0].Data, "until")
+	assert.Contains(t, statusesWith(statuses(), "resumed")[0].Data, "paused_for")
+}
+
+// TestEngine_PauseExpires 测试定时暂停到期后自动恢复
+func TestEngine_PauseExpires(t *testing.T) {
+	bus := events.NewEventBus()
+	statuses := collectEvents(bus, events.EventTypeStatus)
+	engine, _ := newPauseTestEngine(t, bus)
+
+	require.NoError(t, engine.Pause(50*time.Millisecond))
+	assert.True(t, engine.IsPaused())
+	assert.False(t, engine.PausedUntil().IsZero())
+
+	require.Eventually(t, func() bool {
+		return !engine.IsPaused()
+	}, time.Second, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		resumed := statusesWith(statuses(), "resumed")
+		return len(resumed) == 1 && resumed[0].Data["reason"] == PauseReasonExpired
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestEngine_PauseAllowsHotkeys 测试暂停期间快捷键按键仍然被放行
+func TestEngine_PauseAllowsHotkeys(t *testing.T) {
+	bus := events.NewEventBus()
+	keyboard := collectEvents(bus, events.EventTypeKeyboard)
+	engine, m := newPauseTestEngine(t, bus)
+
+	_, err := m.hotkeys.Register(HotkeyToggleMonitoring, func(*HotkeyRegistration, events.Event) {})
+	require.NoError(t, err)
+	hotkey, err := NewHotkey(HotkeyToggleMonitoring)
+	require.NoError(t, err)
+
+	require.NoError(t, engine.Pause(0))
+
+	require.NoError(t, m.Publish(events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{
+		"keycode":   0,
+		"modifiers": uint64(0),
+	})))
+	require.NoError(t, m.Publish(events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{
+		"keycode":   hotkey.KeyCode,
+		"modifiers": hotkey.Modifiers,
+	})))
+
+	require.Eventually(t, func() bool {
+		return len(keyboard()) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, hotkey.KeyCode, keyboard()[0].Data["keycode"])
+}
+
+// TestEngine_IgnoredAppAllowsHotkeys 测试被忽略的应用中仍放行快捷键按键
+func TestEngine_IgnoredAppAllowsHotkeys(t *testing.T) {
+	bus := events.NewEventBus()
+	keyboard := collectEvents(bus, events.EventTypeKeyboard)
+	_, m := newHotkeyTestEngine(t, bus, config.FilterConfig{IgnoreApps: []string{"Terminal"}})
+
+	_, err := m.hotkeys.Register(HotkeyToggleMonitoring, func(*HotkeyRegistration, events.Event) {})
+	require.NoError(t, err)
+	hotkey, err := NewHotkey(HotkeyToggleMonitoring)
+	require.NoError(t, err)
+
+	ignored := &events.EventContext{Application: "Terminal"}
+	require.NoError(t, m.Publish(events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{
+		"keycode":   0,
+		"modifiers": uint64(0),
+	}).WithContext(ignored)))
+	require.NoError(t, m.Publish(events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{
+		"keycode":   hotkey.KeyCode,
+		"modifiers": hotkey.Modifiers,
+	}).WithContext(ignored)))
+
+	require.Eventually(t, func() bool {
+		return len(keyboard()) == 1
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	require.Len(t, keyboard(), 1)
+	assert.Equal(t, hotkey.KeyCode, keyboard()[0].Data["keycode"])
+}
+
+// TestEngine_ToggleMonitoringHotkey 测试切换监控快捷键事件暂停和恢复监控
+func TestEngine_ToggleMonitoringHotkey(t *testing.T) {
+	bus := events.NewEventBus()
+	engine, _ := newPauseTestEngine(t, bus)
+
+	toggle := events.NewEvent(EventTypeHotkeyToggleMonitoring, map[string]interface{}{"action": "toggle"})
+
+	require.NoError(t, bus.Publish(string(EventTypeHotkeyToggleMonitoring), *toggle))
+	require.Eventually(t, engine.IsPaused, time.Second, 10*time.Millisecond)
+
+	until := engine.PausedUntil()
+	assert.WithinDuration(t, time.Now().Add(DefaultHotkeyPauseDuration), until, 5*time.Second)
+
+	require.NoError(t, bus.Publish(string(EventTypeHotkeyToggleMonitoring), *toggle))
+	require.Eventually(t, func() bool {
+		return !engine.IsPaused()
+	}, time.Second, 10*time.Millisecond)
+}
+
+// TestEngine_ToggleMonitoringCausation 测试按键、切换监控和暂停状态事件在同一条因果链上
+func TestEngine_ToggleMonitoringCausation(t *testing.T) {
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+	engine, _ := newPauseTestEngine(t, bus)
+
+	toggles := collectEvents(bus, EventTypeHotkeyToggleMonitoring)
+	statuses := collectEvents(bus, events.EventTypeStatus)
+
+	trigger := *events.NewEvent(events.EventTypeKeyboard, nil).WithContext(&events.EventContext{})
+	handler := createToggleMonitoringHandler(bus)
+	handler(&HotkeyRegistration{Hotkey: &Hotkey{KeyCode: 4}}, trigger)
+
+	require.Eventually(t, engine.IsPaused, time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool {
+		return len(statusesWith(statuses(), "paused")) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Len(t, toggles(), 1)
+	toggle := toggles()[0]
+	paused := statusesWith(statuses(), "paused")[0]
+	assert.Equal(t, trigger.ID, toggle.CausationID)
+	assert.Equal(t, toggle.ID, paused.CausationID)
+	assert.Equal(t, trigger.ID, paused.CorrelationID)
+}
+
+// TestEngine_PauseRequiresRunning 测试引擎未运行时不能暂停
+func TestEngine_PauseRequiresRunning(t *testing.T) {
+	engine := NewEngineWithConfig(events.NewEventBus(), nil, NewRegistry())
+
+	assert.Error(t, engine.Pause(time.Minute))
+	assert.False(t, engine.IsPaused())
+}
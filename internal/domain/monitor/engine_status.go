@@ -0,0 +1,47 @@
+package monitor
+
+import (
+	"time"
+
+	"github.com/chenyang-zz/flowmind/pkg/events"
+)
+
+// TopicEngineStatus 查询监控引擎状态的请求主题
+//
+// 引擎运行期间通过 EventBus.Respond 应答，其他组件用 EventBus.Request 查询，
+// 不需要持有引擎的引用。
+const TopicEngineStatus = "monitor.engine.status"
+
+// EventTypeEngineStatusReply 引擎状态应答事件
+const EventTypeEngineStatusReply events.EventType = "monitor.engine.status.reply"
+
+// Status 获取监控引擎的当前状态
+//
+// Returns: map[string]interface{} - 包含 running、paused、paused_until、monitors、failed 和 dropped
+func (e *Engine) Status() map[string]interface{} {
+	e.mu.RLock()
+	status := map[string]interface{}{
+		"running":  e.isRunning,
+		"monitors": append([]string(nil), e.started...),
+		"failed":   e.failedSnapshot(),
+	}
+	e.mu.RUnlock()
+
+	status["paused"] = e.IsPaused()
+	if until := e.PausedUntil(); !until.IsZero() {
+		status["paused_until"] = until.Format(time.RFC3339)
+	}
+	status["dropped"] = e.gate.DroppedCounts()
+
+	return status
+}
+
+// handleStatusRequest 应答引擎状态查询
+//
+// Parameters:
+//   - request: 请求事件
+//
+// Returns: events.Event - 状态应答事件, error - 始终为 nil
+func (e *Engine) handleStatusRequest(request events.Event) (events.Event, error) {
+	return *events.NewEvent(EventTypeEngineStatusReply, e.Status()), nil
+}
@@ -0,0 +1,90 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestEngine_StatusRequest 测试通过事件总线查询引擎状态
+func TestEngine_StatusRequest(t *testing.T) {
+	bus := events.NewEventBus()
+	engine, _ := newPauseTestEngine(t, bus)
+
+	reply, err := bus.Request(context.Background(), TopicEngineStatus, *events.NewEvent(events.EventType(TopicEngineStatus), nil))
+	require.NoError(t, err)
+	assert.Equal(t, EventTypeEngineStatusReply, reply.Type)
+	assert.Equal(t, true, reply.Data["running"])
+	assert.Equal(t, []string{"fake"}, reply.Data["monitors"])
+	assert.Equal(t, false, reply.Data["paused"])
+
+	// 引擎停止后不再应答
+	require.NoError(t, engine.Stop())
+	_, err = bus.Request(context.Background(), TopicEngineStatus, *events.NewEvent(events.EventType(TopicEngineStatus), nil))
+	assert.ErrorIs(t, err, events.ErrNoResponder)
+	require.NoError(t, engine.Start())
+}
+
+// TestShowStatusHotkey_IncludesEngineStatus 测试显示状态快捷键事件带上引擎状态
+func TestShowStatusHotkey_IncludesEngineStatus(t *testing.T) {
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+
+	received := collectEvents(bus, EventTypeHotkeyShowStatus)
+	handler := createShowStatusHandler(bus)
+	reg := &HotkeyRegistration{Hotkey: &Hotkey{KeyCode: 4}}
+	trigger := *events.NewEvent(events.EventTypeKeyboard, nil).WithContext(&events.EventContext{})
+
+	// 引擎未运行时只发布不带状态的事件
+	handler(reg, trigger)
+	require.Eventually(t, func() bool { return len(received()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.NotContains(t, received()[0].Data, "status")
+	// 快捷键事件由按键引起
+	assert.Equal(t, trigger.ID, received()[0].CausationID)
+
+	newPauseTestEngine(t, bus)
+	handler(reg, trigger)
+	require.Eventually(t, func() bool { return len(received()) == 2 }, time.Second, 10*time.Millisecond)
+
+	status, ok := received()[1].Data["status"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, true, status["running"])
+}
+
+// TestShowStatusHotkey_DoesNotBlock 测试状态查询较慢时快捷键回调不阻塞
+func TestShowStatusHotkey_DoesNotBlock(t *testing.T) {
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+
+	release := make(chan struct{})
+	_, err := bus.Respond(TopicEngineStatus, func(request events.Event) (events.Event, error) {
+		<-release
+		return *events.NewEvent(EventTypeEngineStatusReply, map[string]interface{}{"running": true}), nil
+	})
+	require.NoError(t, err)
+
+	received := collectEvents(bus, EventTypeHotkeyShowStatus)
+	handler := createShowStatusHandler(bus)
+	reg := &HotkeyRegistration{Hotkey: &Hotkey{KeyCode: 4}}
+	trigger := *events.NewEvent(events.EventTypeKeyboard, nil).WithContext(&events.EventContext{})
+
+	done := make(chan struct{})
+	go func() {
+		handler(reg, trigger)
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("快捷键回调被状态查询阻塞")
+	}
+	assert.Empty(t, received())
+
+	close(release)
+	require.Eventually(t, func() bool { return len(received()) == 1 }, time.Second, 10*time.Millisecond)
+	assert.Contains(t, received()[0].Data, "status")
+}
@@ -0,0 +1,362 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/platform"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+// DefaultWriteCoalesceWindow 默认的写入合并窗口
+//
+// 编辑器保存、编译输出等场景会在短时间内产生大量写入事件，窗口内的写入合并为一个事件。
+const DefaultWriteCoalesceWindow = 500 * time.Millisecond
+
+// FileSystemConfig 文件系统监控器配置
+type FileSystemConfig struct {
+	// Roots 需要递归监控的根目录，不存在的目录会在启动时跳过
+	Roots []string
+
+	// IgnorePatterns 忽略的路径模式（glob），匹配任意一级路径名即忽略
+	IgnorePatterns []string
+
+	// CoalesceWindow 写入合并窗口，窗口内同一文件的多次写入只发布一个事件
+	CoalesceWindow time.Duration
+}
+
+// DefaultFileSystemConfig 返回默认的文件系统监控配置
+//
+// 默认监控用户主目录下的 Documents、Desktop 和 Downloads 目录。
+//
+// Returns: FileSystemConfig - 默认配置
+func DefaultFileSystemConfig() FileSystemConfig {
+	var roots []string
+	if homeDir, err := os.UserHomeDir(); err == nil {
+		roots = []string{
+			filepath.Join(homeDir, "Documents"),
+			filepath.Join(homeDir, "Desktop"),
+			filepath.Join(homeDir, "Downloads"),
+		}
+	}
+
+	return FileSystemConfig{
+		Roots:          roots,
+		IgnorePatterns: append([]string(nil), platform.DefaultFileSystemIgnorePatterns...),
+		CoalesceWindow: DefaultWriteCoalesceWindow,
+	}
+}
+
+// pendingWrite 等待合并发布的写入
+type pendingWrite struct {
+	// firstAt 窗口内第一次写入的时间
+	firstAt time.Time
+	// count 窗口内的写入次数
+	count int
+	// timer 窗口到期后发布事件的定时器
+	timer *time.Timer
+}
+
+// FileSystemMonitor 文件系统监控器（业务层）
+//
+// 负责文件变化的监控和事件处理。本监控器采用分层架构：
+//   - 业务层（本结构体）：合并写入、添加上下文、发布到事件总线
+//   - 平台层（platform字段）：递归监控目录，处理忽略规则
+//
+// 工作流程：
+//   1. 平台层检测到文件创建、写入、删除或重命名
+//   2. 写入事件进入合并窗口，窗口内的重复写入只计数
+//   3. 其他事件先发布同一路径上待合并的写入，保证顺序
+//   4. 构造业务事件并附加上下文（含文件路径）
+//   5. 发布到事件总线供其他模块消费
+type FileSystemMonitor struct {
+	// platform 平台层文件系统监控器，负责与操作系统交互
+	platform platform.FileSystemMonitor
+
+	// eventBus 事件发布者（事件总线或监控引擎的过滤闸门），用于发布文件系统事件
+	eventBus events.Publisher
+
+	// contextMgr 上下文管理器，用于获取当前应用信息
+	contextMgr platform.ContextProvider
+
+	// coalesceWindow 写入合并窗口
+	coalesceWindow time.Duration
+
+	// pending 等待合并发布的写入（按路径）
+	pending map[string]*pendingWrite
+
+	// pendingMu 保护 pending 的互斥锁
+	pendingMu sync.Mutex
+
+	// isRunning 监控器运行状态标志
+	isRunning bool
+
+	// mu 读写锁，保护并发访问
+	mu sync.RWMutex
+}
+
+// NewFileSystemMonitor 创建文件系统监控器
+//
+// 只有存在的根目录会被监控；合并窗口未设置时使用默认值。
+//
+// Parameters:
+//   - eventBus: 事件发布者，用于发布文件系统事件
+//   - config: 文件系统监控配置
+//
+// Returns: Monitor - 新创建的文件系统监控器实例（返回接口类型）
+func NewFileSystemMonitor(eventBus events.Publisher, config FileSystemConfig) Monitor {
+	var roots []string
+	for _, root := range config.Roots {
+		if info, err := os.Stat(root); err == nil && info.IsDir() {
+			roots = append(roots, root)
+		} else {
+			logger.Warn("跳过不存在的监控目录",
+				zap.String("component", "filesystem"),
+				zap.String("path", root),
+			)
+		}
+	}
+
+	watchConfig := platform.FileSystemWatchConfig{
+		Roots:          roots,
+		IgnorePatterns: config.IgnorePatterns,
+	}
+
+	return newFileSystemMonitor(
+		eventBus,
+		platform.NewFileSystemMonitor(watchConfig),
+		platform.NewContextProvider(),
+		config.CoalesceWindow,
+	)
+}
+
+// newFileSystemMonitor 使用指定的平台层组件创建文件系统监控器
+//
+// Parameters:
+//   - eventBus: 事件发布者
+//   - fsMonitor: 平台层文件系统监控器
+//   - contextMgr: 上下文管理器
+//   - coalesceWindow: 写入合并窗口
+//
+// Returns: *FileSystemMonitor - 文件系统监控器实例
+func newFileSystemMonitor(
+	eventBus events.Publisher,
+	fsMonitor platform.FileSystemMonitor,
+	contextMgr platform.ContextProvider,
+	coalesceWindow time.Duration,
+) *FileSystemMonitor {
+	if coalesceWindow <= 0 {
+		coalesceWindow = DefaultWriteCoalesceWindow
+	}
+
+	return &FileSystemMonitor{
+		platform:       fsMonitor,
+		eventBus:       eventBus,
+		contextMgr:     contextMgr,
+		coalesceWindow: coalesceWindow,
+		pending:        make(map[string]*pendingWrite),
+	}
+}
+
+// Start 启动文件系统监控
+//
+// 启动平台层的文件系统监控器，并注册事件回调函数。
+// 如果监控器已经在运行，则幂等地返回成功。
+//
+// Returns: error - 启动失败时返回错误
+func (fm *FileSystemMonitor) Start() error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.isRunning {
+		logger.Debug("文件系统监控器已在运行", zap.String("component", "filesystem"))
+		return nil
+	}
+
+	logger.Info("启动文件系统监控器", zap.String("component", "filesystem"))
+
+	if err := fm.platform.Start(fm.handlePlatformEvent); err != nil {
+		logger.Error("启动平台层文件系统监控器失败",
+			zap.String("component", "filesystem"),
+			zap.Error(err),
+		)
+		return fmt.Errorf("启动文件系统监控失败: %w", err)
+	}
+
+	fm.isRunning = true
+	logger.Info("文件系统监控器启动成功", zap.String("component", "filesystem"))
+	return nil
+}
+
+// Stop 停止文件系统监控
+//
+// 停止平台层监控器，并立即发布所有仍在合并窗口中的写入事件。
+// 如果监控器未运行，则幂等地返回成功。
+//
+// Returns: error - 停止失败时返回错误
+func (fm *FileSystemMonitor) Stop() error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if !fm.isRunning {
+		logger.Debug("文件系统监控器未运行", zap.String("component", "filesystem"))
+		return nil
+	}
+
+	logger.Info("停止文件系统监控器", zap.String("component", "filesystem"))
+
+	if err := fm.platform.Stop(); err != nil {
+		logger.Error("停止平台层文件系统监控器失败",
+			zap.String("component", "filesystem"),
+			zap.Error(err),
+		)
+		return err
+	}
+
+	fm.flushAllWrites()
+
+	fm.isRunning = false
+	logger.Info("文件系统监控器已停止", zap.String("component", "filesystem"))
+	return nil
+}
+
+// IsRunning 检查运行状态
+//
+// Returns: bool - true 表示正在运行，false 表示已停止
+func (fm *FileSystemMonitor) IsRunning() bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.isRunning
+}
+
+// handlePlatformEvent 处理平台层传来的文件系统事件
+//
+// 写入事件进入合并窗口；其他事件会先发布相关路径上待合并的写入，再立即发布。
+//
+// Parameters:
+//   - event: 平台层的文件系统事件
+func (fm *FileSystemMonitor) handlePlatformEvent(event platform.FileSystemEvent) {
+	if event.Op == platform.FileSystemOpWrite {
+		fm.coalesceWrite(event.Path)
+		return
+	}
+
+	// 保证同一文件的写入先于删除/重命名发布
+	fm.flushWrite(event.Path)
+	if event.OldPath != "" {
+		fm.flushWrite(event.OldPath)
+	}
+
+	fm.publish(event, time.Now(), 1)
+}
+
+// coalesceWrite 将写入加入合并窗口
+//
+// Parameters:
+//   - path: 被写入的文件路径
+func (fm *FileSystemMonitor) coalesceWrite(path string) {
+	fm.pendingMu.Lock()
+	defer fm.pendingMu.Unlock()
+
+	if p, ok := fm.pending[path]; ok {
+		p.count++
+		return
+	}
+
+	fm.pending[path] = &pendingWrite{
+		firstAt: time.Now(),
+		count:   1,
+		timer: time.AfterFunc(fm.coalesceWindow, func() {
+			fm.flushWrite(path)
+		}),
+	}
+}
+
+// flushWrite 发布指定路径上待合并的写入事件
+//
+// Parameters:
+//   - path: 文件路径
+func (fm *FileSystemMonitor) flushWrite(path string) {
+	fm.pendingMu.Lock()
+	p, ok := fm.pending[path]
+	if ok {
+		delete(fm.pending, path)
+		p.timer.Stop()
+	}
+	fm.pendingMu.Unlock()
+
+	if !ok {
+		return
+	}
+
+	fm.publish(platform.FileSystemEvent{
+		Path: path,
+		Op:   platform.FileSystemOpWrite,
+	}, p.firstAt, p.count)
+}
+
+// flushAllWrites 发布所有待合并的写入事件
+func (fm *FileSystemMonitor) flushAllWrites() {
+	fm.pendingMu.Lock()
+	paths := make([]string, 0, len(fm.pending))
+	for path := range fm.pending {
+		paths = append(paths, path)
+	}
+	fm.pendingMu.Unlock()
+
+	for _, path := range paths {
+		fm.flushWrite(path)
+	}
+}
+
+// publish 构造并发布文件系统业务事件
+//
+// Parameters:
+//   - event: 平台层事件
+//   - timestamp: 事件时间（合并写入取窗口内第一次写入的时间）
+//   - writeCount: 合并的写入次数
+func (fm *FileSystemMonitor) publish(event platform.FileSystemEvent, timestamp time.Time, writeCount int) {
+	data := events.FileSystemEventData{
+		Path:      event.Path,
+		Operation: string(event.Op),
+		Name:      filepath.Base(event.Path),
+		Extension: filepath.Ext(event.Path),
+		IsDir:     event.IsDir,
+		IsCreate:  event.Op == platform.FileSystemOpCreate,
+		IsWrite:   event.Op == platform.FileSystemOpWrite,
+		IsRemove:  event.Op == platform.FileSystemOpRemove,
+		IsRename:  event.Op == platform.FileSystemOpRename,
+		OldPath:   event.OldPath,
+	}
+	if event.Op == platform.FileSystemOpWrite {
+		data.WriteCount = writeCount
+	}
+
+	logger.Debug("检测到文件变化",
+		zap.String("component", "filesystem"),
+		zap.String("operation", string(event.Op)),
+		zap.String("path", event.Path),
+	)
+
+	context := fm.contextMgr.GetContext()
+	if context == nil {
+		context = &events.EventContext{}
+	}
+	context.FilePath = event.Path
+
+	businessEvent := events.NewPayloadEvent(data)
+	businessEvent.Timestamp = timestamp
+	businessEvent.WithContext(context)
+
+	if err := fm.eventBus.Publish(string(events.EventTypeFileSystem), *businessEvent); err != nil {
+		logger.Error("发布文件系统事件失败",
+			zap.String("component", "filesystem"),
+			zap.Error(err),
+		)
+	}
+}
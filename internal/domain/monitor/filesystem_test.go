@@ -0,0 +1,141 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/platform"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeFileSystemPlatform 可手动触发事件的平台层文件系统监控器
+type fakeFileSystemPlatform struct {
+	callback  platform.FileSystemCallback
+	isRunning bool
+}
+
+func (f *fakeFileSystemPlatform) Start(callback platform.FileSystemCallback) error {
+	f.callback = callback
+	f.isRunning = true
+	return nil
+}
+
+func (f *fakeFileSystemPlatform) Stop() error {
+	f.isRunning = false
+	return nil
+}
+
+func (f *fakeFileSystemPlatform) IsRunning() bool {
+	return f.isRunning
+}
+
+// fakeContextProvider 返回固定上下文的上下文管理器
+type fakeContextProvider struct{}
+
+func (fakeContextProvider) GetFrontmostApp() string       { return "VSCode" }
+func (fakeContextProvider) GetBundleID() string           { return "com.microsoft.VSCode" }
+func (fakeContextProvider) GetFocusedWindowTitle() string { return "main.go" }
+func (fakeContextProvider) GetContext() *events.EventContext {
+	return &events.EventContext{Application: "VSCode", BundleID: "com.microsoft.VSCode"}
+}
+
+// collectFileSystemEvents 订阅并收集文件系统事件
+func collectFileSystemEvents(bus *events.EventBus) func() []events.Event {
+	var mu sync.Mutex
+	var received []events.Event
+
+	bus.Subscribe(string(events.EventTypeFileSystem), func(event events.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+		return nil
+	})
+
+	return func() []events.Event {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]events.Event(nil), received...)
+	}
+}
+
+// TestFileSystemMonitor_CoalesceWrites 测试写入合并
+//
+// 验证合并窗口内同一文件的多次写入只发布一个事件，并记录写入次数。
+func TestFileSystemMonitor_CoalesceWrites(t *testing.T) {
+	bus := events.NewEventBus()
+	received := collectFileSystemEvents(bus)
+
+	fake := &fakeFileSystemPlatform{}
+	monitor := newFileSystemMonitor(bus, fake, fakeContextProvider{}, 50*time.Millisecond)
+	require.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	for i := 0; i < 10; i++ {
+		fake.callback(platform.FileSystemEvent{Path: "/work/main.go", Op: platform.FileSystemOpWrite})
+	}
+	require.Eventually(t, func() bool { return len(received()) == 1 }, time.Second, 10*time.Millisecond)
+	time.Sleep(100 * time.Millisecond)
+	require.Len(t, received(), 1, "合并窗口内的写入只应发布一个事件")
+
+	event := received()[0]
+	assert.Equal(t, "write", event.Data["operation"])
+	assert.Equal(t, 10, event.Data["write_count"])
+	assert.Equal(t, true, event.Data["is_write"])
+	assert.Equal(t, ".go", event.Data["extension"])
+	require.NotNil(t, event.Context)
+	assert.Equal(t, "/work/main.go", event.Context.FilePath)
+	assert.Equal(t, "VSCode", event.Context.Application)
+}
+
+// TestFileSystemMonitor_FlushBeforeRename 测试重命名前先发布待合并的写入
+func TestFileSystemMonitor_FlushBeforeRename(t *testing.T) {
+	bus := events.NewEventBus()
+	received := collectFileSystemEvents(bus)
+
+	fake := &fakeFileSystemPlatform{}
+	monitor := newFileSystemMonitor(bus, fake, fakeContextProvider{}, time.Minute)
+	require.NoError(t, monitor.Start())
+	defer monitor.Stop()
+
+	fake.callback(platform.FileSystemEvent{Path: "/work/draft.md", Op: platform.FileSystemOpWrite})
+	fake.callback(platform.FileSystemEvent{Path: "/work/draft.md", Op: platform.FileSystemOpWrite})
+	fake.callback(platform.FileSystemEvent{
+		Path:    "/work/final.md",
+		OldPath: "/work/draft.md",
+		Op:      platform.FileSystemOpRename,
+	})
+
+	require.Eventually(t, func() bool { return len(received()) == 2 }, time.Second, 10*time.Millisecond)
+	got := received()
+	assert.Equal(t, "write", got[0].Data["operation"])
+	assert.Equal(t, 2, got[0].Data["write_count"])
+	assert.Equal(t, "rename", got[1].Data["operation"])
+	assert.Equal(t, "/work/draft.md", got[1].Data["old_path"])
+	assert.Equal(t, true, got[1].Data["is_rename"])
+}
+
+// TestFileSystemMonitor_StopFlushesPending 测试停止时发布待合并的写入
+func TestFileSystemMonitor_StopFlushesPending(t *testing.T) {
+	bus := events.NewEventBus()
+	received := collectFileSystemEvents(bus)
+
+	fake := &fakeFileSystemPlatform{}
+	monitor := newFileSystemMonitor(bus, fake, fakeContextProvider{}, time.Minute)
+	require.NoError(t, monitor.Start())
+	assert.True(t, monitor.IsRunning())
+
+	fake.callback(platform.FileSystemEvent{Path: "/work/a.txt", Op: platform.FileSystemOpCreate})
+	fake.callback(platform.FileSystemEvent{Path: "/work/a.txt", Op: platform.FileSystemOpWrite})
+
+	require.NoError(t, monitor.Stop())
+	assert.False(t, monitor.IsRunning())
+	assert.False(t, fake.IsRunning())
+
+	require.Eventually(t, func() bool { return len(received()) == 2 }, time.Second, 10*time.Millisecond)
+	got := received()
+	assert.Equal(t, "create", got[0].Data["operation"])
+	assert.Equal(t, "write", got[1].Data["operation"])
+}
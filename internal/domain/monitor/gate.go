@@ -0,0 +1,226 @@
+package monitor
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+// EventCheck 事件发布前的检查函数
+//
+// 检查函数可以修改事件内容（如脱敏），返回 false 表示丢弃事件。
+//
+// Parameters:
+//   - eventType: 事件类型
+//   - event: 待发布的事件
+//
+// Returns: bool - true 表示允许发布
+type EventCheck func(eventType string, event *events.Event) bool
+
+// namedCheck 带名称的检查函数，名称用于统计丢弃数量
+type namedCheck struct {
+	name  string
+	check EventCheck
+}
+
+// EventGate 监控事件发布闸门
+//
+// 监控引擎创建的监控器都通过闸门发布事件。闸门按添加顺序执行检查，
+// 任意一个检查拒绝时事件被丢弃，不会进入事件总线。
+// EventGate 实现了 events.Publisher 接口。
+type EventGate struct {
+	// publisher 下游发布者（通常是事件总线）
+	publisher events.Publisher
+
+	// checks 检查函数列表
+	checks []namedCheck
+
+	// dropped 各检查丢弃的事件数
+	dropped map[string]uint64
+
+	// mu 读写锁，保护并发访问
+	mu sync.RWMutex
+}
+
+// NewEventGate 创建事件发布闸门
+//
+// Parameters:
+//   - publisher: 下游发布者
+//
+// Returns: *EventGate - 闸门实例
+func NewEventGate(publisher events.Publisher) *EventGate {
+	return &EventGate{
+		publisher: publisher,
+		dropped:   make(map[string]uint64),
+	}
+}
+
+// AddCheck 添加检查函数
+//
+// Parameters:
+//   - name: 检查名称，用于统计丢弃数量
+//   - check: 检查函数
+func (g *EventGate) AddCheck(name string, check EventCheck) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.checks = append(g.checks, namedCheck{name: name, check: check})
+}
+
+// Publish 执行检查并发布事件
+//
+// 被丢弃的事件不视为错误。
+//
+// Parameters:
+//   - eventType: 事件类型
+//   - event: 事件对象
+//
+// Returns: error - 下游发布失败时返回错误
+func (g *EventGate) Publish(eventType string, event events.Event) error {
+	g.mu.RLock()
+	checks := g.checks
+	g.mu.RUnlock()
+
+	for _, c := range checks {
+		if !c.check(eventType, &event) {
+			g.mu.Lock()
+			g.dropped[c.name]++
+			g.mu.Unlock()
+
+			logger.Debug("事件被监控引擎过滤",
+				zap.String("component", "engine"),
+				zap.String("check", c.name),
+				zap.String("event_type", eventType),
+			)
+			return nil
+		}
+	}
+
+	return g.publisher.Publish(eventType, event)
+}
+
+// DroppedCounts 获取各检查丢弃的事件数
+//
+// Returns: map[string]uint64 - 检查名称到丢弃数量的映射（副本）
+func (g *EventGate) DroppedCounts() map[string]uint64 {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	counts := make(map[string]uint64, len(g.dropped))
+	for name, count := range g.dropped {
+		counts[name] = count
+	}
+	return counts
+}
+
+// IgnoreFilter 按应用和窗口标题忽略事件
+//
+// 应用按名称或 Bundle ID 精确匹配（不区分大小写），
+// 窗口标题按子串匹配（不区分大小写）。
+type IgnoreFilter struct {
+	// apps 忽略的应用名称或 Bundle ID（小写）
+	apps map[string]struct{}
+
+	// windowTitles 忽略的窗口标题片段（小写）
+	windowTitles []string
+}
+
+// NewIgnoreFilter 根据过滤器配置创建忽略过滤器
+//
+// Parameters:
+//   - cfg: 过滤器配置
+//
+// Returns: *IgnoreFilter - 忽略过滤器实例
+func NewIgnoreFilter(cfg config.FilterConfig) *IgnoreFilter {
+	filter := &IgnoreFilter{
+		apps: make(map[string]struct{}, len(cfg.IgnoreApps)),
+	}
+
+	for _, app := range cfg.IgnoreApps {
+		if app = strings.TrimSpace(app); app != "" {
+			filter.apps[strings.ToLower(app)] = struct{}{}
+		}
+	}
+	for _, title := range cfg.IgnoreWindowTitles {
+		if title = strings.TrimSpace(title); title != "" {
+			filter.windowTitles = append(filter.windowTitles, strings.ToLower(title))
+		}
+	}
+
+	return filter
+}
+
+// IsEmpty 判断过滤器是否没有任何规则
+//
+// Returns: bool - true 表示没有规则
+func (f *IgnoreFilter) IsEmpty() bool {
+	return len(f.apps) == 0 && len(f.windowTitles) == 0
+}
+
+// ShouldIgnore 判断事件是否应该被忽略
+//
+// 同时检查事件上下文和事件数据中的应用信息（应用会话事件没有上下文）。
+//
+// Parameters:
+//   - event: 事件对象
+//
+// Returns: bool - true 表示应该忽略
+func (f *IgnoreFilter) ShouldIgnore(event events.Event) bool {
+	if event.Context != nil {
+		if f.matchApp(event.Context.Application) || f.matchApp(event.Context.BundleID) {
+			return true
+		}
+		if f.matchWindowTitle(event.Context.WindowTitle) {
+			return true
+		}
+	}
+
+	for _, key := range []string{"bundle_id", "app_name"} {
+		if value, ok := event.Data[key].(string); ok && f.matchApp(value) {
+			return true
+		}
+	}
+	if window, ok := event.Data["window"].(string); ok && f.matchWindowTitle(window) {
+		return true
+	}
+
+	return false
+}
+
+// Check 作为发布闸门的检查函数使用
+//
+// Parameters:
+//   - eventType: 事件类型
+//   - event: 待发布的事件
+//
+// Returns: bool - true 表示允许发布
+func (f *IgnoreFilter) Check(eventType string, event *events.Event) bool {
+	return !f.ShouldIgnore(*event)
+}
+
+// matchApp 判断应用名称或 Bundle ID 是否被忽略
+func (f *IgnoreFilter) matchApp(app string) bool {
+	if app == "" {
+		return false
+	}
+	_, ok := f.apps[strings.ToLower(app)]
+	return ok
+}
+
+// matchWindowTitle 判断窗口标题是否被忽略
+func (f *IgnoreFilter) matchWindowTitle(title string) bool {
+	if title == "" {
+		return false
+	}
+	lower := strings.ToLower(title)
+	for _, pattern := range f.windowTitles {
+		if strings.Contains(lower, pattern) {
+			return true
+		}
+	}
+	return false
+}
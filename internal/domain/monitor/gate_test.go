@@ -0,0 +1,114 @@
+package monitor
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// recordingPublisher 记录收到事件的发布者
+type recordingPublisher struct {
+	events []events.Event
+	mu     sync.Mutex
+}
+
+func (p *recordingPublisher) Publish(eventType string, event events.Event) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.events = append(p.events, event)
+	return nil
+}
+
+func (p *recordingPublisher) Events() []events.Event {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]events.Event(nil), p.events...)
+}
+
+// TestEventGate_ChecksAndDroppedCounts 测试闸门按检查丢弃事件并统计数量
+func TestEventGate_ChecksAndDroppedCounts(t *testing.T) {
+	downstream := &recordingPublisher{}
+	gate := NewEventGate(downstream)
+
+	gate.AddCheck("no_keyboard", func(eventType string, event *events.Event) bool {
+		return eventType != string(events.EventTypeKeyboard)
+	})
+	gate.AddCheck("tag", func(eventType string, event *events.Event) bool {
+		event.Data["tagged"] = true
+		return true
+	})
+
+	require.NoError(t, gate.Publish(string(events.EventTypeKeyboard),
+		*events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{})))
+	require.NoError(t, gate.Publish(string(events.EventTypeClipboard),
+		*events.NewEvent(events.EventTypeClipboard, map[string]interface{}{})))
+
+	published := downstream.Events()
+	require.Len(t, published, 1)
+	assert.Equal(t, events.EventTypeClipboard, published[0].Type)
+	assert.Equal(t, true, published[0].Data["tagged"])
+	assert.Equal(t, map[string]uint64{"no_keyboard": 1}, gate.DroppedCounts())
+}
+
+// TestIgnoreFilter_ShouldIgnore 测试按应用和窗口标题忽略事件
+func TestIgnoreFilter_ShouldIgnore(t *testing.T) {
+	filter := NewIgnoreFilter(config.FilterConfig{
+		IgnoreApps:         []string{"com.apple.ScreenSaver", "1Password", " "},
+		IgnoreWindowTitles: []string{"Screen Saver"},
+	})
+	require.False(t, filter.IsEmpty())
+
+	tests := []struct {
+		name   string
+		event  events.Event
+		ignore bool
+	}{
+		{
+			name: "上下文 Bundle ID 匹配（不区分大小写）",
+			event: *events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{}).
+				WithContext(&events.EventContext{BundleID: "com.apple.screensaver"}),
+			ignore: true,
+		},
+		{
+			name: "上下文应用名称匹配",
+			event: *events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{}).
+				WithContext(&events.EventContext{Application: "1Password"}),
+			ignore: true,
+		},
+		{
+			name: "窗口标题子串匹配",
+			event: *events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{}).
+				WithContext(&events.EventContext{Application: "Finder", WindowTitle: "macOS screen saver settings"}),
+			ignore: true,
+		},
+		{
+			name: "事件数据中的 Bundle ID 匹配",
+			event: *events.NewEvent(events.EventTypeAppSession, map[string]interface{}{
+				"bundle_id": "com.apple.ScreenSaver",
+			}),
+			ignore: true,
+		},
+		{
+			name: "不匹配的事件",
+			event: *events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{}).
+				WithContext(&events.EventContext{Application: "VSCode", WindowTitle: "main.go"}),
+			ignore: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.ignore, filter.ShouldIgnore(tt.event))
+		})
+	}
+}
+
+// TestIgnoreFilter_Empty 测试空配置的过滤器
+func TestIgnoreFilter_Empty(t *testing.T) {
+	filter := NewIgnoreFilter(config.FilterConfig{})
+	assert.True(t, filter.IsEmpty())
+}
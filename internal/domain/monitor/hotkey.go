@@ -251,11 +251,12 @@ func (h *Hotkey) String() string {
 //
 // 当快捷键被触发时调用此函数。
 // 回调函数在独立的 goroutine 中执行，避免阻塞事件处理流程。
+// 回调发布的事件应通过 Event.CausedBy(trigger) 记录由按键引起。
 //
 // Parameters:
 //   - registration: 快捷键注册信息，包含 ID 和快捷键定义
-//   - context: 事件上下文，包含当前应用等信息
-type HotkeyCallback func(registration *HotkeyRegistration, context *events.EventContext)
+//   - trigger: 触发快捷键的键盘事件，Context 包含当前应用等信息
+type HotkeyCallback func(registration *HotkeyRegistration, trigger events.Event)
 
 // HotkeyRegistration 快捷键注册信息
 //
@@ -343,8 +344,8 @@ func NewHotkeyManager(eventBus *events.EventBus) *HotkeyManager {
 //   - error: 快捷键格式错误或注册失败时返回错误
 //
 // 示例：
-//   id, err := manager.Register("Cmd+Shift+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {
-//       fmt.Println("快捷键触发！当前应用：", ctx.Application)
+//   id, err := manager.Register("Cmd+Shift+A", func(reg *HotkeyRegistration, trigger events.Event) {
+//       fmt.Println("快捷键触发！当前应用：", trigger.Context.Application)
 //   })
 func (hm *HotkeyManager) Register(hotkeyStr string, callback HotkeyCallback) (string, error) {
 	// 解析快捷键
@@ -520,6 +521,40 @@ func (hm *HotkeyManager) GetRegisteredHotkeys() []string {
 	return hotkeys
 }
 
+// MatchesEvent 判断键盘事件是否命中已启用的快捷键
+//
+// 只做匹配，不触发回调。监控暂停时引擎用它放行快捷键按键，
+// 保证暂停期间快捷键（如恢复监控）仍然可用。
+//
+// Parameters:
+//   - event: 键盘事件
+//
+// Returns:
+//   - bool: true 表示命中至少一个已启用的快捷键
+func (hm *HotkeyManager) MatchesEvent(event events.Event) bool {
+	keycode, ok := event.Data["keycode"].(int)
+	if !ok {
+		return false
+	}
+
+	modifiers, ok := event.Data["modifiers"].(uint64)
+	if !ok {
+		return false
+	}
+
+	lookupKey := hm.buildLookupKey(keycode, modifiers&ModifierMask)
+
+	hm.mu.RLock()
+	defer hm.mu.RUnlock()
+
+	for _, reg := range hm.keyCodeMap[lookupKey] {
+		if reg.Enabled {
+			return true
+		}
+	}
+	return false
+}
+
 // buildLookupKey 构造快速查找键
 //
 // 使用位运算将 keycode 和 modifiers 组合成一个 uint64 值，用于快速查找。
@@ -668,7 +703,7 @@ func (hm *HotkeyManager) handleKeyboardEvent(event events.Event) error {
 					)
 				}
 			}()
-			r.Callback(r, event.Context)
+			r.Callback(r, event)
 		}(reg)
 	}
 
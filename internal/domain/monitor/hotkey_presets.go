@@ -1,6 +1,9 @@
 package monitor
 
 import (
+	"context"
+	"time"
+
 	"github.com/chenyang-zz/flowmind/pkg/events"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
 	"go.uber.org/zap"
@@ -31,6 +34,9 @@ const (
 	HotkeyShowStatus = "Cmd+Shift+H"
 )
 
+// showStatusTimeout 显示状态快捷键查询引擎状态的超时时间
+const showStatusTimeout = time.Second
+
 // 预定义快捷键事件类型
 //
 // 这些是快捷键触发后发布的自定义事件类型，
@@ -113,7 +119,8 @@ func registerPresetHotkeys(manager *HotkeyManager, eventBus *events.EventBus) {
 // 功能：打开/关闭 AI 助手面板
 // 发布事件：EventTypeHotkeyToggleAI
 func createToggleAIHandler(eventBus *events.EventBus) HotkeyCallback {
-	return func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	return func(reg *HotkeyRegistration, trigger events.Event) {
+		ctx := trigger.Context
 		logger.Info("🤖 快捷键触发: AI 助手面板",
 			zap.String("hotkey", reg.Hotkey.String()),
 			zap.String("application", ctx.Application),
@@ -125,6 +132,7 @@ func createToggleAIHandler(eventBus *events.EventBus) HotkeyCallback {
 			"source": "hotkey",
 		})
 		event.WithContext(ctx)
+		event.CausedBy(trigger)
 
 		// 发布到事件总线
 		if err := eventBus.Publish(string(EventTypeHotkeyToggleAI), *event); err != nil {
@@ -145,7 +153,8 @@ func createToggleAIHandler(eventBus *events.EventBus) HotkeyCallback {
 // 功能：显示当前操作的自动化建议
 // 发布事件：EventTypeHotkeyShowSuggestions
 func createShowSuggestionsHandler(eventBus *events.EventBus) HotkeyCallback {
-	return func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	return func(reg *HotkeyRegistration, trigger events.Event) {
+		ctx := trigger.Context
 		logger.Info("💡 快捷键触发: 显示自动化建议",
 			zap.String("hotkey", reg.Hotkey.String()),
 			zap.String("application", ctx.Application),
@@ -157,6 +166,7 @@ func createShowSuggestionsHandler(eventBus *events.EventBus) HotkeyCallback {
 			"source": "hotkey",
 		})
 		event.WithContext(ctx)
+		event.CausedBy(trigger)
 
 		// 发布到事件总线
 		if err := eventBus.Publish(string(EventTypeHotkeyShowSuggestions), *event); err != nil {
@@ -178,7 +188,8 @@ func createShowSuggestionsHandler(eventBus *events.EventBus) HotkeyCallback {
 // 功能：显示所有可用快捷键
 // 发布事件：EventTypeHotkeyShowKeybindings
 func createShowKeybindingsHandler(eventBus *events.EventBus) HotkeyCallback {
-	return func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	return func(reg *HotkeyRegistration, trigger events.Event) {
+		ctx := trigger.Context
 		logger.Info("⌨️  快捷键触发: 显示快捷键列表",
 			zap.String("hotkey", reg.Hotkey.String()),
 		)
@@ -189,6 +200,7 @@ func createShowKeybindingsHandler(eventBus *events.EventBus) HotkeyCallback {
 			"source": "hotkey",
 		})
 		event.WithContext(ctx)
+		event.CausedBy(trigger)
 
 		// 发布到事件总线
 		if err := eventBus.Publish(string(EventTypeHotkeyShowKeybindings), *event); err != nil {
@@ -207,9 +219,10 @@ func createShowKeybindingsHandler(eventBus *events.EventBus) HotkeyCallback {
 // createToggleMonitoringHandler 创建切换监控状态处理函数
 //
 // 功能：暂停或恢复工作流监控
-// 发布事件：EventTypeHotkeyToggleMonitoring
+// 发布事件：EventTypeHotkeyToggleMonitoring（由监控引擎订阅并执行暂停/恢复）
 func createToggleMonitoringHandler(eventBus *events.EventBus) HotkeyCallback {
-	return func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	return func(reg *HotkeyRegistration, trigger events.Event) {
+		ctx := trigger.Context
 		logger.Info("⏯️  快捷键触发: 切换监控状态",
 			zap.String("hotkey", reg.Hotkey.String()),
 		)
@@ -220,6 +233,7 @@ func createToggleMonitoringHandler(eventBus *events.EventBus) HotkeyCallback {
 			"source": "hotkey",
 		})
 		event.WithContext(ctx)
+		event.CausedBy(trigger)
 
 		// 发布到事件总线
 		if err := eventBus.Publish(string(EventTypeHotkeyToggleMonitoring), *event); err != nil {
@@ -238,30 +252,56 @@ func createToggleMonitoringHandler(eventBus *events.EventBus) HotkeyCallback {
 // createShowStatusHandler 创建显示状态处理函数
 //
 // 功能：显示 FlowMind 当前状态和统计信息
+// 在独立的 goroutine 中通过 TopicEngineStatus 请求查询引擎状态，放在事件的 status 字段中，
+// 不阻塞快捷键回调；查询失败（如引擎未运行）时只发布不带状态的事件。
 // 发布事件：EventTypeHotkeyShowStatus
 func createShowStatusHandler(eventBus *events.EventBus) HotkeyCallback {
-	return func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	return func(reg *HotkeyRegistration, trigger events.Event) {
 		logger.Info("📊 快捷键触发: 显示状态信息",
 			zap.String("hotkey", reg.Hotkey.String()),
 		)
 
-		// 发布快捷键事件
-		event := events.NewEvent(EventTypeHotkeyShowStatus, map[string]interface{}{
-			"action": "show",
-			"source": "hotkey",
-		})
-		event.WithContext(ctx)
+		go publishShowStatus(eventBus, trigger)
+	}
+}
 
-		// 发布到事件总线
-		if err := eventBus.Publish(string(EventTypeHotkeyShowStatus), *event); err != nil {
-			logger.Error("发布快捷键事件失败",
-				zap.String("event_type", string(EventTypeHotkeyShowStatus)),
-				zap.Error(err),
-			)
-		}
+// publishShowStatus 查询引擎状态并发布显示状态事件
+//
+// 查询最多等待 showStatusTimeout，由 createShowStatusHandler 在独立的 goroutine 中调用
+func publishShowStatus(eventBus *events.EventBus, trigger events.Event) {
+	data := map[string]interface{}{
+		"action": "show",
+		"source": "hotkey",
+	}
+
+	// 查询引擎状态
+	requestCtx, cancel := context.WithTimeout(context.Background(), showStatusTimeout)
+	request := events.NewEvent(events.EventType(TopicEngineStatus), nil).CausedBy(trigger)
+	reply, err := eventBus.Request(requestCtx, TopicEngineStatus, *request)
+	cancel()
+	if err != nil {
+		logger.Warn("查询引擎状态失败",
+			zap.String("topic", TopicEngineStatus),
+			zap.Error(err),
+		)
+	} else {
+		data["status"] = reply.Data
+	}
+
+	// 发布快捷键事件
+	event := events.NewEvent(EventTypeHotkeyShowStatus, data)
+	event.WithContext(trigger.Context)
+	event.CausedBy(trigger)
 
-		logger.Info("✅ 显示状态信息事件已发布",
+	// 发布到事件总线
+	if err := eventBus.Publish(string(EventTypeHotkeyShowStatus), *event); err != nil {
+		logger.Error("发布快捷键事件失败",
 			zap.String("event_type", string(EventTypeHotkeyShowStatus)),
+			zap.Error(err),
 		)
 	}
+
+	logger.Info("✅ 显示状态信息事件已发布",
+		zap.String("event_type", string(EventTypeHotkeyShowStatus)),
+	)
 }
@@ -206,8 +206,8 @@ func TestHotkeyManager_Unregister_MultipleCallbacks(t *testing.T) {
 	manager := NewHotkeyManager(eventBus)
 
 	// 同一个快捷键注册两个回调
-	id1, _ := manager.Register("Cmd+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
-	id2, _ := manager.Register("Cmd+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
+	id1, _ := manager.Register("Cmd+A", func(reg *HotkeyRegistration, trigger events.Event) {})
+	id2, _ := manager.Register("Cmd+A", func(reg *HotkeyRegistration, trigger events.Event) {})
 
 	// 验证快捷键已注册
 	assert.True(t, manager.IsRegistered("cmd+a"))
@@ -235,7 +235,7 @@ func TestHotkeyManager_HandleKeyboardEvent_InvalidData(t *testing.T) {
 	manager := NewHotkeyManager(eventBus)
 
 	// 注册一个快捷键
-	_, _ = manager.Register("Cmd+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
+	_, _ = manager.Register("Cmd+A", func(reg *HotkeyRegistration, trigger events.Event) {})
 
 	// 启动管理器
 	require.NoError(t, manager.Start())
@@ -287,7 +287,7 @@ func TestHotkeyManager_Register(t *testing.T) {
 	manager := NewHotkeyManager(eventBus)
 
 	t.Run("注册单个快捷键", func(t *testing.T) {
-		id, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {
+		id, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, trigger events.Event) {
 			// 回调函数
 		})
 
@@ -298,8 +298,8 @@ func TestHotkeyManager_Register(t *testing.T) {
 	})
 
 	t.Run("注册多个快捷键", func(t *testing.T) {
-		id1, _ := manager.Register("Cmd+B", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
-		id2, _ := manager.Register("Cmd+C", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
+		id1, _ := manager.Register("Cmd+B", func(reg *HotkeyRegistration, trigger events.Event) {})
+		id2, _ := manager.Register("Cmd+C", func(reg *HotkeyRegistration, trigger events.Event) {})
 
 		assert.NotEmpty(t, id1, "ID1 不应为空")
 		assert.NotEmpty(t, id2, "ID2 不应为空")
@@ -316,12 +316,12 @@ func TestHotkeyManager_Register(t *testing.T) {
 		callCount := 0
 		var mu sync.Mutex
 
-		id1, _ := manager.Register("Cmd+D", func(reg *HotkeyRegistration, ctx *events.EventContext) {
+		id1, _ := manager.Register("Cmd+D", func(reg *HotkeyRegistration, trigger events.Event) {
 			mu.Lock()
 			callCount++
 			mu.Unlock()
 		})
-		id2, _ := manager.Register("Cmd+D", func(reg *HotkeyRegistration, ctx *events.EventContext) {
+		id2, _ := manager.Register("Cmd+D", func(reg *HotkeyRegistration, trigger events.Event) {
 			mu.Lock()
 			callCount++
 			mu.Unlock()
@@ -333,7 +333,7 @@ func TestHotkeyManager_Register(t *testing.T) {
 	})
 
 	t.Run("取消注册", func(t *testing.T) {
-		id, _ := manager.Register("Cmd+E", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
+		id, _ := manager.Register("Cmd+E", func(reg *HotkeyRegistration, trigger events.Event) {})
 
 		// 取消注册
 		success := manager.Unregister(id)
@@ -348,8 +348,8 @@ func TestHotkeyManager_Register(t *testing.T) {
 
 	t.Run("UnregisterAll 清空所有注册", func(t *testing.T) {
 		// 先注册几个快捷键
-		_, _ = manager.Register("Cmd+F", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
-		_, _ = manager.Register("Cmd+G", func(reg *HotkeyRegistration, ctx *events.EventContext) {})
+		_, _ = manager.Register("Cmd+F", func(reg *HotkeyRegistration, trigger events.Event) {})
+		_, _ = manager.Register("Cmd+G", func(reg *HotkeyRegistration, trigger events.Event) {})
 
 		// 清空所有注册
 		manager.UnregisterAll()
@@ -382,8 +382,8 @@ func TestHotkeyManager_MatchAndTrigger(t *testing.T) {
 
 	t.Run("发送匹配事件触发回调", func(t *testing.T) {
 		callbackTriggered := make(chan bool, 1)
-		_, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {
-			t.Logf("回调被触发！快捷键：%s，应用：%s", reg.Hotkey.String(), ctx.Application)
+		_, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, trigger events.Event) {
+			t.Logf("回调被触发！快捷键：%s，应用：%s", reg.Hotkey.String(), trigger.Context.Application)
 			callbackTriggered <- true
 		})
 		require.NoError(t, err, "注册不应失败")
@@ -420,7 +420,7 @@ func TestHotkeyManager_MatchAndTrigger(t *testing.T) {
 
 	t.Run("发送不匹配事件不触发回调", func(t *testing.T) {
 		callbackTriggered := make(chan bool, 1)
-		_, err := manager.Register("Cmd+B", func(reg *HotkeyRegistration, ctx *events.EventContext) {
+		_, err := manager.Register("Cmd+B", func(reg *HotkeyRegistration, trigger events.Event) {
 			callbackTriggered <- true
 		})
 		require.NoError(t, err, "注册不应失败")
@@ -449,7 +449,7 @@ func TestHotkeyManager_DebugKeyMap(t *testing.T) {
 	eventBus := events.NewEventBus()
 	manager := NewHotkeyManager(eventBus)
 
-	_, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	_, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, trigger events.Event) {
 		t.Log("回调被触发")
 	})
 	require.NoError(t, err, "注册不应失败")
@@ -479,7 +479,7 @@ func TestHotkeyManager_SetEnabled(t *testing.T) {
 	time.Sleep(100 * time.Millisecond)
 
 	callbackTriggered := make(chan bool, 1)
-	id, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	id, err := manager.Register("Cmd+A", func(reg *HotkeyRegistration, trigger events.Event) {
 		callbackTriggered <- true
 	})
 	require.NoError(t, err, "注册不应失败")
@@ -553,7 +553,7 @@ func TestHotkeyManager_ConcurrentAccess(t *testing.T) {
 			go func(index int) {
 				defer wg.Done()
 				id, err := manager.Register(string(rune('A'+index)),
-					func(reg *HotkeyRegistration, ctx *events.EventContext) {},
+					func(reg *HotkeyRegistration, trigger events.Event) {},
 				)
 				if err == nil {
 					ids <- id
@@ -581,7 +581,7 @@ func TestHotkeyManager_ConcurrentAccess(t *testing.T) {
 			go func(index int) {
 				defer wg.Done()
 				_, _ = manager.Register(string(rune('K'+index)),
-					func(reg *HotkeyRegistration, ctx *events.EventContext) {},
+					func(reg *HotkeyRegistration, trigger events.Event) {},
 				)
 			}(i)
 		}
@@ -667,7 +667,7 @@ func TestHotkeyWithModifierMask(t *testing.T) {
 	callbackTriggered := false
 	var callbackHotkey *Hotkey
 
-	callback := func(reg *HotkeyRegistration, ctx *events.EventContext) {
+	callback := func(reg *HotkeyRegistration, trigger events.Event) {
 		callbackTriggered = true
 		callbackHotkey = reg.Hotkey
 	}
@@ -25,9 +25,12 @@ type KeyboardMonitor struct {
 	// platform 平台层键盘监控器，负责与操作系统交互
 	platform platform.KeyboardMonitor
 
-	// eventBus 事件总线，用于发布键盘事件
+	// eventBus 事件总线，供快捷键管理器订阅键盘事件
 	eventBus *events.EventBus
 
+	// publisher 事件发布者（事件总线或监控引擎的过滤闸门），用于发布键盘事件
+	publisher events.Publisher
+
 	// contextMgr 上下文管理器，用于获取当前应用信息
 	contextMgr platform.ContextProvider
 
@@ -50,9 +53,23 @@ type KeyboardMonitor struct {
 //
 // Returns: Monitor - 新创建的键盘监控器实例（返回接口类型）
 func NewKeyboardMonitor(eventBus *events.EventBus) Monitor {
+	return newKeyboardMonitor(eventBus, eventBus)
+}
+
+// newKeyboardMonitor 创建通过指定发布者发布事件的键盘监控器
+//
+// 快捷键管理器仍然直接订阅事件总线，发布者只影响键盘事件的发布路径。
+//
+// Parameters:
+//   - eventBus: 事件总线实例，供快捷键管理器使用
+//   - publisher: 键盘事件的发布者
+//
+// Returns: *KeyboardMonitor - 键盘监控器实例
+func newKeyboardMonitor(eventBus *events.EventBus, publisher events.Publisher) *KeyboardMonitor {
 	return &KeyboardMonitor{
 		platform:      platform.NewKeyboardMonitor(),
 		eventBus:      eventBus,
+		publisher:     publisher,
 		contextMgr:    platform.NewContextProvider(),
 		hotkeyManager: NewHotkeyManager(eventBus),
 	}
@@ -187,17 +204,17 @@ func (km *KeyboardMonitor) handlePlatformEvent(event platform.KeyboardEvent) {
 	context := km.contextMgr.GetContext()
 
 	// 2. 构造业务事件数据
-	data := map[string]interface{}{
-		"keycode":   event.KeyCode,
-		"modifiers": event.Modifiers,
+	data := events.KeyboardEventData{
+		KeyCode:   event.KeyCode,
+		Modifiers: event.Modifiers,
 	}
 
 	// 3. 创建业务事件
-	businessEvent := events.NewEvent(events.EventTypeKeyboard, data)
+	businessEvent := events.NewPayloadEvent(data)
 	businessEvent.WithContext(context)
 
 	// 4. 发布到事件总线
-	if err := km.eventBus.Publish(string(events.EventTypeKeyboard), *businessEvent); err != nil {
+	if err := km.publisher.Publish(string(events.EventTypeKeyboard), *businessEvent); err != nil {
 		logger.Error("发布键盘事件失败",
 			zap.String("component", "keyboard"),
 			zap.Error(err),
@@ -522,7 +522,7 @@ func TestKeyboardMonitor_GetHotkeyManager(t *testing.T) {
 	assert.False(t, registered, "快捷键尚未注册")
 
 	// 注册快捷键
-	id, err := hotkeyMgr.Register(testHotkey, func(reg *HotkeyRegistration, ctx *events.EventContext) {})
+	id, err := hotkeyMgr.Register(testHotkey, func(reg *HotkeyRegistration, trigger events.Event) {})
 	require.NoError(t, err, "注册快捷键不应失败")
 	assert.NotEmpty(t, id, "注册 ID 不应为空")
 
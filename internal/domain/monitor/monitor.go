@@ -8,6 +8,7 @@
 // 主要组件：
 //   - Engine: 监控引擎，统一管理所有监控器
 //   - KeyboardMonitor: 键盘监控器，捕获键盘输入事件
+//   - FileSystemMonitor: 文件系统监控器，捕获文件创建、写入、删除和重命名事件
 //
 // 事件流程：
 //   1. 平台层捕获原始事件
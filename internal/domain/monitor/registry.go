@@ -0,0 +1,232 @@
+package monitor
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/privacy"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/platform"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+)
+
+// 内置监控器名称，与配置文件 monitor.enabled_monitors 中的取值一致
+const (
+	// MonitorKeyboard 键盘监控器
+	MonitorKeyboard = "keyboard"
+	// MonitorClipboard 剪贴板监控器
+	MonitorClipboard = "clipboard"
+	// MonitorApplication 应用切换监控器
+	MonitorApplication = "application"
+	// MonitorFileSystem 文件系统监控器
+	MonitorFileSystem = "filesystem"
+)
+
+// DefaultEnabledMonitors 未配置 enabled_monitors 时默认启用的监控器
+var DefaultEnabledMonitors = []string{MonitorKeyboard, MonitorClipboard, MonitorApplication}
+
+// MonitorDeps 创建监控器所需的依赖
+type MonitorDeps struct {
+	// EventBus 事件总线，供需要订阅事件的组件使用（如快捷键管理器）
+	EventBus *events.EventBus
+
+	// Publisher 事件发布者，监控器应通过它发布事件以应用引擎的过滤规则
+	Publisher events.Publisher
+
+	// Config 监控配置
+	Config config.MonitorConfig
+}
+
+// MonitorFactory 监控器工厂函数
+//
+// Parameters: deps - 创建监控器所需的依赖
+// Returns: Monitor - 监控器实例, error - 创建失败时返回错误
+type MonitorFactory func(deps MonitorDeps) (Monitor, error)
+
+// MonitorRegistration 监控器注册信息
+type MonitorRegistration struct {
+	// Name 监控器名称，对应配置中的 enabled_monitors 取值
+	Name string
+
+	// Factory 监控器工厂函数
+	Factory MonitorFactory
+
+	// Permissions 启动前需要检查的系统权限
+	Permissions []platform.PermissionType
+
+	// Required 是否为必需监控器，必需监控器启动失败时整个引擎启动失败
+	Required bool
+}
+
+// Registry 监控器注册表
+//
+// 维护监控器名称到工厂函数的映射，引擎按配置从注册表中创建监控器。
+// 注册表是并发安全的。
+type Registry struct {
+	// entries 已注册的监控器
+	entries map[string]MonitorRegistration
+
+	// order 注册顺序
+	order []string
+
+	// mu 读写锁，保护并发访问
+	mu sync.RWMutex
+}
+
+// NewRegistry 创建空的监控器注册表
+//
+// Returns: *Registry - 注册表实例
+func NewRegistry() *Registry {
+	return &Registry{
+		entries: make(map[string]MonitorRegistration),
+	}
+}
+
+// NewDefaultRegistry 创建包含所有内置监控器的注册表
+//
+// Returns: *Registry - 注册了 keyboard、clipboard、application、filesystem 的注册表
+func NewDefaultRegistry() *Registry {
+	registry := NewRegistry()
+
+	for _, registration := range builtinMonitors() {
+		// 内置监控器名称固定且不重复，注册不会失败
+		_ = registry.Register(registration)
+	}
+
+	return registry
+}
+
+// Register 注册监控器
+//
+// Parameters: registration - 监控器注册信息
+// Returns: error - 名称为空、工厂为空或名称重复时返回错误
+func (r *Registry) Register(registration MonitorRegistration) error {
+	if registration.Name == "" {
+		return fmt.Errorf("监控器名称不能为空")
+	}
+	if registration.Factory == nil {
+		return fmt.Errorf("监控器工厂不能为空: %s", registration.Name)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.entries[registration.Name]; exists {
+		return fmt.Errorf("监控器已注册: %s", registration.Name)
+	}
+
+	r.entries[registration.Name] = registration
+	r.order = append(r.order, registration.Name)
+	return nil
+}
+
+// Lookup 查询监控器注册信息
+//
+// Parameters: name - 监控器名称
+// Returns: MonitorRegistration - 注册信息, bool - 是否存在
+func (r *Registry) Lookup(name string) (MonitorRegistration, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	registration, ok := r.entries[name]
+	return registration, ok
+}
+
+// Names 获取所有已注册的监控器名称
+//
+// Returns: []string - 按注册顺序排列的名称列表
+func (r *Registry) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return append([]string(nil), r.order...)
+}
+
+// builtinMonitors 内置监控器注册信息
+//
+// Returns: []MonitorRegistration - 内置监控器列表
+func builtinMonitors() []MonitorRegistration {
+	return []MonitorRegistration{
+		{
+			Name: MonitorKeyboard,
+			Factory: func(deps MonitorDeps) (Monitor, error) {
+				return newKeyboardMonitor(deps.EventBus, deps.Publisher), nil
+			},
+			Permissions: []platform.PermissionType{platform.PermissionAccessibility},
+			Required:    true,
+		},
+		{
+			Name:    MonitorClipboard,
+			Factory: newClipboardMonitorFromConfig,
+		},
+		{
+			Name: MonitorApplication,
+			Factory: func(deps MonitorDeps) (Monitor, error) {
+				return NewApplicationMonitor(deps.Publisher), nil
+			},
+		},
+		{
+			Name:    MonitorFileSystem,
+			Factory: newFileSystemMonitorFromConfig,
+		},
+	}
+}
+
+// newClipboardMonitorFromConfig 根据监控配置创建剪贴板监控器
+//
+// 未配置轮询间隔时使用 platform.DefaultClipboardCheckInterval。
+//
+// Parameters: deps - 创建监控器所需的依赖
+// Returns: Monitor - 剪贴板监控器, error - 配置无效时返回错误
+func newClipboardMonitorFromConfig(deps MonitorDeps) (Monitor, error) {
+	redactor, err := privacy.NewPipelineFromConfig(deps.Config.Redaction)
+	if err != nil {
+		return nil, fmt.Errorf("创建脱敏流水线失败: %w", err)
+	}
+
+	var interval time.Duration
+	if pollInterval := deps.Config.Clipboard.PollInterval; pollInterval != "" {
+		interval, err = time.ParseDuration(pollInterval)
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("剪贴板轮询间隔无效: %q", pollInterval)
+		}
+	}
+
+	return newClipboardMonitor(
+		deps.Publisher,
+		platform.NewClipboardMonitorWithInterval(interval),
+		redactor,
+	), nil
+}
+
+// newFileSystemMonitorFromConfig 根据监控配置创建文件系统监控器
+//
+// 未配置的字段使用 DefaultFileSystemConfig 中的默认值，路径中的环境变量会被展开。
+//
+// Parameters: deps - 创建监控器所需的依赖
+// Returns: Monitor - 文件系统监控器, error - 配置无效时返回错误
+func newFileSystemMonitorFromConfig(deps MonitorDeps) (Monitor, error) {
+	fsConfig := DefaultFileSystemConfig()
+	cfg := deps.Config.FileSystem
+
+	if len(cfg.Roots) > 0 {
+		fsConfig.Roots = make([]string, 0, len(cfg.Roots))
+		for _, root := range cfg.Roots {
+			fsConfig.Roots = append(fsConfig.Roots, os.ExpandEnv(root))
+		}
+	}
+	if len(cfg.IgnorePatterns) > 0 {
+		fsConfig.IgnorePatterns = cfg.IgnorePatterns
+	}
+	if cfg.CoalesceWindow != "" {
+		window, err := time.ParseDuration(cfg.CoalesceWindow)
+		if err != nil {
+			return nil, fmt.Errorf("解析写入合并窗口失败: %w", err)
+		}
+		fsConfig.CoalesceWindow = window
+	}
+
+	return NewFileSystemMonitor(deps.Publisher, fsConfig), nil
+}
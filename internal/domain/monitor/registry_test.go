@@ -0,0 +1,285 @@
+package monitor
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeMonitor 用于测试的监控器，记录启停次数，可通过 Publish 发布事件
+type fakeMonitor struct {
+	publisher events.Publisher
+	startErr  error
+	starts    int
+	stops     int
+	running   bool
+	mu        sync.Mutex
+}
+
+func (m *fakeMonitor) Start() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.startErr != nil {
+		return m.startErr
+	}
+	m.starts++
+	m.running = true
+	return nil
+}
+
+func (m *fakeMonitor) Stop() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stops++
+	m.running = false
+	return nil
+}
+
+func (m *fakeMonitor) IsRunning() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.running
+}
+
+func (m *fakeMonitor) Publish(event *events.Event) error {
+	return m.publisher.Publish(string(event.Type), *event)
+}
+
+// fakeRegistration 创建注册 fakeMonitor 的注册信息，created 记录创建出的监控器
+func fakeRegistration(name string, required bool, startErr error, created map[string]*fakeMonitor) MonitorRegistration {
+	return MonitorRegistration{
+		Name:     name,
+		Required: required,
+		Factory: func(deps MonitorDeps) (Monitor, error) {
+			m := &fakeMonitor{publisher: deps.Publisher, startErr: startErr}
+			created[name] = m
+			return m, nil
+		},
+	}
+}
+
+// TestRegistry_Register 测试注册表的注册和查询
+func TestRegistry_Register(t *testing.T) {
+	registry := NewRegistry()
+	created := make(map[string]*fakeMonitor)
+
+	require.NoError(t, registry.Register(fakeRegistration("a", false, nil, created)))
+	require.NoError(t, registry.Register(fakeRegistration("b", false, nil, created)))
+
+	assert.Error(t, registry.Register(fakeRegistration("a", false, nil, created)), "重复名称应该失败")
+	assert.Error(t, registry.Register(MonitorRegistration{Name: "c"}), "空工厂应该失败")
+	assert.Error(t, registry.Register(fakeRegistration("", false, nil, created)), "空名称应该失败")
+
+	_, ok := registry.Lookup("a")
+	assert.True(t, ok)
+	_, ok = registry.Lookup("missing")
+	assert.False(t, ok)
+	assert.Equal(t, []string{"a", "b"}, registry.Names())
+}
+
+// TestNewDefaultRegistry 测试内置注册表包含所有内置监控器
+func TestNewDefaultRegistry(t *testing.T) {
+	registry := NewDefaultRegistry()
+
+	assert.Equal(t, []string{MonitorKeyboard, MonitorClipboard, MonitorApplication, MonitorFileSystem}, registry.Names())
+
+	keyboard, ok := registry.Lookup(MonitorKeyboard)
+	require.True(t, ok)
+	assert.True(t, keyboard.Required)
+	assert.NotEmpty(t, keyboard.Permissions)
+}
+
+// TestNewClipboardMonitorFromConfig 测试剪贴板监控器轮询间隔配置解析
+func TestNewClipboardMonitorFromConfig(t *testing.T) {
+	deps := MonitorDeps{
+		Publisher: events.NewEventBus(),
+		Config: config.MonitorConfig{
+			Clipboard: config.ClipboardConfig{PollInterval: "1s"},
+		},
+	}
+
+	m, err := newClipboardMonitorFromConfig(deps)
+	require.NoError(t, err)
+	assert.NotNil(t, m)
+
+	deps.Config.Clipboard.PollInterval = "soon"
+	_, err = newClipboardMonitorFromConfig(deps)
+	assert.Error(t, err)
+}
+
+// TestNewFileSystemMonitorFromConfig 测试文件系统监控器配置解析
+func TestNewFileSystemMonitorFromConfig(t *testing.T) {
+	deps := MonitorDeps{
+		Publisher: events.NewEventBus(),
+		Config: config.MonitorConfig{
+			FileSystem: config.FileSystemConfig{
+				Roots:          []string{t.TempDir()},
+				CoalesceWindow: "200ms",
+			},
+		},
+	}
+
+	m, err := newFileSystemMonitorFromConfig(deps)
+	require.NoError(t, err)
+	assert.NotNil(t, m)
+
+	deps.Config.FileSystem.CoalesceWindow = "soon"
+	_, err = newFileSystemMonitorFromConfig(deps)
+	assert.Error(t, err)
+}
+
+// TestEngineWithConfig_StartReportsMonitors 测试引擎只创建启用的监控器并在状态事件中报告结果
+func TestEngineWithConfig_StartReportsMonitors(t *testing.T) {
+	bus := events.NewEventBus()
+	var statuses []events.Event
+	var mu sync.Mutex
+	bus.Subscribe(string(events.EventTypeStatus), func(event events.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, event)
+		return nil
+	})
+
+	created := make(map[string]*fakeMonitor)
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(fakeRegistration("alpha", false, nil, created)))
+	require.NoError(t, registry.Register(fakeRegistration("beta", false, fmt.Errorf("boom"), created)))
+	require.NoError(t, registry.Register(fakeRegistration("disabled", false, nil, created)))
+
+	cfg := &config.Config{Monitor: config.MonitorConfig{
+		EnabledMonitors: []string{"alpha", "Beta", "unknown", "alpha"},
+	}}
+	engine := NewEngineWithConfig(bus, cfg, registry)
+
+	require.NoError(t, engine.Start())
+	assert.True(t, engine.IsRunning())
+	assert.Equal(t, []string{"alpha"}, engine.StartedMonitors())
+	assert.NotContains(t, created, "disabled", "未启用的监控器不应被创建")
+
+	failed := engine.FailedMonitors()
+	assert.Contains(t, failed, "beta")
+	assert.Contains(t, failed, "unknown")
+	assert.Same(t, created["alpha"], engine.GetMonitor("alpha"))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(statuses) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	started := statuses[0]
+	mu.Unlock()
+	assert.Equal(t, "started", started.Data["status"])
+	assert.Equal(t, []string{"alpha"}, started.Data["monitors"])
+	assert.Contains(t, started.Data["failed"], "beta")
+
+	require.NoError(t, engine.Stop())
+	assert.Equal(t, 1, created["alpha"].stops)
+	assert.Nil(t, engine.GetMonitor("alpha"))
+}
+
+// TestEngineWithConfig_RequiredMonitorFails 测试必需监控器失败时引擎启动失败并回滚
+func TestEngineWithConfig_RequiredMonitorFails(t *testing.T) {
+	created := make(map[string]*fakeMonitor)
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(fakeRegistration("first", false, nil, created)))
+	require.NoError(t, registry.Register(fakeRegistration("critical", true, fmt.Errorf("denied"), created)))
+
+	cfg := &config.Config{Monitor: config.MonitorConfig{EnabledMonitors: []string{"first", "critical"}}}
+	engine := NewEngineWithConfig(events.NewEventBus(), cfg, registry)
+
+	err := engine.Start()
+	require.Error(t, err)
+	assert.False(t, engine.IsRunning())
+	assert.Equal(t, 1, created["first"].stops, "已启动的监控器应被停止")
+	assert.Empty(t, engine.StartedMonitors())
+}
+
+// TestEngineWithConfig_IgnoreFilter 测试引擎在发布前执行 ignore_apps 过滤
+func TestEngineWithConfig_IgnoreFilter(t *testing.T) {
+	bus := events.NewEventBus()
+	var received []events.Event
+	var mu sync.Mutex
+	bus.Subscribe(string(events.EventTypeKeyboard), func(event events.Event) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, event)
+		return nil
+	})
+
+	created := make(map[string]*fakeMonitor)
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(fakeRegistration("fake", false, nil, created)))
+
+	cfg := &config.Config{Monitor: config.MonitorConfig{
+		EnabledMonitors: []string{"fake"},
+		Filters:         config.FilterConfig{IgnoreApps: []string{"com.apple.ScreenSaver"}},
+	}}
+	engine := NewEngineWithConfig(bus, cfg, registry)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	m := created["fake"]
+	ignored := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"key": "ignored"}).
+		WithContext(&events.EventContext{BundleID: "com.apple.ScreenSaver"})
+	allowed := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"key": "allowed"}).
+		WithContext(&events.EventContext{BundleID: "com.microsoft.VSCode"})
+	require.NoError(t, m.Publish(ignored))
+	require.NoError(t, m.Publish(allowed))
+
+	require.Eventually(t, func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return len(received) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	mu.Lock()
+	assert.Equal(t, "allowed", received[0].Data["key"])
+	mu.Unlock()
+	assert.Equal(t, uint64(1), engine.Gate().DroppedCounts()["ignore_filter"])
+}
+
+// TestEngineWithConfig_PrivacyPolicy 测试引擎在发布前执行按应用的隐私策略
+func TestEngineWithConfig_PrivacyPolicy(t *testing.T) {
+	bus := events.NewEventBus()
+	received := collectEvents(bus, events.EventTypeClipboard)
+
+	created := make(map[string]*fakeMonitor)
+	registry := NewRegistry()
+	require.NoError(t, registry.Register(fakeRegistration("fake", false, nil, created)))
+
+	cfg := &config.Config{Monitor: config.MonitorConfig{
+		EnabledMonitors: []string{"fake"},
+		Privacy: config.PrivacyConfig{
+			Rules: []config.PrivacyRuleConfig{{App: "Slack", Level: "metadata"}},
+		},
+	}}
+	engine := NewEngineWithConfig(bus, cfg, registry)
+	require.NoError(t, engine.Start())
+	defer engine.Stop()
+
+	m := created["fake"]
+	for _, ctx := range []*events.EventContext{
+		{Application: "1Password", BundleID: "com.1password.1password"},
+		{Application: "Slack", BundleID: "com.tinyspeck.slackmacgap"},
+	} {
+		event := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"content": "secret"}).WithContext(ctx)
+		require.NoError(t, m.Publish(event))
+	}
+
+	require.Eventually(t, func() bool {
+		return len(received()) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	event := received()[0]
+	assert.Equal(t, "Slack", event.Context.Application)
+	assert.NotContains(t, event.Data, "content")
+	assert.Equal(t, uint64(1), engine.Gate().DroppedCounts()["privacy"])
+}
@@ -0,0 +1,299 @@
+package privacy
+
+import (
+	"math"
+	"regexp"
+)
+
+// 内置检测器名称，与配置文件 monitor.redaction.detectors 中的键一致
+const (
+	// DetectorPrivateKey PEM 格式私钥
+	DetectorPrivateKey = "private_key"
+	// DetectorAWSKey AWS Access Key ID 和 Secret Access Key
+	DetectorAWSKey = "aws_key"
+	// DetectorGitHubToken GitHub 个人访问令牌和 OAuth 令牌
+	DetectorGitHubToken = "github_token"
+	// DetectorJWT JSON Web Token
+	DetectorJWT = "jwt"
+	// DetectorCreditCard 信用卡号（Luhn 校验）
+	DetectorCreditCard = "credit_card"
+	// DetectorEmail 电子邮件地址
+	DetectorEmail = "email"
+	// DetectorPhone 电话号码
+	DetectorPhone = "phone"
+	// DetectorHighEntropy 高熵字符串（疑似密钥或密码）
+	DetectorHighEntropy = "high_entropy"
+)
+
+/**
+ * DefaultActions 内置检测器的默认处理动作
+ *
+ * 私钥整条丢弃；令牌、卡号和高熵字符串打码；
+ * 邮箱和电话号码做摘要，保留"同一个联系人"的关联能力
+ */
+var DefaultActions = map[string]Action{
+	DetectorPrivateKey:  ActionDrop,
+	DetectorAWSKey:      ActionMask,
+	DetectorGitHubToken: ActionMask,
+	DetectorJWT:         ActionMask,
+	DetectorCreditCard:  ActionMask,
+	DetectorEmail:       ActionHash,
+	DetectorPhone:       ActionHash,
+	DetectorHighEntropy: ActionMask,
+}
+
+const (
+	// DefaultEntropyThreshold 高熵检测的默认阈值（比特/字符）
+	DefaultEntropyThreshold = 4.0
+
+	// DefaultEntropyMinLength 高熵检测的默认最小长度
+	DefaultEntropyMinLength = 20
+)
+
+var (
+	privateKeyPattern = regexp.MustCompile(
+		`-----BEGIN [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----(?s:.*?)(?:-----END [A-Z0-9 ]*PRIVATE KEY(?: BLOCK)?-----|\z)`)
+
+	awsKeyPatterns = []*regexp.Regexp{
+		regexp.MustCompile(`\b(?:AKIA|ASIA|AGPA|AIDA|AROA|ANPA|ANVA|AIPA)[0-9A-Z]{16}\b`),
+		// Secret Access Key 本身没有固定前缀，只在带有 aws 上下文时识别，捕获组为密钥本身
+		regexp.MustCompile(`(?i)aws[a-z0-9_ .-]{0,20}(?:secret|key)[a-z0-9_ .-]{0,20}['"]?\s*[:=]\s*['"]?([A-Za-z0-9/+=]{40})\b`),
+	}
+
+	githubTokenPattern = regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`)
+
+	jwtPattern = regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{5,}\.eyJ[A-Za-z0-9_-]{5,}\.[A-Za-z0-9_-]+`)
+
+	creditCardPattern = regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`)
+
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}`)
+
+	phonePatterns = []*regexp.Regexp{
+		// 中国大陆手机号
+		regexp.MustCompile(`(?:\+86[ -]?)?\b1[3-9]\d{9}\b`),
+		// 带国际区号的号码
+		regexp.MustCompile(`\+\d{1,3}[ .-]?\(?\d{1,4}\)?(?:[ .-]?\d{2,4}){2,4}\b`),
+		// 北美格式 (555) 123-4567 / 555-123-4567
+		regexp.MustCompile(`(?:\(\d{3}\)\s?|\b\d{3}[ .-])\d{3}[ .-]\d{4}\b`),
+	}
+
+	// 等号只允许出现在末尾（base64 填充），避免把 KEY=value 识别成一个整体
+	entropyTokenPattern = regexp.MustCompile(`[A-Za-z0-9+/_-]+=*`)
+)
+
+/**
+ * regexDetector 基于正则表达式的检测器
+ *
+ * 正则包含捕获组时只把第一个捕获组作为敏感片段
+ */
+type regexDetector struct {
+	name     string
+	patterns []*regexp.Regexp
+}
+
+/**
+ * NewRegexDetector 创建基于正则表达式的检测器
+ *
+ * Parameters:
+ *   - name: 检测器名称
+ *   - patterns: 正则表达式，包含捕获组时只替换第一个捕获组
+ *
+ * Returns: Detector - 检测器
+ */
+func NewRegexDetector(name string, patterns ...*regexp.Regexp) Detector {
+	return &regexDetector{name: name, patterns: patterns}
+}
+
+// Name 检测器名称
+func (d *regexDetector) Name() string {
+	return d.name
+}
+
+// Detect 返回所有正则命中的片段
+func (d *regexDetector) Detect(text string) []Match {
+	var matches []Match
+	for _, pattern := range d.patterns {
+		for _, loc := range pattern.FindAllStringSubmatchIndex(text, -1) {
+			if len(loc) >= 4 && loc[2] >= 0 {
+				matches = append(matches, Match{Start: loc[2], End: loc[3]})
+			} else {
+				matches = append(matches, Match{Start: loc[0], End: loc[1]})
+			}
+		}
+	}
+	return matches
+}
+
+/**
+ * creditCardDetector 信用卡号检测器
+ *
+ * 13-19 位数字（允许空格和连字符分隔）且通过 Luhn 校验
+ */
+type creditCardDetector struct{}
+
+// Name 检测器名称
+func (creditCardDetector) Name() string {
+	return DetectorCreditCard
+}
+
+// Detect 返回所有通过 Luhn 校验的卡号
+func (creditCardDetector) Detect(text string) []Match {
+	var matches []Match
+	for _, loc := range creditCardPattern.FindAllStringIndex(text, -1) {
+		if luhnValid(text[loc[0]:loc[1]]) {
+			matches = append(matches, Match{Start: loc[0], End: loc[1]})
+		}
+	}
+	return matches
+}
+
+/**
+ * luhnValid Luhn 校验
+ *
+ * Parameters:
+ *   - number: 卡号，非数字字符会被忽略
+ *
+ * Returns: bool - 数字位数在 13-19 之间且通过校验
+ */
+func luhnValid(number string) bool {
+	digits := make([]int, 0, len(number))
+	for _, r := range number {
+		if r >= '0' && r <= '9' {
+			digits = append(digits, int(r-'0'))
+		}
+	}
+	if len(digits) < 13 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := digits[i]
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+/**
+ * entropyDetector 高熵字符串检测器
+ *
+ * 查找足够长、同时包含字母和数字、香农熵不低于阈值的连续片段，
+ * 用于兜底识别没有固定格式的密钥和随机密码
+ */
+type entropyDetector struct {
+	threshold float64
+	minLength int
+}
+
+/**
+ * NewEntropyDetector 创建高熵字符串检测器
+ *
+ * Parameters:
+ *   - threshold: 熵阈值（比特/字符），小于等于 0 时使用 DefaultEntropyThreshold
+ *   - minLength: 最小长度，小于等于 0 时使用 DefaultEntropyMinLength
+ *
+ * Returns: Detector - 检测器
+ */
+func NewEntropyDetector(threshold float64, minLength int) Detector {
+	if threshold <= 0 {
+		threshold = DefaultEntropyThreshold
+	}
+	if minLength <= 0 {
+		minLength = DefaultEntropyMinLength
+	}
+	return &entropyDetector{threshold: threshold, minLength: minLength}
+}
+
+// Name 检测器名称
+func (d *entropyDetector) Name() string {
+	return DetectorHighEntropy
+}
+
+// Detect 返回所有高熵片段
+func (d *entropyDetector) Detect(text string) []Match {
+	var matches []Match
+	for _, loc := range entropyTokenPattern.FindAllStringIndex(text, -1) {
+		token := text[loc[0]:loc[1]]
+		if len(token) < d.minLength || !hasLetterAndDigit(token) {
+			continue
+		}
+		if shannonEntropy(token) >= d.threshold {
+			matches = append(matches, Match{Start: loc[0], End: loc[1]})
+		}
+	}
+	return matches
+}
+
+/**
+ * shannonEntropy 计算字符串的香农熵（比特/字符）
+ */
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+
+	counts := make(map[byte]int)
+	for i := 0; i < len(s); i++ {
+		counts[s[i]]++
+	}
+
+	length := float64(len(s))
+	entropy := 0.0
+	for _, count := range counts {
+		p := float64(count) / length
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+/**
+ * hasLetterAndDigit 判断字符串是否同时包含字母和数字
+ */
+func hasLetterAndDigit(s string) bool {
+	var letter, digit bool
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= '0' && c <= '9':
+			digit = true
+		case (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z'):
+			letter = true
+		}
+		if letter && digit {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * builtinDetectors 创建所有内置检测器
+ *
+ * 顺序决定同一位置、同样长度的片段归属，格式明确的检测器排在前面
+ *
+ * Parameters:
+ *   - entropyThreshold: 高熵检测阈值
+ *   - entropyMinLength: 高熵检测最小长度
+ *
+ * Returns: []Detector - 内置检测器列表
+ */
+func builtinDetectors(entropyThreshold float64, entropyMinLength int) []Detector {
+	return []Detector{
+		NewRegexDetector(DetectorPrivateKey, privateKeyPattern),
+		NewRegexDetector(DetectorAWSKey, awsKeyPatterns...),
+		NewRegexDetector(DetectorGitHubToken, githubTokenPattern),
+		NewRegexDetector(DetectorJWT, jwtPattern),
+		creditCardDetector{},
+		NewRegexDetector(DetectorEmail, emailPattern),
+		NewRegexDetector(DetectorPhone, phonePatterns...),
+		NewEntropyDetector(entropyThreshold, entropyMinLength),
+	}
+}
@@ -0,0 +1,45 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestLuhnValid 测试 Luhn 校验
+func TestLuhnValid(t *testing.T) {
+	assert.True(t, luhnValid("4111111111111111"))
+	assert.True(t, luhnValid("5500-0000-0000-0004"))
+	assert.True(t, luhnValid("3782 822463 10005"))
+	assert.False(t, luhnValid("4111111111111112"))
+	assert.False(t, luhnValid("0000"), "位数不足")
+}
+
+// TestShannonEntropy 测试香农熵计算
+func TestShannonEntropy(t *testing.T) {
+	assert.Equal(t, 0.0, shannonEntropy("aaaaaaaa"))
+	assert.InDelta(t, 1.0, shannonEntropy("abababab"), 1e-9)
+	assert.InDelta(t, 3.0, shannonEntropy("abcdefgh"), 1e-9)
+}
+
+// TestEntropyDetector 测试高熵检测只命中足够长的随机串
+func TestEntropyDetector(t *testing.T) {
+	detector := NewEntropyDetector(0, 0)
+
+	assert.Len(t, detector.Detect("secret=9fQ2xLm7Rt4Kz8Pw1Vb6Ns3Hj5Dc0Ga"), 1)
+	assert.Empty(t, detector.Detect("internationalization_configuration"), "只有字母")
+	assert.Empty(t, detector.Detect("abc123"), "太短")
+	assert.Empty(t, detector.Detect("aaaaaaaaaaaaaaaaaaaaaaaa1111"), "熵太低")
+}
+
+// TestRegexDetector_CaptureGroup 测试带捕获组的正则只返回捕获组位置
+func TestRegexDetector_CaptureGroup(t *testing.T) {
+	detector := NewRegexDetector(DetectorAWSKey, awsKeyPatterns...)
+
+	text := `AWS_SECRET_ACCESS_KEY="wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY"`
+	matches := detector.Detect(text)
+
+	if assert.Len(t, matches, 1) {
+		assert.Equal(t, "wJalrXUtnFEMI/K7MDENG/bPxRfiCYEXAMPLEKEY", text[matches[0].Start:matches[0].End])
+	}
+}
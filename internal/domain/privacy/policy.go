@@ -0,0 +1,346 @@
+package privacy
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+)
+
+/**
+ * CaptureLevel 采集级别
+ *
+ * 级别从低到高依次为 none < metadata < full
+ */
+type CaptureLevel int
+
+const (
+	// CaptureNone 不采集任何事件
+	CaptureNone CaptureLevel = iota
+
+	// CaptureMetadata 只采集元数据：不含剪贴板内容、按键码和选中文本
+	CaptureMetadata
+
+	// CaptureFull 完整采集
+	CaptureFull
+)
+
+/**
+ * String 返回采集级别的配置名称
+ *
+ * Returns: string - none、metadata 或 full
+ */
+func (l CaptureLevel) String() string {
+	switch l {
+	case CaptureNone:
+		return "none"
+	case CaptureMetadata:
+		return "metadata"
+	default:
+		return "full"
+	}
+}
+
+/**
+ * ParseCaptureLevel 解析采集级别
+ *
+ * Parameters:
+ *   - s: none、metadata（或 metadata-only、metadata_only）、full，不区分大小写
+ *
+ * Returns: CaptureLevel - 采集级别, error - 无效级别时返回错误
+ */
+func ParseCaptureLevel(s string) (CaptureLevel, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "none":
+		return CaptureNone, nil
+	case "metadata", "metadata-only", "metadata_only":
+		return CaptureMetadata, nil
+	case "full":
+		return CaptureFull, nil
+	default:
+		return CaptureFull, fmt.Errorf("无效的采集级别: %s", s)
+	}
+}
+
+/**
+ * PolicyRule 隐私规则
+ *
+ * BundleID、App 和 WindowTitle 中设置了的条件必须全部满足才算命中
+ */
+type PolicyRule struct {
+	// Name 规则名称，用于日志和调试
+	Name string
+
+	// BundleID 应用 Bundle ID，不区分大小写精确匹配
+	BundleID string
+
+	// App 应用名称，不区分大小写精确匹配
+	App string
+
+	// WindowTitle 窗口标题正则表达式，不区分大小写
+	WindowTitle *regexp.Regexp
+
+	// Level 命中时的采集级别
+	Level CaptureLevel
+}
+
+/**
+ * matches 判断规则是否命中
+ */
+func (r PolicyRule) matches(bundleID, app, windowTitle string) bool {
+	if r.BundleID == "" && r.App == "" && r.WindowTitle == nil {
+		return false
+	}
+	if r.BundleID != "" && !strings.EqualFold(r.BundleID, bundleID) {
+		return false
+	}
+	if r.App != "" && !strings.EqualFold(r.App, app) {
+		return false
+	}
+	if r.WindowTitle != nil && (windowTitle == "" || !r.WindowTitle.MatchString(windowTitle)) {
+		return false
+	}
+	return true
+}
+
+/**
+ * passwordManagerBundleIDs 常见密码管理器的 Bundle ID
+ */
+var passwordManagerBundleIDs = []string{
+	"com.1password.1password",
+	"com.agilebits.onepassword7",
+	"com.agilebits.onepassword-osx",
+	"com.bitwarden.desktop",
+	"com.lastpass.LastPass",
+	"com.dashlane.Dashlane",
+	"com.dashlane.dashlanephonefinal",
+	"org.keepassxc.keepassxc",
+	"com.keepersecurity.keeper",
+	"in.sinew.Enpass-Desktop",
+	"com.nordsec.nordpass",
+	"com.apple.keychainaccess",
+	"com.apple.Passwords",
+}
+
+/**
+ * privateWindowTitlePattern 浏览器隐私窗口的标题特征
+ *
+ * Chrome: Incognito、Firefox/Safari: Private Browsing、Edge: InPrivate
+ */
+var privateWindowTitlePattern = regexp.MustCompile(`(?i)\bincognito\b|\bprivate browsing\b|\binprivate\b|无痕|隐身|隐私浏览`)
+
+/**
+ * DefaultPolicyRules 内置隐私规则
+ *
+ * 密码管理器和浏览器隐私窗口不采集任何事件
+ *
+ * Returns: []PolicyRule - 内置规则列表
+ */
+func DefaultPolicyRules() []PolicyRule {
+	rules := make([]PolicyRule, 0, len(passwordManagerBundleIDs)+1)
+	for _, bundleID := range passwordManagerBundleIDs {
+		rules = append(rules, PolicyRule{
+			Name:     "password_manager",
+			BundleID: bundleID,
+			Level:    CaptureNone,
+		})
+	}
+	rules = append(rules, PolicyRule{
+		Name:        "private_window",
+		WindowTitle: privateWindowTitlePattern,
+		Level:       CaptureNone,
+	})
+	return rules
+}
+
+/**
+ * Policy 隐私策略
+ *
+ * 按应用和窗口标题决定采集级别。配置中的规则按顺序优先匹配，
+ * 之后是内置规则，第一个命中的规则生效；都未命中时使用默认级别。
+ * Policy 创建后只读，可并发使用。
+ */
+type Policy struct {
+	rules        []PolicyRule
+	defaultLevel CaptureLevel
+}
+
+/**
+ * NewPolicy 使用指定规则创建隐私策略
+ *
+ * Parameters:
+ *   - defaultLevel: 未命中任何规则时的采集级别
+ *   - rules: 规则列表，按顺序匹配
+ *
+ * Returns: *Policy - 隐私策略
+ */
+func NewPolicy(defaultLevel CaptureLevel, rules ...PolicyRule) *Policy {
+	return &Policy{
+		rules:        append([]PolicyRule(nil), rules...),
+		defaultLevel: defaultLevel,
+	}
+}
+
+/**
+ * NewDefaultPolicy 创建只包含内置规则的隐私策略
+ *
+ * Returns: *Policy - 隐私策略
+ */
+func NewDefaultPolicy() *Policy {
+	return NewPolicy(CaptureFull, DefaultPolicyRules()...)
+}
+
+/**
+ * NewPolicyFromConfig 根据配置创建隐私策略
+ *
+ * Parameters:
+ *   - cfg: 隐私配置
+ *
+ * Returns: *Policy - 隐私策略, error - 级别或窗口标题正则无效时返回错误
+ */
+func NewPolicyFromConfig(cfg config.PrivacyConfig) (*Policy, error) {
+	defaultLevel := CaptureFull
+	if cfg.DefaultLevel != "" {
+		level, err := ParseCaptureLevel(cfg.DefaultLevel)
+		if err != nil {
+			return nil, fmt.Errorf("解析默认采集级别失败: %w", err)
+		}
+		defaultLevel = level
+	}
+
+	rules := make([]PolicyRule, 0, len(cfg.Rules))
+	for i, ruleCfg := range cfg.Rules {
+		level, err := ParseCaptureLevel(ruleCfg.Level)
+		if err != nil {
+			return nil, fmt.Errorf("解析第 %d 条隐私规则失败: %w", i+1, err)
+		}
+
+		rule := PolicyRule{
+			Name:     fmt.Sprintf("config[%d]", i),
+			BundleID: strings.TrimSpace(ruleCfg.BundleID),
+			App:      strings.TrimSpace(ruleCfg.App),
+			Level:    level,
+		}
+		if ruleCfg.WindowTitle != "" {
+			pattern, err := regexp.Compile("(?i)" + ruleCfg.WindowTitle)
+			if err != nil {
+				return nil, fmt.Errorf("解析第 %d 条隐私规则的窗口标题失败: %w", i+1, err)
+			}
+			rule.WindowTitle = pattern
+		}
+		if rule.BundleID == "" && rule.App == "" && rule.WindowTitle == nil {
+			return nil, fmt.Errorf("第 %d 条隐私规则没有任何匹配条件", i+1)
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if !cfg.DisableDefaults {
+		rules = append(rules, DefaultPolicyRules()...)
+	}
+
+	return NewPolicy(defaultLevel, rules...), nil
+}
+
+/**
+ * LevelFor 获取应用和窗口的采集级别
+ *
+ * Parameters:
+ *   - bundleID: 应用 Bundle ID
+ *   - app: 应用名称
+ *   - windowTitle: 窗口标题
+ *
+ * Returns: CaptureLevel - 采集级别
+ */
+func (p *Policy) LevelFor(bundleID, app, windowTitle string) CaptureLevel {
+	for _, rule := range p.rules {
+		if rule.matches(bundleID, app, windowTitle) {
+			return rule.Level
+		}
+	}
+	return p.defaultLevel
+}
+
+/**
+ * EventLevel 获取事件的采集级别
+ *
+ * 优先使用事件上下文，上下文缺失的字段从事件数据中补充
+ * （应用会话事件没有上下文，应用信息在数据中）
+ *
+ * Parameters:
+ *   - event: 事件
+ *
+ * Returns: CaptureLevel - 采集级别
+ */
+func (p *Policy) EventLevel(event events.Event) CaptureLevel {
+	var bundleID, app, windowTitle string
+	if event.Context != nil {
+		bundleID = event.Context.BundleID
+		app = event.Context.Application
+		windowTitle = event.Context.WindowTitle
+	}
+
+	if bundleID == "" {
+		bundleID, _ = event.Data["bundle_id"].(string)
+	}
+	if app == "" {
+		app, _ = event.Data["app_name"].(string)
+	}
+	if windowTitle == "" {
+		windowTitle, _ = event.Data["window"].(string)
+	}
+
+	return p.LevelFor(bundleID, app, windowTitle)
+}
+
+/**
+ * Apply 按采集级别处理事件
+ *
+ * none 级别丢弃事件；metadata 级别移除剪贴板内容、按键码和选中文本，
+ * 并在事件数据中记录 privacy_level；full 级别不做修改
+ *
+ * Parameters:
+ *   - event: 待处理的事件，metadata 级别下会被原地修改
+ *
+ * Returns: bool - false 表示事件应被丢弃
+ */
+func (p *Policy) Apply(event *events.Event) bool {
+	switch p.EventLevel(*event) {
+	case CaptureNone:
+		return false
+	case CaptureMetadata:
+		StripContent(event)
+	}
+	return true
+}
+
+/**
+ * metadataOnlyRemovedKeys metadata 级别下从事件数据中移除的字段
+ */
+var metadataOnlyRemovedKeys = []string{"content", "redactions", "keycode", "modifiers"}
+
+/**
+ * StripContent 移除事件中的内容信息，只保留元数据
+ *
+ * Parameters:
+ *   - event: 待处理的事件，会被原地修改
+ */
+func StripContent(event *events.Event) {
+	data := make(map[string]interface{}, len(event.Data)+1)
+	for key, value := range event.Data {
+		data[key] = value
+	}
+	for _, key := range metadataOnlyRemovedKeys {
+		delete(data, key)
+	}
+	data["privacy_level"] = CaptureMetadata.String()
+	event.Data = data
+
+	if event.Context != nil && event.Context.Selection != "" {
+		context := *event.Context
+		context.Selection = ""
+		event.Context = &context
+	}
+}
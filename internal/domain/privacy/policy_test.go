@@ -0,0 +1,98 @@
+package privacy
+
+import (
+	"testing"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDefaultPolicy 测试内置规则覆盖密码管理器和浏览器隐私窗口
+func TestDefaultPolicy(t *testing.T) {
+	policy := NewDefaultPolicy()
+
+	assert.Equal(t, CaptureNone, policy.LevelFor("com.1password.1password", "1Password", ""))
+	assert.Equal(t, CaptureNone, policy.LevelFor("COM.BITWARDEN.DESKTOP", "", ""), "Bundle ID 不区分大小写")
+	assert.Equal(t, CaptureNone, policy.LevelFor("com.google.Chrome", "Google Chrome", "New Tab - Incognito"))
+	assert.Equal(t, CaptureNone, policy.LevelFor("org.mozilla.firefox", "Firefox", "Mozilla Firefox Private Browsing"))
+	assert.Equal(t, CaptureFull, policy.LevelFor("com.google.Chrome", "Google Chrome", "GitHub"))
+	assert.Equal(t, CaptureFull, policy.LevelFor("", "", ""))
+}
+
+// TestNewPolicyFromConfig 测试配置规则优先于内置规则
+func TestNewPolicyFromConfig(t *testing.T) {
+	policy, err := NewPolicyFromConfig(config.PrivacyConfig{
+		DefaultLevel: "metadata",
+		Rules: []config.PrivacyRuleConfig{
+			{BundleID: "com.1password.1password", Level: "full"},
+			{App: "Slack", Level: "metadata-only"},
+			{BundleID: "com.google.Chrome", WindowTitle: "bank", Level: "none"},
+			{App: "VSCode", Level: "full"},
+		},
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, CaptureFull, policy.LevelFor("com.1password.1password", "", ""), "配置规则覆盖内置规则")
+	assert.Equal(t, CaptureMetadata, policy.LevelFor("com.tinyspeck.slackmacgap", "slack", ""))
+	assert.Equal(t, CaptureNone, policy.LevelFor("com.google.Chrome", "Chrome", "My Bank - Login"))
+	assert.Equal(t, CaptureMetadata, policy.LevelFor("com.google.Chrome", "Chrome", "GitHub"), "条件不全满足时使用默认级别")
+	assert.Equal(t, CaptureFull, policy.LevelFor("", "VSCode", ""))
+	assert.Equal(t, CaptureNone, policy.LevelFor("com.bitwarden.desktop", "", ""), "内置规则仍然生效")
+
+	withoutDefaults, err := NewPolicyFromConfig(config.PrivacyConfig{DisableDefaults: true})
+	require.NoError(t, err)
+	assert.Equal(t, CaptureFull, withoutDefaults.LevelFor("com.bitwarden.desktop", "", ""))
+}
+
+// TestNewPolicyFromConfig_Invalid 测试无效配置
+func TestNewPolicyFromConfig_Invalid(t *testing.T) {
+	invalid := []config.PrivacyConfig{
+		{DefaultLevel: "partial"},
+		{Rules: []config.PrivacyRuleConfig{{App: "Slack", Level: "some"}}},
+		{Rules: []config.PrivacyRuleConfig{{WindowTitle: "(", Level: "none"}}},
+		{Rules: []config.PrivacyRuleConfig{{Level: "none"}}},
+	}
+
+	for _, cfg := range invalid {
+		_, err := NewPolicyFromConfig(cfg)
+		assert.Error(t, err, "%+v", cfg)
+	}
+}
+
+// TestPolicy_Apply 测试按级别丢弃或移除事件内容
+func TestPolicy_Apply(t *testing.T) {
+	policy := NewPolicy(CaptureFull,
+		PolicyRule{App: "Secret", Level: CaptureNone},
+		PolicyRule{App: "Chat", Level: CaptureMetadata},
+	)
+
+	dropped := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"content": "x"}).
+		WithContext(&events.EventContext{Application: "Secret"})
+	assert.False(t, policy.Apply(dropped))
+
+	session := events.NewEvent(events.EventTypeAppSession, map[string]interface{}{"app_name": "Secret"})
+	assert.False(t, policy.Apply(session), "没有上下文时使用事件数据中的应用信息")
+
+	original := map[string]interface{}{"content": "hello", "type": "text", "size": int64(5)}
+	clipboard := events.NewEvent(events.EventTypeClipboard, original).
+		WithContext(&events.EventContext{Application: "Chat", Selection: "hello"})
+	require.True(t, policy.Apply(clipboard))
+	assert.NotContains(t, clipboard.Data, "content")
+	assert.Equal(t, "text", clipboard.Data["type"])
+	assert.Equal(t, "metadata", clipboard.Data["privacy_level"])
+	assert.Empty(t, clipboard.Context.Selection)
+	assert.Equal(t, "hello", original["content"], "原始数据不应被修改")
+
+	keyboard := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"keycode": 0, "modifiers": uint64(0)}).
+		WithContext(&events.EventContext{Application: "Chat"})
+	require.True(t, policy.Apply(keyboard))
+	assert.NotContains(t, keyboard.Data, "keycode")
+	assert.NotContains(t, keyboard.Data, "modifiers")
+
+	full := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"keycode": 1}).
+		WithContext(&events.EventContext{Application: "Editor"})
+	require.True(t, policy.Apply(full))
+	assert.Equal(t, 1, full.Data["keycode"])
+}
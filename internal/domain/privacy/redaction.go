@@ -0,0 +1,361 @@
+/**
+ * Package privacy 隐私保护组件
+ *
+ * 负责敏感内容检测与脱敏，保证密钥、密码、卡号等敏感信息
+ * 不会进入事件存储、日志或 AI 提示词
+ */
+
+package privacy
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/config"
+)
+
+/**
+ * Action 检测到敏感内容后的处理动作
+ */
+type Action string
+
+const (
+	// ActionDrop 丢弃整条内容
+	ActionDrop Action = "drop"
+
+	// ActionMask 用 [REDACTED:<类型>] 替换敏感片段
+	ActionMask Action = "mask"
+
+	// ActionHash 用敏感片段加盐后的 SHA-256 摘要替换，同一安装中相同内容得到相同摘要，便于关联
+	ActionHash Action = "hash"
+
+	// ActionOff 关闭检测器（仅用于配置）
+	ActionOff Action = "off"
+)
+
+/**
+ * ParseAction 解析处理动作
+ *
+ * Parameters:
+ *   - s: 动作字符串（drop、mask、hash、off，不区分大小写）
+ *
+ * Returns: Action - 处理动作, error - 无效动作时返回错误
+ */
+func ParseAction(s string) (Action, error) {
+	action := Action(strings.ToLower(strings.TrimSpace(s)))
+	switch action {
+	case ActionDrop, ActionMask, ActionHash, ActionOff:
+		return action, nil
+	default:
+		return "", fmt.Errorf("无效的脱敏动作: %s", s)
+	}
+}
+
+/**
+ * Match 检测器在文本中找到的敏感片段
+ */
+type Match struct {
+	// Start 片段起始字节偏移（包含）
+	Start int
+
+	// End 片段结束字节偏移（不包含）
+	End int
+}
+
+/**
+ * Detector 敏感内容检测器
+ *
+ * 检测器只负责定位敏感片段，如何处理由流水线中配置的动作决定
+ */
+type Detector interface {
+	// Name 检测器名称，同时作为脱敏占位符中的类型
+	Name() string
+
+	// Detect 返回文本中所有敏感片段
+	Detect(text string) []Match
+}
+
+/**
+ * Rule 脱敏规则：检测器 + 处理动作
+ */
+type Rule struct {
+	// Detector 敏感内容检测器
+	Detector Detector
+
+	// Action 处理动作
+	Action Action
+}
+
+/**
+ * Result 脱敏结果
+ */
+type Result struct {
+	// Text 脱敏后的文本，Dropped 为 true 时为空
+	Text string
+
+	// Dropped 是否因命中 drop 规则而丢弃整条内容
+	Dropped bool
+
+	// Findings 各检测器命中的次数
+	Findings map[string]int
+}
+
+/**
+ * Redacted 判断是否检测到敏感内容
+ *
+ * Returns: bool - true 表示至少命中一个检测器
+ */
+func (r Result) Redacted() bool {
+	return len(r.Findings) > 0
+}
+
+/**
+ * Kinds 获取命中的检测器名称
+ *
+ * Returns: []string - 按名称排序的检测器列表
+ */
+func (r Result) Kinds() []string {
+	kinds := make([]string, 0, len(r.Findings))
+	for kind := range r.Findings {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+/**
+ * Pipeline 脱敏流水线
+ *
+ * 依次运行所有检测器，任意 drop 规则命中时丢弃整条内容；
+ * 否则按位置替换敏感片段，重叠的片段保留先开始、更长的那个。
+ * Pipeline 创建后只读，可并发使用。
+ */
+type Pipeline struct {
+	rules    []Rule
+	hashSalt string
+}
+
+/**
+ * NewPipeline 使用指定规则创建脱敏流水线
+ *
+ * Parameters:
+ *   - rules: 脱敏规则，action 为 off 的规则会被忽略
+ *
+ * Returns: *Pipeline - 脱敏流水线
+ */
+func NewPipeline(rules ...Rule) *Pipeline {
+	p := &Pipeline{}
+	for _, rule := range rules {
+		if rule.Detector == nil || rule.Action == ActionOff || rule.Action == "" {
+			continue
+		}
+		p.rules = append(p.rules, rule)
+	}
+	return p
+}
+
+/**
+ * NewDefaultPipeline 创建使用内置检测器和默认动作的脱敏流水线
+ *
+ * 没有盐值文件，hash 动作使用本进程内的随机盐值
+ *
+ * Returns: *Pipeline - 脱敏流水线
+ */
+func NewDefaultPipeline() *Pipeline {
+	pipeline, _ := NewPipelineFromConfig(config.RedactionConfig{})
+	return pipeline
+}
+
+/**
+ * NewPipelineFromConfig 根据配置创建脱敏流水线
+ *
+ * 配置中的 detectors 覆盖 DefaultActions 中对应检测器的动作，
+ * 未出现在配置中的检测器使用默认动作。hash 动作始终加盐，
+ * 未配置 hash_salt 时使用 hash_salt_file 中持久化的随机盐值
+ *
+ * Parameters:
+ *   - cfg: 脱敏配置
+ *
+ * Returns: *Pipeline - 脱敏流水线, error - 检测器名称或动作无效、盐值文件读写失败时返回错误
+ */
+func NewPipelineFromConfig(cfg config.RedactionConfig) (*Pipeline, error) {
+	actions := make(map[string]Action, len(DefaultActions))
+	for name, action := range DefaultActions {
+		actions[name] = action
+	}
+
+	for name, value := range cfg.Detectors {
+		if _, ok := DefaultActions[name]; !ok {
+			return nil, fmt.Errorf("未知的敏感内容检测器: %s", name)
+		}
+		action, err := ParseAction(value)
+		if err != nil {
+			return nil, fmt.Errorf("解析检测器 %s 的动作失败: %w", name, err)
+		}
+		actions[name] = action
+	}
+
+	detectors := builtinDetectors(cfg.EntropyThreshold, cfg.EntropyMinLength)
+	rules := make([]Rule, 0, len(detectors))
+	for _, detector := range detectors {
+		rules = append(rules, Rule{Detector: detector, Action: actions[detector.Name()]})
+	}
+
+	pipeline := NewPipeline(rules...)
+	pipeline.hashSalt = cfg.HashSalt
+	if pipeline.hashSalt == "" && pipeline.usesHash() {
+		salt, err := loadOrCreateSalt(cfg.HashSaltFile)
+		if err != nil {
+			return nil, err
+		}
+		pipeline.hashSalt = salt
+	}
+	return pipeline, nil
+}
+
+/**
+ * usesHash 判断是否有规则使用 hash 动作
+ */
+func (p *Pipeline) usesHash() bool {
+	for _, rule := range p.rules {
+		if rule.Action == ActionHash {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * loadOrCreateSalt 读取盐值文件，不存在时生成随机盐值并保存
+ *
+ * 盐值文件只有当前用户可读写
+ *
+ * Parameters:
+ *   - path: 盐值文件路径，支持环境变量；为空时返回不保存的随机盐值
+ *
+ * Returns: string - 盐值, error - 读取、生成或保存失败时返回错误
+ */
+func loadOrCreateSalt(path string) (string, error) {
+	path = os.ExpandEnv(path)
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err == nil {
+			if salt := strings.TrimSpace(string(data)); salt != "" {
+				return salt, nil
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return "", fmt.Errorf("读取盐值文件失败: %w", err)
+		}
+	}
+
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("生成盐值失败: %w", err)
+	}
+	salt := hex.EncodeToString(buf)
+	if path == "" {
+		return salt, nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return "", fmt.Errorf("创建盐值目录失败: %w", err)
+	}
+	if err := os.WriteFile(path, []byte(salt+"\n"), 0o600); err != nil {
+		return "", fmt.Errorf("保存盐值文件失败: %w", err)
+	}
+	return salt, nil
+}
+
+/**
+ * span 待替换的敏感片段
+ */
+type span struct {
+	Match
+	kind   string
+	action Action
+}
+
+/**
+ * Redact 对文本执行脱敏
+ *
+ * Parameters:
+ *   - text: 原始文本
+ *
+ * Returns: Result - 脱敏结果
+ */
+func (p *Pipeline) Redact(text string) Result {
+	result := Result{Findings: make(map[string]int)}
+	if text == "" {
+		result.Text = text
+		return result
+	}
+
+	var spans []span
+	for _, rule := range p.rules {
+		for _, m := range rule.Detector.Detect(text) {
+			spans = append(spans, span{Match: m, kind: rule.Detector.Name(), action: rule.Action})
+		}
+	}
+
+	if len(spans) == 0 {
+		result.Text = text
+		return result
+	}
+
+	// 先开始的优先，同一位置更长的优先，完全相同时保留规则顺序靠前的
+	sort.SliceStable(spans, func(i, j int) bool {
+		if spans[i].Start != spans[j].Start {
+			return spans[i].Start < spans[j].Start
+		}
+		return spans[i].End > spans[j].End
+	})
+
+	var kept []span
+	end := 0
+	for _, s := range spans {
+		if s.Start < end {
+			continue
+		}
+		kept = append(kept, s)
+		end = s.End
+		result.Findings[s.kind]++
+		if s.action == ActionDrop {
+			result.Dropped = true
+		}
+	}
+
+	if result.Dropped {
+		return result
+	}
+
+	var b strings.Builder
+	b.Grow(len(text))
+	last := 0
+	for _, s := range kept {
+		b.WriteString(text[last:s.Start])
+		b.WriteString(p.replacement(s.kind, s.action, text[s.Start:s.End]))
+		last = s.End
+	}
+	b.WriteString(text[last:])
+
+	result.Text = b.String()
+	return result
+}
+
+/**
+ * replacement 生成敏感片段的替换文本
+ */
+func (p *Pipeline) replacement(kind string, action Action, value string) string {
+	if action == ActionHash {
+		sum := sha256.Sum256([]byte(p.hashSalt + value))
+		return fmt.Sprintf("[%s:%s]", kind, hex.EncodeToString(sum[:])[:16])
+	}
+	return fmt.Sprintf("[REDACTED:%s]", kind)
+}
@@ -0,0 +1,180 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 模型提供商的熔断器
+ */
+
+package ai
+
+import (
+	"sync"
+	"time"
+)
+
+/**
+ * BreakerState 熔断器状态
+ */
+type BreakerState string
+
+const (
+	// BreakerClosed 正常，请求直接通过
+	BreakerClosed BreakerState = "closed"
+
+	// BreakerOpen 熔断，请求直接跳过，冷却时间结束后进入半开
+	BreakerOpen BreakerState = "open"
+
+	// BreakerHalfOpen 半开，只放行一个探测请求，成功则恢复，失败则重新熔断
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+/**
+ * CircuitBreaker 熔断器
+ *
+ * 连续失败达到阈值后熔断，冷却时间内跳过该提供商；冷却结束后放行一个探测请求，
+ * 探测成功恢复正常，失败则再次熔断
+ */
+type CircuitBreaker struct {
+	// failureThreshold 触发熔断的连续失败次数
+	failureThreshold int
+
+	// openTimeout 熔断后的冷却时间
+	openTimeout time.Duration
+
+	// now 当前时间，测试时可替换
+	now func() time.Time
+
+	mu        sync.Mutex
+	state     BreakerState
+	failures  int
+	openedAt  time.Time
+	probing   bool
+	lastError string
+}
+
+/**
+ * NewCircuitBreaker 创建熔断器
+ *
+ * Parameters:
+ *   - failureThreshold: 触发熔断的连续失败次数，小于等于 0 时为 3
+ *   - openTimeout: 熔断后的冷却时间，小于等于 0 时为 30 秒
+ *
+ * Returns: *CircuitBreaker - 熔断器实例
+ */
+func NewCircuitBreaker(failureThreshold int, openTimeout time.Duration) *CircuitBreaker {
+	if failureThreshold <= 0 {
+		failureThreshold = 3
+	}
+	if openTimeout <= 0 {
+		openTimeout = 30 * time.Second
+	}
+	return &CircuitBreaker{
+		failureThreshold: failureThreshold,
+		openTimeout:      openTimeout,
+		now:              time.Now,
+		state:            BreakerClosed,
+	}
+}
+
+/**
+ * Allow 判断是否放行请求
+ *
+ * 熔断冷却结束时转为半开并放行一个探测请求，探测结束前其他请求仍被拒绝
+ *
+ * Returns: bool - 是否放行
+ */
+func (b *CircuitBreaker) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		if b.now().Sub(b.openedAt) < b.openTimeout {
+			return false
+		}
+		b.state = BreakerHalfOpen
+		b.probing = true
+		return true
+	case BreakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+/**
+ * RecordSuccess 记录一次成功，恢复正常状态
+ */
+func (b *CircuitBreaker) RecordSuccess() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.state = BreakerClosed
+	b.failures = 0
+	b.probing = false
+	b.lastError = ""
+}
+
+/**
+ * RecordFailure 记录一次失败
+ *
+ * Parameters:
+ *   - err: 失败原因
+ */
+func (b *CircuitBreaker) RecordFailure(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures++
+	b.probing = false
+	if err != nil {
+		b.lastError = err.Error()
+	}
+	if b.state == BreakerHalfOpen || b.failures >= b.failureThreshold {
+		b.state = BreakerOpen
+		b.openedAt = b.now()
+	}
+}
+
+/**
+ * Release 放弃已放行的请求，不计入成功或失败（如上下文被取消）
+ */
+func (b *CircuitBreaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+/**
+ * State 获取当前状态
+ *
+ * Returns: BreakerState - 熔断器状态
+ */
+func (b *CircuitBreaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	// 冷却结束但还没有请求时，对外表现为半开
+	if b.state == BreakerOpen && b.now().Sub(b.openedAt) >= b.openTimeout {
+		return BreakerHalfOpen
+	}
+	return b.state
+}
+
+/**
+ * snapshot 获取状态快照
+ */
+func (b *CircuitBreaker) snapshot() (state BreakerState, failures int, lastError string, retryAt time.Time) {
+	state = b.State()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if state == BreakerOpen {
+		retryAt = b.openedAt.Add(b.openTimeout)
+	}
+	return state, b.failures, b.lastError, retryAt
+}
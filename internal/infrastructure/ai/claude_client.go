@@ -8,13 +8,14 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/metrics"
 	"github.com/cloudwego/eino-ext/components/model/claude"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
@@ -61,6 +62,12 @@ type ClaudeConfig struct {
 
 	// Timeout 请求超时时间
 	Timeout time.Duration
+
+	// MaxRepairs 响应不符合输出格式时的最大重新请求次数（0 为默认值 2，负数不重试）
+	MaxRepairs int
+
+	// UsageRecorder 每次收到响应后的用量回调（可选），为 nil 时不上报
+	UsageRecorder UsageRecorder
 }
 
 /**
@@ -109,6 +116,10 @@ func (c *ClaudeConfig) Validate() error {
 		c.Timeout = 30 * time.Second
 	}
 
+	if c.MaxRepairs == 0 {
+		c.MaxRepairs = DefaultMaxRepairs
+	}
+
 	return nil
 }
 
@@ -167,20 +178,34 @@ func NewClaudeClient(config *ClaudeConfig) (*ClaudeClient, error) {
  */
 func (c *ClaudeClient) AnalyzePattern(ctx context.Context, patternData map[string]interface{}) (*PatternAnalysis, error) {
 	// 构建提示词
-	prompt := BuildPatternAnalysisPrompt(patternData)
-
-	// 准备消息
-	messages := []*schema.Message{
-		{
-			Role:    schema.System,
-			Content: "你是一个专业的自动化分析助手，擅长评估用户操作模式是否值得自动化。请以 JSON 格式返回分析结果。",
-		},
-		{
-			Role:    schema.User,
-			Content: prompt,
-		},
+	prompt := RenderPatternAnalysisPrompt(patternData)
+
+	// 调用 Claude API，响应不符合格式时带着错误重新请求
+	analysis, err := AnalyzeWithRepair(patternUsageContext(ctx, patternData), c.generate, prompt, c.config.MaxRepairs)
+	if err != nil {
+		var outputErr *OutputError
+		if errors.As(err, &outputErr) {
+			logger.Error("解析 Claude 响应失败",
+				zap.String("response", outputErr.Content),
+				zap.Error(err))
+			return nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+		return nil, err
 	}
 
+	return analysis, nil
+}
+
+/**
+ * generate 调用一次 Claude API
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - messages: 对话消息
+ *
+ * Returns: string - 响应内容
+ */
+func (c *ClaudeClient) generate(ctx context.Context, messages []*schema.Message) (string, error) {
 	// 设置超时
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
@@ -191,10 +216,11 @@ func (c *ClaudeClient) AnalyzePattern(ctx context.Context, patternData map[strin
 	duration := time.Since(startTime)
 
 	if err != nil {
+		metrics.ObserveAICall("claude", duration, 0, 0, err)
 		logger.Error("调用 Claude API 失败",
 			zap.Error(err),
 			zap.Duration("duration", duration))
-		return nil, fmt.Errorf("调用 Claude API 失败: %w", err)
+		return "", fmt.Errorf("调用 Claude API 失败: %w", err)
 	}
 
 	logger.Info("调用 Claude API 成功",
@@ -202,17 +228,14 @@ func (c *ClaudeClient) AnalyzePattern(ctx context.Context, patternData map[strin
 		zap.Int("promptTokens", response.ResponseMeta.Usage.PromptTokens),
 		zap.Int("completionTokens", response.ResponseMeta.Usage.CompletionTokens),
 		zap.Int("totalTokens", response.ResponseMeta.Usage.TotalTokens))
-
-	// 解析响应
-	analysis, err := c.parseAnalysisResponse(response.Content)
-	if err != nil {
-		logger.Error("解析 Claude 响应失败",
-			zap.String("response", response.Content),
-			zap.Error(err))
-		return nil, fmt.Errorf("解析响应失败: %w", err)
-	}
-
-	return analysis, nil
+	metrics.ObserveAICall("claude", duration,
+		response.ResponseMeta.Usage.PromptTokens,
+		response.ResponseMeta.Usage.CompletionTokens, nil)
+	reportUsage(ctx, c.config.UsageRecorder, "claude", c.config.Model,
+		response.ResponseMeta.Usage.PromptTokens,
+		response.ResponseMeta.Usage.CompletionTokens, duration)
+
+	return response.Content, nil
 }
 
 /**
@@ -252,59 +275,6 @@ func (c *ClaudeClient) AnalyzePatternBatch(ctx context.Context, patterns []map[s
 	return results, nil
 }
 
-/**
- * parseAnalysisResponse 解析 Claude 响应
- *
- * Parameters:
- *   - content: Claude 返回的内容
- *
- * Returns: *PatternAnalysis - 解析后的分析结果
- */
-func (c *ClaudeClient) parseAnalysisResponse(content string) (*PatternAnalysis, error) {
-	// 尝试提取 JSON（如果响应包含 markdown 代码块）
-	jsonStr := content
-	if len(content) > 0 {
-		// 移除可能的 markdown 代码块标记
-		if content[0] == '`' {
-			// 查找第一个 ``` 后的位置
-			start := 0
-			for i := 0; i < len(content)-3; i++ {
-				if content[i] == '`' && content[i+1] == '`' && content[i+2] == '`' {
-					start = i + 3
-					// 跳过换行符
-					for start < len(content) && content[start] == '\n' {
-						start++
-					}
-					break
-				}
-			}
-			// 查找最后一个 ``` 的位置
-			end := len(content)
-			for i := len(content) - 1; i >= 3; i-- {
-				if content[i] == '`' && content[i-1] == '`' && content[i-2] == '`' {
-					end = i - 2
-					break
-				}
-			}
-			if start > 0 && end > start {
-				jsonStr = content[start:end]
-			}
-		}
-	}
-
-	// 解析 JSON
-	var analysis PatternAnalysis
-	err := json.Unmarshal([]byte(jsonStr), &analysis)
-	if err != nil {
-		return nil, fmt.Errorf("JSON 解析失败: %w", err)
-	}
-
-	// 设置分析时间
-	analysis.AnalyzedAt = time.Now()
-
-	return &analysis, nil
-}
-
 /**
  * PatternAnalysis 模式分析结果
  */
@@ -316,10 +286,10 @@ type PatternAnalysis struct {
 	Reason string `json:"reason"`
 
 	// EstimatedTimeSaving 预计节省时间（秒）
-	EstimatedTimeSaving int64 `json:"estimated_time_saving"`
+	EstimatedTimeSaving int64 `json:"estimated_time_saving" schema:"minimum=0"`
 
 	// Complexity 实现复杂度（low/medium/high）
-	Complexity string `json:"complexity"`
+	Complexity string `json:"complexity" schema:"enum=low|medium|high"`
 
 	// SuggestedName 建议的自动化名称
 	SuggestedName string `json:"suggested_name"`
@@ -328,7 +298,13 @@ type PatternAnalysis struct {
 	SuggestedSteps []string `json:"suggested_steps"`
 
 	// AnalyzedAt 分析时间
-	AnalyzedAt time.Time `json:"analyzed_at"`
+	AnalyzedAt time.Time `json:"analyzed_at" schema:"-"`
+
+	// PromptVersion 生成该结果的提示词模板，如 pattern_analysis.v1.zh
+	PromptVersion string `json:"prompt_version,omitempty" schema:"-"`
+
+	// Provider 给出该结果的提供商，由降级链填写
+	Provider string `json:"provider,omitempty" schema:"-"`
 }
 
 /**
@@ -27,6 +27,12 @@ const (
 
 	// ModelTypeOllama Ollama 本地模型
 	ModelTypeOllama ModelType = "ollama"
+
+	// ModelTypeFallback 按顺序降级的组合模型
+	ModelTypeFallback ModelType = "fallback"
+
+	// ModelTypeReplay 录制回放或脚本模型，用于测试和离线演示
+	ModelTypeReplay ModelType = "replay"
 )
 
 /**
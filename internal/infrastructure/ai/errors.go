@@ -0,0 +1,124 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 模型调用错误的分类
+ */
+
+package ai
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	goopenai "github.com/meguminnnnnnnnn/go-openai"
+)
+
+/**
+ * HTTPStatusError 模型服务返回的非成功 HTTP 状态
+ *
+ * 自行实现 HTTP 调用的客户端（如 Ollama）返回此错误，便于判断是否可以重试
+ */
+type HTTPStatusError struct {
+	// Provider 模型提供商
+	Provider string
+
+	// StatusCode HTTP 状态码
+	StatusCode int
+
+	// Message 服务返回的错误信息
+	Message string
+
+	// RetryAfter 服务要求的重试等待时间（Retry-After 响应头），未提供时为 0
+	RetryAfter time.Duration
+}
+
+/**
+ * Error 实现 error 接口
+ */
+func (e *HTTPStatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%s 返回错误状态 %d", e.Provider, e.StatusCode)
+	}
+	return fmt.Sprintf("%s 返回错误 (%d): %s", e.Provider, e.StatusCode, e.Message)
+}
+
+/**
+ * StatusCode 提取错误中的 HTTP 状态码
+ *
+ * 支持 HTTPStatusError 以及 Claude、OpenAI 兼容 SDK 返回的错误
+ *
+ * Parameters:
+ *   - err: 模型调用返回的错误
+ *
+ * Returns: int - HTTP 状态码，无法识别时返回 0
+ */
+func StatusCode(err error) int {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode
+	}
+
+	var apiErr *goopenai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+
+	var requestErr *goopenai.RequestError
+	if errors.As(err, &requestErr) {
+		return requestErr.HTTPStatusCode
+	}
+
+	var claudeErr *anthropic.Error
+	if errors.As(err, &claudeErr) {
+		return claudeErr.StatusCode
+	}
+
+	return 0
+}
+
+/**
+ * IsRetryable 判断错误是否为可重试的暂时性错误
+ *
+ * 限流（429）和服务端错误（5xx）可以重试；鉴权失败、参数错误和响应解析失败重试也不会成功
+ *
+ * Parameters:
+ *   - err: 模型调用返回的错误
+ *
+ * Returns: bool - 是否可以重试
+ */
+func IsRetryable(err error) bool {
+	code := StatusCode(err)
+	return code == http.StatusTooManyRequests || code >= 500
+}
+
+/**
+ * retryAfter 提取服务要求的重试等待时间
+ */
+func retryAfter(err error) time.Duration {
+	var statusErr *HTTPStatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.RetryAfter
+	}
+
+	var claudeErr *anthropic.Error
+	if errors.As(err, &claudeErr) && claudeErr.Response != nil {
+		return parseRetryAfter(claudeErr.Response.Header.Get("Retry-After"))
+	}
+
+	return 0
+}
+
+/**
+ * parseRetryAfter 解析 Retry-After 响应头（秒数）
+ */
+func parseRetryAfter(value string) time.Duration {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
@@ -15,7 +15,7 @@ import (
  * AIConfig AI 模型通用配置
  */
 type AIConfig struct {
-	// Provider 模型提供商（claude, openai, zhipu, ollama）
+	// Provider 模型提供商（claude, openai, zhipu, ollama, replay）
 	Provider string
 
 	// APIKey API 密钥
@@ -35,6 +35,21 @@ type AIConfig struct {
 
 	// Timeout 请求超时时间
 	Timeout int // 秒
+
+	// JSONMode 是否要求 JSON 格式响应（OpenAI 兼容接口和 Ollama，nil 表示开启）
+	JSONMode *bool
+
+	// AutoPull 模型未下载时自动拉取（仅 Ollama）
+	AutoPull bool
+
+	// ReplayDir 录制夹具目录（仅 replay）
+	ReplayDir string
+
+	// MaxRepairs 响应不符合输出格式时的最大重新请求次数（0 为默认值，负数不重试）
+	MaxRepairs int
+
+	// UsageRecorder 每次收到响应后的用量回调（可选），为 nil 时不上报
+	UsageRecorder UsageRecorder
 }
 
 /**
@@ -44,10 +59,11 @@ type AIConfig struct {
  * - AI_PROVIDER: 提供商（claude, openai, zhipu）
  * - AI_API_KEY: API 密钥
  * - AI_MODEL: 模型名称
- * - AI_BASE_URL: 自定义 API 端点
+ * - AI_BASE_URL: 自定义 API 端点（OpenAI 兼容服务也可用 OPENAI_BASE_URL）
  * - AI_MAX_TOKENS: 最大 token 数
  * - AI_TEMPERATURE: 温度参数
  * - AI_TIMEOUT: 超时时间（秒）
+ * - AI_REPLAY_DIR: 录制夹具目录（replay）
  */
 func (c *AIConfig) LoadFromEnv() *AIConfig {
 	// 加载提供商
@@ -70,8 +86,8 @@ func (c *AIConfig) LoadFromEnv() *AIConfig {
 				c.APIKey = os.Getenv("OPENAI_API_KEY")
 			case "zhipu":
 				c.APIKey = os.Getenv("ZHIPU_API_KEY")
-			case "ollama":
-				// Ollama 本地运行不需要 API Key
+			case "ollama", "replay":
+				// Ollama 本地运行、回放模型不需要 API Key
 				c.APIKey = ""
 			}
 		}
@@ -98,6 +114,14 @@ func (c *AIConfig) LoadFromEnv() *AIConfig {
 	// 加载 BaseURL
 	if baseURL := os.Getenv("AI_BASE_URL"); baseURL != "" {
 		c.BaseURL = &baseURL
+	} else if c.BaseURL == nil && c.Provider == "openai" {
+		if baseURL := os.Getenv("OPENAI_BASE_URL"); baseURL != "" {
+			c.BaseURL = &baseURL
+		}
+	}
+
+	if c.ReplayDir == "" {
+		c.ReplayDir = os.Getenv("AI_REPLAY_DIR")
 	}
 
 	// 设置默认值
@@ -118,20 +142,34 @@ func (c *AIConfig) Validate() error {
 
 	// 验证提供商是否支持
 	switch c.Provider {
-	case "claude", "openai", "zhipu", "ollama":
+	case "claude", "openai", "zhipu", "ollama", "replay":
 		// 支持的提供商
 	default:
 		return fmt.Errorf("不支持的提供商: %s", c.Provider)
 	}
 
-	// 验证 API Key（Ollama 除外）
-	if c.Provider != "ollama" && c.APIKey == "" {
+	if c.Provider == "replay" {
+		if c.ReplayDir == "" {
+			return fmt.Errorf("回放模型需要配置夹具目录")
+		}
+		return nil
+	}
+
+	// 验证 API Key（Ollama 和自定义端点的 OpenAI 兼容服务除外）
+	if c.Provider != "ollama" && c.APIKey == "" && !c.localOpenAICompatible() {
 		return fmt.Errorf("API Key 不能为空")
 	}
 
 	return nil
 }
 
+/**
+ * localOpenAICompatible 是否为自定义端点的 OpenAI 兼容服务（vLLM、LM Studio 等通常不需要 API Key）
+ */
+func (c *AIConfig) localOpenAICompatible() bool {
+	return c.Provider == "openai" && c.BaseURL != nil && *c.BaseURL != "" && *c.BaseURL != DefaultOpenAIBaseURL
+}
+
 /**
  * NewAIModel 创建 AI 模型实例（工厂方法）
  *
@@ -155,13 +193,13 @@ func NewAIModel(config *AIConfig) (AIModel, error) {
 	case "claude":
 		return NewClaudeClientFromConfig(config)
 	case "openai":
-		// TODO: 实现 OpenAI 客户端
-		return nil, fmt.Errorf("OpenAI 客户端尚未实现")
+		return NewOpenAIClientFromConfig(config)
 	case "zhipu":
 		return NewZhipuClientFromConfig(config)
 	case "ollama":
-		// TODO: 实现 Ollama 客户端
-		return nil, fmt.Errorf("Ollama 客户端尚未实现")
+		return NewOllamaClientFromConfig(config)
+	case "replay":
+		return NewReplayModelFromConfig(config, nil)
 	default:
 		return nil, fmt.Errorf("未知的提供商: %s", config.Provider)
 	}
@@ -172,11 +210,13 @@ func NewAIModel(config *AIConfig) (AIModel, error) {
  */
 func NewClaudeClientFromConfig(config *AIConfig) (AIModel, error) {
 	claudeConfig := &ClaudeConfig{
-		APIKey:      config.APIKey,
-		Model:       config.Model,
-		BaseURL:     config.BaseURL,
-		MaxTokens:   config.MaxTokens,
-		Temperature: config.Temperature,
+		APIKey:        config.APIKey,
+		Model:         config.Model,
+		BaseURL:       config.BaseURL,
+		MaxTokens:     config.MaxTokens,
+		Temperature:   config.Temperature,
+		MaxRepairs:    config.MaxRepairs,
+		UsageRecorder: config.UsageRecorder,
 	}
 
 	// 设置超时
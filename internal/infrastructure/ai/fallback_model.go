@@ -0,0 +1,395 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 按顺序降级的组合模型
+ */
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+// 确保 FallbackModel 实现了 AIModel 接口
+var _ AIModel = (*FallbackModel)(nil)
+
+/**
+ * ErrAllProvidersFailed 所有提供商都调用失败或已熔断
+ */
+var ErrAllProvidersFailed = errors.New("所有 AI 提供商均不可用")
+
+/**
+ * FallbackProvider 降级链中的一个提供商
+ */
+type FallbackProvider struct {
+	// Name 提供商名称，用于日志和健康状态，为空时使用模型类型
+	Name string
+
+	// Model 模型实例
+	Model AIModel
+}
+
+/**
+ * FallbackConfig 降级链配置
+ */
+type FallbackConfig struct {
+	// FailureThreshold 触发熔断的连续失败次数（默认 3）
+	FailureThreshold int
+
+	// OpenTimeout 熔断后的冷却时间，结束后放行一个探测请求（默认 30 秒）
+	OpenTimeout time.Duration
+
+	// MaxRetries 遇到 429/5xx 时对同一提供商的最大重试次数（默认 2）
+	MaxRetries int
+
+	// InitialBackoff 第一次重试前的等待时间，之后每次翻倍（默认 500 毫秒）
+	InitialBackoff time.Duration
+
+	// MaxBackoff 重试等待时间上限（默认 10 秒）
+	MaxBackoff time.Duration
+
+	// OnHealthChange 提供商熔断状态变化时的回调（可选），在调用方的 goroutine 中执行
+	OnHealthChange func(health ProviderHealth)
+}
+
+/**
+ * ProviderHealth 提供商健康状态
+ */
+type ProviderHealth struct {
+	// Name 提供商名称
+	Name string `json:"name"`
+
+	// State 熔断器状态
+	State BreakerState `json:"state"`
+
+	// PreviousState 变化前的状态，Health 返回的快照中为空
+	PreviousState BreakerState `json:"previous_state,omitempty"`
+
+	// ConsecutiveFailures 连续失败次数
+	ConsecutiveFailures int `json:"consecutive_failures"`
+
+	// LastError 最近一次失败的原因
+	LastError string `json:"last_error,omitempty"`
+
+	// RetryAt 熔断状态下允许下一次探测的时间
+	RetryAt time.Time `json:"retry_at,omitempty"`
+}
+
+/**
+ * fallbackEntry 降级链中的提供商及其熔断器
+ */
+type fallbackEntry struct {
+	name     string
+	model    AIModel
+	breaker  *CircuitBreaker
+	reported BreakerState
+}
+
+/**
+ * FallbackModel 按顺序降级的组合模型
+ *
+ * 按配置顺序尝试各个提供商：遇到限流或服务端错误时退避重试，仍然失败则换下一个；
+ * 每个提供商有独立的熔断器，连续失败后暂时跳过，冷却后用一个请求探测是否恢复。
+ * 通常把本地模型（Ollama）放在最后，云端服务不可用时分析仍能继续
+ */
+type FallbackModel struct {
+	config  FallbackConfig
+	entries []*fallbackEntry
+
+	// mu 保护 entries 中的 reported，与 freeOnly 创建的视图共用
+	mu *sync.Mutex
+
+	// sleep 等待重试，测试时可替换
+	sleep func(ctx context.Context, d time.Duration) error
+
+	// view 是否为 freeOnly 创建的视图
+	view bool
+}
+
+/**
+ * NewFallbackModel 创建降级链
+ *
+ * Parameters:
+ *   - providers: 按优先级排列的提供商，至少一个
+ *   - config: 降级链配置
+ *
+ * Returns: *FallbackModel - 组合模型实例
+ */
+func NewFallbackModel(providers []FallbackProvider, config FallbackConfig) (*FallbackModel, error) {
+	if len(providers) == 0 {
+		return nil, fmt.Errorf("降级链至少需要一个提供商")
+	}
+
+	if config.MaxRetries <= 0 {
+		config.MaxRetries = 2
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = 500 * time.Millisecond
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = 10 * time.Second
+	}
+
+	m := &FallbackModel{config: config, mu: &sync.Mutex{}, sleep: sleepContext}
+	for _, provider := range providers {
+		if provider.Model == nil {
+			return nil, fmt.Errorf("提供商 %s 的模型不能为空", provider.Name)
+		}
+		name := provider.Name
+		if name == "" {
+			name = string(provider.Model.GetType())
+		}
+		m.entries = append(m.entries, &fallbackEntry{
+			name:     name,
+			model:    provider.Model,
+			breaker:  NewCircuitBreaker(config.FailureThreshold, config.OpenTimeout),
+			reported: BreakerClosed,
+		})
+	}
+	return m, nil
+}
+
+/**
+ * AnalyzePattern 分析模式，当前提供商失败时降级到下一个
+ *
+ * Parameters:
+ *   - ctx: 上下文，取消时立即返回，不计为提供商失败
+ *   - patternData: 模式数据（JSON格式）
+ *
+ * Returns: *PatternAnalysis - 第一个成功的提供商的分析结果，Provider 为该提供商名称
+ */
+func (m *FallbackModel) AnalyzePattern(ctx context.Context, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	var errs []error
+	for i, entry := range m.entries {
+		if !entry.breaker.Allow() {
+			errs = append(errs, fmt.Errorf("%s: 已熔断", entry.name))
+			continue
+		}
+		m.report(entry)
+
+		analysis, err := m.try(ctx, entry, patternData)
+		if err == nil {
+			analysis.Provider = entry.name
+			entry.breaker.RecordSuccess()
+			m.report(entry)
+			if i > 0 {
+				logger.Info("已降级到备用 AI 提供商",
+					zap.String("provider", entry.name))
+			}
+			return analysis, nil
+		}
+
+		if ctx.Err() != nil {
+			entry.breaker.Release()
+			return nil, ctx.Err()
+		}
+
+		entry.breaker.RecordFailure(err)
+		m.report(entry)
+		logger.Warn("AI 提供商调用失败，尝试下一个",
+			zap.String("provider", entry.name),
+			zap.Error(err))
+		errs = append(errs, fmt.Errorf("%s: %w", entry.name, err))
+	}
+
+	return nil, fmt.Errorf("%w: %w", ErrAllProvidersFailed, errors.Join(errs...))
+}
+
+/**
+ * try 调用单个提供商，遇到可重试错误时指数退避重试
+ */
+func (m *FallbackModel) try(ctx context.Context, entry *fallbackEntry, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	backoff := m.config.InitialBackoff
+	for attempt := 0; ; attempt++ {
+		analysis, err := entry.model.AnalyzePattern(ctx, patternData)
+		if err == nil || ctx.Err() != nil || !IsRetryable(err) || attempt >= m.config.MaxRetries {
+			return analysis, err
+		}
+
+		wait := backoff
+		if after := retryAfter(err); after > wait {
+			wait = after
+		}
+		if wait > m.config.MaxBackoff {
+			wait = m.config.MaxBackoff
+		}
+		logger.Info("AI 提供商暂时不可用，等待后重试",
+			zap.String("provider", entry.name),
+			zap.Int("status", StatusCode(err)),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("wait", wait))
+
+		if err := m.sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+		backoff *= 2
+	}
+}
+
+/**
+ * report 熔断状态变化时通知回调
+ */
+func (m *FallbackModel) report(entry *fallbackEntry) {
+	health := entry.health()
+
+	m.mu.Lock()
+	previous := entry.reported
+	changed := health.State != previous
+	entry.reported = health.State
+	m.mu.Unlock()
+
+	if !changed {
+		return
+	}
+
+	logger.Info("AI 提供商健康状态变化",
+		zap.String("provider", entry.name),
+		zap.String("from", string(previous)),
+		zap.String("to", string(health.State)))
+
+	if m.config.OnHealthChange != nil {
+		health.PreviousState = previous
+		m.config.OnHealthChange(health)
+	}
+}
+
+/**
+ * Health 获取各提供商的健康状态
+ *
+ * Returns: []ProviderHealth - 按降级顺序排列
+ */
+func (m *FallbackModel) Health() []ProviderHealth {
+	healths := make([]ProviderHealth, 0, len(m.entries))
+	for _, entry := range m.entries {
+		healths = append(healths, entry.health())
+	}
+	return healths
+}
+
+/**
+ * health 获取提供商的健康状态
+ */
+func (e *fallbackEntry) health() ProviderHealth {
+	state, failures, lastError, retryAt := e.breaker.snapshot()
+	return ProviderHealth{
+		Name:                e.name,
+		State:               state,
+		ConsecutiveFailures: failures,
+		LastError:           lastError,
+		RetryAt:             retryAt,
+	}
+}
+
+/**
+ * AnalyzePatternBatch 批量分析模式
+ *
+ * 每个模式单独走降级链；上下文取消后剩余的模式不再分析
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - patterns: 模式列表
+ *
+ * Returns: []*PatternAnalysis - 分析结果列表
+ */
+func (m *FallbackModel) AnalyzePatternBatch(ctx context.Context, patterns []map[string]interface{}) ([]*PatternAnalysis, error) {
+	results := make([]*PatternAnalysis, len(patterns))
+
+	for i, pattern := range patterns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		analysis, err := m.AnalyzePattern(ctx, pattern)
+		if err != nil {
+			logger.Error("分析模式失败",
+				zap.Int("index", i),
+				zap.Error(err))
+			// 继续处理其他模式，不中断整个批次
+			results[i] = &PatternAnalysis{
+				ShouldAutomate: false,
+				Reason:         fmt.Sprintf("分析失败: %v", err),
+				Complexity:     "unknown",
+			}
+		} else {
+			results[i] = analysis
+		}
+	}
+
+	return results, nil
+}
+
+/**
+ * freeOnly 创建只包含免费提供商的视图
+ *
+ * 视图与原降级链共用提供商和熔断器，关闭视图不会关闭提供商
+ *
+ * Returns: *FallbackModel - 视图，没有免费提供商时返回 nil
+ */
+func (m *FallbackModel) freeOnly() *FallbackModel {
+	view := &FallbackModel{config: m.config, mu: m.mu, sleep: m.sleep, view: true}
+	for _, entry := range m.entries {
+		if !IsPaid(entry.model) {
+			view.entries = append(view.entries, entry)
+		}
+	}
+	if len(view.entries) == 0 {
+		return nil
+	}
+	return view
+}
+
+/**
+ * Primary 获取降级链的首选提供商名称
+ *
+ * Returns: string - 第一个提供商的名称
+ */
+func (m *FallbackModel) Primary() string {
+	return m.entries[0].name
+}
+
+/**
+ * GetType 获取模型类型
+ */
+func (m *FallbackModel) GetType() ModelType {
+	return ModelTypeFallback
+}
+
+/**
+ * Close 关闭所有提供商，视图不关闭
+ *
+ * Returns: error - 关闭错误
+ */
+func (m *FallbackModel) Close() error {
+	if m.view {
+		return nil
+	}
+	var errs []error
+	for _, entry := range m.entries {
+		if err := entry.model.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("关闭 %s 失败: %w", entry.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+/**
+ * sleepContext 等待指定时间，上下文取消时提前返回
+ */
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,284 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 降级链和熔断器单元测试
+ */
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	goopenai "github.com/meguminnnnnnnnn/go-openai"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedModel 按预设顺序返回错误的模拟模型，脚本用完后返回成功
+type scriptedModel struct {
+	modelType ModelType
+
+	mu     sync.Mutex
+	errs   []error
+	calls  int
+	closed bool
+}
+
+func (m *scriptedModel) AnalyzePattern(ctx context.Context, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls++
+	if len(m.errs) > 0 {
+		err := m.errs[0]
+		m.errs = m.errs[1:]
+		return nil, err
+	}
+	return &PatternAnalysis{ShouldAutomate: true, SuggestedName: string(m.modelType)}, nil
+}
+
+func (m *scriptedModel) AnalyzePatternBatch(ctx context.Context, patterns []map[string]interface{}) ([]*PatternAnalysis, error) {
+	return nil, errors.New("not used")
+}
+
+func (m *scriptedModel) GetType() ModelType { return m.modelType }
+
+func (m *scriptedModel) Close() error {
+	m.closed = true
+	return nil
+}
+
+// script 追加预设错误
+func (m *scriptedModel) script(errs ...error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.errs = append(m.errs, errs...)
+}
+
+// callCount 获取调用次数
+func (m *scriptedModel) callCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.calls
+}
+
+// statusErr 创建指定状态码的错误
+func statusErr(code int) error {
+	return &HTTPStatusError{Provider: "test", StatusCode: code}
+}
+
+// newTestFallback 创建不真正等待的降级链，返回记录的等待时间和健康状态变化
+func newTestFallback(t *testing.T, config FallbackConfig, providers ...FallbackProvider) (*FallbackModel, *[]time.Duration, *[]ProviderHealth) {
+	t.Helper()
+	var waits []time.Duration
+	var changes []ProviderHealth
+	config.OnHealthChange = func(health ProviderHealth) { changes = append(changes, health) }
+
+	model, err := NewFallbackModel(providers, config)
+	require.NoError(t, err)
+	model.sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return ctx.Err()
+	}
+	return model, &waits, &changes
+}
+
+// TestStatusCode 测试从各种 SDK 错误中提取状态码
+func TestStatusCode(t *testing.T) {
+	assert.Equal(t, 429, StatusCode(fmt.Errorf("调用失败: %w", statusErr(429))))
+	assert.Equal(t, 503, StatusCode(fmt.Errorf("wrap: %w", &goopenai.APIError{HTTPStatusCode: 503})))
+	assert.Equal(t, 502, StatusCode(&goopenai.RequestError{HTTPStatusCode: 502}))
+	assert.Equal(t, 0, StatusCode(errors.New("JSON 解析失败")))
+
+	assert.True(t, IsRetryable(statusErr(http.StatusTooManyRequests)))
+	assert.True(t, IsRetryable(statusErr(http.StatusInternalServerError)))
+	assert.False(t, IsRetryable(statusErr(http.StatusUnauthorized)))
+	assert.False(t, IsRetryable(errors.New("解析响应失败")))
+
+	assert.Equal(t, 5*time.Second, retryAfter(&HTTPStatusError{StatusCode: 429, RetryAfter: 5 * time.Second}))
+}
+
+// TestCircuitBreaker 测试熔断、冷却和半开探测
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+
+	assert.True(t, breaker.Allow())
+	breaker.RecordFailure(errors.New("boom"))
+	assert.Equal(t, BreakerClosed, breaker.State())
+	breaker.RecordFailure(errors.New("boom"))
+	assert.Equal(t, BreakerOpen, breaker.State())
+	assert.False(t, breaker.Allow())
+
+	// 冷却结束后只放行一个探测请求
+	now = now.Add(time.Minute)
+	assert.Equal(t, BreakerHalfOpen, breaker.State())
+	assert.True(t, breaker.Allow())
+	assert.False(t, breaker.Allow())
+
+	// 探测失败重新熔断
+	breaker.RecordFailure(errors.New("still down"))
+	assert.Equal(t, BreakerOpen, breaker.State())
+	_, failures, lastError, retryAt := breaker.snapshot()
+	assert.Equal(t, 3, failures)
+	assert.Equal(t, "still down", lastError)
+	assert.Equal(t, now.Add(time.Minute), retryAt)
+
+	// 被取消的探测不影响状态，下一个请求继续探测
+	now = now.Add(time.Minute)
+	assert.True(t, breaker.Allow())
+	breaker.Release()
+	assert.True(t, breaker.Allow())
+
+	// 探测成功恢复
+	breaker.RecordSuccess()
+	assert.Equal(t, BreakerClosed, breaker.State())
+	assert.True(t, breaker.Allow())
+}
+
+// TestFallbackModel_RetryWithBackoff 测试 429/5xx 时退避重试
+func TestFallbackModel_RetryWithBackoff(t *testing.T) {
+	primary := &scriptedModel{modelType: ModelTypeClaude}
+	primary.script(statusErr(429), statusErr(503))
+	model, waits, _ := newTestFallback(t, FallbackConfig{MaxRetries: 2, InitialBackoff: 100 * time.Millisecond},
+		FallbackProvider{Model: primary})
+
+	analysis, err := model.AnalyzePattern(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "claude", analysis.SuggestedName)
+	assert.Equal(t, 3, primary.callCount())
+	assert.Equal(t, []time.Duration{100 * time.Millisecond, 200 * time.Millisecond}, *waits)
+
+	// Retry-After 比退避时间长时按服务要求等待，但不超过上限
+	primary.script(&HTTPStatusError{StatusCode: 429, RetryAfter: time.Hour})
+	*waits = nil
+	_, err = model.AnalyzePattern(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, []time.Duration{10 * time.Second}, *waits)
+
+	// 不可重试的错误不重试
+	primary.script(statusErr(401))
+	_, err = model.AnalyzePattern(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrAllProvidersFailed)
+	assert.Equal(t, 401, StatusCode(err))
+}
+
+// TestFallbackModel_Degrade 测试按顺序降级并在熔断后跳过提供商
+func TestFallbackModel_Degrade(t *testing.T) {
+	claude := &scriptedModel{modelType: ModelTypeClaude}
+	ollama := &scriptedModel{modelType: ModelTypeOllama}
+	model, _, changes := newTestFallback(t, FallbackConfig{MaxRetries: 1, FailureThreshold: 2, OpenTimeout: time.Minute},
+		FallbackProvider{Model: claude},
+		FallbackProvider{Name: "local", Model: ollama})
+
+	now := time.Now()
+	model.entries[0].breaker.now = func() time.Time { return now }
+
+	// 主提供商持续 503，两次调用后熔断
+	for i := 0; i < 2; i++ {
+		claude.script(statusErr(503), statusErr(503))
+		analysis, err := model.AnalyzePattern(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "ollama", analysis.SuggestedName)
+		assert.Equal(t, "local", analysis.Provider)
+	}
+	assert.Equal(t, 4, claude.callCount())
+	require.Len(t, *changes, 1)
+	assert.Equal(t, ProviderHealth{
+		Name:                "claude",
+		State:               BreakerOpen,
+		PreviousState:       BreakerClosed,
+		ConsecutiveFailures: 2,
+		LastError:           "test 返回错误状态 503",
+		RetryAt:             now.Add(time.Minute),
+	}, (*changes)[0])
+
+	// 熔断期间直接使用备用提供商
+	_, err := model.AnalyzePattern(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, 4, claude.callCount())
+	assert.Equal(t, 3, ollama.callCount())
+
+	health := model.Health()
+	assert.Equal(t, BreakerOpen, health[0].State)
+	assert.Equal(t, "local", health[1].Name)
+	assert.Equal(t, BreakerClosed, health[1].State)
+
+	// 冷却结束后探测成功，恢复使用主提供商
+	now = now.Add(time.Minute)
+	analysis, err := model.AnalyzePattern(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "claude", analysis.SuggestedName)
+	assert.Equal(t, "claude", analysis.Provider)
+	assert.Equal(t, "claude", model.Primary())
+
+	var states []BreakerState
+	for _, change := range *changes {
+		states = append(states, change.State)
+	}
+	assert.Equal(t, []BreakerState{BreakerOpen, BreakerHalfOpen, BreakerClosed}, states)
+}
+
+// TestFallbackModel_AllFailed 测试所有提供商都失败
+func TestFallbackModel_AllFailed(t *testing.T) {
+	claude := &scriptedModel{modelType: ModelTypeClaude}
+	ollama := &scriptedModel{modelType: ModelTypeOllama}
+	claude.script(errors.New("鉴权失败"), errors.New("鉴权失败"))
+	ollama.script(fmt.Errorf("%w: llama3.2", ErrOllamaModelNotFound), fmt.Errorf("%w: llama3.2", ErrOllamaModelNotFound))
+	model, _, _ := newTestFallback(t, FallbackConfig{}, FallbackProvider{Model: claude}, FallbackProvider{Model: ollama})
+
+	_, err := model.AnalyzePattern(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrAllProvidersFailed)
+	assert.ErrorIs(t, err, ErrOllamaModelNotFound)
+	assert.ErrorContains(t, err, "鉴权失败")
+
+	// 批量分析中失败的模式返回失败结果，其余正常
+	results, err := model.AnalyzePatternBatch(context.Background(), []map[string]interface{}{{}, {}})
+	require.NoError(t, err)
+	assert.False(t, results[0].ShouldAutomate)
+	assert.Equal(t, "unknown", results[0].Complexity)
+	assert.True(t, results[1].ShouldAutomate)
+
+	assert.Equal(t, ModelTypeFallback, model.GetType())
+	require.NoError(t, model.Close())
+	assert.True(t, claude.closed)
+	assert.True(t, ollama.closed)
+}
+
+// TestFallbackModel_ContextCancel 测试上下文取消不计为提供商失败
+func TestFallbackModel_ContextCancel(t *testing.T) {
+	primary := &scriptedModel{modelType: ModelTypeClaude}
+	backup := &scriptedModel{modelType: ModelTypeOllama}
+	model, _, changes := newTestFallback(t, FallbackConfig{FailureThreshold: 1},
+		FallbackProvider{Model: primary}, FallbackProvider{Model: backup})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	primary.script(context.Canceled)
+
+	_, err := model.AnalyzePattern(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, backup.callCount())
+	assert.Equal(t, BreakerClosed, model.Health()[0].State)
+	assert.Empty(t, *changes)
+
+	// 等待重试时取消
+	primary.script(statusErr(429))
+	ctx, cancel = context.WithCancel(context.Background())
+	model.sleep = func(ctx context.Context, d time.Duration) error {
+		cancel()
+		return sleepContext(ctx, d)
+	}
+	_, err = model.AnalyzePattern(ctx, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 0, backup.callCount())
+
+	_, err = NewFallbackModel(nil, FallbackConfig{})
+	assert.Error(t, err)
+}
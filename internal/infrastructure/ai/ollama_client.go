@@ -0,0 +1,687 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * Ollama 本地模型客户端实现
+ */
+
+package ai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/metrics"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// 确保 OllamaClient 实现了 AIModel 接口
+var _ AIModel = (*OllamaClient)(nil)
+
+/**
+ * DefaultOllamaBaseURL Ollama 默认监听地址
+ */
+const DefaultOllamaBaseURL = "http://localhost:11434"
+
+/**
+ * ErrOllamaModelNotFound 本地没有下载指定的模型
+ */
+var ErrOllamaModelNotFound = errors.New("Ollama 模型未下载")
+
+/**
+ * ErrOllamaModelPulling 模型正在后台拉取，拉取完成前不可用
+ */
+var ErrOllamaModelPulling = errors.New("Ollama 模型正在拉取")
+
+/**
+ * OllamaClient Ollama 本地模型客户端
+ *
+ * 直接调用 Ollama HTTP API，模式分析完全在本机运行，数据不会离开本机
+ */
+type OllamaClient struct {
+	// httpClient HTTP 客户端
+	httpClient *http.Client
+
+	// config Ollama 配置
+	config *OllamaConfig
+
+	// mu 保护 ready、pulling 和 cancelPull，不在网络请求期间持有
+	mu sync.Mutex
+
+	// ready 模型已确认可用
+	ready bool
+
+	// pulling 后台拉取是否正在进行
+	pulling bool
+
+	// cancelPull 取消后台拉取，Close 时调用
+	cancelPull context.CancelFunc
+}
+
+/**
+ * OllamaConfig Ollama 配置
+ */
+type OllamaConfig struct {
+	// BaseURL Ollama 服务地址（默认读取 OLLAMA_HOST，否则 DefaultOllamaBaseURL）
+	BaseURL string
+
+	// Model 模型名称（llama3.2, qwen2.5 等）
+	Model string
+
+	// MaxTokens 最大生成 token 数（num_predict）
+	MaxTokens int
+
+	// Temperature 温度参数（0.0-2.0）
+	Temperature *float32
+
+	// Timeout 单次生成的超时时间，本地模型较慢，默认 2 分钟
+	Timeout time.Duration
+
+	// AutoPull 首次调用时模型未下载则自动拉取
+	AutoPull bool
+
+	// JSONMode 是否使用 format: json 约束输出为 JSON，为 nil 时开启
+	JSONMode *bool
+
+	// MaxRepairs 响应不符合输出格式时的最大重新请求次数（0 为默认值 2，负数不重试）
+	MaxRepairs int
+
+	// UsageRecorder 每次收到响应后的用量回调（可选），为 nil 时不上报
+	UsageRecorder UsageRecorder
+}
+
+/**
+ * GetType 获取模型类型
+ */
+func (c *OllamaConfig) GetType() ModelType {
+	return ModelTypeOllama
+}
+
+/**
+ * Validate 验证配置
+ */
+func (c *OllamaConfig) Validate() error {
+	if c.BaseURL == "" {
+		c.BaseURL = GetEnvOrDefault("OLLAMA_HOST", DefaultOllamaBaseURL)
+	}
+	// OLLAMA_HOST 常写作 127.0.0.1:11434，不带协议
+	if !strings.Contains(c.BaseURL, "://") {
+		c.BaseURL = "http://" + c.BaseURL
+	}
+	c.BaseURL = strings.TrimRight(c.BaseURL, "/")
+	if _, err := url.Parse(c.BaseURL); err != nil {
+		return fmt.Errorf("无效的 Ollama 地址: %w", err)
+	}
+
+	if c.Model == "" {
+		c.Model = GetEnvOrDefault("OLLAMA_MODEL", "llama3.2")
+	}
+
+	if c.MaxTokens <= 0 {
+		c.MaxTokens = 4096
+	}
+
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > 2) {
+		return fmt.Errorf("temperature 必须在 0.0-2.0 之间")
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = 2 * time.Minute
+	}
+
+	if c.MaxRepairs == 0 {
+		c.MaxRepairs = DefaultMaxRepairs
+	}
+
+	return nil
+}
+
+/**
+ * jsonMode 是否开启 JSON 输出
+ */
+func (c *OllamaConfig) jsonMode() bool {
+	return c.JSONMode == nil || *c.JSONMode
+}
+
+/**
+ * NewOllamaClient 创建 Ollama 客户端
+ *
+ * 创建时不连接 Ollama，服务未启动不影响应用启动；首次分析时检查模型是否可用
+ *
+ * Parameters:
+ *   - config: Ollama 配置
+ *
+ * Returns: *OllamaClient - Ollama 客户端实例
+ */
+func NewOllamaClient(config *OllamaConfig) (*OllamaClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("配置不能为空")
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("配置验证失败: %w", err)
+	}
+
+	if !isLocalURL(config.BaseURL) {
+		logger.Warn("Ollama 地址不是本机，模式数据将发送到该主机",
+			zap.String("baseURL", config.BaseURL))
+	}
+
+	logger.Info("创建 Ollama 客户端成功",
+		zap.String("baseURL", config.BaseURL),
+		zap.String("model", config.Model),
+		zap.Bool("autoPull", config.AutoPull))
+
+	return &OllamaClient{
+		// 超时由每次请求的上下文控制，拉取模型可能需要很长时间
+		httpClient: &http.Client{},
+		config:     config,
+	}, nil
+}
+
+/**
+ * NewOllamaClientFromConfig 从通用配置创建 Ollama 客户端
+ */
+func NewOllamaClientFromConfig(config *AIConfig) (AIModel, error) {
+	ollamaConfig := &OllamaConfig{
+		Model:         config.Model,
+		MaxTokens:     config.MaxTokens,
+		Temperature:   config.Temperature,
+		AutoPull:      config.AutoPull,
+		JSONMode:      config.JSONMode,
+		MaxRepairs:    config.MaxRepairs,
+		UsageRecorder: config.UsageRecorder,
+	}
+	if config.BaseURL != nil {
+		ollamaConfig.BaseURL = *config.BaseURL
+	}
+
+	// 设置超时
+	if config.Timeout > 0 {
+		ollamaConfig.Timeout = time.Duration(config.Timeout) * time.Second
+	}
+
+	return NewOllamaClient(ollamaConfig)
+}
+
+/**
+ * ollamaMessage 对话消息
+ */
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+/**
+ * ollamaChatRequest /api/chat 请求
+ */
+type ollamaChatRequest struct {
+	Model    string                 `json:"model"`
+	Messages []ollamaMessage        `json:"messages"`
+	Stream   bool                   `json:"stream"`
+	Format   string                 `json:"format,omitempty"`
+	Options  map[string]interface{} `json:"options,omitempty"`
+}
+
+/**
+ * ollamaChatResponse /api/chat 响应
+ */
+type ollamaChatResponse struct {
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	DoneReason      string        `json:"done_reason"`
+	PromptEvalCount int           `json:"prompt_eval_count"`
+	EvalCount       int           `json:"eval_count"`
+}
+
+/**
+ * ollamaPullProgress /api/pull 流式响应的一行
+ */
+type ollamaPullProgress struct {
+	Status    string `json:"status"`
+	Completed int64  `json:"completed"`
+	Total     int64  `json:"total"`
+	Error     string `json:"error"`
+}
+
+/**
+ * ListModels 列出本地已下载的模型
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *
+ * Returns: []string - 模型名称（含标签，如 llama3.2:latest）
+ */
+func (c *OllamaClient) ListModels(ctx context.Context) ([]string, error) {
+	resp, err := c.do(ctx, http.MethodGet, "/api/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var tags struct {
+		Models []struct {
+			Name string `json:"name"`
+		} `json:"models"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tags); err != nil {
+		return nil, fmt.Errorf("解析 Ollama 模型列表失败: %w", err)
+	}
+
+	names := make([]string, 0, len(tags.Models))
+	for _, model := range tags.Models {
+		names = append(names, model.Name)
+	}
+	return names, nil
+}
+
+/**
+ * CheckAvailability 检查 Ollama 服务是否可用且模型已下载
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *
+ * Returns: error - 服务无法连接时返回连接错误，模型未下载时返回 ErrOllamaModelNotFound
+ */
+func (c *OllamaClient) CheckAvailability(ctx context.Context) error {
+	models, err := c.ListModels(ctx)
+	if err != nil {
+		return err
+	}
+
+	want := normalizeOllamaModel(c.config.Model)
+	for _, name := range models {
+		if normalizeOllamaModel(name) == want {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s（可运行 ollama pull %s）", ErrOllamaModelNotFound, c.config.Model, c.config.Model)
+}
+
+/**
+ * PullModel 下载模型
+ *
+ * Parameters:
+ *   - ctx: 上下文，取消时中止下载
+ *   - progress: 进度回调（可为 nil），参数为状态、已完成字节数和总字节数
+ *
+ * Returns: error - 下载失败时返回错误
+ */
+func (c *OllamaClient) PullModel(ctx context.Context, progress func(status string, completed, total int64)) error {
+	resp, err := c.do(ctx, http.MethodPost, "/api/pull", map[string]interface{}{
+		"model":  c.config.Model,
+		"stream": true,
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	logger.Info("开始拉取 Ollama 模型", zap.String("model", c.config.Model))
+
+	scanner := bufio.NewScanner(resp.Body)
+	var last string
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		var p ollamaPullProgress
+		if err := json.Unmarshal(line, &p); err != nil {
+			return fmt.Errorf("解析 Ollama 拉取进度失败: %w", err)
+		}
+		if p.Error != "" {
+			return fmt.Errorf("拉取 Ollama 模型失败: %s", p.Error)
+		}
+		last = p.Status
+		if progress != nil {
+			progress(p.Status, p.Completed, p.Total)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return fmt.Errorf("读取 Ollama 拉取进度失败: %w", err)
+	}
+	if last != "success" {
+		return fmt.Errorf("拉取 Ollama 模型未完成: %s", last)
+	}
+
+	logger.Info("Ollama 模型拉取完成", zap.String("model", c.config.Model))
+	return nil
+}
+
+/**
+ * EnsureModel 确保模型可用
+ *
+ * 检查结果会被缓存，之后的调用直接返回。模型未下载且开启 AutoPull 时在后台拉取，
+ * 拉取使用独立的上下文，不受调用方超时影响，同一时间只拉取一次；拉取完成前返回
+ * ErrOllamaModelPulling，调用方（如降级链）可以先使用其他提供商
+ *
+ * Parameters:
+ *   - ctx: 上下文，只用于检查模型
+ *
+ * Returns: error - 服务不可用、模型未下载或正在拉取时返回错误
+ */
+func (c *OllamaClient) EnsureModel(ctx context.Context) error {
+	c.mu.Lock()
+	ready, pulling := c.ready, c.pulling
+	c.mu.Unlock()
+	if ready {
+		return nil
+	}
+	if pulling {
+		return fmt.Errorf("%w: %s", ErrOllamaModelPulling, c.config.Model)
+	}
+
+	err := c.CheckAvailability(ctx)
+	if errors.Is(err, ErrOllamaModelNotFound) && c.config.AutoPull {
+		c.startPull()
+		return fmt.Errorf("%w: %s，已开始在后台拉取", ErrOllamaModelPulling, c.config.Model)
+	}
+	if err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	c.ready = true
+	c.mu.Unlock()
+	return nil
+}
+
+/**
+ * startPull 在后台拉取模型，已有拉取在进行时不重复拉取
+ */
+func (c *OllamaClient) startPull() {
+	c.mu.Lock()
+	if c.pulling {
+		c.mu.Unlock()
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	c.pulling = true
+	c.cancelPull = cancel
+	c.mu.Unlock()
+
+	go func() {
+		defer cancel()
+
+		err := c.PullModel(ctx, nil)
+		if err != nil {
+			logger.Error("后台拉取 Ollama 模型失败",
+				zap.String("model", c.config.Model),
+				zap.Error(err))
+		}
+
+		c.mu.Lock()
+		c.pulling = false
+		c.cancelPull = nil
+		if err == nil {
+			c.ready = true
+		}
+		c.mu.Unlock()
+	}()
+}
+
+/**
+ * AnalyzePattern 分析模式（Ollama 实现）
+ *
+ * Parameters:
+ *   - ctx: 上下文，取消时立即中止生成
+ *   - patternData: 模式数据（JSON格式）
+ *
+ * Returns: *PatternAnalysis - 分析结果
+ */
+func (c *OllamaClient) AnalyzePattern(ctx context.Context, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	if err := c.EnsureModel(ctx); err != nil {
+		return nil, fmt.Errorf("Ollama 模型不可用: %w", err)
+	}
+
+	prompt := RenderPatternAnalysisPrompt(patternData)
+
+	// 响应不符合格式时带着错误重新请求
+	analysis, err := AnalyzeWithRepair(patternUsageContext(ctx, patternData), c.generate, prompt, c.config.MaxRepairs)
+	if err != nil {
+		var outputErr *OutputError
+		if errors.As(err, &outputErr) {
+			logger.Error("解析 Ollama 响应失败",
+				zap.String("response", outputErr.Content),
+				zap.Error(err))
+			return nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+		return nil, err
+	}
+
+	return analysis, nil
+}
+
+/**
+ * generate 调用一次 Ollama 对话接口
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - messages: 对话消息
+ *
+ * Returns: string - 响应内容
+ */
+func (c *OllamaClient) generate(ctx context.Context, messages []*schema.Message) (string, error) {
+	options := map[string]interface{}{"num_predict": c.config.MaxTokens}
+	if c.config.Temperature != nil {
+		options["temperature"] = *c.config.Temperature
+	}
+	req := ollamaChatRequest{
+		Model:   c.config.Model,
+		Options: options,
+	}
+	for _, message := range messages {
+		req.Messages = append(req.Messages, ollamaMessage{Role: string(message.Role), Content: message.Content})
+	}
+	if c.config.jsonMode() {
+		req.Format = "json"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	response, err := c.chat(ctx, &req)
+	duration := time.Since(startTime)
+
+	if err != nil {
+		if errors.Is(err, ErrOllamaModelNotFound) {
+			// 模型在检查之后被删除，下次调用时重新检查
+			c.mu.Lock()
+			c.ready = false
+			c.mu.Unlock()
+		}
+		metrics.ObserveAICall("ollama", duration, 0, 0, err)
+		logger.Error("调用 Ollama 失败",
+			zap.Error(err),
+			zap.Duration("duration", duration))
+		return "", fmt.Errorf("调用 Ollama 失败: %w", err)
+	}
+
+	logger.Info("调用 Ollama 成功",
+		zap.Duration("duration", duration),
+		zap.String("doneReason", response.DoneReason),
+		zap.Int("promptTokens", response.PromptEvalCount),
+		zap.Int("completionTokens", response.EvalCount))
+	metrics.ObserveAICall("ollama", duration, response.PromptEvalCount, response.EvalCount, nil)
+	reportUsage(ctx, c.config.UsageRecorder, "ollama", c.config.Model, response.PromptEvalCount, response.EvalCount, duration)
+
+	if response.DoneReason == "length" {
+		return "", fmt.Errorf("响应超出 max_tokens 被截断")
+	}
+
+	return response.Message.Content, nil
+}
+
+/**
+ * AnalyzePatternBatch 批量分析模式
+ *
+ * 本地模型一次只处理一个请求，按顺序分析；上下文取消后剩余的模式不再分析
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - patterns: 模式列表
+ *
+ * Returns: []*PatternAnalysis - 分析结果列表
+ */
+func (c *OllamaClient) AnalyzePatternBatch(ctx context.Context, patterns []map[string]interface{}) ([]*PatternAnalysis, error) {
+	results := make([]*PatternAnalysis, len(patterns))
+
+	for i, pattern := range patterns {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		analysis, err := c.AnalyzePattern(ctx, pattern)
+		if err != nil {
+			logger.Error("分析模式失败",
+				zap.Int("index", i),
+				zap.Error(err))
+			// 继续处理其他模式，不中断整个批次
+			results[i] = &PatternAnalysis{
+				ShouldAutomate: false,
+				Reason:         fmt.Sprintf("分析失败: %v", err),
+				Complexity:     "unknown",
+			}
+		} else {
+			results[i] = analysis
+		}
+	}
+
+	return results, nil
+}
+
+/**
+ * GetType 获取模型类型
+ */
+func (c *OllamaClient) GetType() ModelType {
+	return ModelTypeOllama
+}
+
+/**
+ * Close 关闭连接，取消正在进行的后台拉取
+ *
+ * Returns: error - 关闭错误
+ */
+func (c *OllamaClient) Close() error {
+	c.mu.Lock()
+	if c.cancelPull != nil {
+		c.cancelPull()
+	}
+	c.mu.Unlock()
+
+	c.httpClient.CloseIdleConnections()
+	logger.Info("Ollama 客户端已关闭")
+	return nil
+}
+
+/**
+ * chat 调用 /api/chat（非流式）
+ */
+func (c *OllamaClient) chat(ctx context.Context, req *ollamaChatRequest) (*ollamaChatResponse, error) {
+	resp, err := c.do(ctx, http.MethodPost, "/api/chat", req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var response ollamaChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("解析 Ollama 响应失败: %w", err)
+	}
+	return &response, nil
+}
+
+/**
+ * do 发送请求，非 2xx 响应转换为错误
+ */
+func (c *OllamaClient) do(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("序列化 Ollama 请求失败: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.config.BaseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("创建 Ollama 请求失败: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, fmt.Errorf("连接 Ollama 失败（请确认 ollama serve 已运行）: %w", err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		var apiErr struct {
+			Error string `json:"error"`
+		}
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		json.Unmarshal(data, &apiErr)
+		if resp.StatusCode == http.StatusNotFound && apiErr.Error != "" {
+			return nil, fmt.Errorf("%w: %s", ErrOllamaModelNotFound, apiErr.Error)
+		}
+		return nil, &HTTPStatusError{
+			Provider:   "Ollama",
+			StatusCode: resp.StatusCode,
+			Message:    apiErr.Error,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After")),
+		}
+	}
+	return resp, nil
+}
+
+/**
+ * normalizeOllamaModel 补全默认标签，llama3.2 与 llama3.2:latest 视为同一模型
+ */
+func normalizeOllamaModel(name string) string {
+	if !strings.Contains(name, ":") {
+		return name + ":latest"
+	}
+	return name
+}
+
+/**
+ * isLocalURL 判断地址是否指向本机
+ */
+func isLocalURL(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := parsed.Hostname()
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -0,0 +1,347 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * Ollama 客户端单元测试
+ */
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOllamaServer 模拟 Ollama HTTP API
+type fakeOllamaServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	models   []string
+	chats    []ollamaChatRequest
+	pulls    int
+	content  string
+	blocking bool
+
+	// pullGate 不为 nil 时拉取在通道关闭后才完成
+	pullGate chan struct{}
+}
+
+// newFakeOllamaServer 创建模拟服务
+func newFakeOllamaServer(t *testing.T, models ...string) *fakeOllamaServer {
+	t.Helper()
+	f := &fakeOllamaServer{models: models, content: analysisJSON}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/tags", func(w http.ResponseWriter, r *http.Request) {
+		f.mu.Lock()
+		defer f.mu.Unlock()
+		list := make([]map[string]string, 0, len(f.models))
+		for _, name := range f.models {
+			list = append(list, map[string]string{"name": name, "model": name})
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"models": list})
+	})
+	mux.HandleFunc("POST /api/pull", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Model string `json:"model"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		if req.Model == "missing-model" {
+			fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+			fmt.Fprintln(w, `{"error":"pull model manifest: file does not exist"}`)
+			return
+		}
+		fmt.Fprintln(w, `{"status":"pulling manifest"}`)
+		f.mu.Lock()
+		gate := f.pullGate
+		f.mu.Unlock()
+		if gate != nil {
+			select {
+			case <-gate:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		fmt.Fprintln(w, `{"status":"pulling 6a0746a1ec1a","digest":"sha256:6a07","total":2019377376,"completed":1009688688}`)
+		fmt.Fprintln(w, `{"status":"pulling 6a0746a1ec1a","digest":"sha256:6a07","total":2019377376,"completed":2019377376}`)
+		fmt.Fprintln(w, `{"status":"success"}`)
+
+		f.mu.Lock()
+		f.pulls++
+		f.models = append(f.models, req.Model+":latest")
+		f.mu.Unlock()
+	})
+	mux.HandleFunc("POST /api/chat", func(w http.ResponseWriter, r *http.Request) {
+		var req ollamaChatRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+
+		f.mu.Lock()
+		f.chats = append(f.chats, req)
+		content, blocking := f.content, f.blocking
+		found := false
+		for _, name := range f.models {
+			if normalizeOllamaModel(name) == normalizeOllamaModel(req.Model) {
+				found = true
+			}
+		}
+		f.mu.Unlock()
+
+		if blocking {
+			<-r.Context().Done()
+			return
+		}
+		if !found {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprintf(w, `{"error":"model '%s' not found"}`, req.Model)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"model":             req.Model,
+			"message":           map[string]string{"role": "assistant", "content": content},
+			"done":              true,
+			"done_reason":       "stop",
+			"prompt_eval_count": 200,
+			"eval_count":        60,
+		})
+	})
+
+	f.Server = httptest.NewServer(mux)
+	t.Cleanup(f.Close)
+	return f
+}
+
+// newTestOllamaClient 创建连接模拟服务的客户端
+func newTestOllamaClient(t *testing.T, server *fakeOllamaServer, model string, autoPull bool) *OllamaClient {
+	t.Helper()
+	client, err := NewOllamaClient(&OllamaConfig{
+		BaseURL:  server.URL + "/",
+		Model:    model,
+		AutoPull: autoPull,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+// TestOllamaConfig_Validate 测试默认值和 OLLAMA_HOST
+func TestOllamaConfig_Validate(t *testing.T) {
+	t.Setenv("OLLAMA_HOST", "")
+	t.Setenv("OLLAMA_MODEL", "")
+
+	config := &OllamaConfig{}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, DefaultOllamaBaseURL, config.BaseURL)
+	assert.Equal(t, "llama3.2", config.Model)
+	assert.Equal(t, 2*time.Minute, config.Timeout)
+	assert.True(t, config.jsonMode())
+
+	t.Setenv("OLLAMA_HOST", "127.0.0.1:11500")
+	config = &OllamaConfig{}
+	require.NoError(t, config.Validate())
+	assert.Equal(t, "http://127.0.0.1:11500", config.BaseURL)
+
+	temperature := float32(3)
+	assert.Error(t, (&OllamaConfig{Temperature: &temperature}).Validate())
+
+	assert.True(t, isLocalURL("http://localhost:11434"))
+	assert.True(t, isLocalURL("http://[::1]:11434"))
+	assert.False(t, isLocalURL("http://192.168.1.10:11434"))
+}
+
+// TestOllamaClient_CheckAvailability 测试服务和模型可用性检查
+func TestOllamaClient_CheckAvailability(t *testing.T) {
+	server := newFakeOllamaServer(t, "llama3.2:latest", "qwen2.5:7b")
+	ctx := context.Background()
+
+	models, err := newTestOllamaClient(t, server, "llama3.2", false).ListModels(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"llama3.2:latest", "qwen2.5:7b"}, models)
+
+	assert.NoError(t, newTestOllamaClient(t, server, "llama3.2", false).CheckAvailability(ctx))
+	assert.NoError(t, newTestOllamaClient(t, server, "qwen2.5:7b", false).CheckAvailability(ctx))
+
+	err = newTestOllamaClient(t, server, "qwen2.5", false).CheckAvailability(ctx)
+	assert.ErrorIs(t, err, ErrOllamaModelNotFound)
+
+	// 服务未启动
+	client, err := NewOllamaClient(&OllamaConfig{BaseURL: "http://127.0.0.1:1", Model: "llama3.2"})
+	require.NoError(t, err)
+	err = client.CheckAvailability(ctx)
+	assert.ErrorContains(t, err, "连接 Ollama 失败")
+	assert.False(t, errors.Is(err, ErrOllamaModelNotFound))
+}
+
+// TestOllamaClient_PullModel 测试拉取模型和进度回调
+func TestOllamaClient_PullModel(t *testing.T) {
+	server := newFakeOllamaServer(t)
+	client := newTestOllamaClient(t, server, "llama3.2", false)
+
+	var statuses []string
+	var lastCompleted int64
+	require.NoError(t, client.PullModel(context.Background(), func(status string, completed, total int64) {
+		statuses = append(statuses, status)
+		if total > 0 {
+			lastCompleted = completed
+		}
+	}))
+	assert.Equal(t, "success", statuses[len(statuses)-1])
+	assert.EqualValues(t, 2019377376, lastCompleted)
+	assert.NoError(t, client.CheckAvailability(context.Background()))
+
+	err := newTestOllamaClient(t, server, "missing-model", false).PullModel(context.Background(), nil)
+	assert.ErrorContains(t, err, "file does not exist")
+}
+
+// TestOllamaClient_AnalyzePattern 测试使用 format: json 分析模式
+func TestOllamaClient_AnalyzePattern(t *testing.T) {
+	server := newFakeOllamaServer(t, "llama3.2:latest")
+	temperature := float32(0.2)
+	client, err := NewOllamaClient(&OllamaConfig{
+		BaseURL:     server.URL,
+		Model:       "llama3.2",
+		MaxTokens:   512,
+		Temperature: &temperature,
+	})
+	require.NoError(t, err)
+
+	analysis, err := client.AnalyzePattern(context.Background(), map[string]interface{}{"pattern_id": "pattern-1"})
+	require.NoError(t, err)
+	assert.True(t, analysis.ShouldAutomate)
+	assert.Equal(t, "复制构建日志", analysis.SuggestedName)
+	assert.Equal(t, ModelTypeOllama, client.GetType())
+
+	require.Len(t, server.chats, 1)
+	req := server.chats[0]
+	assert.Equal(t, "llama3.2", req.Model)
+	assert.Equal(t, "json", req.Format)
+	assert.False(t, req.Stream)
+	assert.EqualValues(t, 512, req.Options["num_predict"])
+	assert.InDelta(t, 0.2, req.Options["temperature"], 0.001)
+	require.Len(t, req.Messages, 2)
+	assert.Equal(t, "system", req.Messages[0].Role)
+
+	// 关闭 JSON 模式时解析代码块包裹的响应
+	server.mu.Lock()
+	server.content = "```json\n" + analysisJSON + "\n```"
+	server.mu.Unlock()
+	disabled := false
+	client, err = NewOllamaClient(&OllamaConfig{BaseURL: server.URL, Model: "llama3.2", JSONMode: &disabled})
+	require.NoError(t, err)
+	analysis, err = client.AnalyzePattern(context.Background(), map[string]interface{}{"pattern_id": "pattern-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "复制构建日志", analysis.SuggestedName)
+	assert.Empty(t, server.chats[1].Format)
+}
+
+// TestOllamaClient_ModelMissing 测试模型未下载时的提示和自动拉取
+func TestOllamaClient_ModelMissing(t *testing.T) {
+	server := newFakeOllamaServer(t)
+	patternData := map[string]interface{}{"pattern_id": "pattern-1"}
+
+	_, err := newTestOllamaClient(t, server, "llama3.2", false).AnalyzePattern(context.Background(), patternData)
+	assert.ErrorIs(t, err, ErrOllamaModelNotFound)
+	assert.ErrorContains(t, err, "ollama pull llama3.2")
+	assert.Empty(t, server.chats)
+
+	// 开启自动拉取时在后台拉取，完成前返回 ErrOllamaModelPulling
+	client := newTestOllamaClient(t, server, "llama3.2", true)
+	_, err = client.AnalyzePattern(context.Background(), patternData)
+	assert.ErrorIs(t, err, ErrOllamaModelPulling)
+	require.Eventually(t, func() bool { return client.EnsureModel(context.Background()) == nil },
+		time.Second, 10*time.Millisecond)
+	_, err = client.AnalyzePattern(context.Background(), patternData)
+	require.NoError(t, err)
+	_, err = client.AnalyzePattern(context.Background(), patternData)
+	require.NoError(t, err)
+	assert.Equal(t, 1, server.pulls)
+	assert.Len(t, server.chats, 2)
+
+	// 模型在检查之后被删除
+	server.mu.Lock()
+	server.models = nil
+	server.mu.Unlock()
+	_, err = client.AnalyzePattern(context.Background(), patternData)
+	assert.ErrorIs(t, err, ErrOllamaModelNotFound)
+	assert.False(t, client.ready)
+}
+
+// TestOllamaClient_BackgroundPull 测试拉取只进行一次，不受调用方上下文影响，也不阻塞其他调用
+func TestOllamaClient_BackgroundPull(t *testing.T) {
+	server := newFakeOllamaServer(t)
+	gate := make(chan struct{})
+	server.pullGate = gate
+	client := newTestOllamaClient(t, server, "llama3.2", true)
+
+	// 调用方的上下文很快取消，拉取仍在后台继续
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	assert.ErrorIs(t, client.EnsureModel(ctx), ErrOllamaModelPulling)
+	cancel()
+
+	// 拉取期间的调用立即返回，不重复拉取
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			assert.ErrorIs(t, client.EnsureModel(context.Background()), ErrOllamaModelPulling)
+			assert.Less(t, time.Since(start), time.Second)
+		}()
+	}
+	wg.Wait()
+
+	close(gate)
+	require.Eventually(t, func() bool { return client.EnsureModel(context.Background()) == nil },
+		time.Second, 10*time.Millisecond)
+	assert.Equal(t, 1, server.pulls)
+}
+
+// TestOllamaClient_ContextCancel 测试取消上下文时立即中止生成
+func TestOllamaClient_ContextCancel(t *testing.T) {
+	server := newFakeOllamaServer(t, "llama3.2:latest")
+	client := newTestOllamaClient(t, server, "llama3.2", false)
+	require.NoError(t, client.EnsureModel(context.Background()))
+
+	server.mu.Lock()
+	server.blocking = true
+	server.mu.Unlock()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+
+	start := time.Now()
+	_, err := client.AnalyzePattern(ctx, map[string]interface{}{"pattern_id": "pattern-1"})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, time.Since(start), 2*time.Second)
+
+	// 已取消的上下文不再分析剩余的模式
+	_, err = client.AnalyzePatternBatch(ctx, []map[string]interface{}{{"pattern_id": "pattern-1"}})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.NoError(t, client.Close())
+}
+
+// TestNewAIModel_Ollama 测试工厂方法创建 Ollama 客户端
+func TestNewAIModel_Ollama(t *testing.T) {
+	t.Setenv("AI_API_KEY", "")
+	t.Setenv("AI_MODEL", "")
+	server := newFakeOllamaServer(t, "qwen2.5:latest")
+	t.Setenv("AI_BASE_URL", server.URL)
+
+	aiModel, err := NewAIModel(&AIConfig{Provider: "ollama", Model: "qwen2.5"})
+	require.NoError(t, err)
+	assert.Equal(t, ModelTypeOllama, aiModel.GetType())
+
+	results, err := aiModel.AnalyzePatternBatch(context.Background(), []map[string]interface{}{{"pattern_id": "pattern-1"}})
+	require.NoError(t, err)
+	require.Len(t, results, 1)
+	assert.True(t, results[0].ShouldAutomate)
+}
@@ -0,0 +1,348 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * OpenAI 兼容接口客户端实现
+ */
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/metrics"
+	"github.com/cloudwego/eino-ext/components/model/openai"
+	"github.com/cloudwego/eino/components/model"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+// 确保 OpenAIClient 实现了 AIModel 接口
+var _ AIModel = (*OpenAIClient)(nil)
+
+/**
+ * DefaultOpenAIBaseURL OpenAI 官方 API 地址
+ */
+const DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+/**
+ * OpenAIClient OpenAI 兼容接口客户端
+ *
+ * 除 OpenAI 官方 API 外，还可以通过 BaseURL 连接任何 OpenAI 兼容的服务，
+ * 如 vLLM、LM Studio、LocalAI
+ */
+type OpenAIClient struct {
+	// chatModel Eino ChatModel 实例
+	chatModel model.ChatModel
+
+	// config OpenAI 配置
+	config *OpenAIConfig
+}
+
+/**
+ * OpenAIConfig OpenAI 配置
+ */
+type OpenAIConfig struct {
+	// APIKey API 密钥，自定义 BaseURL 的本地服务可以为空
+	APIKey string
+
+	// Model 模型名称（gpt-4o, gpt-4o-mini 或兼容服务加载的模型名）
+	Model string
+
+	// BaseURL API 基础 URL（可选，默认 DefaultOpenAIBaseURL）
+	BaseURL *string
+
+	// MaxTokens 最大生成 token 数
+	MaxTokens int
+
+	// Temperature 温度参数（0.0-2.0）
+	Temperature *float32
+
+	// Timeout 请求超时时间
+	Timeout time.Duration
+
+	// JSONMode 是否要求以 JSON 对象格式响应（response_format: json_object），为 nil 时开启
+	//
+	// 部分兼容服务不支持该参数，可以关闭后依赖提示词约束输出格式
+	JSONMode *bool
+
+	// MaxRepairs 响应不符合输出格式时的最大重新请求次数（0 为默认值 2，负数不重试）
+	MaxRepairs int
+
+	// UsageRecorder 每次收到响应后的用量回调（可选），为 nil 时不上报
+	UsageRecorder UsageRecorder
+}
+
+/**
+ * GetType 获取模型类型
+ */
+func (c *OpenAIConfig) GetType() ModelType {
+	return ModelTypeOpenAI
+}
+
+/**
+ * Validate 验证配置
+ */
+func (c *OpenAIConfig) Validate() error {
+	if c.APIKey == "" {
+		c.APIKey = GetEnvOrDefault("OPENAI_API_KEY", "")
+		// 官方 API 必须提供密钥，自定义端点的本地服务通常不需要
+		if c.APIKey == "" && !c.customBaseURL() {
+			return fmt.Errorf("未找到 OPENAI_API_KEY 环境变量")
+		}
+	}
+
+	if c.Model == "" {
+		c.Model = GetEnvOrDefault("OPENAI_MODEL", "gpt-4o")
+	}
+
+	if c.MaxTokens <= 0 {
+		c.MaxTokens = 4096
+	}
+
+	if c.Temperature != nil && (*c.Temperature < 0 || *c.Temperature > 2) {
+		return fmt.Errorf("temperature 必须在 0.0-2.0 之间")
+	}
+
+	if c.Timeout <= 0 {
+		c.Timeout = 60 * time.Second
+	}
+
+	if c.MaxRepairs == 0 {
+		c.MaxRepairs = DefaultMaxRepairs
+	}
+
+	return nil
+}
+
+/**
+ * customBaseURL 是否配置了自定义端点
+ */
+func (c *OpenAIConfig) customBaseURL() bool {
+	return c.BaseURL != nil && *c.BaseURL != "" && *c.BaseURL != DefaultOpenAIBaseURL
+}
+
+/**
+ * jsonMode 是否开启 JSON 模式
+ */
+func (c *OpenAIConfig) jsonMode() bool {
+	return c.JSONMode == nil || *c.JSONMode
+}
+
+/**
+ * NewOpenAIClient 创建 OpenAI 兼容接口客户端
+ *
+ * Parameters:
+ *   - config: OpenAI 配置
+ *
+ * Returns: *OpenAIClient - OpenAI 客户端实例
+ */
+func NewOpenAIClient(config *OpenAIConfig) (*OpenAIClient, error) {
+	if config == nil {
+		return nil, fmt.Errorf("配置不能为空")
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, fmt.Errorf("配置验证失败: %w", err)
+	}
+
+	baseURL := DefaultOpenAIBaseURL
+	if config.BaseURL != nil && *config.BaseURL != "" {
+		baseURL = strings.TrimRight(*config.BaseURL, "/")
+	}
+
+	maxTokens := config.MaxTokens
+	chatConfig := &openai.ChatModelConfig{
+		BaseURL:     baseURL,
+		APIKey:      config.APIKey,
+		Model:       config.Model,
+		MaxTokens:   &maxTokens,
+		Temperature: config.Temperature,
+		Timeout:     config.Timeout,
+	}
+	if config.jsonMode() {
+		chatConfig.ResponseFormat = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	chatModel, err := openai.NewChatModel(ctx, chatConfig)
+	if err != nil {
+		return nil, fmt.Errorf("创建 OpenAI ChatModel 失败: %w", err)
+	}
+
+	logger.Info("创建 OpenAI 客户端成功",
+		zap.String("baseURL", baseURL),
+		zap.String("model", config.Model),
+		zap.Int("maxTokens", config.MaxTokens),
+		zap.Bool("jsonMode", config.jsonMode()))
+
+	return &OpenAIClient{
+		chatModel: chatModel,
+		config:    config,
+	}, nil
+}
+
+/**
+ * NewOpenAIClientFromConfig 从通用配置创建 OpenAI 客户端
+ */
+func NewOpenAIClientFromConfig(config *AIConfig) (AIModel, error) {
+	openaiConfig := &OpenAIConfig{
+		APIKey:        config.APIKey,
+		Model:         config.Model,
+		BaseURL:       config.BaseURL,
+		MaxTokens:     config.MaxTokens,
+		Temperature:   config.Temperature,
+		JSONMode:      config.JSONMode,
+		MaxRepairs:    config.MaxRepairs,
+		UsageRecorder: config.UsageRecorder,
+	}
+
+	// 设置超时
+	if config.Timeout > 0 {
+		openaiConfig.Timeout = time.Duration(config.Timeout) * time.Second
+	}
+
+	return NewOpenAIClient(openaiConfig)
+}
+
+/**
+ * AnalyzePattern 分析模式（OpenAI 实现）
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - patternData: 模式数据（JSON格式）
+ *
+ * Returns: *PatternAnalysis - 分析结果
+ */
+func (c *OpenAIClient) AnalyzePattern(ctx context.Context, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	if c.chatModel == nil {
+		return nil, fmt.Errorf("OpenAI 客户端未正确初始化：chatModel为空")
+	}
+
+	prompt := RenderPatternAnalysisPrompt(patternData)
+
+	// 响应不符合格式时带着错误重新请求
+	analysis, err := AnalyzeWithRepair(patternUsageContext(ctx, patternData), c.generate, prompt, c.config.MaxRepairs)
+	if err != nil {
+		var outputErr *OutputError
+		if errors.As(err, &outputErr) {
+			logger.Error("解析 OpenAI 响应失败",
+				zap.String("response", outputErr.Content),
+				zap.Error(err))
+			return nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+		return nil, err
+	}
+
+	return analysis, nil
+}
+
+/**
+ * generate 调用一次 OpenAI 兼容接口
+ *
+ * JSON 模式要求消息中出现 "JSON" 字样，内置模板的系统提示词已包含
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - messages: 对话消息
+ *
+ * Returns: string - 响应内容
+ */
+func (c *OpenAIClient) generate(ctx context.Context, messages []*schema.Message) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	response, err := c.chatModel.Generate(ctx, messages)
+	duration := time.Since(startTime)
+
+	if err != nil {
+		metrics.ObserveAICall("openai", duration, 0, 0, err)
+		logger.Error("调用 OpenAI API 失败",
+			zap.Error(err),
+			zap.Duration("duration", duration))
+		return "", fmt.Errorf("调用 OpenAI API 失败: %w", err)
+	}
+
+	var promptTokens, completionTokens int
+	var finishReason string
+	if response.ResponseMeta != nil {
+		finishReason = response.ResponseMeta.FinishReason
+		if response.ResponseMeta.Usage != nil {
+			promptTokens = response.ResponseMeta.Usage.PromptTokens
+			completionTokens = response.ResponseMeta.Usage.CompletionTokens
+		}
+	}
+
+	logger.Info("调用 OpenAI API 成功",
+		zap.Duration("duration", duration),
+		zap.String("finishReason", finishReason),
+		zap.Int("promptTokens", promptTokens),
+		zap.Int("completionTokens", completionTokens))
+	metrics.ObserveAICall("openai", duration, promptTokens, completionTokens, nil)
+	reportUsage(ctx, c.config.UsageRecorder, "openai", c.config.Model, promptTokens, completionTokens, duration)
+
+	// JSON 模式下输出被截断时得到的是不完整的 JSON，用同样的 max_tokens 重新请求也会被截断
+	if finishReason == "length" {
+		return "", fmt.Errorf("响应超出 max_tokens 被截断")
+	}
+
+	return response.Content, nil
+}
+
+/**
+ * AnalyzePatternBatch 批量分析模式
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - patterns: 模式列表
+ *
+ * Returns: []*PatternAnalysis - 分析结果列表
+ */
+func (c *OpenAIClient) AnalyzePatternBatch(ctx context.Context, patterns []map[string]interface{}) ([]*PatternAnalysis, error) {
+	results := make([]*PatternAnalysis, len(patterns))
+
+	for i, pattern := range patterns {
+		analysis, err := c.AnalyzePattern(ctx, pattern)
+		if err != nil {
+			logger.Error("分析模式失败",
+				zap.Int("index", i),
+				zap.Error(err))
+			// 继续处理其他模式，不中断整个批次
+			results[i] = &PatternAnalysis{
+				ShouldAutomate: false,
+				Reason:         fmt.Sprintf("分析失败: %v", err),
+				Complexity:     "unknown",
+			}
+		} else {
+			results[i] = analysis
+		}
+	}
+
+	return results, nil
+}
+
+/**
+ * GetType 获取模型类型
+ */
+func (c *OpenAIClient) GetType() ModelType {
+	return ModelTypeOpenAI
+}
+
+/**
+ * Close 关闭连接
+ *
+ * Returns: error - 关闭错误
+ */
+func (c *OpenAIClient) Close() error {
+	logger.Info("OpenAI 客户端已关闭")
+	return nil
+}
@@ -0,0 +1,248 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * OpenAI 兼容接口客户端单元测试
+ */
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeOpenAIServer 模拟 OpenAI 兼容服务的 /chat/completions 接口
+type fakeOpenAIServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []map[string]interface{}
+	headers  []http.Header
+
+	// content 返回的消息内容
+	content string
+
+	// replies 依次返回的消息内容，用完后返回 content
+	replies []string
+
+	// finishReason 返回的结束原因
+	finishReason string
+
+	// status 非 0 时返回该错误状态码
+	status int
+}
+
+// newFakeOpenAIServer 创建模拟服务
+func newFakeOpenAIServer(t *testing.T, content string) *fakeOpenAIServer {
+	t.Helper()
+	f := &fakeOpenAIServer{content: content, finishReason: "stop"}
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		f.mu.Lock()
+		f.requests = append(f.requests, body)
+		f.headers = append(f.headers, r.Header.Clone())
+		status, content, finishReason := f.status, f.content, f.finishReason
+		if len(f.replies) > 0 {
+			content, f.replies = f.replies[0], f.replies[1:]
+		}
+		f.mu.Unlock()
+
+		if r.URL.Path != "/v1/chat/completions" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]interface{}{"message": "model overloaded", "type": "server_error"},
+			})
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"id":      "chatcmpl-test",
+			"object":  "chat.completion",
+			"created": 1700000000,
+			"model":   body["model"],
+			"choices": []map[string]interface{}{{
+				"index":         0,
+				"message":       map[string]interface{}{"role": "assistant", "content": content},
+				"finish_reason": finishReason,
+			}},
+			"usage": map[string]interface{}{"prompt_tokens": 120, "completion_tokens": 40, "total_tokens": 160},
+		})
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// lastRequest 获取最后一次请求的请求体和请求头
+func (f *fakeOpenAIServer) lastRequest(t *testing.T) (map[string]interface{}, http.Header) {
+	t.Helper()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	require.NotEmpty(t, f.requests)
+	return f.requests[len(f.requests)-1], f.headers[len(f.headers)-1]
+}
+
+// newTestOpenAIClient 创建连接模拟服务的客户端
+func newTestOpenAIClient(t *testing.T, server *fakeOpenAIServer, apiKey string, jsonMode *bool) *OpenAIClient {
+	t.Helper()
+	baseURL := server.URL + "/v1/"
+	client, err := NewOpenAIClient(&OpenAIConfig{
+		APIKey:   apiKey,
+		Model:    "qwen2.5-7b-instruct",
+		BaseURL:  &baseURL,
+		JSONMode: jsonMode,
+	})
+	require.NoError(t, err)
+	return client
+}
+
+const analysisJSON = `{
+	"should_automate": true,
+	"reason": "每天重复多次",
+	"estimated_time_saving": 45,
+	"complexity": "low",
+	"suggested_name": "复制构建日志",
+	"suggested_steps": ["打开终端", "复制输出"]
+}`
+
+// TestNewOpenAIClient 测试配置校验
+func TestNewOpenAIClient(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("OPENAI_MODEL", "")
+
+	_, err := NewOpenAIClient(nil)
+	assert.Error(t, err)
+
+	// 官方 API 需要密钥
+	_, err = NewOpenAIClient(&OpenAIConfig{})
+	assert.Error(t, err)
+
+	// 自定义端点的本地服务不需要密钥
+	baseURL := "http://localhost:1234/v1"
+	client, err := NewOpenAIClient(&OpenAIConfig{BaseURL: &baseURL})
+	require.NoError(t, err)
+	assert.Equal(t, "gpt-4o", client.config.Model)
+	assert.Equal(t, ModelTypeOpenAI, client.GetType())
+	assert.NoError(t, client.Close())
+
+	temperature := float32(2.5)
+	_, err = NewOpenAIClient(&OpenAIConfig{APIKey: "sk-test", Temperature: &temperature})
+	assert.Error(t, err)
+}
+
+// TestOpenAIClient_AnalyzePattern 测试 JSON 模式下的请求和响应解析
+func TestOpenAIClient_AnalyzePattern(t *testing.T) {
+	server := newFakeOpenAIServer(t, analysisJSON)
+	client := newTestOpenAIClient(t, server, "sk-test", nil)
+
+	analysis, err := client.AnalyzePattern(context.Background(), map[string]interface{}{
+		"pattern_id":    "pattern-1",
+		"support_count": 12,
+	})
+	require.NoError(t, err)
+	assert.True(t, analysis.ShouldAutomate)
+	assert.Equal(t, "复制构建日志", analysis.SuggestedName)
+	assert.EqualValues(t, 45, analysis.EstimatedTimeSaving)
+	assert.Equal(t, []string{"打开终端", "复制输出"}, analysis.SuggestedSteps)
+	assert.False(t, analysis.AnalyzedAt.IsZero())
+	assert.Equal(t, "pattern_analysis.v1.zh", analysis.PromptVersion)
+
+	body, headers := server.lastRequest(t)
+	assert.Equal(t, "Bearer sk-test", headers.Get("Authorization"))
+	assert.Equal(t, "qwen2.5-7b-instruct", body["model"])
+	assert.Equal(t, map[string]interface{}{"type": "json_object"}, body["response_format"])
+	messages := body["messages"].([]interface{})
+	require.Len(t, messages, 2)
+	assert.Contains(t, messages[0].(map[string]interface{})["content"], "JSON")
+}
+
+// TestOpenAIClient_JSONModeDisabled 测试关闭 JSON 模式时解析代码块包裹的响应
+func TestOpenAIClient_JSONModeDisabled(t *testing.T) {
+	server := newFakeOpenAIServer(t, "```json\n"+analysisJSON+"\n```")
+	disabled := false
+	client := newTestOpenAIClient(t, server, "", &disabled)
+
+	analysis, err := client.AnalyzePattern(context.Background(), map[string]interface{}{"pattern_id": "pattern-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "复制构建日志", analysis.SuggestedName)
+
+	body, _ := server.lastRequest(t)
+	assert.NotContains(t, body, "response_format")
+}
+
+// TestOpenAIClient_Errors 测试服务错误、截断和无效响应
+func TestOpenAIClient_Errors(t *testing.T) {
+	server := newFakeOpenAIServer(t, analysisJSON)
+	client := newTestOpenAIClient(t, server, "sk-test", nil)
+	patternData := map[string]interface{}{"pattern_id": "pattern-1"}
+
+	server.mu.Lock()
+	server.status = http.StatusServiceUnavailable
+	server.mu.Unlock()
+	_, err := client.AnalyzePattern(context.Background(), patternData)
+	assert.ErrorContains(t, err, "调用 OpenAI API 失败")
+
+	server.mu.Lock()
+	server.status = 0
+	server.content = `{"should_automate": tr`
+	server.finishReason = "length"
+	server.mu.Unlock()
+	_, err = client.AnalyzePattern(context.Background(), patternData)
+	assert.ErrorContains(t, err, "截断")
+
+	server.mu.Lock()
+	server.content = "我认为应该自动化"
+	server.finishReason = "stop"
+	server.mu.Unlock()
+	_, err = client.AnalyzePattern(context.Background(), patternData)
+	assert.ErrorContains(t, err, "解析响应失败")
+
+	// 批量分析中单个失败不影响整体
+	results, err := client.AnalyzePatternBatch(context.Background(), []map[string]interface{}{patternData, patternData})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.False(t, results[0].ShouldAutomate)
+	assert.Equal(t, "unknown", results[0].Complexity)
+}
+
+// TestNewAIModel_OpenAI 测试工厂方法创建 OpenAI 兼容客户端
+func TestNewAIModel_OpenAI(t *testing.T) {
+	t.Setenv("AI_API_KEY", "")
+	t.Setenv("AI_BASE_URL", "")
+	t.Setenv("AI_MODEL", "")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	server := newFakeOpenAIServer(t, analysisJSON)
+	t.Setenv("OPENAI_BASE_URL", server.URL+"/v1")
+
+	aiModel, err := NewAIModel(&AIConfig{Provider: "openai", Model: "llama-3.1-8b"})
+	require.NoError(t, err)
+	assert.Equal(t, ModelTypeOpenAI, aiModel.GetType())
+
+	analysis, err := aiModel.AnalyzePattern(context.Background(), map[string]interface{}{"pattern_id": "pattern-1"})
+	require.NoError(t, err)
+	assert.True(t, analysis.ShouldAutomate)
+
+	body, _ := server.lastRequest(t)
+	assert.Equal(t, "llama-3.1-8b", body["model"])
+
+	// 官方 API 仍然需要密钥
+	t.Setenv("OPENAI_BASE_URL", "")
+	_, err = NewAIModel(&AIConfig{Provider: "openai"})
+	assert.Error(t, err)
+}
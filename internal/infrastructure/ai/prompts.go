@@ -7,84 +7,491 @@
 package ai
 
 import (
+	"embed"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed templates/*.tmpl
+var builtinTemplates embed.FS
+
+const (
+	// PromptPatternAnalysis 模式分析提示词模板名称
+	PromptPatternAnalysis = "pattern_analysis"
+
+	// DefaultPromptLanguage 默认提示词语言
+	DefaultPromptLanguage = "zh"
+
+	// promptFileExt 模板文件扩展名
+	promptFileExt = ".tmpl"
 )
 
 /**
- * BuildPatternAnalysisPrompt 构建模式分析提示词
+ * PromptTemplate 提示词模板
+ *
+ * 模板文件名为 <名称>.<版本>.<语言>.tmpl，如 pattern_analysis.v2.en.tmpl；
+ * 文件以 YAML 头部开始，声明系统提示词和期望的输出 JSON Schema，之后是 text/template 格式的用户提示词
+ */
+type PromptTemplate struct {
+	// Name 模板名称
+	Name string
+
+	// Version 版本，如 v1
+	Version string
+
+	// Language 语言，如 zh、en
+	Language string
+
+	// Description 模板说明
+	Description string
+
+	// System 系统提示词
+	System string
+
+	// OutputSchema 期望的输出 JSON Schema
+	OutputSchema json.RawMessage
+
+	// Source 模板来源，内置模板为 builtin，其余为文件路径
+	Source string
+
+	body *template.Template
+}
+
+/**
+ * promptFrontMatter 模板文件的 YAML 头部
+ */
+type promptFrontMatter struct {
+	Description  string                 `yaml:"description"`
+	System       string                 `yaml:"system"`
+	OutputSchema map[string]interface{} `yaml:"output_schema"`
+}
+
+/**
+ * PromptData 渲染模板时可用的数据
+ */
+type PromptData struct {
+	// Pattern 模式数据
+	Pattern map[string]interface{}
+
+	// PatternJSON 格式化后的模式数据
+	PatternJSON string
+
+	// OutputSchema 格式化后的输出 JSON Schema
+	OutputSchema string
+}
+
+/**
+ * RenderedPrompt 渲染后的提示词
+ */
+type RenderedPrompt struct {
+	// System 系统提示词
+	System string
+
+	// User 用户提示词
+	User string
+
+	// Version 模板标识，记录到分析结果中
+	Version string
+
+	// Language 语言
+	Language string
+
+	// OutputSchema 期望的输出 JSON Schema
+	OutputSchema json.RawMessage
+}
+
+/**
+ * ID 获取模板标识
+ *
+ * Returns: string - <名称>.<版本>.<语言>，如 pattern_analysis.v1.zh
+ */
+func (t *PromptTemplate) ID() string {
+	return t.Name + "." + t.Version + "." + t.Language
+}
+
+/**
+ * Render 渲染提示词
  *
  * Parameters:
  *   - patternData: 模式数据
  *
- * Returns: string - 提示词
+ * Returns: *RenderedPrompt - 渲染结果
  */
-func BuildPatternAnalysisPrompt(patternData map[string]interface{}) string {
+func (t *PromptTemplate) Render(patternData map[string]interface{}) (*RenderedPrompt, error) {
 	// 将模式数据转换为可读的字符串
 	patternJSON, _ := json.MarshalIndent(patternData, "", "  ")
+	schemaJSON, _ := json.MarshalIndent(json.RawMessage(t.OutputSchema), "", "  ")
+
+	var user strings.Builder
+	data := PromptData{
+		Pattern:      patternData,
+		PatternJSON:  string(patternJSON),
+		OutputSchema: string(schemaJSON),
+	}
+	if err := t.body.Execute(&user, data); err != nil {
+		return nil, fmt.Errorf("渲染提示词模板 %s 失败: %w", t.ID(), err)
+	}
+
+	return &RenderedPrompt{
+		System:       t.System,
+		User:         user.String(),
+		Version:      t.ID(),
+		Language:     t.Language,
+		OutputSchema: t.OutputSchema,
+	}, nil
+}
+
+/**
+ * ParsePromptTemplate 解析模板文件
+ *
+ * Parameters:
+ *   - filename: 文件名，用于确定名称、版本和语言
+ *   - content: 文件内容
+ *
+ * Returns: *PromptTemplate - 模板
+ */
+func ParsePromptTemplate(filename string, content []byte) (*PromptTemplate, error) {
+	stem := strings.TrimSuffix(filepath.Base(filename), promptFileExt)
+	parts := strings.Split(stem, ".")
+	if len(parts) < 3 {
+		return nil, fmt.Errorf("模板文件名应为 <名称>.<版本>.<语言>%s: %s", promptFileExt, filename)
+	}
+	tmpl := &PromptTemplate{
+		Name:     parts[0],
+		Version:  strings.Join(parts[1:len(parts)-1], "."),
+		Language: parts[len(parts)-1],
+		Source:   filename,
+	}
+
+	text := strings.ReplaceAll(string(content), "\r\n", "\n")
+	if !strings.HasPrefix(text, "---\n") {
+		return nil, fmt.Errorf("模板 %s 缺少 YAML 头部", tmpl.ID())
+	}
+	header, body, found := strings.Cut(text[len("---\n"):], "\n---\n")
+	if !found {
+		return nil, fmt.Errorf("模板 %s 的 YAML 头部没有结束", tmpl.ID())
+	}
+
+	var front promptFrontMatter
+	if err := yaml.Unmarshal([]byte(header), &front); err != nil {
+		return nil, fmt.Errorf("解析模板 %s 的头部失败: %w", tmpl.ID(), err)
+	}
+	if front.System == "" {
+		return nil, fmt.Errorf("模板 %s 没有声明 system", tmpl.ID())
+	}
+	if len(front.OutputSchema) == 0 {
+		return nil, fmt.Errorf("模板 %s 没有声明 output_schema", tmpl.ID())
+	}
+	schema, err := json.Marshal(front.OutputSchema)
+	if err != nil {
+		return nil, fmt.Errorf("模板 %s 的 output_schema 无法转换为 JSON: %w", tmpl.ID(), err)
+	}
+	tmpl.Description = front.Description
+	tmpl.System = strings.TrimSpace(front.System)
+	tmpl.OutputSchema = schema
+
+	tmpl.body, err = template.New(tmpl.ID()).Option("missingkey=error").Parse(strings.TrimSpace(body))
+	if err != nil {
+		return nil, fmt.Errorf("解析模板 %s 失败: %w", tmpl.ID(), err)
+	}
+
+	// 用示例数据试渲染，尽早发现引用了不存在字段的模板
+	sample := FormatPatternForAnalysis("sample", nil, 0, 0, 0, "")
+	if _, err := tmpl.Render(sample); err != nil {
+		return nil, err
+	}
+	return tmpl, nil
+}
+
+/**
+ * PromptLibrary 提示词模板库
+ *
+ * 包含内置模板和模板目录中的模板，目录中的同名同版本模板覆盖内置模板
+ */
+type PromptLibrary struct {
+	templates map[string]*PromptTemplate
+}
+
+/**
+ * NewPromptLibrary 加载提示词模板库
+ *
+ * Parameters:
+ *   - dir: 模板目录，为空或不存在时只使用内置模板
+ *
+ * Returns: *PromptLibrary - 模板库
+ */
+func NewPromptLibrary(dir string) (*PromptLibrary, error) {
+	lib := &PromptLibrary{templates: make(map[string]*PromptTemplate)}
 
-	prompt := `请分析以下用户操作模式，判断是否值得自动化。
+	if err := lib.loadFS(builtinTemplates, "templates", "builtin"); err != nil {
+		return nil, fmt.Errorf("加载内置提示词模板失败: %w", err)
+	}
 
-## 模式信息
+	if dir == "" {
+		return lib, nil
+	}
+	if _, err := os.Stat(dir); errors.Is(err, os.ErrNotExist) {
+		logger.Debug("提示词模板目录不存在，使用内置模板", zap.String("dir", dir))
+		return lib, nil
+	}
+	if err := lib.loadFS(os.DirFS(dir), ".", dir); err != nil {
+		return nil, fmt.Errorf("加载提示词模板目录失败: %w", err)
+	}
+	return lib, nil
+}
 
-` + string(patternJSON) + `
+/**
+ * loadFS 加载目录下的所有模板文件
+ */
+func (l *PromptLibrary) loadFS(fsys fs.FS, root, source string) error {
+	entries, err := fs.ReadDir(fsys, root)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || filepath.Ext(entry.Name()) != promptFileExt {
+			continue
+		}
+		content, err := fs.ReadFile(fsys, filepath.ToSlash(filepath.Join(root, entry.Name())))
+		if err != nil {
+			return err
+		}
+		tmpl, err := ParsePromptTemplate(entry.Name(), content)
+		if err != nil {
+			return err
+		}
+		if source != "builtin" {
+			tmpl.Source = filepath.Join(source, entry.Name())
+		} else {
+			tmpl.Source = source
+		}
+		l.templates[tmpl.ID()] = tmpl
+	}
+	return nil
+}
 
-## 分析维度
+/**
+ * Get 获取模板
+ *
+ * Parameters:
+ *   - name: 模板名称
+ *   - language: 语言
+ *   - version: 版本，为空时取最新版本
+ *
+ * Returns: *PromptTemplate - 模板
+ */
+func (l *PromptLibrary) Get(name, language, version string) (*PromptTemplate, error) {
+	if version != "" {
+		tmpl, ok := l.templates[name+"."+version+"."+language]
+		if !ok {
+			return nil, fmt.Errorf("提示词模板 %s.%s.%s 不存在", name, version, language)
+		}
+		return tmpl, nil
+	}
 
-请从以下维度评估该模式：
+	var latest *PromptTemplate
+	for _, tmpl := range l.templates {
+		if tmpl.Name != name || tmpl.Language != language {
+			continue
+		}
+		if latest == nil || compareVersions(tmpl.Version, latest.Version) > 0 {
+			latest = tmpl
+		}
+	}
+	if latest == nil {
+		return nil, fmt.Errorf("没有语言为 %s 的提示词模板 %s", language, name)
+	}
+	return latest, nil
+}
 
-1. **频率**：模式出现的频率（支持计数、每小时出现次数）
-2. **时间节省**：如果自动化，每次可节省的时间（秒）
-3. **复杂度**：实现自动化的技术难度（low/medium/high）
-4. **可行性**：技术实现的可行性
-5. **价值**：对用户体验的提升程度
+/**
+ * List 列出所有模板
+ *
+ * Returns: []*PromptTemplate - 按名称、语言、版本排列
+ */
+func (l *PromptLibrary) List() []*PromptTemplate {
+	templates := make([]*PromptTemplate, 0, len(l.templates))
+	for _, tmpl := range l.templates {
+		templates = append(templates, tmpl)
+	}
+	sort.Slice(templates, func(i, j int) bool {
+		a, b := templates[i], templates[j]
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+		if a.Language != b.Language {
+			return a.Language < b.Language
+		}
+		return compareVersions(a.Version, b.Version) < 0
+	})
+	return templates
+}
 
-## 判断标准
+/**
+ * compareVersions 比较版本号，v2 < v10，无法解析的部分按字符串比较
+ */
+func compareVersions(a, b string) int {
+	as := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	bs := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(as) || i < len(bs); i++ {
+		if i >= len(as) {
+			return -1
+		}
+		if i >= len(bs) {
+			return 1
+		}
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		switch {
+		case aErr == nil && bErr == nil && an != bn:
+			if an < bn {
+				return -1
+			}
+			return 1
+		case (aErr != nil || bErr != nil) && as[i] != bs[i]:
+			return strings.Compare(as[i], bs[i])
+		}
+	}
+	return 0
+}
 
-**值得自动化**的情况：
-- 高频率（每天多次）
-- 明显的时间节省（每次 > 10秒）
-- 技术可行性高
-- 实现复杂度合理（low 或 medium）
+/**
+ * PromptConfig 提示词配置
+ */
+type PromptConfig struct {
+	// TemplatesDir 模板目录，为空时只使用内置模板
+	TemplatesDir string
 
-**不值得自动化**的情况：
-- 低频率（每周少于 1 次）
-- 时间节省不明显（< 5秒）
-- 技术实现困难或复杂度高（high）
-- 用户可能需要灵活调整的操作
+	// Language 语言（zh、en），为空时为 zh
+	Language string
+
+	// Version 版本，为空时使用最新版本
+	Version string
+}
 
-## 输出格式
+var (
+	promptMu sync.RWMutex
 
-请严格按照以下 JSON 格式返回分析结果：
+	// builtinPrompt 内置的默认模板，渲染失败时回退使用
+	builtinPrompt = mustBuiltinPrompt()
 
-{
-  "should_automate": true,
-  "reason": "简明扼要的原因说明（1-2句话）",
-  "estimated_time_saving": 30,
-  "complexity": "low",
-  "suggested_name": "自动化建议名称",
-  "suggested_steps": [
-    "步骤1描述",
-    "步骤2描述",
-    "步骤3描述"
-  ]
+	// activePrompt 当前使用的模式分析模板
+	activePrompt = builtinPrompt
+)
+
+/**
+ * mustBuiltinPrompt 加载内置的默认模式分析模板
+ */
+func mustBuiltinPrompt() *PromptTemplate {
+	lib, err := NewPromptLibrary("")
+	if err != nil {
+		panic(err)
+	}
+	tmpl, err := lib.Get(PromptPatternAnalysis, DefaultPromptLanguage, "")
+	if err != nil {
+		panic(err)
+	}
+	return tmpl
 }
 
-## 字段说明
+/**
+ * ConfigurePrompts 选择模式分析使用的提示词模板
+ *
+ * 所有模型客户端共用选中的模板
+ *
+ * Parameters:
+ *   - config: 提示词配置
+ *
+ * Returns: *PromptTemplate - 选中的模板
+ */
+func ConfigurePrompts(config PromptConfig) (*PromptTemplate, error) {
+	if config.Language == "" {
+		config.Language = DefaultPromptLanguage
+	}
 
-- should_automate: boolean - 是否值得自动化
-- reason: string - 原因说明（中文）
-- estimated_time_saving: number - 预计每次节省的时间（秒）
-- complexity: string - 实现复杂度，必须是 "low"、"medium" 或 "high" 之一
-- suggested_name: string - 建议的自动化名称（简洁明了）
-- suggested_steps: array<string> - 建议的自动化步骤列表
+	lib, err := NewPromptLibrary(config.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := lib.Get(PromptPatternAnalysis, config.Language, config.Version)
+	if err != nil {
+		return nil, err
+	}
+
+	promptMu.Lock()
+	activePrompt = tmpl
+	promptMu.Unlock()
+
+	logger.Info("已选择提示词模板",
+		zap.String("version", tmpl.ID()),
+		zap.String("source", tmpl.Source))
+	return tmpl, nil
+}
+
+/**
+ * ActivePrompt 获取当前使用的模式分析模板
+ *
+ * Returns: *PromptTemplate - 模板
+ */
+func ActivePrompt() *PromptTemplate {
+	promptMu.RLock()
+	defer promptMu.RUnlock()
+	return activePrompt
+}
 
-请基于以上信息，返回 JSON 格式的分析结果：`
+/**
+ * RenderPatternAnalysisPrompt 用当前模板渲染模式分析提示词
+ *
+ * 模板渲染失败时回退到内置模板
+ *
+ * Parameters:
+ *   - patternData: 模式数据
+ *
+ * Returns: *RenderedPrompt - 渲染结果
+ */
+func RenderPatternAnalysisPrompt(patternData map[string]interface{}) *RenderedPrompt {
+	tmpl := ActivePrompt()
+	prompt, err := tmpl.Render(patternData)
+	if err == nil {
+		return prompt
+	}
 
+	logger.Warn("渲染提示词模板失败，使用内置模板",
+		zap.String("version", tmpl.ID()),
+		zap.Error(err))
+	prompt, err = builtinPrompt.Render(patternData)
+	if err != nil {
+		// 内置模板在加载时已试渲染过
+		panic(err)
+	}
 	return prompt
 }
 
+/**
+ * BuildPatternAnalysisPrompt 构建模式分析提示词
+ *
+ * Parameters:
+ *   - patternData: 模式数据
+ *
+ * Returns: string - 用户提示词
+ */
+func BuildPatternAnalysisPrompt(patternData map[string]interface{}) string {
+	return RenderPatternAnalysisPrompt(patternData).User
+}
+
 /**
  * FormatPatternForAnalysis 格式化模式数据用于分析
  *
@@ -7,9 +7,14 @@
 package ai
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 // TestBuildPatternAnalysisPrompt 测试构建模式分析提示词
@@ -160,3 +165,154 @@ func TestStepContextInfo_AllFields(t *testing.T) {
 	assert.Equal(t, "com.microsoft.VSCode", context.BundleID)
 	assert.Equal(t, "cmd+s", context.PatternValue)
 }
+
+// TestPromptLibrary_Builtin 测试内置的中英文模板
+func TestPromptLibrary_Builtin(t *testing.T) {
+	lib, err := NewPromptLibrary("")
+	require.NoError(t, err)
+
+	for _, language := range []string{"zh", "en"} {
+		tmpl, err := lib.Get(PromptPatternAnalysis, language, "")
+		require.NoError(t, err)
+		assert.Equal(t, "v1", tmpl.Version)
+		assert.Equal(t, "builtin", tmpl.Source)
+		assert.Contains(t, tmpl.System, "JSON")
+
+		var schema map[string]interface{}
+		require.NoError(t, json.Unmarshal(tmpl.OutputSchema, &schema))
+		assert.Equal(t, "object", schema["type"])
+		assert.Len(t, schema["required"], 6)
+
+		prompt, err := tmpl.Render(map[string]interface{}{"pattern_id": "p-1"})
+		require.NoError(t, err)
+		assert.Contains(t, prompt.User, `"pattern_id": "p-1"`)
+		assert.Equal(t, "pattern_analysis.v1."+language, prompt.Version)
+	}
+
+	en, _ := lib.Get(PromptPatternAnalysis, "en", "v1")
+	prompt, _ := en.Render(nil)
+	assert.Contains(t, prompt.User, `"enum": [`)
+
+	_, err = lib.Get(PromptPatternAnalysis, "fr", "")
+	assert.Error(t, err)
+	_, err = lib.Get(PromptPatternAnalysis, "zh", "v9")
+	assert.Error(t, err)
+}
+
+// TestPromptLibrary_Dir 测试模板目录覆盖和补充内置模板
+func TestPromptLibrary_Dir(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate := func(name, body string) {
+		content := "---\nsystem: 只返回 JSON\noutput_schema:\n  type: object\n---\n" + body
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0644))
+	}
+	writeTemplate("pattern_analysis.v2.zh.tmpl", "v2 支持度 {{.Pattern.support_count}}")
+	writeTemplate("pattern_analysis.v10.zh.tmpl", "v10 {{.PatternJSON}}")
+	writeTemplate("pattern_analysis.v1.en.tmpl", "custom english")
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("忽略"), 0644))
+
+	lib, err := NewPromptLibrary(dir)
+	require.NoError(t, err)
+
+	// 按版本号而不是字符串取最新版本
+	latest, err := lib.Get(PromptPatternAnalysis, "zh", "")
+	require.NoError(t, err)
+	assert.Equal(t, "v10", latest.Version)
+	assert.Equal(t, filepath.Join(dir, "pattern_analysis.v10.zh.tmpl"), latest.Source)
+
+	v2, err := lib.Get(PromptPatternAnalysis, "zh", "v2")
+	require.NoError(t, err)
+	prompt, err := v2.Render(FormatPatternForAnalysis("p", nil, 7, 0, 0, ""))
+	require.NoError(t, err)
+	assert.Equal(t, "v2 支持度 7", prompt.User)
+
+	// 内置的 v1 仍然可用，同名文件覆盖内置模板
+	v1, err := lib.Get(PromptPatternAnalysis, "zh", "v1")
+	require.NoError(t, err)
+	assert.Equal(t, "builtin", v1.Source)
+	en, err := lib.Get(PromptPatternAnalysis, "en", "")
+	require.NoError(t, err)
+	assert.Equal(t, "只返回 JSON", en.System)
+
+	var ids []string
+	for _, tmpl := range lib.List() {
+		ids = append(ids, tmpl.ID())
+	}
+	assert.Equal(t, []string{
+		"pattern_analysis.v1.en",
+		"pattern_analysis.v1.zh",
+		"pattern_analysis.v2.zh",
+		"pattern_analysis.v10.zh",
+	}, ids)
+
+	// 不存在的目录只使用内置模板
+	lib, err = NewPromptLibrary(filepath.Join(dir, "missing"))
+	require.NoError(t, err)
+	assert.Len(t, lib.List(), 2)
+
+	// 目录中有错误的模板时报错
+	writeTemplate("pattern_analysis.v3.zh.tmpl", "{{.Unknown}}")
+	_, err = NewPromptLibrary(dir)
+	assert.Error(t, err)
+}
+
+// TestParsePromptTemplate_Invalid 测试无效的模板文件
+func TestParsePromptTemplate_Invalid(t *testing.T) {
+	valid := "---\nsystem: s\noutput_schema:\n  type: object\n---\nbody"
+
+	_, err := ParsePromptTemplate("pattern_analysis.zh.tmpl", []byte(valid))
+	assert.ErrorContains(t, err, "文件名")
+
+	_, err = ParsePromptTemplate("p.v1.zh.tmpl", []byte("body"))
+	assert.ErrorContains(t, err, "YAML 头部")
+
+	_, err = ParsePromptTemplate("p.v1.zh.tmpl", []byte("---\nsystem: s\nbody"))
+	assert.ErrorContains(t, err, "没有结束")
+
+	_, err = ParsePromptTemplate("p.v1.zh.tmpl", []byte("---\nsystem: s\n---\nbody"))
+	assert.ErrorContains(t, err, "output_schema")
+
+	_, err = ParsePromptTemplate("p.v1.zh.tmpl", []byte("---\noutput_schema:\n  type: object\n---\nbody"))
+	assert.ErrorContains(t, err, "system")
+
+	_, err = ParsePromptTemplate("p.v1.zh.tmpl", []byte("---\nsystem: s\noutput_schema:\n  type: object\n---\n{{.Pattern.missing}}"))
+	assert.Error(t, err)
+
+	tmpl, err := ParsePromptTemplate("p.v1.2.zh.tmpl", []byte(strings.ReplaceAll(valid, "\n", "\r\n")))
+	require.NoError(t, err)
+	assert.Equal(t, "v1.2", tmpl.Version)
+	assert.Equal(t, "body", strings.TrimSpace(mustRender(t, tmpl).User))
+}
+
+// TestConfigurePrompts 测试切换所有客户端共用的模板
+func TestConfigurePrompts(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := ConfigurePrompts(PromptConfig{})
+		require.NoError(t, err)
+	})
+
+	tmpl, err := ConfigurePrompts(PromptConfig{Language: "en"})
+	require.NoError(t, err)
+	assert.Equal(t, "pattern_analysis.v1.en", tmpl.ID())
+	assert.Equal(t, tmpl, ActivePrompt())
+	assert.Contains(t, BuildPatternAnalysisPrompt(nil), "worth automating")
+	assert.Equal(t, "pattern_analysis.v1.en", RenderPatternAnalysisPrompt(nil).Version)
+
+	// 选择失败时保持当前模板
+	_, err = ConfigurePrompts(PromptConfig{Language: "en", Version: "v9"})
+	assert.Error(t, err)
+	assert.Equal(t, tmpl, ActivePrompt())
+
+	assert.Equal(t, -1, compareVersions("v2", "v10"))
+	assert.Equal(t, 1, compareVersions("v1.1", "v1"))
+	assert.Equal(t, 0, compareVersions("v3", "v3"))
+	assert.Equal(t, -1, compareVersions("v1-alpha", "v1-beta"))
+}
+
+// mustRender 用空数据渲染模板
+func mustRender(t *testing.T, tmpl *PromptTemplate) *RenderedPrompt {
+	t.Helper()
+	prompt, err := tmpl.Render(nil)
+	require.NoError(t, err)
+	return prompt
+}
@@ -0,0 +1,445 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 可录制和回放的确定性模型，用于测试和离线演示
+ */
+
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+// 确保 ReplayModel 实现了 AIModel 接口
+var _ AIModel = (*ReplayModel)(nil)
+
+/**
+ * ErrFixtureNotFound 回放时找不到对应提示词的录制结果
+ */
+var ErrFixtureNotFound = errors.New("未找到录制的 AI 响应")
+
+/**
+ * ReplayMode 回放模型的工作模式
+ */
+type ReplayMode string
+
+const (
+	// ReplayModeReplay 只回放录制结果，找不到时返回 ErrFixtureNotFound，不访问网络
+	ReplayModeReplay ReplayMode = "replay"
+
+	// ReplayModeRecord 调用真实模型并录制结果，已有录制结果时直接回放
+	ReplayModeRecord ReplayMode = "record"
+
+	// ReplayModeScripted 按脚本返回预设结果
+	ReplayModeScripted ReplayMode = "scripted"
+)
+
+/**
+ * ScriptStep 脚本模式下一次调用的结果
+ */
+type ScriptStep struct {
+	// Analysis 返回的分析结果，返回前会复制一份
+	Analysis *PatternAnalysis
+
+	// Err 返回的错误，不为空时忽略 Analysis
+	Err error
+
+	// Latency 返回前的模拟延迟，期间上下文取消会立即返回
+	Latency time.Duration
+}
+
+/**
+ * Fixture 录制的一次请求和响应
+ */
+type Fixture struct {
+	// Key 系统提示词、用户提示词和模板标识的 SHA-256
+	Key string `json:"key"`
+
+	// Provider 录制时使用的模型类型
+	Provider ModelType `json:"provider"`
+
+	// RecordedAt 录制时间
+	RecordedAt time.Time `json:"recorded_at"`
+
+	// PatternData 请求的模式数据
+	PatternData map[string]interface{} `json:"pattern_data"`
+
+	// Version 录制时的模板标识
+	Version string `json:"version"`
+
+	// System 实际发送的系统提示词，便于审阅夹具
+	System string `json:"system"`
+
+	// Prompt 实际发送的提示词，便于审阅夹具
+	Prompt string `json:"prompt"`
+
+	// Response 模型返回的分析结果
+	Response *PatternAnalysis `json:"response"`
+}
+
+/**
+ * ReplayCall 一次调用的记录
+ */
+type ReplayCall struct {
+	// Key 系统提示词、用户提示词和模板标识的 SHA-256
+	Key string
+
+	// PatternData 请求的模式数据
+	PatternData map[string]interface{}
+}
+
+/**
+ * ReplayModel 可录制和回放的确定性模型
+ *
+ * 录制模式把真实模型的请求和响应按提示词哈希保存为夹具文件，回放模式只读取夹具，
+ * 整个分析流程可以在没有网络的环境中测试和演示；脚本模式直接返回预设的结果、延迟或错误
+ */
+type ReplayModel struct {
+	mode     ReplayMode
+	dir      string
+	upstream AIModel
+
+	// script 按顺序返回的脚本，用完后重复最后一步
+	script []ScriptStep
+
+	// scriptFunc 根据模式数据决定结果，优先于 script
+	scriptFunc func(patternData map[string]interface{}) ScriptStep
+
+	mu    sync.Mutex
+	calls []ReplayCall
+	next  int
+}
+
+/**
+ * NewReplayModel 创建只回放录制结果的模型
+ *
+ * Parameters:
+ *   - dir: 夹具目录
+ *
+ * Returns: *ReplayModel - 回放模型
+ */
+func NewReplayModel(dir string) *ReplayModel {
+	return &ReplayModel{mode: ReplayModeReplay, dir: dir}
+}
+
+/**
+ * NewRecordingModel 创建录制模型
+ *
+ * 已有夹具的请求直接回放，没有的调用 upstream 并保存结果
+ *
+ * Parameters:
+ *   - dir: 夹具目录，不存在时自动创建
+ *   - upstream: 真实模型
+ *
+ * Returns: *ReplayModel - 录制模型
+ */
+func NewRecordingModel(dir string, upstream AIModel) *ReplayModel {
+	return &ReplayModel{mode: ReplayModeRecord, dir: dir, upstream: upstream}
+}
+
+/**
+ * NewScriptedModel 创建按顺序返回预设结果的模型
+ *
+ * Parameters:
+ *   - steps: 每次调用的结果，用完后重复最后一步；为空时返回默认的"值得自动化"结果
+ *
+ * Returns: *ReplayModel - 脚本模型
+ */
+func NewScriptedModel(steps ...ScriptStep) *ReplayModel {
+	if len(steps) == 0 {
+		steps = []ScriptStep{{Analysis: &PatternAnalysis{
+			ShouldAutomate:      true,
+			Reason:              "脚本模型的默认结果",
+			EstimatedTimeSaving: 60,
+			Complexity:          "low",
+			SuggestedName:       "脚本自动化",
+			SuggestedSteps:      []string{"步骤1", "步骤2"},
+		}}}
+	}
+	return &ReplayModel{mode: ReplayModeScripted, script: steps}
+}
+
+/**
+ * NewScriptedModelFunc 创建根据模式数据决定结果的模型
+ *
+ * Parameters:
+ *   - fn: 根据模式数据返回本次调用的结果，可能被并发调用
+ *
+ * Returns: *ReplayModel - 脚本模型
+ */
+func NewScriptedModelFunc(fn func(patternData map[string]interface{}) ScriptStep) *ReplayModel {
+	return &ReplayModel{mode: ReplayModeScripted, scriptFunc: fn}
+}
+
+/**
+ * NewReplayModelFromConfig 从通用配置创建回放模型
+ *
+ * 配置了 upstream 时为录制模式，否则只回放
+ *
+ * Parameters:
+ *   - config: 通用配置，使用 ReplayDir
+ *   - upstream: 录制时调用的真实模型，可为 nil
+ *
+ * Returns: AIModel - 回放模型
+ */
+func NewReplayModelFromConfig(config *AIConfig, upstream AIModel) (AIModel, error) {
+	if config.ReplayDir == "" {
+		return nil, fmt.Errorf("回放模型需要配置夹具目录")
+	}
+	if upstream != nil {
+		return NewRecordingModel(config.ReplayDir, upstream), nil
+	}
+	return NewReplayModel(config.ReplayDir), nil
+}
+
+/**
+ * Mode 获取工作模式
+ */
+func (m *ReplayModel) Mode() ReplayMode {
+	return m.mode
+}
+
+/**
+ * AnalyzePattern 分析模式
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - patternData: 模式数据（JSON格式）
+ *
+ * Returns: *PatternAnalysis - 回放、录制或脚本预设的分析结果
+ */
+func (m *ReplayModel) AnalyzePattern(ctx context.Context, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	prompt := RenderPatternAnalysisPrompt(patternData)
+	key := PromptHash(prompt)
+
+	m.mu.Lock()
+	m.calls = append(m.calls, ReplayCall{Key: key, PatternData: patternData})
+	m.mu.Unlock()
+
+	switch m.mode {
+	case ReplayModeScripted:
+		return m.scripted(ctx, patternData)
+	case ReplayModeRecord:
+		if fixture, err := m.load(key); err == nil {
+			return copyAnalysis(fixture.Response), nil
+		} else if !errors.Is(err, ErrFixtureNotFound) {
+			return nil, err
+		}
+		return m.record(ctx, key, prompt, patternData)
+	default:
+		fixture, err := m.load(key)
+		if err != nil {
+			return nil, err
+		}
+		return copyAnalysis(fixture.Response), nil
+	}
+}
+
+/**
+ * scripted 返回脚本中的下一步
+ */
+func (m *ReplayModel) scripted(ctx context.Context, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	var step ScriptStep
+	if m.scriptFunc != nil {
+		step = m.scriptFunc(patternData)
+	} else {
+		m.mu.Lock()
+		step = m.script[m.next]
+		if m.next < len(m.script)-1 {
+			m.next++
+		}
+		m.mu.Unlock()
+	}
+
+	if step.Latency > 0 {
+		if err := sleepContext(ctx, step.Latency); err != nil {
+			return nil, err
+		}
+	}
+	if step.Err != nil {
+		return nil, step.Err
+	}
+	if step.Analysis == nil {
+		return nil, fmt.Errorf("脚本步骤没有设置分析结果")
+	}
+
+	analysis := copyAnalysis(step.Analysis)
+	if analysis.AnalyzedAt.IsZero() {
+		analysis.AnalyzedAt = time.Now()
+	}
+	return analysis, nil
+}
+
+/**
+ * record 调用真实模型并保存夹具
+ */
+func (m *ReplayModel) record(ctx context.Context, key string, prompt *RenderedPrompt, patternData map[string]interface{}) (*PatternAnalysis, error) {
+	if m.upstream == nil {
+		return nil, fmt.Errorf("录制模式需要真实模型")
+	}
+
+	analysis, err := m.upstream.AnalyzePattern(ctx, patternData)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture := Fixture{
+		Key:         key,
+		Provider:    m.upstream.GetType(),
+		RecordedAt:  time.Now(),
+		PatternData: patternData,
+		Version:     prompt.Version,
+		System:      prompt.System,
+		Prompt:      prompt.User,
+		Response:    analysis,
+	}
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化夹具失败: %w", err)
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建夹具目录失败: %w", err)
+	}
+	if err := os.WriteFile(m.fixturePath(key), data, 0644); err != nil {
+		return nil, fmt.Errorf("保存夹具失败: %w", err)
+	}
+
+	logger.Info("已录制 AI 响应",
+		zap.String("key", key[:16]),
+		zap.String("provider", string(fixture.Provider)))
+	return copyAnalysis(analysis), nil
+}
+
+/**
+ * load 读取夹具
+ */
+func (m *ReplayModel) load(key string) (*Fixture, error) {
+	data, err := os.ReadFile(m.fixturePath(key))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s（可用录制模式生成）", ErrFixtureNotFound, key[:16])
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取夹具失败: %w", err)
+	}
+
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("解析夹具失败: %w", err)
+	}
+	if fixture.Response == nil {
+		return nil, fmt.Errorf("夹具 %s 没有响应", key[:16])
+	}
+	return &fixture, nil
+}
+
+/**
+ * fixturePath 夹具文件路径，文件名取提示词哈希的前 16 位
+ */
+func (m *ReplayModel) fixturePath(key string) string {
+	return filepath.Join(m.dir, key[:16]+".json")
+}
+
+/**
+ * Calls 获取所有调用记录
+ *
+ * Returns: []ReplayCall - 按调用顺序排列
+ */
+func (m *ReplayModel) Calls() []ReplayCall {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]ReplayCall(nil), m.calls...)
+}
+
+/**
+ * CallCount 获取调用次数
+ */
+func (m *ReplayModel) CallCount() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.calls)
+}
+
+/**
+ * AnalyzePatternBatch 批量分析模式
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - patterns: 模式列表
+ *
+ * Returns: []*PatternAnalysis - 分析结果列表，任一失败时返回错误
+ */
+func (m *ReplayModel) AnalyzePatternBatch(ctx context.Context, patterns []map[string]interface{}) ([]*PatternAnalysis, error) {
+	results := make([]*PatternAnalysis, len(patterns))
+	for i, pattern := range patterns {
+		analysis, err := m.AnalyzePattern(ctx, pattern)
+		if err != nil {
+			return nil, err
+		}
+		results[i] = analysis
+	}
+	return results, nil
+}
+
+/**
+ * GetType 获取模型类型
+ */
+func (m *ReplayModel) GetType() ModelType {
+	return ModelTypeReplay
+}
+
+/**
+ * Close 关闭连接，录制模式下同时关闭真实模型
+ *
+ * Returns: error - 关闭错误
+ */
+func (m *ReplayModel) Close() error {
+	if m.upstream != nil {
+		return m.upstream.Close()
+	}
+	return nil
+}
+
+/**
+ * PromptHash 计算提示词的哈希，作为夹具的键
+ *
+ * 模板标识、系统提示词和用户提示词都参与计算，修改任何一处都会重新录制，
+ * 避免回放过期的响应
+ *
+ * Parameters:
+ *   - prompt: 渲染后的提示词
+ *
+ * Returns: string - 十六进制 SHA-256
+ */
+func PromptHash(prompt *RenderedPrompt) string {
+	h := sha256.New()
+	for _, part := range []string{prompt.Version, prompt.System, prompt.User} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+/**
+ * copyAnalysis 复制分析结果，避免调用方修改脚本或夹具中的数据
+ */
+func copyAnalysis(analysis *PatternAnalysis) *PatternAnalysis {
+	copied := *analysis
+	copied.SuggestedSteps = append([]string(nil), analysis.SuggestedSteps...)
+	return &copied
+}
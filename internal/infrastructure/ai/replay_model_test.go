@@ -0,0 +1,159 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 录制回放模型单元测试
+ */
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestReplayModel_RecordAndReplay 测试录制后离线回放
+func TestReplayModel_RecordAndReplay(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	pattern := map[string]interface{}{"id": "p1", "sequence": "copy -> paste", "support_count": 5}
+
+	upstream := &scriptedModel{modelType: ModelTypeOllama}
+	recorder := NewRecordingModel(dir, upstream)
+	assert.Equal(t, ReplayModeRecord, recorder.Mode())
+
+	recorded, err := recorder.AnalyzePattern(context.Background(), pattern)
+	require.NoError(t, err)
+	assert.Equal(t, "ollama", recorded.SuggestedName)
+	assert.Equal(t, 1, upstream.callCount())
+
+	// 已录制的请求不再调用真实模型
+	_, err = recorder.AnalyzePattern(context.Background(), pattern)
+	require.NoError(t, err)
+	assert.Equal(t, 1, upstream.callCount())
+
+	key := PromptHash(RenderPatternAnalysisPrompt(pattern))
+	data, err := os.ReadFile(filepath.Join(dir, key[:16]+".json"))
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"provider": "ollama"`)
+	assert.Contains(t, string(data), `"prompt":`)
+	assert.Contains(t, string(data), `"system":`)
+
+	// 回放模式不需要真实模型
+	replayer := NewReplayModel(dir)
+	replayed, err := replayer.AnalyzePattern(context.Background(), pattern)
+	require.NoError(t, err)
+	assert.Equal(t, recorded, replayed)
+	assert.Equal(t, ModelTypeReplay, replayer.GetType())
+	assert.Equal(t, key, replayer.Calls()[0].Key)
+
+	// 不同的请求没有录制结果
+	_, err = replayer.AnalyzePattern(context.Background(), map[string]interface{}{"id": "p2"})
+	assert.ErrorIs(t, err, ErrFixtureNotFound)
+
+	// 录制模式下真实模型失败时不保存夹具
+	upstream.script(statusErr(503))
+	_, err = recorder.AnalyzePattern(context.Background(), map[string]interface{}{"id": "p3"})
+	assert.Equal(t, 503, StatusCode(err))
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Len(t, entries, 1)
+
+	require.NoError(t, recorder.Close())
+	assert.True(t, upstream.closed)
+}
+
+// TestReplayModel_SystemPromptChange 测试修改系统提示词后不回放旧夹具
+func TestReplayModel_SystemPromptChange(t *testing.T) {
+	t.Cleanup(func() {
+		_, err := ConfigurePrompts(PromptConfig{})
+		require.NoError(t, err)
+	})
+
+	templates := t.TempDir()
+	useSystem := func(system string) {
+		content := "---\nsystem: " + system + "\noutput_schema:\n  type: object\n---\n固定的用户提示词"
+		require.NoError(t, os.WriteFile(filepath.Join(templates, "pattern_analysis.v1.zh.tmpl"), []byte(content), 0644))
+		_, err := ConfigurePrompts(PromptConfig{TemplatesDir: templates})
+		require.NoError(t, err)
+	}
+
+	dir := filepath.Join(t.TempDir(), "fixtures")
+	pattern := map[string]interface{}{"id": "p1"}
+
+	useSystem("只返回 JSON")
+	_, err := NewRecordingModel(dir, &scriptedModel{modelType: ModelTypeOllama}).AnalyzePattern(context.Background(), pattern)
+	require.NoError(t, err)
+
+	replayer := NewReplayModel(dir)
+	_, err = replayer.AnalyzePattern(context.Background(), pattern)
+	require.NoError(t, err)
+
+	// 用户提示词和模板标识不变，只修改系统提示词
+	useSystem("只返回 YAML")
+	_, err = replayer.AnalyzePattern(context.Background(), pattern)
+	assert.ErrorIs(t, err, ErrFixtureNotFound)
+}
+
+// TestScriptedModel 测试按脚本返回结果、错误和延迟
+func TestScriptedModel(t *testing.T) {
+	boom := errors.New("模拟失败")
+	model := NewScriptedModel(
+		ScriptStep{Analysis: &PatternAnalysis{ShouldAutomate: true, SuggestedSteps: []string{"a"}}},
+		ScriptStep{Err: boom},
+		ScriptStep{Analysis: &PatternAnalysis{Reason: "最后一步"}},
+	)
+
+	first, err := model.AnalyzePattern(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, first.ShouldAutomate)
+	assert.False(t, first.AnalyzedAt.IsZero())
+
+	// 修改返回值不影响脚本
+	first.SuggestedSteps[0] = "changed"
+
+	_, err = model.AnalyzePattern(context.Background(), nil)
+	assert.ErrorIs(t, err, boom)
+
+	// 脚本用完后重复最后一步
+	for i := 0; i < 2; i++ {
+		analysis, err := model.AnalyzePattern(context.Background(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, "最后一步", analysis.Reason)
+	}
+	assert.Equal(t, 4, model.CallCount())
+
+	// 批量分析任一失败时返回错误
+	_, err = NewScriptedModel(ScriptStep{Err: boom}).AnalyzePatternBatch(context.Background(), []map[string]interface{}{{}})
+	assert.ErrorIs(t, err, boom)
+
+	// 默认脚本
+	analysis, err := NewScriptedModel().AnalyzePattern(context.Background(), nil)
+	require.NoError(t, err)
+	assert.True(t, analysis.ShouldAutomate)
+
+	// 延迟期间取消上下文
+	slow := NewScriptedModelFunc(func(map[string]interface{}) ScriptStep {
+		return ScriptStep{Analysis: &PatternAnalysis{}, Latency: time.Minute}
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = slow.AnalyzePattern(ctx, nil)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestNewAIModel_Replay 测试通过工厂创建回放模型
+func TestNewAIModel_Replay(t *testing.T) {
+	model, err := NewAIModel(&AIConfig{Provider: "replay", ReplayDir: t.TempDir()})
+	require.NoError(t, err)
+	assert.Equal(t, ModelTypeReplay, model.GetType())
+
+	t.Setenv("AI_REPLAY_DIR", "")
+	_, err = NewAIModel(&AIConfig{Provider: "replay"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,523 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 结构化输出：从模型响应中提取 JSON，按 JSON Schema 校验，不合格时带着错误重新请求
+ */
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/cloudwego/eino/schema"
+	"go.uber.org/zap"
+)
+
+/**
+ * DefaultMaxRepairs 响应不合格时默认的最大重新请求次数
+ */
+const DefaultMaxRepairs = 2
+
+/**
+ * JSONSchema JSON Schema 的子集
+ *
+ * 支持 type、properties、required、items、enum 和 minimum，足够描述分析结果这类扁平结构
+ */
+type JSONSchema struct {
+	// Type 类型：object、array、string、integer、number、boolean
+	Type string `json:"type,omitempty"`
+
+	// Required 必填属性
+	Required []string `json:"required,omitempty"`
+
+	// Properties 对象属性
+	Properties map[string]*JSONSchema `json:"properties,omitempty"`
+
+	// Items 数组元素
+	Items *JSONSchema `json:"items,omitempty"`
+
+	// Enum 允许的取值
+	Enum []string `json:"enum,omitempty"`
+
+	// Minimum 数值下限
+	Minimum *float64 `json:"minimum,omitempty"`
+}
+
+/**
+ * OutputError 模型响应不符合输出格式
+ */
+type OutputError struct {
+	// Content 模型的原始响应
+	Content string
+
+	// Problems 发现的问题，每条一句
+	Problems []string
+}
+
+/**
+ * Error 实现 error 接口
+ */
+func (e *OutputError) Error() string {
+	return "响应不符合输出格式: " + strings.Join(e.Problems, "; ")
+}
+
+/**
+ * DeriveSchema 从结构体类型生成 JSON Schema
+ *
+ * 属性名取 json 标签；带 omitempty 的字段不是必填；schema 标签补充约束：
+ * schema:"-" 忽略字段，schema:"enum=a|b|c" 限定取值，schema:"minimum=0" 限定下限
+ *
+ * Parameters:
+ *   - t: 类型
+ *
+ * Returns: *JSONSchema - 对应的 Schema
+ */
+func DeriveSchema(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &JSONSchema{Type: "boolean"}
+	case reflect.String:
+		return &JSONSchema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &JSONSchema{Type: "array", Items: DeriveSchema(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: "object"}
+	case reflect.Struct:
+		if t == reflect.TypeOf(time.Time{}) {
+			return &JSONSchema{Type: "string"}
+		}
+	default:
+		return &JSONSchema{}
+	}
+
+	s := &JSONSchema{Type: "object", Properties: make(map[string]*JSONSchema)}
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("schema")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		prop := DeriveSchema(field.Type)
+		for _, rule := range strings.Split(tag, ",") {
+			key, value, _ := strings.Cut(rule, "=")
+			switch key {
+			case "enum":
+				prop.Enum = strings.Split(value, "|")
+			case "minimum":
+				if min, err := strconv.ParseFloat(value, 64); err == nil {
+					prop.Minimum = &min
+				}
+			}
+		}
+
+		s.Properties[name] = prop
+		if !strings.Contains(options, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+/**
+ * Validate 校验 JSON 值
+ *
+ * Parameters:
+ *   - value: json.Unmarshal 到 interface{} 得到的值
+ *
+ * Returns: []string - 发现的问题，为空表示通过
+ */
+func (s *JSONSchema) Validate(value interface{}) []string {
+	var problems []string
+	s.validate("$", value, &problems)
+	return problems
+}
+
+/**
+ * validate 递归校验，问题前缀为字段路径
+ */
+func (s *JSONSchema) validate(path string, value interface{}, problems *[]string) {
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			fail("应为 object，实际为 %s", jsonType(value))
+			return
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				fail("缺少必填字段 %s", name)
+			}
+		}
+		for name, prop := range s.Properties {
+			if v, ok := obj[name]; ok {
+				prop.validate(path+"."+name, v, problems)
+			}
+		}
+	case "array":
+		arr, ok := value.([]interface{})
+		if !ok {
+			fail("应为 array，实际为 %s", jsonType(value))
+			return
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, problems)
+			}
+		}
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("应为 string，实际为 %s", jsonType(value))
+			return
+		}
+		if len(s.Enum) > 0 && !containsString(s.Enum, str) {
+			fail("%q 不是允许的取值，应为 %s 之一", str, strings.Join(s.Enum, "、"))
+		}
+	case "integer", "number":
+		num, ok := value.(float64)
+		if !ok {
+			fail("应为 %s，实际为 %s", s.Type, jsonType(value))
+			return
+		}
+		if s.Type == "integer" && num != math.Trunc(num) {
+			fail("应为整数，实际为 %v", num)
+		}
+		if s.Minimum != nil && num < *s.Minimum {
+			fail("不能小于 %v", *s.Minimum)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("应为 boolean，实际为 %s", jsonType(value))
+		}
+	}
+}
+
+/**
+ * jsonType 获取 JSON 值的类型名
+ */
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
+
+/**
+ * containsString 判断切片中是否包含字符串
+ */
+func containsString(values []string, target string) bool {
+	for _, v := range values {
+		if v == target {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * ExtractJSON 从模型响应中提取 JSON 对象
+ *
+ * 依次处理：markdown 代码块、对象前后的说明文字、被截断的对象（补全未闭合的字符串和括号，
+ * 丢弃最后一个不完整的字段）
+ *
+ * Parameters:
+ *   - content: 模型响应
+ *
+ * Returns: string - JSON 对象文本
+ */
+func ExtractJSON(content string) (string, error) {
+	text := stripCodeFence(content)
+
+	start := strings.IndexByte(text, '{')
+	if start < 0 {
+		return "", fmt.Errorf("响应中没有 JSON 对象")
+	}
+	text = text[start:]
+
+	// cuts 记录字符串外的逗号和左括号位置，截断时从这些位置回退
+	var cuts []int
+	var stack []byte
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		ch := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, ch)
+			cuts = append(cuts, i+1)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+			if len(stack) == 0 {
+				candidate := text[:i+1]
+				if !json.Valid([]byte(candidate)) {
+					return "", fmt.Errorf("JSON 格式错误")
+				}
+				return candidate, nil
+			}
+		case ',':
+			cuts = append(cuts, i)
+		}
+	}
+
+	// 响应被截断：先尝试只补全结尾，再逐个丢弃最后的不完整字段
+	if candidate := closeJSON(text); json.Valid([]byte(candidate)) {
+		return candidate, nil
+	}
+	for i := len(cuts) - 1; i >= 0; i-- {
+		if candidate := closeJSON(text[:cuts[i]]); json.Valid([]byte(candidate)) {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("JSON 不完整且无法补全")
+}
+
+/**
+ * stripCodeFence 去掉 markdown 代码块标记，没有代码块时原样返回
+ */
+func stripCodeFence(content string) string {
+	start := strings.Index(content, "```")
+	if start < 0 {
+		return content
+	}
+	body := content[start+3:]
+	// 跳过语言标识（如 json）
+	if newline := strings.IndexByte(body, '\n'); newline >= 0 && !strings.ContainsAny(body[:newline], "{[") {
+		body = body[newline+1:]
+	}
+	if end := strings.Index(body, "```"); end >= 0 {
+		body = body[:end]
+	}
+	return body
+}
+
+/**
+ * closeJSON 补全被截断的 JSON：闭合字符串、去掉结尾的逗号和冒号、闭合所有括号
+ */
+func closeJSON(prefix string) string {
+	var stack []byte
+	inString, escaped := false, false
+	for i := 0; i < len(prefix); i++ {
+		ch := prefix[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case ch == '\\':
+				escaped = true
+			case ch == '"':
+				inString = false
+			}
+			continue
+		}
+		switch ch {
+		case '"':
+			inString = true
+		case '{', '[':
+			stack = append(stack, ch)
+		case '}', ']':
+			if len(stack) > 0 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(prefix)
+	if inString {
+		if escaped {
+			b.WriteByte('\\')
+		}
+		b.WriteByte('"')
+	}
+	result := strings.TrimRight(b.String(), " \t\r\n,:")
+
+	var closing strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		if stack[i] == '{' {
+			closing.WriteByte('}')
+		} else {
+			closing.WriteByte(']')
+		}
+	}
+	return result + closing.String()
+}
+
+/**
+ * ParseStructured 提取、校验并解析模型响应
+ *
+ * Parameters:
+ *   - content: 模型响应
+ *   - s: 输出 Schema
+ *   - out: 解析目标
+ *
+ * Returns: error - 不符合格式时为 *OutputError
+ */
+func ParseStructured(content string, s *JSONSchema, out interface{}) error {
+	jsonStr, err := ExtractJSON(content)
+	if err != nil {
+		return &OutputError{Content: content, Problems: []string{err.Error()}}
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(jsonStr), &value); err != nil {
+		return &OutputError{Content: content, Problems: []string{"JSON 解析失败: " + err.Error()}}
+	}
+	if problems := s.Validate(value); len(problems) > 0 {
+		return &OutputError{Content: content, Problems: problems}
+	}
+	if err := json.Unmarshal([]byte(jsonStr), out); err != nil {
+		return &OutputError{Content: content, Problems: []string{"JSON 解析失败: " + err.Error()}}
+	}
+	return nil
+}
+
+/**
+ * patternAnalysisSchema 从 PatternAnalysis 生成的输出 Schema
+ */
+var patternAnalysisSchema = DeriveSchema(reflect.TypeOf(PatternAnalysis{}))
+
+/**
+ * PatternAnalysisSchema 获取模式分析结果的输出 Schema
+ *
+ * Returns: *JSONSchema - 从 PatternAnalysis 生成的 Schema
+ */
+func PatternAnalysisSchema() *JSONSchema {
+	return patternAnalysisSchema
+}
+
+/**
+ * ParsePatternAnalysis 解析模式分析响应
+ *
+ * Parameters:
+ *   - content: 模型响应
+ *
+ * Returns: *PatternAnalysis - 分析结果，AnalyzedAt 为当前时间
+ */
+func ParsePatternAnalysis(content string) (*PatternAnalysis, error) {
+	var analysis PatternAnalysis
+	if err := ParseStructured(content, patternAnalysisSchema, &analysis); err != nil {
+		return nil, err
+	}
+	analysis.AnalyzedAt = time.Now()
+	return &analysis, nil
+}
+
+/**
+ * GenerateFunc 调用一次模型，返回响应文本
+ */
+type GenerateFunc func(ctx context.Context, messages []*schema.Message) (string, error)
+
+/**
+ * AnalyzeWithRepair 请求模式分析，响应不符合格式时带着错误重新请求
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - generate: 调用模型的函数
+ *   - prompt: 渲染后的提示词
+ *   - maxRepairs: 最大重新请求次数
+ *
+ * Returns: *PatternAnalysis - 分析结果，记录了提示词版本
+ */
+func AnalyzeWithRepair(ctx context.Context, generate GenerateFunc, prompt *RenderedPrompt, maxRepairs int) (*PatternAnalysis, error) {
+	messages := []*schema.Message{
+		schema.SystemMessage(prompt.System),
+		schema.UserMessage(prompt.User),
+	}
+
+	for attempt := 0; ; attempt++ {
+		content, err := generate(ctx, messages)
+		if err != nil {
+			return nil, err
+		}
+
+		analysis, err := ParsePatternAnalysis(content)
+		if err == nil {
+			analysis.PromptVersion = prompt.Version
+			return analysis, nil
+		}
+
+		var outputErr *OutputError
+		if !errors.As(err, &outputErr) || attempt >= maxRepairs {
+			return nil, err
+		}
+
+		logger.Warn("AI 响应不符合输出格式，重新请求",
+			zap.Int("attempt", attempt+1),
+			zap.Strings("problems", outputErr.Problems))
+		messages = append(messages,
+			schema.AssistantMessage(content, nil),
+			schema.UserMessage(repairPrompt(prompt.Language, outputErr.Problems)))
+		ctx = WithUsageInfo(ctx, "", PurposeOutputRepair)
+	}
+}
+
+/**
+ * repairPrompt 生成要求模型修正输出的提示词
+ */
+func repairPrompt(language string, problems []string) string {
+	schemaJSON, _ := json.MarshalIndent(patternAnalysisSchema, "", "  ")
+	list := "- " + strings.Join(problems, "\n- ")
+	if language == "en" {
+		return "Your previous reply does not match the required output format:\n" + list +
+			"\n\nReply again with only a JSON object matching this schema:\n" + string(schemaJSON)
+	}
+	return "上一次的回复不符合输出格式：\n" + list +
+		"\n\n请只返回符合以下 JSON Schema 的 JSON 对象：\n" + string(schemaJSON)
+}
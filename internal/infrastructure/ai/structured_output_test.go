@@ -0,0 +1,178 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 结构化输出单元测试
+ */
+
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/cloudwego/eino/schema"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPatternAnalysisSchema 测试生成的 Schema 与内置模板声明的一致
+func TestPatternAnalysisSchema(t *testing.T) {
+	derived, err := json.Marshal(PatternAnalysisSchema())
+	require.NoError(t, err)
+
+	var want map[string]interface{}
+	require.NoError(t, json.Unmarshal(derived, &want))
+	assert.Equal(t, []interface{}{"should_automate", "reason", "estimated_time_saving",
+		"complexity", "suggested_name", "suggested_steps"}, want["required"])
+
+	lib, err := NewPromptLibrary("")
+	require.NoError(t, err)
+	for _, tmpl := range lib.List() {
+		var declared map[string]interface{}
+		require.NoError(t, json.Unmarshal(tmpl.OutputSchema, &declared))
+		assert.Equal(t, want, declared, tmpl.ID())
+	}
+}
+
+// TestExtractJSON 测试从各种响应中提取 JSON
+func TestExtractJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"纯 JSON", `{"a": 1}`, `{"a": 1}`},
+		{"代码块", "```json\n{\"a\": 1}\n```", `{"a": 1}`},
+		{"无语言标识的代码块", "```\n{\"a\": [1, 2]}\n```", `{"a": [1, 2]}`},
+		{"前后有说明文字", "分析如下：\n{\"a\": \"x}\"} 希望有帮助", `{"a": "x}"}`},
+		{"截断在字符串中", `{"a": 1, "b": "半句`, `{"a": 1, "b": "半句"}`},
+		{"截断在冒号后", `{"a": 1, "b":`, `{"a": 1}`},
+		{"截断在键名中", `{"a": 1, "bc`, `{"a": 1}`},
+		{"截断在数组中", `{"a": true, "s": ["x", "y"`, `{"a": true, "s": ["x", "y"]}`},
+		{"截断在代码块中", "```json\n{\"a\": {\"b\": 1,", `{"a": {"b": 1}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jsonStr, err := ExtractJSON(tt.content)
+			require.NoError(t, err)
+			assert.JSONEq(t, tt.expected, jsonStr)
+		})
+	}
+
+	_, err := ExtractJSON("我认为应该自动化")
+	assert.ErrorContains(t, err, "没有 JSON 对象")
+	_, err = ExtractJSON(`{invalid json}`)
+	assert.Error(t, err)
+}
+
+// TestJSONSchema_Validate 测试按 Schema 校验
+func TestJSONSchema_Validate(t *testing.T) {
+	var value interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{
+		"should_automate": "yes",
+		"reason": "原因",
+		"estimated_time_saving": 12.5,
+		"complexity": "trivial",
+		"suggested_steps": ["a", 2]
+	}`), &value))
+
+	problems := PatternAnalysisSchema().Validate(value)
+	assert.ElementsMatch(t, []string{
+		"$: 缺少必填字段 suggested_name",
+		"$.should_automate: 应为 boolean，实际为 string",
+		"$.estimated_time_saving: 应为整数，实际为 12.5",
+		`$.complexity: "trivial" 不是允许的取值，应为 low、medium、high 之一`,
+		"$.suggested_steps[1]: 应为 string，实际为 number",
+	}, problems)
+
+	assert.Equal(t, []string{"$: 应为 object，实际为 array"}, PatternAnalysisSchema().Validate([]interface{}{}))
+	assert.Equal(t, []string{"$: 不能小于 0"}, PatternAnalysisSchema().Properties["estimated_time_saving"].Validate(-1.0))
+}
+
+// TestAnalyzeWithRepair 测试带着校验错误重新请求
+func TestAnalyzeWithRepair(t *testing.T) {
+	prompt := &RenderedPrompt{System: "system", User: "user", Version: "pattern_analysis.v1.zh", Language: "zh"}
+	replies := []string{
+		`好的：{"should_automate": true, "complexity": "trivial"}`,
+		"```json\n" + analysisJSON + "\n```",
+	}
+	var calls [][]*schema.Message
+	generate := func(ctx context.Context, messages []*schema.Message) (string, error) {
+		calls = append(calls, append([]*schema.Message(nil), messages...))
+		reply := replies[0]
+		if len(replies) > 1 {
+			replies = replies[1:]
+		}
+		return reply, nil
+	}
+
+	analysis, err := AnalyzeWithRepair(context.Background(), generate, prompt, 2)
+	require.NoError(t, err)
+	assert.Equal(t, "复制构建日志", analysis.SuggestedName)
+	assert.Equal(t, "pattern_analysis.v1.zh", analysis.PromptVersion)
+	assert.False(t, analysis.AnalyzedAt.IsZero())
+
+	// 第二次请求带上了上一次的回复和发现的问题
+	require.Len(t, calls, 2)
+	repair := calls[1]
+	require.Len(t, repair, 4)
+	assert.Equal(t, schema.Assistant, repair[2].Role)
+	assert.Contains(t, repair[2].Content, "trivial")
+	assert.Equal(t, schema.User, repair[3].Role)
+	assert.Contains(t, repair[3].Content, "缺少必填字段 reason")
+	assert.Contains(t, repair[3].Content, `"trivial" 不是允许的取值`)
+
+	// 达到上限后返回最后一次的格式错误
+	replies = []string{"不是 JSON"}
+	calls = nil
+	_, err = AnalyzeWithRepair(context.Background(), generate, prompt, 1)
+	var outputErr *OutputError
+	require.ErrorAs(t, err, &outputErr)
+	assert.Equal(t, "不是 JSON", outputErr.Content)
+	assert.Len(t, calls, 2)
+
+	// 负数不重新请求
+	calls = nil
+	_, err = AnalyzeWithRepair(context.Background(), generate, prompt, -1)
+	assert.Error(t, err)
+	assert.Len(t, calls, 1)
+
+	// 调用失败直接返回
+	boom := errors.New("网络错误")
+	_, err = AnalyzeWithRepair(context.Background(), func(context.Context, []*schema.Message) (string, error) {
+		return "", boom
+	}, prompt, 2)
+	assert.ErrorIs(t, err, boom)
+
+	assert.Contains(t, repairPrompt("en", []string{"$: x"}), "does not match")
+}
+
+// TestZhipuClient_RepairLoop 测试智谱客户端通过兼容接口重新请求
+func TestZhipuClient_RepairLoop(t *testing.T) {
+	server := newFakeOpenAIServer(t, analysisJSON)
+	server.replies = []string{`{"should_automate": true, "reason": "缺少其他字段"`}
+	baseURL := server.URL + "/v1/"
+	client, err := NewZhipuClient(&ZhipuConfig{APIKey: "test", BaseURL: &baseURL})
+	require.NoError(t, err)
+
+	analysis, err := client.AnalyzePattern(context.Background(), map[string]interface{}{"pattern_id": "p-1"})
+	require.NoError(t, err)
+	assert.Equal(t, "复制构建日志", analysis.SuggestedName)
+	assert.Equal(t, ActivePrompt().ID(), analysis.PromptVersion)
+
+	body, _ := server.lastRequest(t)
+	messages := body["messages"].([]interface{})
+	require.Len(t, messages, 4)
+	assert.Contains(t, messages[3].(map[string]interface{})["content"], "缺少必填字段 complexity")
+
+	// 始终不合格时在上限后失败
+	server.mu.Lock()
+	server.content = "无法分析"
+	server.requests = nil
+	server.mu.Unlock()
+	_, err = client.AnalyzePattern(context.Background(), map[string]interface{}{"pattern_id": "p-1"})
+	assert.ErrorContains(t, err, "解析响应失败")
+	assert.Len(t, server.requests, DefaultMaxRepairs+1)
+}
@@ -0,0 +1,292 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * token 用量上报、模型价格表和付费提供商判断
+ */
+
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	// PurposePatternAnalysis 模式分析
+	PurposePatternAnalysis = "pattern_analysis"
+
+	// PurposeOutputRepair 响应不符合输出格式时的重新请求
+	PurposeOutputRepair = "output_repair"
+)
+
+// ErrBudgetExceeded AI 花费超出预算
+var ErrBudgetExceeded = errors.New("AI 花费超出预算")
+
+/**
+ * Usage 一次 AI 调用的 token 用量
+ */
+type Usage struct {
+	// Provider 提供商（claude、openai、zhipu、ollama）
+	Provider string
+
+	// Model 模型名称
+	Model string
+
+	// PatternID 被分析的模式 ID
+	PatternID string
+
+	// Purpose 调用目的，如 PurposePatternAnalysis
+	Purpose string
+
+	// InputTokens 输入 token 数
+	InputTokens int
+
+	// OutputTokens 输出 token 数
+	OutputTokens int
+
+	// Duration 调用耗时
+	Duration time.Duration
+
+	// CreatedAt 调用时间
+	CreatedAt time.Time
+}
+
+/**
+ * UsageRecorder 接收每次 AI 调用用量的回调
+ *
+ * 通过 AIConfig.UsageRecorder 注入模型客户端，在调用路径上同步执行，不应长时间阻塞
+ */
+type UsageRecorder func(ctx context.Context, usage Usage)
+
+/**
+ * usageInfo 随上下文传递的调用信息
+ */
+type usageInfo struct {
+	patternID string
+	purpose   string
+}
+
+type usageInfoKey struct{}
+
+/**
+ * WithUsageInfo 在上下文中标记调用关联的模式和目的
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - patternID: 模式 ID，为空时保留上下文中已有的值
+ *   - purpose: 调用目的，为空时保留上下文中已有的值
+ *
+ * Returns: context.Context - 新的上下文
+ */
+func WithUsageInfo(ctx context.Context, patternID, purpose string) context.Context {
+	info, _ := ctx.Value(usageInfoKey{}).(usageInfo)
+	if patternID != "" {
+		info.patternID = patternID
+	}
+	if purpose != "" {
+		info.purpose = purpose
+	}
+	return context.WithValue(ctx, usageInfoKey{}, info)
+}
+
+/**
+ * patternUsageContext 为模式分析调用补齐用量信息
+ *
+ * 上下文中没有模式 ID 时取模式数据中的 pattern_id，没有目的时记为模式分析
+ */
+func patternUsageContext(ctx context.Context, patternData map[string]interface{}) context.Context {
+	info, _ := ctx.Value(usageInfoKey{}).(usageInfo)
+	if info.patternID == "" {
+		if id, ok := patternData["pattern_id"].(string); ok {
+			info.patternID = id
+		}
+	}
+	if info.purpose == "" {
+		info.purpose = PurposePatternAnalysis
+	}
+	return context.WithValue(ctx, usageInfoKey{}, info)
+}
+
+/**
+ * reportUsage 把一次收到响应的调用上报给用量回调
+ *
+ * Parameters:
+ *   - ctx: 调用的上下文，携带模式 ID 和目的
+ *   - recorder: 用量回调，为 nil 时不上报
+ *   - provider: 提供商
+ *   - model: 模型名称
+ *   - inputTokens: 输入 token 数
+ *   - outputTokens: 输出 token 数
+ *   - duration: 调用耗时
+ */
+func reportUsage(ctx context.Context, recorder UsageRecorder, provider, model string, inputTokens, outputTokens int, duration time.Duration) {
+	if recorder == nil {
+		return
+	}
+
+	info, _ := ctx.Value(usageInfoKey{}).(usageInfo)
+	recorder(ctx, Usage{
+		Provider:     provider,
+		Model:        model,
+		PatternID:    info.patternID,
+		Purpose:      info.purpose,
+		InputTokens:  inputTokens,
+		OutputTokens: outputTokens,
+		Duration:     duration,
+		CreatedAt:    time.Now(),
+	})
+}
+
+/**
+ * ModelPrice 模型价格，单位为美元每百万 token
+ */
+type ModelPrice struct {
+	// Input 输入价格
+	Input float64
+
+	// Output 输出价格
+	Output float64
+}
+
+/**
+ * DefaultPrices 内置的模型价格
+ *
+ * 按模型名前缀匹配，带日期后缀的版本（如 claude-3-5-sonnet-20241022）使用同一价格。
+ * 价格会调整，可通过 ai.budget.prices 覆盖；智谱按人民币价格折算
+ */
+var DefaultPrices = map[string]ModelPrice{
+	"claude-3-5-sonnet": {Input: 3, Output: 15},
+	"claude-3-5-haiku":  {Input: 0.8, Output: 4},
+	"claude-3-opus":     {Input: 15, Output: 75},
+	"claude-3-haiku":    {Input: 0.25, Output: 1.25},
+	"gpt-4o":            {Input: 2.5, Output: 10},
+	"gpt-4o-mini":       {Input: 0.15, Output: 0.6},
+	"gpt-4-turbo":       {Input: 10, Output: 30},
+	"gpt-3.5-turbo":     {Input: 0.5, Output: 1.5},
+	"glm-4":             {Input: 14, Output: 14},
+	"glm-4-flash":       {Input: 0, Output: 0},
+}
+
+/**
+ * PriceTable 按模型计算调用费用
+ */
+type PriceTable struct {
+	prices map[string]ModelPrice
+}
+
+/**
+ * NewPriceTable 创建价格表
+ *
+ * Parameters:
+ *   - overrides: 覆盖或补充内置价格的模型价格
+ *
+ * Returns: *PriceTable - 价格表
+ */
+func NewPriceTable(overrides map[string]ModelPrice) *PriceTable {
+	prices := make(map[string]ModelPrice, len(DefaultPrices)+len(overrides))
+	for model, price := range DefaultPrices {
+		prices[model] = price
+	}
+	for model, price := range overrides {
+		prices[model] = price
+	}
+	return &PriceTable{prices: prices}
+}
+
+/**
+ * Cost 计算一次调用的费用
+ *
+ * 免费提供商（Ollama、回放）总是 0；付费提供商的模型按精确名称或最长前缀匹配价格
+ *
+ * Parameters:
+ *   - provider: 提供商
+ *   - model: 模型名称
+ *   - inputTokens: 输入 token 数
+ *   - outputTokens: 输出 token 数
+ *
+ * Returns:
+ *   - float64: 费用（美元）
+ *   - bool: 是否找到价格，付费提供商的未知模型返回 false
+ */
+func (t *PriceTable) Cost(provider, model string, inputTokens, outputTokens int) (float64, bool) {
+	if !isPaidProvider(ModelType(provider)) {
+		return 0, true
+	}
+
+	price, ok := t.prices[model]
+	if !ok {
+		matched := ""
+		for name, candidate := range t.prices {
+			if strings.HasPrefix(model, name) && len(name) > len(matched) {
+				matched, price = name, candidate
+			}
+		}
+		if matched == "" {
+			return 0, false
+		}
+	}
+	return (float64(inputTokens)*price.Input + float64(outputTokens)*price.Output) / 1e6, true
+}
+
+/**
+ * isPaidProvider 判断提供商是否按 token 收费
+ */
+func isPaidProvider(provider ModelType) bool {
+	switch provider {
+	case ModelTypeOllama, ModelTypeReplay:
+		return false
+	}
+	return true
+}
+
+/**
+ * IsPaid 判断模型是否可能调用付费提供商
+ *
+ * 降级链中任一提供商付费即视为付费；录制模式下取决于被录制的模型
+ *
+ * Parameters:
+ *   - model: AI 模型
+ *
+ * Returns: bool - 是否付费
+ */
+func IsPaid(model AIModel) bool {
+	switch m := model.(type) {
+	case *FallbackModel:
+		for _, entry := range m.entries {
+			if IsPaid(entry.model) {
+				return true
+			}
+		}
+		return false
+	case *ReplayModel:
+		return m.upstream != nil && IsPaid(m.upstream)
+	}
+	return isPaidProvider(model.GetType())
+}
+
+/**
+ * FreeOnly 获取只使用免费提供商的模型
+ *
+ * 用于超出预算后继续分析：免费模型原样返回，降级链只保留其中的免费提供商
+ *
+ * Parameters:
+ *   - model: AI 模型
+ *
+ * Returns:
+ *   - AIModel: 只使用免费提供商的模型
+ *   - error: 没有免费提供商时返回 ErrBudgetExceeded
+ */
+func FreeOnly(model AIModel) (AIModel, error) {
+	if !IsPaid(model) {
+		return model, nil
+	}
+	if fallback, ok := model.(*FallbackModel); ok {
+		if free := fallback.freeOnly(); free != nil {
+			return free, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s 没有免费的提供商", ErrBudgetExceeded, model.GetType())
+}
@@ -0,0 +1,121 @@
+/**
+ * Package ai AI 服务基础设施层
+ *
+ * 用量上报和价格表单元测试
+ */
+
+package ai
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPriceTable 测试按模型名计算费用
+func TestPriceTable(t *testing.T) {
+	prices := NewPriceTable(map[string]ModelPrice{"qwen-max": {Input: 2, Output: 6}})
+
+	cost, ok := prices.Cost("claude", "claude-3-5-sonnet-20241022", 1_000_000, 100_000)
+	assert.True(t, ok)
+	assert.InDelta(t, 4.5, cost, 1e-9)
+
+	// 最长前缀优先
+	cost, ok = prices.Cost("openai", "gpt-4o-mini-2024-07-18", 1_000_000, 0)
+	assert.True(t, ok)
+	assert.InDelta(t, 0.15, cost, 1e-9)
+
+	cost, ok = prices.Cost("openai", "qwen-max", 500_000, 500_000)
+	assert.True(t, ok)
+	assert.InDelta(t, 4, cost, 1e-9)
+
+	// 本地模型免费，付费提供商的未知模型没有价格
+	cost, ok = prices.Cost("ollama", "llama3.2", 1_000_000, 1_000_000)
+	assert.True(t, ok)
+	assert.Zero(t, cost)
+	_, ok = prices.Cost("openai", "unknown-model", 10, 10)
+	assert.False(t, ok)
+}
+
+// TestIsPaidAndFreeOnly 测试判断付费模型并在超出预算时只保留免费提供商
+func TestIsPaidAndFreeOnly(t *testing.T) {
+	claude := &scriptedModel{modelType: ModelTypeClaude}
+	ollama := &scriptedModel{modelType: ModelTypeOllama}
+
+	assert.True(t, IsPaid(claude))
+	assert.False(t, IsPaid(ollama))
+	assert.False(t, IsPaid(NewScriptedModel()))
+	assert.True(t, IsPaid(NewRecordingModel(t.TempDir(), claude)))
+
+	_, err := FreeOnly(claude)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+	free, err := FreeOnly(ollama)
+	require.NoError(t, err)
+	assert.Same(t, ollama, free)
+
+	model, _, _ := newTestFallback(t, FallbackConfig{}, FallbackProvider{Model: claude}, FallbackProvider{Model: ollama})
+	assert.True(t, IsPaid(model))
+	free, err = FreeOnly(model)
+	require.NoError(t, err)
+	assert.False(t, IsPaid(free))
+
+	analysis, err := free.AnalyzePattern(context.Background(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, "ollama", analysis.SuggestedName)
+	assert.Equal(t, 0, claude.callCount())
+
+	// 关闭视图不关闭提供商
+	require.NoError(t, free.Close())
+	assert.False(t, ollama.closed)
+
+	paidOnly, _, _ := newTestFallback(t, FallbackConfig{}, FallbackProvider{Model: claude})
+	_, err = FreeOnly(paidOnly)
+	assert.ErrorIs(t, err, ErrBudgetExceeded)
+}
+
+// TestReportUsage 测试客户端上报每次调用的用量，包括格式修复的重新请求
+func TestReportUsage(t *testing.T) {
+	var mu sync.Mutex
+	var usages []Usage
+	server := newFakeOpenAIServer(t, analysisJSON)
+	server.replies = []string{`{"should_automate": true}`}
+	client := newTestOpenAIClient(t, server, "", nil)
+	client.config.UsageRecorder = func(ctx context.Context, usage Usage) {
+		mu.Lock()
+		defer mu.Unlock()
+		usages = append(usages, usage)
+	}
+
+	_, err := client.AnalyzePattern(context.Background(), map[string]interface{}{"pattern_id": "p-1"})
+	require.NoError(t, err)
+
+	require.Len(t, usages, 2)
+	assert.Equal(t, "openai", usages[0].Provider)
+	assert.Equal(t, "qwen2.5-7b-instruct", usages[0].Model)
+	assert.Equal(t, "p-1", usages[0].PatternID)
+	assert.Equal(t, PurposePatternAnalysis, usages[0].Purpose)
+	assert.Equal(t, 120, usages[0].InputTokens)
+	assert.Equal(t, 40, usages[0].OutputTokens)
+	assert.False(t, usages[0].CreatedAt.IsZero())
+	assert.Equal(t, PurposeOutputRepair, usages[1].Purpose)
+	assert.Equal(t, "p-1", usages[1].PatternID)
+
+	// 调用方指定的模式和目的优先
+	ctx := WithUsageInfo(context.Background(), "p-2", "custom")
+	_, err = client.AnalyzePattern(ctx, map[string]interface{}{"pattern_id": "p-1"})
+	require.NoError(t, err)
+	require.Len(t, usages, 3)
+	assert.Equal(t, "p-2", usages[2].PatternID)
+	assert.Equal(t, "custom", usages[2].Purpose)
+
+	// 请求失败时没有用量
+	server.mu.Lock()
+	server.status = 500
+	server.mu.Unlock()
+	_, err = client.AnalyzePattern(context.Background(), nil)
+	assert.Error(t, err)
+	assert.Len(t, usages, 3)
+}
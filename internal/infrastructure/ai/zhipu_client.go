@@ -8,13 +8,14 @@ package ai
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"os"
 	"time"
 
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/metrics"
 	"github.com/cloudwego/eino-ext/components/model/openai"
 	"github.com/cloudwego/eino/components/model"
 	"github.com/cloudwego/eino/schema"
@@ -62,6 +63,12 @@ type ZhipuConfig struct {
 
 	// Timeout 请求超时时间
 	Timeout time.Duration
+
+	// MaxRepairs 响应不符合输出格式时的最大重新请求次数（0 为默认值 2，负数不重试）
+	MaxRepairs int
+
+	// UsageRecorder 每次收到响应后的用量回调（可选），为 nil 时不上报
+	UsageRecorder UsageRecorder
 }
 
 /**
@@ -98,6 +105,10 @@ func (c *ZhipuConfig) Validate() error {
 		c.Timeout = 30 * time.Second
 	}
 
+	if c.MaxRepairs == 0 {
+		c.MaxRepairs = DefaultMaxRepairs
+	}
+
 	return nil
 }
 
@@ -156,11 +167,13 @@ func NewZhipuClient(config *ZhipuConfig) (*ZhipuClient, error) {
  */
 func NewZhipuClientFromConfig(config *AIConfig) (AIModel, error) {
 	zhipuConfig := &ZhipuConfig{
-		APIKey:      config.APIKey,
-		Model:       config.Model,
-		BaseURL:     config.BaseURL,
-		MaxTokens:   config.MaxTokens,
-		Temperature: config.Temperature,
+		APIKey:        config.APIKey,
+		Model:         config.Model,
+		BaseURL:       config.BaseURL,
+		MaxTokens:     config.MaxTokens,
+		Temperature:   config.Temperature,
+		MaxRepairs:    config.MaxRepairs,
+		UsageRecorder: config.UsageRecorder,
 	}
 
 	// 设置超时
@@ -189,20 +202,34 @@ func (c *ZhipuClient) AnalyzePattern(ctx context.Context, patternData map[string
 	}
 
 	// 构建提示词
-	prompt := BuildPatternAnalysisPrompt(patternData)
-
-	// 准备消息
-	messages := []*schema.Message{
-		{
-			Role:    schema.System,
-			Content: "你是一个专业的自动化分析助手，擅长评估用户操作模式是否值得自动化。请以 JSON 格式返回分析结果。",
-		},
-		{
-			Role:    schema.User,
-			Content: prompt,
-		},
+	prompt := RenderPatternAnalysisPrompt(patternData)
+
+	// 调用智谱AI API，响应不符合格式时带着错误重新请求
+	analysis, err := AnalyzeWithRepair(patternUsageContext(ctx, patternData), c.generate, prompt, c.config.MaxRepairs)
+	if err != nil {
+		var outputErr *OutputError
+		if errors.As(err, &outputErr) {
+			logger.Error("解析智谱AI响应失败",
+				zap.String("response", outputErr.Content),
+				zap.Error(err))
+			return nil, fmt.Errorf("解析响应失败: %w", err)
+		}
+		return nil, err
 	}
 
+	return analysis, nil
+}
+
+/**
+ * generate 调用一次智谱AI API
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - messages: 对话消息
+ *
+ * Returns: string - 响应内容
+ */
+func (c *ZhipuClient) generate(ctx context.Context, messages []*schema.Message) (string, error) {
 	// 设置超时
 	ctx, cancel := context.WithTimeout(ctx, c.config.Timeout)
 	defer cancel()
@@ -213,10 +240,11 @@ func (c *ZhipuClient) AnalyzePattern(ctx context.Context, patternData map[string
 	duration := time.Since(startTime)
 
 	if err != nil {
+		metrics.ObserveAICall("zhipu", duration, 0, 0, err)
 		logger.Error("调用智谱AI API失败",
 			zap.Error(err),
 			zap.Duration("duration", duration))
-		return nil, fmt.Errorf("调用智谱AI API失败: %w", err)
+		return "", fmt.Errorf("调用智谱AI API失败: %w", err)
 	}
 
 	logger.Info("调用智谱AI API成功",
@@ -224,17 +252,14 @@ func (c *ZhipuClient) AnalyzePattern(ctx context.Context, patternData map[string
 		zap.Int("promptTokens", response.ResponseMeta.Usage.PromptTokens),
 		zap.Int("completionTokens", response.ResponseMeta.Usage.CompletionTokens),
 		zap.Int("totalTokens", response.ResponseMeta.Usage.TotalTokens))
-
-	// 解析响应
-	analysis, err := c.parseAnalysisResponse(response.Content)
-	if err != nil {
-		logger.Error("解析智谱AI响应失败",
-			zap.String("response", response.Content),
-			zap.Error(err))
-		return nil, fmt.Errorf("解析响应失败: %w", err)
-	}
-
-	return analysis, nil
+	metrics.ObserveAICall("zhipu", duration,
+		response.ResponseMeta.Usage.PromptTokens,
+		response.ResponseMeta.Usage.CompletionTokens, nil)
+	reportUsage(ctx, c.config.UsageRecorder, "zhipu", c.config.Model,
+		response.ResponseMeta.Usage.PromptTokens,
+		response.ResponseMeta.Usage.CompletionTokens, duration)
+
+	return response.Content, nil
 }
 
 /**
@@ -291,55 +316,6 @@ func (c *ZhipuClient) Close() error {
 	return nil
 }
 
-/**
- * parseAnalysisResponse 解析智谱AI响应
- */
-func (c *ZhipuClient) parseAnalysisResponse(content string) (*PatternAnalysis, error) {
-	jsonStr := content
-
-	// 移除可能的 markdown 代码块
-	if len(content) > 0 && content[0] == '`' {
-		start := 0
-		for i := 0; i < len(content)-3; i++ {
-			if content[i] == '`' && content[i+1] == '`' && content[i+2] == '`' {
-				start = i + 3
-				// 跳过语言标识符（如 "json", "python" 等）
-				for start < len(content) && (content[start] >= 'a' && content[start] <= 'z') ||
-					(content[start] >= 'A' && content[start] <= 'Z') ||
-					(content[start] >= '0' && content[start] <= '9') {
-					start++
-				}
-				// 跳过空白字符和换行符
-				for start < len(content) && (content[start] == '\n' || content[start] == '\r' || content[start] == ' ' || content[start] == '\t') {
-					start++
-				}
-				break
-			}
-		}
-
-		end := len(content)
-		for i := len(content) - 1; i >= 3; i-- {
-			if content[i] == '`' && content[i-1] == '`' && content[i-2] == '`' {
-				end = i - 2
-				break
-			}
-		}
-
-		if start > 0 && end > start {
-			jsonStr = content[start:end]
-		}
-	}
-
-	var analysis PatternAnalysis
-	err := json.Unmarshal([]byte(jsonStr), &analysis)
-	if err != nil {
-		return nil, fmt.Errorf("JSON 解析失败: %w", err)
-	}
-
-	analysis.AnalyzedAt = time.Now()
-	return &analysis, nil
-}
-
 /**
  * GetEnvOrDefault 获取环境变量或返回默认值
  */
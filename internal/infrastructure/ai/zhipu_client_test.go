@@ -298,8 +298,6 @@ func TestZhipuClient_AnalyzePattern(t *testing.T) {
 
 // TestZhipuClient_parseAnalysisResponse 测试响应解析
 func TestZhipuClient_parseAnalysisResponse(t *testing.T) {
-	client := &ZhipuClient{}
-
 	tests := []struct {
 		name        string
 		response    string
@@ -319,9 +317,14 @@ func TestZhipuClient_parseAnalysisResponse(t *testing.T) {
 		},
 		{
 			name:        "带Markdown代码块的响应",
-			response:    "```json\n{\"should_automate\": true, \"reason\": \"测试\", \"estimated_time_saving\": 30}\n```",
+			response:    "```json\n{\"should_automate\": true, \"reason\": \"测试\", \"estimated_time_saving\": 30, \"complexity\": \"low\", \"suggested_name\": \"测试\", \"suggested_steps\": []}\n```",
 			expectError: false,
 		},
+		{
+			name:        "缺少必填字段",
+			response:    "```json\n{\"should_automate\": true, \"reason\": \"测试\", \"estimated_time_saving\": 30}\n```",
+			expectError: true,
+		},
 		{
 			name:        "无效JSON",
 			response:    `{invalid json}`,
@@ -331,7 +334,7 @@ func TestZhipuClient_parseAnalysisResponse(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			analysis, err := client.parseAnalysisResponse(tt.response)
+			analysis, err := ParsePatternAnalysis(tt.response)
 
 			if tt.expectError {
 				assert.Error(t, err)
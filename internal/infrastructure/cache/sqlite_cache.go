@@ -0,0 +1,450 @@
+/**
+ * Package cache 缓存实现
+ *
+ * 提供基于 SQLite 的持久化缓存，重启后缓存仍然有效
+ */
+
+package cache
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * SQLiteCacheConfig SQLite 缓存配置
+ */
+type SQLiteCacheConfig struct {
+	// Namespace 键的命名空间，不同命名空间的缓存互不可见（如按提示词版本区分分析结果）
+	Namespace string
+
+	// MaxSize 命名空间内的最大缓存项数（0 表示无限制），超出时淘汰最久未访问的项
+	MaxSize int
+
+	// CleanupInterval 清理过期项的间隔（0 表示不定期清理）
+	CleanupInterval time.Duration
+
+	// NewValue 创建用于反序列化的值，Get 返回反序列化后的结果；
+	// 为 nil 时 Get 返回 json.RawMessage
+	NewValue func() interface{}
+}
+
+/**
+ * SQLiteCache SQLite 持久化缓存实现
+ *
+ * 特性：
+ * - 值以 JSON 保存在 cache_entries 表中，进程重启后仍然有效
+ * - TTL 支持
+ * - 按命名空间隔离，Clear 和 Count 只作用于当前命名空间
+ * - 超出大小上限时淘汰最久未访问的项
+ * - 定期清理所有命名空间的过期项
+ */
+type SQLiteCache struct {
+	// db 数据库连接，由调用方负责关闭
+	db *sql.DB
+
+	// config 缓存配置
+	config SQLiteCacheConfig
+
+	// stats 缓存统计
+	stats *CacheStats
+
+	// now 当前时间，测试时可替换
+	now func() time.Time
+
+	// ctx 上下文
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+
+	// stopped 是否已停止
+	stopped bool
+	mu      sync.RWMutex
+}
+
+/**
+ * NewSQLiteCache 创建 SQLite 缓存
+ *
+ * 数据库需要已执行迁移（cache_entries 表）
+ *
+ * Parameters:
+ *   - db: 数据库连接
+ *   - config: 缓存配置
+ *
+ * Returns: *SQLiteCache - SQLite 缓存实例
+ */
+func NewSQLiteCache(db *sql.DB, config SQLiteCacheConfig) *SQLiteCache {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	cache := &SQLiteCache{
+		db:     db,
+		config: config,
+		stats:  &CacheStats{},
+		now:    time.Now,
+		ctx:    ctx,
+		cancel: cancel,
+	}
+
+	if config.CleanupInterval > 0 {
+		cache.wg.Add(1)
+		go cache.cleanupLoop()
+	}
+
+	logger.Info("SQLite 缓存已启动",
+		zap.String("namespace", config.Namespace),
+		zap.Int("max_size", config.MaxSize),
+		zap.Duration("cleanup_interval", config.CleanupInterval))
+
+	return cache
+}
+
+/**
+ * Set 设置缓存值
+ *
+ * Parameters:
+ *   - key: 缓存键
+ *   - value: 缓存值，必须能序列化为 JSON
+ *   - ttl: 过期时间（0表示永不过期）
+ *
+ * Returns: error - 错误信息
+ */
+func (c *SQLiteCache) Set(key string, value interface{}, ttl time.Duration) error {
+	if c.isStopped() {
+		return fmt.Errorf("缓存已停止")
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("序列化缓存值失败: %w", err)
+	}
+
+	now := c.now()
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = now.Add(ttl).UnixMilli()
+	}
+
+	// 新键超出大小上限时先淘汰
+	if c.config.MaxSize > 0 && !c.Exists(key) {
+		if err := c.evict(c.Count() - c.config.MaxSize + 1); err != nil {
+			return err
+		}
+	}
+
+	_, err = c.db.Exec(`
+		INSERT INTO cache_entries (namespace, key, value, expires_at, created_at, accessed_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+		ON CONFLICT(namespace, key) DO UPDATE SET
+			value = excluded.value,
+			expires_at = excluded.expires_at,
+			created_at = excluded.created_at,
+			accessed_at = excluded.accessed_at
+	`, c.config.Namespace, key, data, expiresAt, now.UnixMilli(), now.UnixMilli())
+	if err != nil {
+		return fmt.Errorf("写入缓存失败: %w", err)
+	}
+	c.stats.RecordSet()
+
+	logger.Debug("缓存已设置",
+		zap.String("namespace", c.config.Namespace),
+		zap.String("key", key),
+		zap.Duration("ttl", ttl))
+
+	return nil
+}
+
+/**
+ * Get 获取缓存值
+ *
+ * Parameters:
+ *   - key: 缓存键
+ *
+ * Returns: interface{} - 缓存值, bool - 是否找到
+ */
+func (c *SQLiteCache) Get(key string) (interface{}, bool) {
+	if c.isStopped() {
+		return nil, false
+	}
+
+	var data []byte
+	var expiresAt int64
+	err := c.db.QueryRow(`
+		SELECT value, expires_at FROM cache_entries WHERE namespace = ? AND key = ?
+	`, c.config.Namespace, key).Scan(&data, &expiresAt)
+	if err != nil {
+		if !errors.Is(err, sql.ErrNoRows) {
+			logger.Warn("读取缓存失败", zap.String("key", key), zap.Error(err))
+		}
+		c.stats.RecordMiss()
+		return nil, false
+	}
+
+	now := c.now()
+	if expiresAt > 0 && now.UnixMilli() >= expiresAt {
+		c.remove(key)
+		c.stats.RecordMiss()
+		c.stats.RecordEviction()
+		return nil, false
+	}
+
+	value, err := c.decode(data)
+	if err != nil {
+		// 值的结构已变化，视为未命中并删除
+		logger.Warn("反序列化缓存值失败", zap.String("key", key), zap.Error(err))
+		c.remove(key)
+		c.stats.RecordMiss()
+		return nil, false
+	}
+
+	if _, err := c.db.Exec(`
+		UPDATE cache_entries SET accessed_at = ? WHERE namespace = ? AND key = ?
+	`, now.UnixMilli(), c.config.Namespace, key); err != nil {
+		logger.Debug("更新缓存访问时间失败", zap.String("key", key), zap.Error(err))
+	}
+
+	c.stats.RecordHit()
+	return value, true
+}
+
+/**
+ * Delete 删除缓存
+ *
+ * Parameters:
+ *   - key: 缓存键
+ *
+ * Returns: error - 错误信息
+ */
+func (c *SQLiteCache) Delete(key string) error {
+	if c.isStopped() {
+		return fmt.Errorf("缓存已停止")
+	}
+
+	if _, err := c.db.Exec(`
+		DELETE FROM cache_entries WHERE namespace = ? AND key = ?
+	`, c.config.Namespace, key); err != nil {
+		return fmt.Errorf("删除缓存失败: %w", err)
+	}
+	c.stats.RecordDelete()
+
+	logger.Debug("缓存已删除", zap.String("key", key))
+	return nil
+}
+
+/**
+ * Clear 清空当前命名空间的缓存
+ *
+ * Returns: error - 错误信息
+ */
+func (c *SQLiteCache) Clear() error {
+	if c.isStopped() {
+		return fmt.Errorf("缓存已停止")
+	}
+
+	if _, err := c.db.Exec(`
+		DELETE FROM cache_entries WHERE namespace = ?
+	`, c.config.Namespace); err != nil {
+		return fmt.Errorf("清空缓存失败: %w", err)
+	}
+
+	logger.Info("缓存已清空", zap.String("namespace", c.config.Namespace))
+	return nil
+}
+
+/**
+ * Exists 检查键是否存在且未过期
+ *
+ * Parameters:
+ *   - key: 缓存键
+ *
+ * Returns: bool - 是否存在
+ */
+func (c *SQLiteCache) Exists(key string) bool {
+	if c.isStopped() {
+		return false
+	}
+
+	var count int
+	err := c.db.QueryRow(`
+		SELECT COUNT(*) FROM cache_entries
+		WHERE namespace = ? AND key = ? AND (expires_at = 0 OR expires_at > ?)
+	`, c.config.Namespace, key, c.now().UnixMilli()).Scan(&count)
+	if err != nil {
+		logger.Warn("检查缓存失败", zap.String("key", key), zap.Error(err))
+		return false
+	}
+	return count > 0
+}
+
+/**
+ * Count 获取当前命名空间中未过期的缓存项数量
+ *
+ * Returns: int - 缓存项数量
+ */
+func (c *SQLiteCache) Count() int {
+	var count int
+	err := c.db.QueryRow(`
+		SELECT COUNT(*) FROM cache_entries
+		WHERE namespace = ? AND (expires_at = 0 OR expires_at > ?)
+	`, c.config.Namespace, c.now().UnixMilli()).Scan(&count)
+	if err != nil {
+		logger.Warn("统计缓存失败", zap.Error(err))
+		return 0
+	}
+	return count
+}
+
+/**
+ * GetStats 获取缓存统计信息
+ *
+ * Returns: *CacheStats - 统计信息
+ */
+func (c *SQLiteCache) GetStats() *CacheStats {
+	return c.stats
+}
+
+/**
+ * decode 反序列化缓存值
+ */
+func (c *SQLiteCache) decode(data []byte) (interface{}, error) {
+	if c.config.NewValue == nil {
+		return json.RawMessage(data), nil
+	}
+	value := c.config.NewValue()
+	if err := json.Unmarshal(data, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+/**
+ * remove 删除缓存项，失败时只记录日志
+ */
+func (c *SQLiteCache) remove(key string) {
+	if _, err := c.db.Exec(`
+		DELETE FROM cache_entries WHERE namespace = ? AND key = ?
+	`, c.config.Namespace, key); err != nil {
+		logger.Debug("删除缓存项失败", zap.String("key", key), zap.Error(err))
+	}
+}
+
+/**
+ * evict 淘汰当前命名空间中的过期项和最久未访问的项
+ *
+ * Parameters:
+ *   - n: 需要腾出的位置数，小于等于 0 时只清理过期项
+ *
+ * Returns: error - 错误信息
+ */
+func (c *SQLiteCache) evict(n int) error {
+	now := c.now().UnixMilli()
+	result, err := c.db.Exec(`
+		DELETE FROM cache_entries WHERE namespace = ? AND expires_at > 0 AND expires_at <= ?
+	`, c.config.Namespace, now)
+	if err != nil {
+		return fmt.Errorf("清理过期缓存失败: %w", err)
+	}
+	expired, _ := result.RowsAffected()
+	n -= int(expired)
+	if n <= 0 {
+		return nil
+	}
+
+	result, err = c.db.Exec(`
+		DELETE FROM cache_entries WHERE namespace = ? AND key IN (
+			SELECT key FROM cache_entries WHERE namespace = ?
+			ORDER BY accessed_at ASC LIMIT ?
+		)
+	`, c.config.Namespace, c.config.Namespace, n)
+	if err != nil {
+		return fmt.Errorf("淘汰缓存失败: %w", err)
+	}
+	evicted, _ := result.RowsAffected()
+	for i := int64(0); i < evicted; i++ {
+		c.stats.RecordEviction()
+	}
+
+	logger.Debug("LRU 淘汰缓存项",
+		zap.String("namespace", c.config.Namespace),
+		zap.Int64("count", evicted))
+	return nil
+}
+
+/**
+ * cleanupLoop 定期清理过期缓存
+ */
+func (c *SQLiteCache) cleanupLoop() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(c.config.CleanupInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			c.cleanup()
+		case <-c.ctx.Done():
+			return
+		}
+	}
+}
+
+/**
+ * cleanup 清理所有命名空间的过期缓存
+ *
+ * 旧提示词版本的命名空间不再被访问，过期后由这里删除
+ */
+func (c *SQLiteCache) cleanup() {
+	result, err := c.db.Exec(`
+		DELETE FROM cache_entries WHERE expires_at > 0 AND expires_at <= ?
+	`, c.now().UnixMilli())
+	if err != nil {
+		logger.Warn("清理过期缓存失败", zap.Error(err))
+		return
+	}
+
+	if deleted, _ := result.RowsAffected(); deleted > 0 {
+		for i := int64(0); i < deleted; i++ {
+			c.stats.RecordEviction()
+		}
+		logger.Debug("清理过期缓存", zap.Int64("count", deleted))
+	}
+}
+
+/**
+ * isStopped 检查缓存是否已停止
+ */
+func (c *SQLiteCache) isStopped() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.stopped
+}
+
+/**
+ * Stop 停止缓存
+ *
+ * 停止清理循环，缓存内容保留在数据库中，数据库连接由调用方关闭
+ */
+func (c *SQLiteCache) Stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.stopped {
+		return
+	}
+
+	c.cancel()
+	c.wg.Wait()
+	c.stopped = true
+
+	logger.Info("SQLite 缓存已停止",
+		zap.String("namespace", c.config.Namespace),
+		zap.Float64("hit_rate", c.stats.HitRate()))
+}
@@ -0,0 +1,183 @@
+package cache
+
+import (
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEntry struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+/**
+ * newTestDB 创建已执行迁移的临时数据库
+ */
+func newTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/cache.db"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, storage.RunMigrations(db))
+	return db
+}
+
+/**
+ * newTestSQLiteCache 创建时间可控的 SQLite 缓存
+ */
+func newTestSQLiteCache(t *testing.T, db *sql.DB, config SQLiteCacheConfig, now *time.Time) *SQLiteCache {
+	t.Helper()
+
+	if config.NewValue == nil {
+		config.NewValue = func() interface{} { return &testEntry{} }
+	}
+	cache := NewSQLiteCache(db, config)
+	cache.now = func() time.Time { return *now }
+	t.Cleanup(cache.Stop)
+	return cache
+}
+
+/**
+ * TestSQLiteCache_Persistence 测试缓存在新实例中仍然有效
+ */
+func TestSQLiteCache_Persistence(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+
+	cache := newTestSQLiteCache(t, db, SQLiteCacheConfig{Namespace: "v1"}, &now)
+	require.NoError(t, cache.Set("key1", &testEntry{Name: "a", Count: 1}, 0))
+	cache.Stop()
+
+	// 停止后不可用
+	_, found := cache.Get("key1")
+	assert.False(t, found)
+	assert.Error(t, cache.Set("key2", &testEntry{}, 0))
+
+	reopened := newTestSQLiteCache(t, db, SQLiteCacheConfig{Namespace: "v1"}, &now)
+	value, found := reopened.Get("key1")
+	require.True(t, found)
+	assert.Equal(t, &testEntry{Name: "a", Count: 1}, value)
+
+	// 覆盖已有键
+	require.NoError(t, reopened.Set("key1", &testEntry{Name: "b"}, 0))
+	value, _ = reopened.Get("key1")
+	assert.Equal(t, &testEntry{Name: "b"}, value)
+	assert.Equal(t, 1, reopened.Count())
+
+	_, found = reopened.Get("missing")
+	assert.False(t, found)
+	hits, misses, _, _, _ := reopened.GetStats().GetStats()
+	assert.Equal(t, int64(2), hits)
+	assert.Equal(t, int64(1), misses)
+}
+
+/**
+ * TestSQLiteCache_Expiration 测试 TTL 过期和定期清理
+ */
+func TestSQLiteCache_Expiration(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+	cache := newTestSQLiteCache(t, db, SQLiteCacheConfig{Namespace: "v1"}, &now)
+
+	require.NoError(t, cache.Set("short", &testEntry{Name: "short"}, time.Minute))
+	require.NoError(t, cache.Set("long", &testEntry{Name: "long"}, time.Hour))
+	assert.True(t, cache.Exists("short"))
+
+	now = now.Add(2 * time.Minute)
+	assert.False(t, cache.Exists("short"))
+	assert.Equal(t, 1, cache.Count())
+	_, found := cache.Get("short")
+	assert.False(t, found)
+
+	// 清理所有命名空间中的过期项
+	other := newTestSQLiteCache(t, db, SQLiteCacheConfig{Namespace: "v0"}, &now)
+	require.NoError(t, other.Set("old", &testEntry{}, time.Minute))
+	now = now.Add(2 * time.Hour)
+	cache.cleanup()
+
+	var rows int
+	require.NoError(t, db.QueryRow("SELECT COUNT(*) FROM cache_entries").Scan(&rows))
+	assert.Equal(t, 0, rows)
+}
+
+/**
+ * TestSQLiteCache_MaxSize 测试超出大小上限时淘汰最久未访问的项
+ */
+func TestSQLiteCache_MaxSize(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+	cache := newTestSQLiteCache(t, db, SQLiteCacheConfig{Namespace: "v1", MaxSize: 2}, &now)
+
+	require.NoError(t, cache.Set("key1", &testEntry{Count: 1}, 0))
+	now = now.Add(time.Second)
+	require.NoError(t, cache.Set("key2", &testEntry{Count: 2}, 0))
+	now = now.Add(time.Second)
+
+	// 访问 key1，key2 成为最久未访问的项
+	_, found := cache.Get("key1")
+	require.True(t, found)
+	now = now.Add(time.Second)
+
+	require.NoError(t, cache.Set("key3", &testEntry{Count: 3}, 0))
+	assert.Equal(t, 2, cache.Count())
+	assert.True(t, cache.Exists("key1"))
+	assert.False(t, cache.Exists("key2"))
+	assert.True(t, cache.Exists("key3"))
+	_, _, _, _, evictions := cache.GetStats().GetStats()
+	assert.Equal(t, int64(1), evictions)
+
+	// 更新已有键不淘汰
+	require.NoError(t, cache.Set("key3", &testEntry{Count: 4}, 0))
+	assert.Equal(t, 2, cache.Count())
+}
+
+/**
+ * TestSQLiteCache_Namespace 测试命名空间隔离
+ */
+func TestSQLiteCache_Namespace(t *testing.T) {
+	db := newTestDB(t)
+	now := time.Now()
+	v1 := newTestSQLiteCache(t, db, SQLiteCacheConfig{Namespace: "v1"}, &now)
+	v2 := newTestSQLiteCache(t, db, SQLiteCacheConfig{Namespace: "v2"}, &now)
+
+	require.NoError(t, v1.Set("key", &testEntry{Name: "v1"}, 0))
+	_, found := v2.Get("key")
+	assert.False(t, found)
+
+	require.NoError(t, v2.Set("key", &testEntry{Name: "v2"}, 0))
+	require.NoError(t, v2.Set("other", &testEntry{}, 0))
+	value, _ := v1.Get("key")
+	assert.Equal(t, "v1", value.(*testEntry).Name)
+
+	// 删除和清空只作用于自己的命名空间
+	require.NoError(t, v1.Delete("key"))
+	assert.True(t, v2.Exists("key"))
+	require.NoError(t, v1.Set("key", &testEntry{}, 0))
+	require.NoError(t, v2.Clear())
+	assert.Equal(t, 0, v2.Count())
+	assert.Equal(t, 1, v1.Count())
+}
+
+/**
+ * TestSQLiteCache_RawValue 测试未指定值类型时返回原始 JSON
+ */
+func TestSQLiteCache_RawValue(t *testing.T) {
+	db := newTestDB(t)
+	cache := NewSQLiteCache(db, SQLiteCacheConfig{Namespace: "raw"})
+	defer cache.Stop()
+
+	require.NoError(t, cache.Set("key", map[string]int{"a": 1}, 0))
+	value, found := cache.Get("key")
+	require.True(t, found)
+	assert.JSONEq(t, `{"a":1}`, string(value.(json.RawMessage)))
+
+	// 无法序列化的值
+	assert.Error(t, cache.Set("bad", make(chan int), 0))
+}
@@ -42,6 +42,15 @@ type Config struct {
 
 	// Logging 日志配置
 	Logging LoggingConfig `yaml:"logging"`
+
+	// Metrics 指标端点配置
+	Metrics MetricsConfig `yaml:"metrics"`
+
+	// API 本地 HTTP API 配置
+	API APIConfig `yaml:"api"`
+
+	// MCP Model Context Protocol 服务配置
+	MCP MCPConfig `yaml:"mcp"`
 }
 
 /**
@@ -68,14 +77,96 @@ type MonitorConfig struct {
 	/** 启用的监控器列表 */
 	EnabledMonitors []string `yaml:"enabled_monitors"`
 
-	/** 采样率 */
-	SampleRate string `yaml:"sample_rate"`
-
 	/** 事件缓冲区大小 */
 	EventBufferSize int `yaml:"event_buffer_size"`
 
 	/** 过滤器配置 */
 	Filters FilterConfig `yaml:"filters"`
+
+	/** 文件系统监控配置 */
+	FileSystem FileSystemConfig `yaml:"filesystem"`
+
+	/** 剪贴板监控配置 */
+	Clipboard ClipboardConfig `yaml:"clipboard"`
+
+	/** 敏感内容脱敏配置 */
+	Redaction RedactionConfig `yaml:"redaction"`
+
+	/** 按应用的隐私规则配置 */
+	Privacy PrivacyConfig `yaml:"privacy"`
+}
+
+/**
+ * PrivacyConfig 隐私规则配置
+ */
+type PrivacyConfig struct {
+	/** 未命中任何规则时的采集级别：none、metadata、full（默认 full） */
+	DefaultLevel string `yaml:"default_level"`
+
+	/** 是否禁用内置规则（密码管理器、浏览器隐私窗口） */
+	DisableDefaults bool `yaml:"disable_defaults"`
+
+	/** 隐私规则列表，按顺序匹配，优先于内置规则 */
+	Rules []PrivacyRuleConfig `yaml:"rules"`
+}
+
+/**
+ * PrivacyRuleConfig 单条隐私规则配置
+ */
+type PrivacyRuleConfig struct {
+	/** 应用 Bundle ID（精确匹配，不区分大小写） */
+	BundleID string `yaml:"bundle_id"`
+
+	/** 应用名称（精确匹配，不区分大小写） */
+	App string `yaml:"app"`
+
+	/** 窗口标题正则表达式（不区分大小写） */
+	WindowTitle string `yaml:"window_title"`
+
+	/** 采集级别：none、metadata、full */
+	Level string `yaml:"level"`
+}
+
+/**
+ * RedactionConfig 敏感内容脱敏配置
+ */
+type RedactionConfig struct {
+	/** 检测器动作覆盖（检测器名称 -> drop/mask/hash/off），未配置的检测器使用默认动作 */
+	Detectors map[string]string `yaml:"detectors"`
+
+	/** 高熵字符串检测阈值（比特/字符） */
+	EntropyThreshold float64 `yaml:"entropy_threshold"`
+
+	/** 高熵字符串检测的最小长度 */
+	EntropyMinLength int `yaml:"entropy_min_length"`
+
+	/** hash 动作使用的盐值，为空时使用 HashSaltFile 中的盐值 */
+	HashSalt string `yaml:"hash_salt"`
+
+	/** 盐值文件，文件不存在时生成随机盐值并保存；为空时每次启动使用新的随机盐值 */
+	HashSaltFile string `yaml:"hash_salt_file"`
+}
+
+/**
+ * FileSystemConfig 文件系统监控配置
+ */
+type FileSystemConfig struct {
+	/** 递归监控的根目录列表 */
+	Roots []string `yaml:"roots"`
+
+	/** 忽略的路径模式（glob） */
+	IgnorePatterns []string `yaml:"ignore_patterns"`
+
+	/** 写入合并窗口 */
+	CoalesceWindow string `yaml:"coalesce_window"`
+}
+
+/**
+ * ClipboardConfig 剪贴板监控配置
+ */
+type ClipboardConfig struct {
+	/** 剪贴板轮询间隔 */
+	PollInterval string `yaml:"poll_interval"`
 }
 
 /**
@@ -99,11 +190,88 @@ type AIConfig struct {
 	/** Claude 配置 */
 	Claude ClaudeConfig `yaml:"claude"`
 
+	/** OpenAI 及兼容服务配置 */
+	OpenAI OpenAIConfig `yaml:"openai"`
+
 	/** Ollama 配置 */
 	Ollama OllamaConfig `yaml:"ollama"`
 
 	/** 缓存配置 */
 	Cache CacheConfig `yaml:"cache"`
+
+	/** 降级顺序，provider 不可用时依次尝试这些提供商，如 ["openai", "ollama"] */
+	Fallback []string `yaml:"fallback"`
+
+	/** 重试和熔断配置 */
+	Resilience ResilienceConfig `yaml:"resilience"`
+
+	/** 录制回放配置，provider 为 replay 时生效 */
+	Replay ReplayConfig `yaml:"replay"`
+
+	/** 提示词模板目录，其中的模板覆盖或补充内置模板 */
+	TemplatesDir string `yaml:"templates_dir"`
+
+	/** 提示词语言（zh、en） */
+	PromptLanguage string `yaml:"prompt_language"`
+
+	/** 提示词模板版本，为空时使用最新版本 */
+	PromptVersion string `yaml:"prompt_version"`
+
+	/** 响应不符合输出 Schema 时带着错误重新请求的次数，负数不重试 */
+	MaxRepairs int `yaml:"max_repairs"`
+
+	/** 花费预算和模型价格 */
+	Budget BudgetConfig `yaml:"budget"`
+}
+
+/**
+ * BudgetConfig AI 花费预算配置
+ */
+type BudgetConfig struct {
+	/** 每日预算（美元），0 表示不限制 */
+	DailyUSD float64 `yaml:"daily_usd"`
+
+	/** 每月预算（美元），0 表示不限制 */
+	MonthlyUSD float64 `yaml:"monthly_usd"`
+
+	/** 按模型名覆盖或补充内置价格 */
+	Prices map[string]PriceConfig `yaml:"prices"`
+}
+
+/**
+ * PriceConfig 模型价格，单位为美元每百万 token
+ */
+type PriceConfig struct {
+	/** 输入价格 */
+	Input float64 `yaml:"input"`
+
+	/** 输出价格 */
+	Output float64 `yaml:"output"`
+}
+
+/**
+ * ReplayConfig 录制回放配置
+ */
+type ReplayConfig struct {
+	/** 夹具目录 */
+	Dir string `yaml:"dir"`
+
+	/** 录制时调用的真实提供商，为空时只回放已录制的响应 */
+	RecordFrom string `yaml:"record_from"`
+}
+
+/**
+ * ResilienceConfig AI 调用的重试和熔断配置
+ */
+type ResilienceConfig struct {
+	/** 遇到 429/5xx 时对同一提供商的最大重试次数 */
+	MaxRetries int `yaml:"max_retries"`
+
+	/** 触发熔断的连续失败次数 */
+	FailureThreshold int `yaml:"failure_threshold"`
+
+	/** 熔断后的冷却时间，结束后放行一个探测请求 */
+	OpenTimeout string `yaml:"open_timeout"`
 }
 
 /**
@@ -123,6 +291,26 @@ type ClaudeConfig struct {
 	Temperature float64 `yaml:"temperature"`
 }
 
+/**
+ * OpenAIConfig OpenAI 兼容接口配置
+ */
+type OpenAIConfig struct {
+	/** API 密钥，本地兼容服务可以为空 */
+	APIKey string `yaml:"api_key"`
+
+	/** 基础 URL，为空时使用 OpenAI 官方 API */
+	BaseURL string `yaml:"base_url"`
+
+	/** 使用的模型 */
+	Model string `yaml:"model"`
+
+	/** 最大 token 数 */
+	MaxTokens int `yaml:"max_tokens"`
+
+	/** 是否要求 JSON 对象格式响应，未设置时开启 */
+	JSONMode *bool `yaml:"json_mode"`
+}
+
 /**
  * OllamaConfig Ollama 配置
  */
@@ -132,6 +320,15 @@ type OllamaConfig struct {
 
 	/** 使用的模型 */
 	Model string `yaml:"model"`
+
+	/** 最大生成 token 数 */
+	MaxTokens int `yaml:"max_tokens"`
+
+	/** 模型未下载时是否自动拉取 */
+	AutoPull bool `yaml:"auto_pull"`
+
+	/** 是否使用 format: json 约束输出，未设置时开启 */
+	JSONMode *bool `yaml:"json_mode"`
 }
 
 /**
@@ -146,6 +343,9 @@ type CacheConfig struct {
 
 	/** 最大缓存数量 */
 	MaxSize int `yaml:"max_size"`
+
+	/** 是否把 AI 分析结果持久化到 SQLite，重启后相同的模式不再重新分析 */
+	Persistent bool `yaml:"persistent"`
 }
 
 /**
@@ -403,6 +603,54 @@ type FileConfig struct {
 	Compress bool `yaml:"compress"`
 }
 
+/**
+ * MetricsConfig 指标端点配置
+ */
+type MetricsConfig struct {
+	/** 是否启用 Prometheus 指标端点 */
+	Enabled bool `yaml:"enabled"`
+
+	/** 监听地址，只允许本机回环地址 */
+	Addr string `yaml:"addr"`
+}
+
+/**
+ * APIConfig 本地 HTTP API 配置
+ */
+type APIConfig struct {
+	/** 是否启用本地 HTTP API */
+	Enabled bool `yaml:"enabled"`
+
+	/** 监听地址，只允许本机回环地址 */
+	Addr string `yaml:"addr"`
+
+	/** 访问令牌，为空时自动生成并保存到 TokenFile */
+	Token string `yaml:"token"`
+
+	/** 自动生成的令牌保存路径 */
+	TokenFile string `yaml:"token_file"`
+}
+
+/**
+ * MCPConfig Model Context Protocol 服务配置
+ */
+type MCPConfig struct {
+	/** 是否随应用和 daemon 启动本机 HTTP 传输（stdio 传输由 flowmind mcp 命令提供） */
+	Enabled bool `yaml:"enabled"`
+
+	/** HTTP 传输的监听地址，只允许本机回环地址 */
+	Addr string `yaml:"addr"`
+
+	/** HTTP 传输的访问令牌，为空时自动生成并保存到 TokenFile */
+	Token string `yaml:"token"`
+
+	/** 自动生成的令牌保存路径 */
+	TokenFile string `yaml:"token_file"`
+
+	/** 授予的只读权限范围，未授予范围的工具和资源不会暴露给客户端 */
+	Scopes []string `yaml:"scopes"`
+}
+
 /**
  * Load 加载配置文件
  *
@@ -427,6 +675,20 @@ func Load() (*Config, error) {
 		return LoadDefault()
 	}
 
+	return LoadFile(configPath)
+}
+
+/**
+ * LoadFile 从指定路径加载配置文件
+ *
+ * Parameters:
+ *   - configPath: 配置文件路径
+ *
+ * Returns:
+ *   - *Config: 加载的配置
+ *   - error: 文件不存在或解析失败时返回错误
+ */
+func LoadFile(configPath string) (*Config, error) {
 	// 读取配置文件
 	data, err := os.ReadFile(configPath)
 	if err != nil {
@@ -461,6 +723,29 @@ func LoadDefault() (*Config, error) {
 			Version:  "1.0.0",
 			LogLevel: "info",
 		},
+		Monitor: MonitorConfig{
+			EnabledMonitors: []string{"keyboard", "clipboard", "application"},
+			EventBufferSize: 1000,
+			Filters: FilterConfig{
+				IgnoreApps:         []string{"com.apple.ScreenSaver", "com.apple.systemuiserver"},
+				IgnoreWindowTitles: []string{"Screen Saver"},
+			},
+			Redaction: RedactionConfig{
+				HashSaltFile: "${HOME}/.flowmind/hash_salt",
+			},
+		},
+		Metrics: MetricsConfig{
+			Addr: "127.0.0.1:9464",
+		},
+		API: APIConfig{
+			Addr:      "127.0.0.1:9465",
+			TokenFile: "${HOME}/.flowmind/api_token",
+		},
+		MCP: MCPConfig{
+			Addr:      "127.0.0.1:9466",
+			TokenFile: "${HOME}/.flowmind/mcp_token",
+			Scopes:    []string{"events:read", "patterns:read", "app_usage:read", "sessions:read"},
+		},
 	}, nil
 }
 
@@ -59,6 +59,37 @@ func InitLogger() error {
 	return initErr
 }
 
+// InitCLILogger 初始化命令行日志
+//
+// 命令行工具的标准输出用于命令结果，日志统一写到标准错误：
+//   - 控制台格式，不带颜色
+//   - 级别由参数指定，LOG_LEVEL 环境变量优先
+//
+// 必须在第一次记录日志之前调用，否则使用已初始化的日志配置。
+//
+// Parameters:
+//   - level: 默认日志级别
+func InitCLILogger(level string) {
+	once.Do(func() {
+		atomicLevel, err := zapcore.ParseLevel(getEnv("LOG_LEVEL", level))
+		if err != nil {
+			atomicLevel = zapcore.WarnLevel
+		}
+
+		encoderConfig := zap.NewDevelopmentEncoderConfig()
+		encoderConfig.EncodeTime = zapcore.TimeEncoderOfLayout("2006-01-02 15:04:05.999")
+		encoderConfig.EncodeCaller = nil
+		encoderConfig.CallerKey = ""
+
+		logger = zap.New(zapcore.NewCore(
+			zapcore.NewConsoleEncoder(encoderConfig),
+			zapcore.Lock(os.Stderr),
+			zap.NewAtomicLevelAt(atomicLevel),
+		))
+		sugar = logger.Sugar()
+	})
+}
+
 // initDevelopmentLogger 初始化开发环境日志
 //
 // 开发环境配置：
@@ -0,0 +1,196 @@
+package metrics
+
+import (
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/cache"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+)
+
+/**
+ * Default 默认指标注册表
+ *
+ * 下面的管道指标都注册在这里，组件直接记录，不需要传递注册表
+ */
+var Default = NewRegistry()
+
+var (
+	// EventsPublished 按事件类型统计发布的事件数
+	EventsPublished = Default.NewCounterVec("flowmind_events_published_total",
+		"Events published on the event bus.", "type")
+
+	// HandlerDuration 订阅者处理函数的耗时
+	HandlerDuration = Default.NewHistogramVec("flowmind_event_handler_duration_seconds",
+		"Event handler latency per subscriber.", nil, "subscriber")
+
+	// HandlerErrors 订阅者处理函数返回错误的次数
+	HandlerErrors = Default.NewCounterVec("flowmind_event_handler_errors_total",
+		"Event handler errors per subscriber, including retried attempts.", "subscriber")
+
+	// AICallDuration AI 调用耗时
+	AICallDuration = Default.NewHistogramVec("flowmind_ai_call_duration_seconds",
+		"AI model call latency.", nil, "provider", "status")
+
+	// AITokens AI 调用消耗的 token 数
+	AITokens = Default.NewCounterVec("flowmind_ai_tokens_total",
+		"Tokens consumed by AI model calls.", "provider", "kind")
+
+	// AnalysisDuration 一次模式分析的耗时
+	AnalysisDuration = Default.NewHistogramVec("flowmind_analysis_duration_seconds",
+		"Pattern analysis run duration.", nil, "status")
+)
+
+/**
+ * ObserveAICall 记录一次 AI 调用
+ *
+ * Parameters:
+ *   - provider: 模型提供方，如 claude、zhipu
+ *   - duration: 调用耗时
+ *   - promptTokens: 输入 token 数
+ *   - completionTokens: 输出 token 数
+ *   - err: 调用错误
+ */
+func ObserveAICall(provider string, duration time.Duration, promptTokens, completionTokens int, err error) {
+	AICallDuration.Observe(duration.Seconds(), provider, status(err))
+	AITokens.Add(float64(promptTokens), provider, "prompt")
+	AITokens.Add(float64(completionTokens), provider, "completion")
+}
+
+/**
+ * ObserveAnalysis 记录一次模式分析
+ *
+ * Parameters:
+ *   - duration: 分析耗时
+ *   - err: 分析错误
+ */
+func ObserveAnalysis(duration time.Duration, err error) {
+	AnalysisDuration.Observe(duration.Seconds(), status(err))
+}
+
+func status(err error) string {
+	if err != nil {
+		return "error"
+	}
+	return "ok"
+}
+
+/**
+ * BusObserver 把事件总线的发布和处理记录到默认注册表
+ *
+ * 通过 events.WithObserver 设置
+ */
+type BusObserver struct{}
+
+/**
+ * ObservePublish 记录发布的事件
+ */
+func (BusObserver) ObservePublish(eventType string, event events.Event) {
+	EventsPublished.Inc(eventTypeLabel(string(event.Type)))
+}
+
+/**
+ * eventTypeLabel 事件类型标签，每个请求独立的应答主题 _reply.<uuid> 合并为 _reply
+ */
+func eventTypeLabel(eventType string) string {
+	if strings.HasPrefix(eventType, events.ReplyTopicPrefix) {
+		return strings.TrimSuffix(events.ReplyTopicPrefix, ".")
+	}
+	return eventType
+}
+
+/**
+ * ObserveHandle 记录处理耗时和错误
+ */
+func (BusObserver) ObserveHandle(subscriber string, event events.Event, duration time.Duration, err error) {
+	HandlerDuration.Observe(duration.Seconds(), subscriber)
+	if err != nil {
+		HandlerErrors.Inc(subscriber)
+	}
+}
+
+/**
+ * BusCollector 采集事件总线订阅者的缓冲区和交付统计
+ *
+ * Parameters:
+ *   - bus: 事件总线
+ *
+ * Returns:
+ *   - Collector: 采集函数
+ */
+func BusCollector(bus *events.EventBus) Collector {
+	return func(c *Collection) {
+		for _, stats := range bus.Stats() {
+			labels := []Label{{"subscriber", stats.Name}, {"topic", stats.Topic}}
+			c.Gauge("flowmind_subscriber_buffer_depth", "Events queued but not yet delivered to the subscriber.", float64(stats.Lagging), labels...)
+			c.Gauge("flowmind_subscriber_buffer_size", "Subscriber buffer capacity.", float64(stats.BufferSize), labels...)
+			c.Counter("flowmind_subscriber_delivered_total", "Events delivered to the subscriber.", float64(stats.Delivered), labels...)
+			c.Counter("flowmind_subscriber_dropped_total", "Events dropped because the subscriber buffer was full.", float64(stats.Dropped), labels...)
+			c.Counter("flowmind_subscriber_coalesced_total", "Events replaced by a newer event with the same key.", float64(stats.Coalesced), labels...)
+			c.Counter("flowmind_subscriber_retried_total", "Handler retries.", float64(stats.Retried), labels...)
+			c.Counter("flowmind_subscriber_dead_lettered_total", "Events moved to the dead letter store.", float64(stats.DeadLettered), labels...)
+		}
+	}
+}
+
+/**
+ * BatchWriterCollector 采集批量写入器的统计
+ *
+ * Parameters:
+ *   - writer: 批量写入器
+ *
+ * Returns:
+ *   - Collector: 采集函数
+ */
+func BatchWriterCollector(writer *storage.BatchWriter) Collector {
+	return func(c *Collection) {
+		total, persisted, failed, latency := writer.GetStats().Snapshot()
+		c.Gauge("flowmind_batch_writer_buffered_events", "Events buffered in the batch writer.", float64(writer.GetBufferSize()))
+		c.Counter("flowmind_batch_writer_received_total", "Events received by the batch writer.", float64(total))
+		c.Counter("flowmind_batch_writer_persisted_total", "Events written to the database.", float64(persisted))
+		c.Counter("flowmind_batch_writer_failed_total", "Events in failed batch writes.", float64(failed))
+		c.Gauge("flowmind_batch_writer_average_latency_seconds", "Average batch write latency.", latency.Seconds())
+	}
+}
+
+/**
+ * CacheCollector 采集缓存统计
+ *
+ * Parameters:
+ *   - name: 缓存名称，作为 cache 标签
+ *   - stats: 缓存统计
+ *
+ * Returns:
+ *   - Collector: 采集函数
+ */
+func CacheCollector(name string, stats *cache.CacheStats) Collector {
+	return func(c *Collection) {
+		hits, misses, sets, deletes, evictions := stats.GetStats()
+		label := Label{"cache", name}
+		c.Counter("flowmind_cache_hits_total", "Cache hits.", float64(hits), label)
+		c.Counter("flowmind_cache_misses_total", "Cache misses.", float64(misses), label)
+		c.Counter("flowmind_cache_sets_total", "Cache writes.", float64(sets), label)
+		c.Counter("flowmind_cache_deletes_total", "Cache deletes.", float64(deletes), label)
+		c.Counter("flowmind_cache_evictions_total", "Cache evictions.", float64(evictions), label)
+	}
+}
+
+/**
+ * DroppedCollector 采集按检查项统计的丢弃事件数
+ *
+ * 用于监控引擎的发布闸门（EventGate.DroppedCounts）
+ *
+ * Parameters:
+ *   - dropped: 返回检查项 -> 丢弃数的函数
+ *
+ * Returns:
+ *   - Collector: 采集函数
+ */
+func DroppedCollector(dropped func() map[string]uint64) Collector {
+	return func(c *Collection) {
+		for check, count := range dropped() {
+			c.Counter("flowmind_monitor_gate_dropped_total", "Monitor events dropped by the publish gate.", float64(count), Label{"check", check})
+		}
+	}
+}
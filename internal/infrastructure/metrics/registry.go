@@ -0,0 +1,477 @@
+/**
+ * Package metrics 提供内部指标的采集和 Prometheus 文本格式导出
+ *
+ * 计数器和直方图在事件发生时记录，事件总线、批量写入器、缓存等组件已有的统计
+ * 通过采集函数在每次抓取时读取
+ */
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+/**
+ * MetricType 指标类型
+ */
+type MetricType string
+
+const (
+	// TypeCounter 单调递增的计数器
+	TypeCounter MetricType = "counter"
+
+	// TypeGauge 可增可减的瞬时值
+	TypeGauge MetricType = "gauge"
+
+	// TypeHistogram 分桶统计的直方图
+	TypeHistogram MetricType = "histogram"
+)
+
+/**
+ * DefaultBuckets 默认的耗时分桶（秒）
+ */
+var DefaultBuckets = []float64{0.001, 0.005, 0.01, 0.05, 0.1, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+/**
+ * Label 指标标签
+ */
+type Label struct {
+	Name  string
+	Value string
+}
+
+/**
+ * Collector 采集函数
+ *
+ * 每次抓取时调用，把组件当前的统计写入 Collection
+ */
+type Collector func(c *Collection)
+
+/**
+ * Registry 指标注册表
+ */
+type Registry struct {
+	mu         sync.RWMutex
+	counters   []*CounterVec
+	histograms []*HistogramVec
+	collectors []Collector
+}
+
+/**
+ * NewRegistry 创建指标注册表
+ *
+ * Returns:
+ *   - *Registry: 注册表实例
+ */
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+/**
+ * NewCounterVec 创建带标签的计数器
+ *
+ * Parameters:
+ *   - name: 指标名称，计数器以 _total 结尾
+ *   - help: 指标说明
+ *   - labels: 标签名
+ *
+ * Returns:
+ *   - *CounterVec: 计数器
+ */
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	counter := &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		series: make(map[string]*counterSeries),
+	}
+
+	r.mu.Lock()
+	r.counters = append(r.counters, counter)
+	r.mu.Unlock()
+	return counter
+}
+
+/**
+ * NewHistogramVec 创建带标签的直方图
+ *
+ * Parameters:
+ *   - name: 指标名称
+ *   - help: 指标说明
+ *   - buckets: 分桶上限（升序），为空时使用 DefaultBuckets
+ *   - labels: 标签名
+ *
+ * Returns:
+ *   - *HistogramVec: 直方图
+ */
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	if len(buckets) == 0 {
+		buckets = DefaultBuckets
+	}
+	histogram := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: append([]float64(nil), buckets...),
+		series:  make(map[string]*histogramSeries),
+	}
+	sort.Float64s(histogram.buckets)
+
+	r.mu.Lock()
+	r.histograms = append(r.histograms, histogram)
+	r.mu.Unlock()
+	return histogram
+}
+
+/**
+ * Register 注册采集函数
+ *
+ * Parameters:
+ *   - collector: 采集函数
+ */
+func (r *Registry) Register(collector Collector) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.collectors = append(r.collectors, collector)
+}
+
+/**
+ * WriteText 以 Prometheus 文本格式（0.0.4）输出所有指标
+ *
+ * Parameters:
+ *   - w: 输出目标
+ *
+ * Returns:
+ *   - error: 写入失败时返回错误
+ */
+func (r *Registry) WriteText(w io.Writer) error {
+	collection := newCollection()
+
+	r.mu.RLock()
+	for _, counter := range r.counters {
+		counter.collect(collection)
+	}
+	for _, histogram := range r.histograms {
+		histogram.collect(collection)
+	}
+	collectors := append([]Collector(nil), r.collectors...)
+	r.mu.RUnlock()
+
+	for _, collector := range collectors {
+		collector(collection)
+	}
+
+	return collection.write(w)
+}
+
+/**
+ * CounterVec 带标签的计数器
+ */
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	series map[string]*counterSeries
+}
+
+type counterSeries struct {
+	labels []string
+	value  float64
+}
+
+/**
+ * Inc 计数加一
+ *
+ * Parameters:
+ *   - labelValues: 标签值，与创建时的标签名一一对应
+ */
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+/**
+ * Add 计数增加 delta，负数被忽略
+ *
+ * Parameters:
+ *   - delta: 增量
+ *   - labelValues: 标签值
+ */
+func (c *CounterVec) Add(delta float64, labelValues ...string) {
+	if delta < 0 {
+		return
+	}
+
+	key := seriesKey(labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	series, ok := c.series[key]
+	if !ok {
+		series = &counterSeries{labels: append([]string(nil), labelValues...)}
+		c.series[key] = series
+	}
+	series.value += delta
+}
+
+/**
+ * Value 获取计数值
+ *
+ * Parameters:
+ *   - labelValues: 标签值
+ *
+ * Returns:
+ *   - float64: 计数值，没有记录时为 0
+ */
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if series, ok := c.series[seriesKey(labelValues)]; ok {
+		return series.value
+	}
+	return 0
+}
+
+func (c *CounterVec) collect(collection *Collection) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	family := collection.family(c.name, c.help, TypeCounter)
+	for _, series := range c.series {
+		family.add(c.name, pairLabels(c.labels, series.labels), series.value)
+	}
+}
+
+/**
+ * HistogramVec 带标签的直方图
+ */
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+
+	mu     sync.Mutex
+	series map[string]*histogramSeries
+}
+
+type histogramSeries struct {
+	labels []string
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+/**
+ * Observe 记录一次观测值
+ *
+ * Parameters:
+ *   - value: 观测值，耗时使用秒
+ *   - labelValues: 标签值
+ */
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	key := seriesKey(labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	series, ok := h.series[key]
+	if !ok {
+		series = &histogramSeries{
+			labels: append([]string(nil), labelValues...),
+			counts: make([]uint64, len(h.buckets)),
+		}
+		h.series[key] = series
+	}
+
+	for i, upper := range h.buckets {
+		if value <= upper {
+			series.counts[i]++
+		}
+	}
+	series.count++
+	series.sum += value
+}
+
+/**
+ * Count 获取观测次数
+ *
+ * Parameters:
+ *   - labelValues: 标签值
+ *
+ * Returns:
+ *   - uint64: 观测次数
+ */
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if series, ok := h.series[seriesKey(labelValues)]; ok {
+		return series.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) collect(collection *Collection) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	family := collection.family(h.name, h.help, TypeHistogram)
+	for _, series := range h.series {
+		labels := pairLabels(h.labels, series.labels)
+		for i, upper := range h.buckets {
+			family.add(h.name+"_bucket", append(labels, Label{"le", formatFloat(upper)}), float64(series.counts[i]))
+		}
+		family.add(h.name+"_bucket", append(labels, Label{"le", "+Inf"}), float64(series.count))
+		family.add(h.name+"_sum", labels, series.sum)
+		family.add(h.name+"_count", labels, float64(series.count))
+	}
+}
+
+/**
+ * Collection 一次抓取收集到的指标
+ */
+type Collection struct {
+	families map[string]*family
+}
+
+type family struct {
+	name    string
+	help    string
+	typ     MetricType
+	samples []sample
+}
+
+type sample struct {
+	name   string
+	labels []Label
+	value  float64
+}
+
+func newCollection() *Collection {
+	return &Collection{families: make(map[string]*family)}
+}
+
+/**
+ * Gauge 记录瞬时值
+ *
+ * Parameters:
+ *   - name: 指标名称
+ *   - help: 指标说明
+ *   - value: 当前值
+ *   - labels: 标签
+ */
+func (c *Collection) Gauge(name, help string, value float64, labels ...Label) {
+	c.family(name, help, TypeGauge).add(name, labels, value)
+}
+
+/**
+ * Counter 记录组件自己维护的累计值
+ *
+ * Parameters:
+ *   - name: 指标名称，以 _total 结尾
+ *   - help: 指标说明
+ *   - value: 累计值
+ *   - labels: 标签
+ */
+func (c *Collection) Counter(name, help string, value float64, labels ...Label) {
+	c.family(name, help, TypeCounter).add(name, labels, value)
+}
+
+func (c *Collection) family(name, help string, typ MetricType) *family {
+	f, ok := c.families[name]
+	if !ok {
+		f = &family{name: name, help: help, typ: typ}
+		c.families[name] = f
+	}
+	return f
+}
+
+func (f *family) add(name string, labels []Label, value float64) {
+	f.samples = append(f.samples, sample{name: name, labels: labels, value: value})
+}
+
+/**
+ * write 按名称排序输出，样本按标签排序，保证输出稳定
+ */
+func (c *Collection) write(w io.Writer) error {
+	names := make([]string, 0, len(c.families))
+	for name := range c.families {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		f := c.families[name]
+		fmt.Fprintf(&b, "# HELP %s %s\n", f.name, escapeHelp(f.help))
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+
+		sort.SliceStable(f.samples, func(i, j int) bool {
+			return formatLabels(f.samples[i].labels) < formatLabels(f.samples[j].labels)
+		})
+		for _, s := range f.samples {
+			fmt.Fprintf(&b, "%s%s %s\n", s.name, formatLabels(s.labels), formatFloat(s.value))
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func seriesKey(labelValues []string) string {
+	return strings.Join(labelValues, "\xff")
+}
+
+func pairLabels(names, values []string) []Label {
+	labels := make([]Label, 0, len(names))
+	for i, name := range names {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		labels = append(labels, Label{Name: name, Value: value})
+	}
+	return labels
+}
+
+func formatLabels(labels []Label) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	parts := make([]string, 0, len(labels))
+	for _, label := range labels {
+		parts = append(parts, label.Name+`="`+escapeLabelValue(label.Value)+`"`)
+	}
+	return "{" + strings.Join(parts, ",") + "}"
+}
+
+func formatFloat(value float64) string {
+	switch {
+	case math.IsInf(value, 1):
+		return "+Inf"
+	case math.IsInf(value, -1):
+		return "-Inf"
+	case math.IsNaN(value):
+		return "NaN"
+	}
+	return strconv.FormatFloat(value, 'g', -1, 64)
+}
+
+var (
+	labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+	helpEscaper       = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+)
+
+func escapeLabelValue(value string) string {
+	return labelValueEscaper.Replace(value)
+}
+
+func escapeHelp(help string) string {
+	return helpEscaper.Replace(help)
+}
@@ -0,0 +1,175 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/cache"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeText(t *testing.T, registry *Registry) string {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, registry.WriteText(&buf))
+	return buf.String()
+}
+
+// TestCounterVec 测试计数器按标签累加
+func TestCounterVec(t *testing.T) {
+	registry := NewRegistry()
+	counter := registry.NewCounterVec("test_events_total", "Test events.", "type")
+
+	counter.Inc("keyboard")
+	counter.Inc("keyboard")
+	counter.Add(3, "clipboard")
+	counter.Add(-1, "clipboard")
+
+	assert.Equal(t, 2.0, counter.Value("keyboard"))
+	assert.Equal(t, 3.0, counter.Value("clipboard"))
+	assert.Equal(t, 0.0, counter.Value("unknown"))
+
+	output := writeText(t, registry)
+	assert.Contains(t, output, "# HELP test_events_total Test events.\n# TYPE test_events_total counter\n")
+	assert.Contains(t, output, `test_events_total{type="clipboard"} 3`+"\n")
+	assert.Contains(t, output, `test_events_total{type="keyboard"} 2`+"\n")
+	assert.Less(t, strings.Index(output, "clipboard"), strings.Index(output, "keyboard"))
+}
+
+// TestHistogramVec 测试直方图分桶是累计的
+func TestHistogramVec(t *testing.T) {
+	registry := NewRegistry()
+	histogram := registry.NewHistogramVec("test_duration_seconds", "Test duration.", []float64{1, 0.1}, "status")
+
+	histogram.Observe(0.05, "ok")
+	histogram.Observe(0.5, "ok")
+	histogram.Observe(2, "ok")
+
+	assert.Equal(t, uint64(3), histogram.Count("ok"))
+
+	output := writeText(t, registry)
+	assert.Contains(t, output, "# TYPE test_duration_seconds histogram\n")
+	assert.Contains(t, output, `test_duration_seconds_bucket{status="ok",le="0.1"} 1`+"\n")
+	assert.Contains(t, output, `test_duration_seconds_bucket{status="ok",le="1"} 2`+"\n")
+	assert.Contains(t, output, `test_duration_seconds_bucket{status="ok",le="+Inf"} 3`+"\n")
+	assert.Contains(t, output, `test_duration_seconds_sum{status="ok"} 2.55`+"\n")
+	assert.Contains(t, output, `test_duration_seconds_count{status="ok"} 3`+"\n")
+}
+
+// TestRegistry_Collector 测试采集函数在每次输出时读取最新值
+func TestRegistry_Collector(t *testing.T) {
+	registry := NewRegistry()
+	depth := 1.0
+	registry.Register(func(c *Collection) {
+		c.Gauge("test_depth", "Queue depth.", depth, Label{"queue", `a"b\c`})
+	})
+
+	assert.Contains(t, writeText(t, registry), `test_depth{queue="a\"b\\c"} 1`+"\n")
+
+	depth = 7
+	output := writeText(t, registry)
+	assert.Contains(t, output, "# TYPE test_depth gauge\n")
+	assert.Contains(t, output, `test_depth{queue="a\"b\\c"} 7`+"\n")
+}
+
+// TestBusObserver 测试事件总线的发布和处理被记录
+func TestBusObserver(t *testing.T) {
+	bus := events.NewEventBus(events.WithObserver(BusObserver{}))
+	defer bus.Stop(time.Second)
+
+	published := EventsPublished.Value("metrics_test")
+	handled := HandlerDuration.Count("metrics-test-subscriber")
+	failed := HandlerErrors.Value("metrics-test-subscriber")
+
+	bus.SubscribeWithOptions("metrics_test", func(event events.Event) error {
+		return errors.New("boom")
+	}, events.WithName("metrics-test-subscriber"))
+
+	require.NoError(t, bus.Publish("metrics_test", *events.NewEvent("metrics_test", nil)))
+
+	require.Eventually(t, func() bool {
+		return HandlerDuration.Count("metrics-test-subscriber") > handled
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, published+1, EventsPublished.Value("metrics_test"))
+	assert.Greater(t, HandlerErrors.Value("metrics-test-subscriber"), failed)
+}
+
+// TestBusObserver_BoundedLabels 测试未命名订阅者和请求应答不会产生新的标签值
+func TestBusObserver_BoundedLabels(t *testing.T) {
+	bus := events.NewEventBus(events.WithObserver(BusObserver{}))
+	defer bus.Stop(time.Second)
+
+	anonymous := HandlerDuration.Count(events.AnonymousSubscriber)
+	requesters := HandlerDuration.Count(events.ReplySubscriberName)
+	replies := EventsPublished.Value("_reply")
+
+	bus.Subscribe("metrics_anonymous", func(event events.Event) error { return nil })
+	require.NoError(t, bus.Publish("metrics_anonymous", *events.NewEvent("metrics_anonymous", nil)))
+	require.Eventually(t, func() bool {
+		return HandlerDuration.Count(events.AnonymousSubscriber) > anonymous
+	}, time.Second, 10*time.Millisecond)
+
+	_, err := bus.Respond("metrics_request", func(request events.Event) (events.Event, error) {
+		return events.Event{}, errors.New("boom")
+	})
+	require.NoError(t, err)
+	for i := 0; i < 2; i++ {
+		_, err := bus.Request(context.Background(), "metrics_request", *events.NewEvent("metrics_request", nil))
+		require.Error(t, err)
+	}
+	require.Eventually(t, func() bool {
+		return HandlerDuration.Count(events.ReplySubscriberName) == requesters+2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, replies+2, EventsPublished.Value("_reply"))
+}
+
+// TestBusCollector 测试订阅者统计的采集
+func TestBusCollector(t *testing.T) {
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+	bus.SubscribeWithOptions("collector_test", func(event events.Event) error { return nil },
+		events.WithName("collector"), events.WithBufferSize(8))
+
+	registry := NewRegistry()
+	registry.Register(BusCollector(bus))
+
+	output := writeText(t, registry)
+	assert.Contains(t, output, `flowmind_subscriber_buffer_size{subscriber="collector",topic="collector_test"} 8`)
+	assert.Contains(t, output, `flowmind_subscriber_buffer_depth{subscriber="collector",topic="collector_test"} 0`)
+	assert.Contains(t, output, "# TYPE flowmind_subscriber_delivered_total counter\n")
+}
+
+// TestCacheCollector 测试缓存统计的采集
+func TestCacheCollector(t *testing.T) {
+	stats := &cache.CacheStats{}
+	stats.RecordHit()
+	stats.RecordHit()
+	stats.RecordMiss()
+
+	registry := NewRegistry()
+	registry.Register(CacheCollector("ai", stats))
+
+	output := writeText(t, registry)
+	assert.Contains(t, output, `flowmind_cache_hits_total{cache="ai"} 2`)
+	assert.Contains(t, output, `flowmind_cache_misses_total{cache="ai"} 1`)
+}
+
+// TestObserveAICall 测试 AI 调用的耗时和 token 记录
+func TestObserveAICall(t *testing.T) {
+	calls := AICallDuration.Count("test-provider", "ok")
+	prompt := AITokens.Value("test-provider", "prompt")
+
+	ObserveAICall("test-provider", 200*time.Millisecond, 100, 20, nil)
+	ObserveAICall("test-provider", time.Second, 0, 0, errors.New("timeout"))
+
+	assert.Equal(t, calls+1, AICallDuration.Count("test-provider", "ok"))
+	assert.Equal(t, uint64(1), AICallDuration.Count("test-provider", "error"))
+	assert.Equal(t, prompt+100, AITokens.Value("test-provider", "prompt"))
+	assert.Equal(t, 20.0, AITokens.Value("test-provider", "completion"))
+}
@@ -0,0 +1,150 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * DefaultAddr 指标端点的默认监听地址
+ */
+const DefaultAddr = "127.0.0.1:9464"
+
+/**
+ * Handler 返回输出 Prometheus 文本格式的 HTTP 处理器
+ *
+ * Parameters:
+ *   - registry: 指标注册表
+ *
+ * Returns:
+ *   - http.Handler: 处理器
+ */
+func Handler(registry *Registry) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet && r.Method != http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := registry.WriteText(w); err != nil {
+			logger.Warn("输出指标失败",
+				zap.String("component", "metrics"),
+				zap.Error(err),
+			)
+		}
+	})
+}
+
+/**
+ * Server 指标 HTTP 服务
+ *
+ * 只允许监听本机回环地址，在 /metrics 路径输出指标
+ */
+type Server struct {
+	addr     string
+	registry *Registry
+	server   *http.Server
+	listener net.Listener
+}
+
+/**
+ * NewServer 创建指标 HTTP 服务
+ *
+ * Parameters:
+ *   - addr: 监听地址，为空时使用 DefaultAddr，必须是回环地址
+ *   - registry: 指标注册表
+ *
+ * Returns:
+ *   - *Server: 服务实例
+ *   - error: 地址不是回环地址时返回错误
+ */
+func NewServer(addr string, registry *Registry) (*Server, error) {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("解析指标监听地址失败: %w", err)
+	}
+	if host != "localhost" {
+		ip := net.ParseIP(host)
+		if ip == nil || !ip.IsLoopback() {
+			return nil, fmt.Errorf("指标端点只能监听本机回环地址: %s", addr)
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", Handler(registry))
+
+	return &Server{
+		addr:     addr,
+		registry: registry,
+		server: &http.Server{
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		},
+	}, nil
+}
+
+/**
+ * Start 开始监听并在后台提供服务
+ *
+ * Returns:
+ *   - error: 监听失败时返回错误
+ */
+func (s *Server) Start() error {
+	listener, err := net.Listen("tcp", s.addr)
+	if err != nil {
+		return fmt.Errorf("监听指标端点失败: %w", err)
+	}
+	s.listener = listener
+
+	go func() {
+		if err := s.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("指标服务异常退出",
+				zap.String("component", "metrics"),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	logger.Info("指标端点已启动",
+		zap.String("component", "metrics"),
+		zap.String("addr", listener.Addr().String()),
+	)
+	return nil
+}
+
+/**
+ * Addr 获取实际监听地址
+ *
+ * Returns:
+ *   - string: 监听地址，未启动时为配置的地址
+ */
+func (s *Server) Addr() string {
+	if s.listener != nil {
+		return s.listener.Addr().String()
+	}
+	return s.addr
+}
+
+/**
+ * Stop 停止服务
+ *
+ * Parameters:
+ *   - ctx: 上下文，用于限制等待时间
+ *
+ * Returns:
+ *   - error: 关闭失败时返回错误
+ */
+func (s *Server) Stop(ctx context.Context) error {
+	return s.server.Shutdown(ctx)
+}
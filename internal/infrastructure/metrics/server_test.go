@@ -0,0 +1,70 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandler 测试指标处理器输出 Prometheus 文本格式
+func TestHandler(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("test_requests_total", "Test requests.", "path").Inc("/")
+
+	server := httptest.NewServer(Handler(registry))
+	defer server.Close()
+
+	resp, err := http.Get(server.URL)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", resp.Header.Get("Content-Type"))
+	assert.Contains(t, string(body), `test_requests_total{path="/"} 1`)
+
+	resp, err = http.Post(server.URL, "text/plain", nil)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+}
+
+// TestNewServer_LoopbackOnly 测试指标端点只允许回环地址
+func TestNewServer_LoopbackOnly(t *testing.T) {
+	for _, addr := range []string{"", "127.0.0.1:0", "localhost:9464", "[::1]:9464"} {
+		_, err := NewServer(addr, NewRegistry())
+		assert.NoError(t, err, addr)
+	}
+
+	for _, addr := range []string{"0.0.0.0:9464", ":9464", "192.168.1.10:9464", "example.com:9464", "9464"} {
+		_, err := NewServer(addr, NewRegistry())
+		assert.Error(t, err, addr)
+	}
+}
+
+// TestServer_StartStop 测试指标端点的启动、抓取和停止
+func TestServer_StartStop(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("test_scrapes_total", "Test scrapes.").Inc()
+
+	server, err := NewServer("127.0.0.1:0", registry)
+	require.NoError(t, err)
+	require.NoError(t, server.Start())
+
+	resp, err := http.Get("http://" + server.Addr() + "/metrics")
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	require.NoError(t, err)
+	assert.Contains(t, string(body), "test_scrapes_total 1")
+
+	require.NoError(t, server.Stop(context.Background()))
+	_, err = http.Get("http://" + server.Addr() + "/metrics")
+	assert.Error(t, err)
+}
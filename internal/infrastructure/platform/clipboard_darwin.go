@@ -95,9 +95,20 @@ type DarwinClipboardMonitor struct {
 // 在 macOS 平台上，此函数返回 DarwinClipboardMonitor 实例。
 // Returns: ClipboardMonitor 接口的 macOS 实现
 func NewClipboardMonitor() ClipboardMonitor {
+	return NewClipboardMonitorWithInterval(DefaultClipboardCheckInterval)
+}
+
+// NewClipboardMonitorWithInterval 创建指定检查间隔的剪贴板监控器
+//
+// Parameters: interval - 剪贴板检查间隔，小于等于 0 时使用默认值
+// Returns: ClipboardMonitor 接口的 macOS 实现
+func NewClipboardMonitorWithInterval(interval time.Duration) ClipboardMonitor {
+	if interval <= 0 {
+		interval = DefaultClipboardCheckInterval
+	}
 	return &DarwinClipboardMonitor{
 		stopChan:       make(chan struct{}),
-		checkInterval:  500 * time.Millisecond,
+		checkInterval:  interval,
 		lastChangeCount: -1,
 	}
 }
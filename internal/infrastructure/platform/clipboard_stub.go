@@ -5,6 +5,7 @@ package platform
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 // StubClipboardMonitor 存根剪贴板监控器（非 macOS 平台）
@@ -30,6 +31,15 @@ type StubClipboardMonitor struct {
 // - 其他平台：返回 StubClipboardMonitor（空实现）
 // Returns: ClipboardMonitor 接口实例
 func NewClipboardMonitor() ClipboardMonitor {
+	return NewClipboardMonitorWithInterval(DefaultClipboardCheckInterval)
+}
+
+// NewClipboardMonitorWithInterval 创建指定检查间隔的剪贴板监控器
+//
+// 在非 macOS 平台上检查间隔不会被使用。
+// Parameters: interval - 剪贴板检查间隔
+// Returns: ClipboardMonitor 接口实例
+func NewClipboardMonitorWithInterval(interval time.Duration) ClipboardMonitor {
 	return &StubClipboardMonitor{
 		stopChan: make(chan struct{}),
 	}
@@ -1,6 +1,8 @@
 package platform
 
 import (
+	"time"
+
 	"github.com/chenyang-zz/flowmind/pkg/events"
 )
 
@@ -81,6 +83,9 @@ type ClipboardEvent struct {
 	Size int64
 }
 
+// DefaultClipboardCheckInterval 默认的剪贴板检查间隔
+const DefaultClipboardCheckInterval = 500 * time.Millisecond
+
 // ClipboardCallback 剪贴板事件回调函数类型
 //
 // 当检测到剪贴板内容变化时，监控器会调用此回调函数，将事件数据传递给调用者。
@@ -155,3 +160,71 @@ type AppSwitchMonitor interface {
 	// Returns: bool - 监控器是否正在运行
 	IsRunning() bool
 }
+
+// FileSystemOp 文件系统操作类型
+type FileSystemOp string
+
+const (
+	// FileSystemOpCreate 创建文件或目录
+	FileSystemOpCreate FileSystemOp = "create"
+	// FileSystemOpWrite 写入文件
+	FileSystemOpWrite FileSystemOp = "write"
+	// FileSystemOpRemove 删除文件或目录
+	FileSystemOpRemove FileSystemOp = "remove"
+	// FileSystemOpRename 重命名或移动文件
+	FileSystemOpRename FileSystemOp = "rename"
+)
+
+// FileSystemEvent 文件系统原始事件数据
+//
+// FileSystemEvent 封装了单个文件系统变化的基本信息。
+// 重命名事件会同时携带新旧路径；移出监控范围的文件表现为删除，移入的表现为创建。
+type FileSystemEvent struct {
+	// Path 发生变化的文件绝对路径（重命名时为新路径）
+	Path string
+	// OldPath 重命名前的路径，仅在 Op 为 rename 时有值
+	OldPath string
+	// Op 操作类型
+	Op FileSystemOp
+	// IsDir 是否为目录
+	IsDir bool
+}
+
+// FileSystemCallback 文件系统事件回调函数类型
+//
+// 当检测到文件变化时，监控器会调用此回调函数，将事件数据传递给调用者。
+// Parameters: event - 文件系统事件数据
+type FileSystemCallback func(FileSystemEvent)
+
+// FileSystemWatchConfig 文件系统监控配置
+//
+// 描述需要递归监控的根目录以及需要忽略的路径模式。
+type FileSystemWatchConfig struct {
+	// Roots 需要递归监控的根目录列表
+	Roots []string
+	// IgnorePatterns 忽略的路径模式（glob），匹配任意一级路径名即忽略
+	// 例如 ".git"、"node_modules"、"*.swp"
+	IgnorePatterns []string
+}
+
+// FileSystemMonitor 文件系统监控器接口
+//
+// FileSystemMonitor 定义了文件系统变化监控的生命周期管理方法。
+// 监控器会递归监控配置的根目录，并跳过匹配忽略模式的路径。
+// 注意：目前仅 Linux 平台（inotify）提供完整实现。
+type FileSystemMonitor interface {
+	// Start 启动文件系统监控
+	// 启动后会持续监听根目录下的文件变化并通过回调函数通知
+	// Parameters: callback - 文件系统事件回调函数
+	// Returns: error - 启动失败时返回错误（如根目录不存在、已运行等）
+	Start(callback FileSystemCallback) error
+
+	// Stop 停止文件系统监控
+	// 停止后会释放所有监控句柄
+	// Returns: error - 停止失败时返回错误（如未运行等）
+	Stop() error
+
+	// IsRunning 检查运行状态
+	// Returns: bool - 监控器是否正在运行
+	IsRunning() bool
+}
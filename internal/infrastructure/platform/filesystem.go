@@ -0,0 +1,67 @@
+package platform
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// DefaultFileSystemIgnorePatterns 默认忽略的路径模式
+//
+// 覆盖版本控制目录、依赖目录以及编辑器临时文件，这些路径变化频繁且对工作流分析没有价值。
+var DefaultFileSystemIgnorePatterns = []string{
+	".git",
+	".svn",
+	".hg",
+	"node_modules",
+	"__pycache__",
+	".DS_Store",
+	"*.swp",
+	"*.swx",
+	"*~",
+	".#*",
+}
+
+// ShouldIgnorePath 判断路径是否匹配忽略模式
+//
+// 不含路径分隔符的模式会与路径的每一级名称进行匹配（如 "node_modules" 会忽略其下所有内容），
+// 含路径分隔符的模式与完整路径匹配。
+//
+// Parameters:
+//   - path: 待检查的路径
+//   - patterns: 忽略模式列表（glob 语法）
+//
+// Returns: bool - true 表示应该忽略
+func ShouldIgnorePath(path string, patterns []string) bool {
+	if len(patterns) == 0 {
+		return false
+	}
+
+	cleaned := filepath.Clean(path)
+	elements := strings.Split(filepath.ToSlash(cleaned), "/")
+
+	for _, pattern := range patterns {
+		if pattern == "" {
+			continue
+		}
+
+		// 含分隔符的模式匹配完整路径
+		if strings.ContainsRune(pattern, '/') || strings.ContainsRune(pattern, filepath.Separator) {
+			if matched, _ := filepath.Match(pattern, cleaned); matched {
+				return true
+			}
+			continue
+		}
+
+		// 否则匹配任意一级路径名
+		for _, element := range elements {
+			if element == "" {
+				continue
+			}
+			if matched, _ := filepath.Match(pattern, element); matched {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,415 @@
+//go:build linux
+
+package platform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+// inotifyWatchMask 目录监控关注的 inotify 事件掩码
+const inotifyWatchMask = syscall.IN_CREATE |
+	syscall.IN_MODIFY |
+	syscall.IN_DELETE |
+	syscall.IN_MOVED_FROM |
+	syscall.IN_MOVED_TO |
+	syscall.IN_DONT_FOLLOW |
+	syscall.IN_EXCL_UNLINK
+
+// inotifyBufferSize 单次读取的缓冲区大小，可容纳多个带文件名的事件
+const inotifyBufferSize = 64 * (syscall.SizeofInotifyEvent + syscall.NAME_MAX + 1)
+
+// LinuxFileSystemMonitor 基于 inotify 的文件系统监控器
+//
+// LinuxFileSystemMonitor 递归地为根目录及其子目录添加 inotify 监控，
+// 新建的子目录会自动加入监控，匹配忽略模式的目录不会被监控。
+// 同一批读取到的 IN_MOVED_FROM/IN_MOVED_TO 事件按 cookie 配对为重命名事件，
+// 未配对的移出事件视为删除，未配对的移入事件视为创建。
+type LinuxFileSystemMonitor struct {
+	// config 监控配置（根目录与忽略模式）
+	config FileSystemWatchConfig
+	// roots 规范化后的根目录绝对路径
+	roots []string
+	// callback 文件系统事件回调函数
+	callback FileSystemCallback
+	// fd inotify 文件描述符
+	fd int
+	// file 包装 fd 的文件对象，使读取可以被 Close 中断
+	file *os.File
+	// watches 监控描述符到目录路径的映射（仅由读取协程和 Start 访问）
+	watches map[int]string
+	// isRunning 监控器运行状态标志
+	isRunning bool
+	// mu 读写锁，保护运行状态
+	mu sync.RWMutex
+	// done 读取协程退出信号
+	done chan struct{}
+}
+
+// NewFileSystemMonitor 创建文件系统监控器
+//
+// 根据编译平台自动返回相应的 FileSystemMonitor 实现：
+// - Linux 平台：返回 LinuxFileSystemMonitor（基于 inotify 的完整实现）
+// - 其他平台：返回 StubFileSystemMonitor（空实现）
+// Parameters: config - 监控配置，包含根目录和忽略模式
+// Returns: FileSystemMonitor 接口实例
+func NewFileSystemMonitor(config FileSystemWatchConfig) FileSystemMonitor {
+	return &LinuxFileSystemMonitor{
+		config: config,
+	}
+}
+
+// Start 启动文件系统监控
+//
+// 创建 inotify 实例，递归监控所有根目录，并启动后台读取协程。
+// Parameters: callback - 文件系统事件回调函数
+// Returns: error - 根目录无效、inotify 初始化失败或已在运行时返回错误
+func (fm *LinuxFileSystemMonitor) Start(callback FileSystemCallback) error {
+	fm.mu.Lock()
+	defer fm.mu.Unlock()
+
+	if fm.isRunning {
+		return fmt.Errorf("文件系统监控器已在运行")
+	}
+
+	if len(fm.config.Roots) == 0 {
+		return fmt.Errorf("未配置需要监控的目录")
+	}
+
+	roots := make([]string, 0, len(fm.config.Roots))
+	for _, root := range fm.config.Roots {
+		absRoot, err := filepath.Abs(root)
+		if err != nil {
+			return fmt.Errorf("解析监控目录失败 %s: %w", root, err)
+		}
+		info, err := os.Stat(absRoot)
+		if err != nil {
+			return fmt.Errorf("监控目录不可访问 %s: %w", absRoot, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("监控路径不是目录: %s", absRoot)
+		}
+		roots = append(roots, absRoot)
+	}
+
+	fd, err := syscall.InotifyInit1(syscall.IN_CLOEXEC | syscall.IN_NONBLOCK)
+	if err != nil {
+		return fmt.Errorf("初始化 inotify 失败: %w", err)
+	}
+
+	fm.fd = fd
+	// 非阻塞 fd 交给运行时轮询器管理，Close 可以中断阻塞中的 Read
+	fm.file = os.NewFile(uintptr(fd), "inotify")
+	fm.roots = roots
+	fm.watches = make(map[int]string)
+	fm.callback = callback
+
+	for _, root := range roots {
+		if err := fm.addWatchRecursive(root); err != nil {
+			fm.file.Close()
+			return fmt.Errorf("添加目录监控失败 %s: %w", root, err)
+		}
+	}
+
+	fm.done = make(chan struct{})
+	fm.isRunning = true
+
+	go fm.readLoop()
+
+	logger.Info("文件系统监控已启动",
+		zap.String("component", "filesystem"),
+		zap.Strings("roots", roots),
+		zap.Int("watches", len(fm.watches)),
+	)
+
+	return nil
+}
+
+// Stop 停止文件系统监控
+//
+// 关闭 inotify 文件描述符（内核会自动移除所有监控），并等待读取协程退出。
+// Returns: error - 监控器未运行时返回错误
+func (fm *LinuxFileSystemMonitor) Stop() error {
+	fm.mu.Lock()
+	if !fm.isRunning {
+		fm.mu.Unlock()
+		return fmt.Errorf("文件系统监控器未运行")
+	}
+	fm.isRunning = false
+	file := fm.file
+	done := fm.done
+	fm.mu.Unlock()
+
+	logger.Info("停止文件系统监控", zap.String("component", "filesystem"))
+
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("关闭 inotify 失败: %w", err)
+	}
+	<-done
+
+	return nil
+}
+
+// IsRunning 检查运行状态
+//
+// Returns: bool - 监控器是否正在运行
+func (fm *LinuxFileSystemMonitor) IsRunning() bool {
+	fm.mu.RLock()
+	defer fm.mu.RUnlock()
+	return fm.isRunning
+}
+
+// readLoop 读取并分发 inotify 事件
+//
+// 在后台协程中运行，直到 inotify 文件被关闭。
+func (fm *LinuxFileSystemMonitor) readLoop() {
+	defer close(fm.done)
+
+	buf := make([]byte, inotifyBufferSize)
+	for {
+		n, err := fm.file.Read(buf)
+		if err != nil {
+			if !errors.Is(err, os.ErrClosed) {
+				logger.Error("读取 inotify 事件失败",
+					zap.String("component", "filesystem"),
+					zap.Error(err),
+				)
+			}
+			return
+		}
+
+		for _, event := range fm.parseEvents(buf[:n]) {
+			fm.callback(event)
+		}
+	}
+}
+
+// parseEvents 解析一批 inotify 原始事件
+//
+// 同时维护目录监控：新建或移入的目录会被递归监控，移出的目录会取消监控。
+// Parameters: buf - inotify 读取到的原始字节
+// Returns: []FileSystemEvent - 解析后的事件列表
+func (fm *LinuxFileSystemMonitor) parseEvents(buf []byte) []FileSystemEvent {
+	var result []FileSystemEvent
+	// 按 cookie 暂存移出事件，用于与同批次的移入事件配对
+	pendingMoves := make(map[uint32]FileSystemEvent)
+	var moveOrder []uint32
+
+	for offset := 0; offset+syscall.SizeofInotifyEvent <= len(buf); {
+		raw := (*syscall.InotifyEvent)(unsafe.Pointer(&buf[offset]))
+		nameStart := offset + syscall.SizeofInotifyEvent
+		nameEnd := nameStart + int(raw.Len)
+		if nameEnd > len(buf) {
+			break
+		}
+		name := string(bytes.TrimRight(buf[nameStart:nameEnd], "\x00"))
+		offset = nameEnd
+
+		mask := raw.Mask
+		wd := int(raw.Wd)
+
+		if mask&syscall.IN_Q_OVERFLOW != 0 {
+			logger.Warn("inotify 事件队列溢出，部分文件变化可能丢失",
+				zap.String("component", "filesystem"))
+			continue
+		}
+
+		if mask&syscall.IN_IGNORED != 0 {
+			delete(fm.watches, wd)
+			continue
+		}
+
+		dir, ok := fm.watches[wd]
+		if !ok || name == "" {
+			continue
+		}
+
+		path := filepath.Join(dir, name)
+		if fm.isIgnored(path) {
+			continue
+		}
+		isDir := mask&syscall.IN_ISDIR != 0
+
+		switch {
+		case mask&syscall.IN_CREATE != 0:
+			if isDir {
+				fm.watchNewDir(path)
+			}
+			result = append(result, FileSystemEvent{Path: path, Op: FileSystemOpCreate, IsDir: isDir})
+
+		case mask&syscall.IN_MODIFY != 0:
+			if !isDir {
+				result = append(result, FileSystemEvent{Path: path, Op: FileSystemOpWrite})
+			}
+
+		case mask&syscall.IN_DELETE != 0:
+			result = append(result, FileSystemEvent{Path: path, Op: FileSystemOpRemove, IsDir: isDir})
+
+		case mask&syscall.IN_MOVED_FROM != 0:
+			pendingMoves[raw.Cookie] = FileSystemEvent{Path: path, IsDir: isDir}
+			moveOrder = append(moveOrder, raw.Cookie)
+
+		case mask&syscall.IN_MOVED_TO != 0:
+			if from, ok := pendingMoves[raw.Cookie]; ok {
+				delete(pendingMoves, raw.Cookie)
+				if isDir {
+					fm.renameWatches(from.Path, path)
+				}
+				result = append(result, FileSystemEvent{
+					Path:    path,
+					OldPath: from.Path,
+					Op:      FileSystemOpRename,
+					IsDir:   isDir,
+				})
+			} else {
+				// 从监控范围外移入，视为创建
+				if isDir {
+					fm.watchNewDir(path)
+				}
+				result = append(result, FileSystemEvent{Path: path, Op: FileSystemOpCreate, IsDir: isDir})
+			}
+		}
+	}
+
+	// 未配对的移出事件：文件被移出监控范围，视为删除
+	for _, cookie := range moveOrder {
+		from, ok := pendingMoves[cookie]
+		if !ok {
+			continue
+		}
+		if from.IsDir {
+			fm.removeWatches(from.Path)
+		}
+		from.Op = FileSystemOpRemove
+		result = append(result, from)
+	}
+
+	return result
+}
+
+// addWatchRecursive 递归监控目录及其所有未被忽略的子目录
+//
+// Parameters: dir - 目录绝对路径
+// Returns: error - 目录本身无法监控时返回错误，子目录失败仅记录日志
+func (fm *LinuxFileSystemMonitor) addWatchRecursive(dir string) error {
+	return filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if path == dir {
+				return err
+			}
+			logger.Debug("跳过无法访问的目录",
+				zap.String("component", "filesystem"),
+				zap.String("path", path),
+				zap.Error(err),
+			)
+			return nil
+		}
+
+		if !d.IsDir() {
+			return nil
+		}
+		if path != dir && fm.isIgnored(path) {
+			return filepath.SkipDir
+		}
+
+		if err := fm.addWatch(path); err != nil {
+			if path == dir {
+				return err
+			}
+			logger.Warn("添加子目录监控失败",
+				zap.String("component", "filesystem"),
+				zap.String("path", path),
+				zap.Error(err),
+			)
+			// 监控数量达到系统上限时停止继续遍历
+			if errors.Is(err, syscall.ENOSPC) {
+				return filepath.SkipAll
+			}
+		}
+		return nil
+	})
+}
+
+// addWatch 为单个目录添加 inotify 监控
+//
+// Parameters: dir - 目录绝对路径
+// Returns: error - 添加失败时返回错误
+func (fm *LinuxFileSystemMonitor) addWatch(dir string) error {
+	wd, err := syscall.InotifyAddWatch(fm.fd, dir, inotifyWatchMask)
+	if err != nil {
+		if errors.Is(err, syscall.ENOSPC) {
+			logger.Warn("inotify 监控数量达到上限，请调大 fs.inotify.max_user_watches",
+				zap.String("component", "filesystem"))
+		}
+		return err
+	}
+	fm.watches[wd] = dir
+	return nil
+}
+
+// watchNewDir 监控运行期间新出现的目录
+//
+// Parameters: dir - 新目录的绝对路径
+func (fm *LinuxFileSystemMonitor) watchNewDir(dir string) {
+	if err := fm.addWatchRecursive(dir); err != nil {
+		logger.Debug("监控新目录失败",
+			zap.String("component", "filesystem"),
+			zap.String("path", dir),
+			zap.Error(err),
+		)
+	}
+}
+
+// renameWatches 目录在监控范围内重命名后更新路径映射
+//
+// inotify 监控的是 inode，重命名后监控仍然有效，只需更新记录的路径。
+// Parameters:
+//   - oldDir: 原目录路径
+//   - newDir: 新目录路径
+func (fm *LinuxFileSystemMonitor) renameWatches(oldDir, newDir string) {
+	for wd, path := range fm.watches {
+		if path == oldDir {
+			fm.watches[wd] = newDir
+		} else if strings.HasPrefix(path, oldDir+string(filepath.Separator)) {
+			fm.watches[wd] = newDir + strings.TrimPrefix(path, oldDir)
+		}
+	}
+}
+
+// removeWatches 取消目录及其子目录的监控
+//
+// Parameters: dir - 目录路径
+func (fm *LinuxFileSystemMonitor) removeWatches(dir string) {
+	for wd, path := range fm.watches {
+		if path == dir || strings.HasPrefix(path, dir+string(filepath.Separator)) {
+			syscall.InotifyRmWatch(fm.fd, uint32(wd))
+			delete(fm.watches, wd)
+		}
+	}
+}
+
+// isIgnored 判断路径相对于其根目录是否匹配忽略模式
+//
+// Parameters: path - 绝对路径
+// Returns: bool - true 表示应该忽略
+func (fm *LinuxFileSystemMonitor) isIgnored(path string) bool {
+	for _, root := range fm.roots {
+		rel, err := filepath.Rel(root, path)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return ShouldIgnorePath(rel, fm.config.IgnorePatterns)
+	}
+	return ShouldIgnorePath(path, fm.config.IgnorePatterns)
+}
@@ -0,0 +1,154 @@
+//go:build linux
+
+package platform
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fsEventRecorder 记录收到的文件系统事件
+type fsEventRecorder struct {
+	mu     sync.Mutex
+	events []FileSystemEvent
+}
+
+func (r *fsEventRecorder) record(event FileSystemEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// waitFor 等待出现满足条件的事件
+func (r *fsEventRecorder) waitFor(t *testing.T, match func(FileSystemEvent) bool) FileSystemEvent {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		r.mu.Lock()
+		for _, event := range r.events {
+			if match(event) {
+				r.mu.Unlock()
+				return event
+			}
+		}
+		r.mu.Unlock()
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatalf("等待文件系统事件超时")
+	return FileSystemEvent{}
+}
+
+// startTestFileSystemMonitor 在临时目录上启动文件系统监控器
+func startTestFileSystemMonitor(t *testing.T) (string, *fsEventRecorder, FileSystemMonitor) {
+	root := t.TempDir()
+	recorder := &fsEventRecorder{}
+
+	monitor := NewFileSystemMonitor(FileSystemWatchConfig{
+		Roots:          []string{root},
+		IgnorePatterns: []string{".git", "*.swp"},
+	})
+	require.NoError(t, monitor.Start(recorder.record))
+	t.Cleanup(func() {
+		if monitor.IsRunning() {
+			monitor.Stop()
+		}
+	})
+
+	return root, recorder, monitor
+}
+
+// TestLinuxFileSystemMonitor_CreateWriteRemove 测试创建、写入和删除事件
+func TestLinuxFileSystemMonitor_CreateWriteRemove(t *testing.T) {
+	root, recorder, _ := startTestFileSystemMonitor(t)
+	path := filepath.Join(root, "note.txt")
+
+	require.NoError(t, os.WriteFile(path, []byte("hello"), 0o644))
+	recorder.waitFor(t, func(e FileSystemEvent) bool {
+		return e.Op == FileSystemOpCreate && e.Path == path
+	})
+	recorder.waitFor(t, func(e FileSystemEvent) bool {
+		return e.Op == FileSystemOpWrite && e.Path == path
+	})
+
+	require.NoError(t, os.Remove(path))
+	recorder.waitFor(t, func(e FileSystemEvent) bool {
+		return e.Op == FileSystemOpRemove && e.Path == path
+	})
+}
+
+// TestLinuxFileSystemMonitor_Rename 测试重命名事件配对
+func TestLinuxFileSystemMonitor_Rename(t *testing.T) {
+	root, recorder, _ := startTestFileSystemMonitor(t)
+	oldPath := filepath.Join(root, "draft.md")
+	newPath := filepath.Join(root, "final.md")
+
+	require.NoError(t, os.WriteFile(oldPath, []byte("x"), 0o644))
+	require.NoError(t, os.Rename(oldPath, newPath))
+
+	event := recorder.waitFor(t, func(e FileSystemEvent) bool {
+		return e.Op == FileSystemOpRename
+	})
+	assert.Equal(t, newPath, event.Path)
+	assert.Equal(t, oldPath, event.OldPath)
+}
+
+// TestLinuxFileSystemMonitor_RecursiveAndIgnore 测试新建子目录的递归监控与忽略规则
+func TestLinuxFileSystemMonitor_RecursiveAndIgnore(t *testing.T) {
+	root, recorder, _ := startTestFileSystemMonitor(t)
+
+	// 忽略目录及其内容不应产生事件
+	require.NoError(t, os.MkdirAll(filepath.Join(root, ".git", "objects"), 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(root, ".git", "HEAD"), []byte("ref"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(root, "a.txt.swp"), []byte("x"), 0o644))
+
+	// 新建子目录后，子目录中的文件变化也应被监控
+	subDir := filepath.Join(root, "src")
+	require.NoError(t, os.Mkdir(subDir, 0o755))
+	recorder.waitFor(t, func(e FileSystemEvent) bool {
+		return e.Op == FileSystemOpCreate && e.Path == subDir && e.IsDir
+	})
+
+	nested := filepath.Join(subDir, "main.go")
+	require.NoError(t, os.WriteFile(nested, []byte("package main"), 0o644))
+	recorder.waitFor(t, func(e FileSystemEvent) bool {
+		return e.Op == FileSystemOpCreate && e.Path == nested
+	})
+
+	recorder.mu.Lock()
+	defer recorder.mu.Unlock()
+	for _, event := range recorder.events {
+		assert.False(t, ShouldIgnorePath(event.Path, []string{".git", "*.swp"}),
+			"不应收到忽略路径的事件: %s", event.Path)
+	}
+}
+
+// TestLinuxFileSystemMonitor_StartStop 测试启动和停止
+func TestLinuxFileSystemMonitor_StartStop(t *testing.T) {
+	_, _, monitor := startTestFileSystemMonitor(t)
+
+	assert.True(t, monitor.IsRunning())
+	assert.Error(t, monitor.Start(func(FileSystemEvent) {}), "重复启动应返回错误")
+
+	require.NoError(t, monitor.Stop())
+	assert.False(t, monitor.IsRunning())
+	assert.Error(t, monitor.Stop(), "重复停止应返回错误")
+}
+
+// TestLinuxFileSystemMonitor_InvalidRoot 测试无效根目录
+func TestLinuxFileSystemMonitor_InvalidRoot(t *testing.T) {
+	monitor := NewFileSystemMonitor(FileSystemWatchConfig{
+		Roots: []string{filepath.Join(t.TempDir(), "missing")},
+	})
+
+	assert.Error(t, monitor.Start(func(FileSystemEvent) {}))
+	assert.False(t, monitor.IsRunning())
+
+	empty := NewFileSystemMonitor(FileSystemWatchConfig{})
+	assert.Error(t, empty.Start(func(FileSystemEvent) {}))
+}
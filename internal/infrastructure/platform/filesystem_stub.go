@@ -0,0 +1,78 @@
+//go:build !linux
+
+package platform
+
+import (
+	"fmt"
+	"sync"
+)
+
+// StubFileSystemMonitor 存根文件系统监控器（非 Linux 平台）
+//
+// StubFileSystemMonitor 是 FileSystemMonitor 接口的空实现，用于尚未实现文件监控的平台。
+// 该实现保存回调函数和运行状态，但不会实际监控文件变化。
+type StubFileSystemMonitor struct {
+	// config 监控配置（在此实现中不会被使用）
+	config FileSystemWatchConfig
+	// callback 文件系统事件回调函数（在此实现中不会被调用）
+	callback FileSystemCallback
+	// isRunning 监控器运行状态标志
+	isRunning bool
+	// mu 读写锁，保护并发访问
+	mu sync.RWMutex
+}
+
+// NewFileSystemMonitor 创建文件系统监控器
+//
+// 根据编译平台自动返回相应的 FileSystemMonitor 实现：
+// - Linux 平台：返回 LinuxFileSystemMonitor（基于 inotify 的完整实现）
+// - 其他平台：返回 StubFileSystemMonitor（空实现）
+// Parameters: config - 监控配置，包含根目录和忽略模式
+// Returns: FileSystemMonitor 接口实例
+func NewFileSystemMonitor(config FileSystemWatchConfig) FileSystemMonitor {
+	return &StubFileSystemMonitor{
+		config: config,
+	}
+}
+
+// Start 启动文件系统监控（非 Linux 实现）
+//
+// 此方法只保存回调函数并设置运行状态，不会实际开始监控。
+// Parameters: callback - 文件系统事件回调函数
+// Returns: error - 如果监控器已在运行则返回错误
+func (sm *StubFileSystemMonitor) Start(callback FileSystemCallback) error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if sm.isRunning {
+		return fmt.Errorf("文件系统监控器已在运行")
+	}
+
+	sm.callback = callback
+	sm.isRunning = true
+	return nil
+}
+
+// Stop 停止文件系统监控（非 Linux 实现）
+//
+// Returns: error - 如果监控器未运行则返回错误
+func (sm *StubFileSystemMonitor) Stop() error {
+	sm.mu.Lock()
+	defer sm.mu.Unlock()
+
+	if !sm.isRunning {
+		return fmt.Errorf("文件系统监控器未运行")
+	}
+
+	sm.isRunning = false
+	return nil
+}
+
+// IsRunning 检查运行状态（非 Linux 实现）
+//
+// Returns: bool - 监控器是否正在运行
+func (sm *StubFileSystemMonitor) IsRunning() bool {
+	sm.mu.RLock()
+	defer sm.mu.RUnlock()
+	return sm.isRunning
+}
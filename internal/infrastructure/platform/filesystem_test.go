@@ -0,0 +1,35 @@
+package platform
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestShouldIgnorePath 测试忽略模式匹配
+func TestShouldIgnorePath(t *testing.T) {
+	patterns := []string{".git", "node_modules", "*.swp", "build/*.o"}
+
+	tests := []struct {
+		name   string
+		path   string
+		expect bool
+	}{
+		{"版本控制目录", ".git", true},
+		{"版本控制目录下的文件", "project/.git/HEAD", true},
+		{"依赖目录", "web/node_modules/react/index.js", true},
+		{"编辑器临时文件", "notes/.todo.md.swp", true},
+		{"完整路径模式", "build/main.o", true},
+		{"完整路径模式不匹配子目录", "src/build/main.o", false},
+		{"普通文件", "src/main.go", false},
+		{"名称相似但不匹配", "src/.gitignore", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expect, ShouldIgnorePath(tt.path, patterns))
+		})
+	}
+
+	assert.False(t, ShouldIgnorePath("project/.git/HEAD", nil), "没有模式时不应忽略")
+}
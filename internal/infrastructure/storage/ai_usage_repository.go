@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+)
+
+/**
+ * SQLiteAIUsageRepository SQLite AI 用量仓储实现
+ */
+type SQLiteAIUsageRepository struct {
+	db *sql.DB
+}
+
+/**
+ * NewSQLiteAIUsageRepository 创建 SQLite AI 用量仓储
+ *
+ * Parameters:
+ *   - db: 数据库连接
+ *
+ * Returns: *SQLiteAIUsageRepository - AI 用量仓储实例
+ */
+func NewSQLiteAIUsageRepository(db *sql.DB) *SQLiteAIUsageRepository {
+	return &SQLiteAIUsageRepository{db: db}
+}
+
+/**
+ * Save 保存一次调用的用量
+ *
+ * Parameters:
+ *   - usage: 用量记录，保存后回填 ID
+ *
+ * Returns: error - 错误信息
+ */
+func (r *SQLiteAIUsageRepository) Save(usage *models.AIUsage) error {
+	var patternID sql.NullString
+	if usage.PatternID != "" {
+		patternID = sql.NullString{String: usage.PatternID, Valid: true}
+	}
+
+	result, err := r.db.Exec(`
+		INSERT INTO ai_usage (provider, model, pattern_id, purpose, input_tokens, output_tokens,
+			cost_usd, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		usage.Provider,
+		usage.Model,
+		patternID,
+		usage.Purpose,
+		usage.InputTokens,
+		usage.OutputTokens,
+		usage.CostUSD,
+		usage.CreatedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("保存 AI 用量失败: %w", err)
+	}
+
+	if id, err := result.LastInsertId(); err == nil {
+		usage.ID = id
+	}
+	return nil
+}
+
+/**
+ * FindByTimeRange 按时间范围查询用量，按时间升序
+ *
+ * Parameters:
+ *   - start: 开始时间（包含）
+ *   - end: 结束时间（不包含）
+ *
+ * Returns: []*models.AIUsage - 用量列表, error - 错误信息
+ */
+func (r *SQLiteAIUsageRepository) FindByTimeRange(start, end time.Time) ([]*models.AIUsage, error) {
+	rows, err := r.db.Query(`
+		SELECT id, provider, model, pattern_id, purpose, input_tokens, output_tokens, cost_usd, created_at
+		FROM ai_usage
+		WHERE created_at >= ? AND created_at < ?
+		ORDER BY created_at ASC, id ASC
+	`, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("查询 AI 用量失败: %w", err)
+	}
+	defer rows.Close()
+
+	var usages []*models.AIUsage
+	for rows.Next() {
+		var usage models.AIUsage
+		var patternID sql.NullString
+		if err := rows.Scan(
+			&usage.ID,
+			&usage.Provider,
+			&usage.Model,
+			&patternID,
+			&usage.Purpose,
+			&usage.InputTokens,
+			&usage.OutputTokens,
+			&usage.CostUSD,
+			&usage.CreatedAt,
+		); err != nil {
+			return nil, fmt.Errorf("扫描 AI 用量行失败: %w", err)
+		}
+		usage.PatternID = patternID.String
+		usages = append(usages, &usage)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历 AI 用量行失败: %w", err)
+	}
+	return usages, nil
+}
+
+/**
+ * TotalCost 统计时间范围内的总费用
+ *
+ * Parameters:
+ *   - start: 开始时间（包含）
+ *   - end: 结束时间（不包含）
+ *
+ * Returns: float64 - 总费用（美元）, error - 错误信息
+ */
+func (r *SQLiteAIUsageRepository) TotalCost(start, end time.Time) (float64, error) {
+	var total float64
+	err := r.db.QueryRow(`
+		SELECT COALESCE(SUM(cost_usd), 0)
+		FROM ai_usage
+		WHERE created_at >= ? AND created_at < ?
+	`, start, end).Scan(&total)
+	if err != nil {
+		return 0, fmt.Errorf("统计 AI 费用失败: %w", err)
+	}
+	return total, nil
+}
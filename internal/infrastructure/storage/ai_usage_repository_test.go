@@ -0,0 +1,66 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSQLiteAIUsageRepository 测试保存用量、按时间范围查询和统计费用
+func TestSQLiteAIUsageRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAIUsageRepository(db)
+	now := time.Now()
+
+	total, err := repo.TotalCost(now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+
+	yesterday := &models.AIUsage{
+		Provider:     "claude",
+		Model:        "claude-3-5-sonnet-20241022",
+		PatternID:    "pattern-1",
+		Purpose:      "pattern_analysis",
+		InputTokens:  1000,
+		OutputTokens: 200,
+		CostUSD:      0.006,
+		CreatedAt:    now.Add(-24 * time.Hour),
+	}
+	recent := &models.AIUsage{
+		Provider:     "ollama",
+		Model:        "llama3.2",
+		Purpose:      "output_repair",
+		InputTokens:  800,
+		OutputTokens: 100,
+		CreatedAt:    now.Add(-time.Minute),
+	}
+	require.NoError(t, repo.Save(yesterday))
+	require.NoError(t, repo.Save(recent))
+	assert.NotZero(t, yesterday.ID)
+
+	usages, err := repo.FindByTimeRange(now.Add(-48*time.Hour), now)
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+	assert.Equal(t, "pattern-1", usages[0].PatternID)
+	assert.Equal(t, 1000, usages[0].InputTokens)
+	assert.Equal(t, "", usages[1].PatternID)
+	assert.Equal(t, "output_repair", usages[1].Purpose)
+
+	// 结束时间不包含在范围内
+	usages, err = repo.FindByTimeRange(now.Add(-time.Hour), recent.CreatedAt)
+	require.NoError(t, err)
+	assert.Empty(t, usages)
+
+	total, err = repo.TotalCost(now.Add(-48*time.Hour), now)
+	require.NoError(t, err)
+	assert.InDelta(t, 0.006, total, 1e-9)
+
+	total, err = repo.TotalCost(now.Add(-time.Hour), now)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+}
@@ -0,0 +1,190 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * SQLiteAutomationRepository SQLite 自动化仓储实现
+ */
+type SQLiteAutomationRepository struct {
+	db *sql.DB
+}
+
+/**
+ * NewSQLiteAutomationRepository 创建 SQLite 自动化仓储
+ *
+ * Parameters:
+ *   - db: 数据库连接
+ *
+ * Returns: *SQLiteAutomationRepository - 自动化仓储实例
+ */
+func NewSQLiteAutomationRepository(db *sql.DB) *SQLiteAutomationRepository {
+	return &SQLiteAutomationRepository{db: db}
+}
+
+/**
+ * Save 保存自动化
+ *
+ * Parameters:
+ *   - automation: 自动化对象
+ *
+ * Returns: error - 错误信息
+ */
+func (r *SQLiteAutomationRepository) Save(automation *models.Automation) error {
+	stepsJSON, err := json.Marshal(automation.Steps)
+	if err != nil {
+		return fmt.Errorf("序列化自动化步骤失败: %w", err)
+	}
+
+	var description, patternID sql.NullString
+	if automation.Description != "" {
+		description = sql.NullString{String: automation.Description, Valid: true}
+	}
+	if automation.PatternID != "" {
+		patternID = sql.NullString{String: automation.PatternID, Valid: true}
+	}
+
+	_, err = r.db.Exec(`
+		INSERT INTO automations (uuid, name, description, pattern_id, steps, enabled,
+			created_at, updated_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`,
+		automation.ID,
+		automation.Name,
+		description,
+		patternID,
+		string(stepsJSON),
+		automation.Enabled,
+		automation.CreatedAt,
+		automation.UpdatedAt,
+	)
+	if err != nil {
+		logger.Error("保存自动化失败",
+			zap.String("automation_id", automation.ID),
+			zap.Error(err))
+		return fmt.Errorf("保存自动化失败: %w", err)
+	}
+
+	logger.Debug("自动化已保存",
+		zap.String("automation_id", automation.ID),
+		zap.String("pattern_id", automation.PatternID))
+
+	return nil
+}
+
+/**
+ * FindByID 根据ID查询自动化
+ *
+ * Parameters:
+ *   - id: 自动化ID
+ *
+ * Returns: *models.Automation - 自动化对象, error - 错误信息
+ */
+func (r *SQLiteAutomationRepository) FindByID(id string) (*models.Automation, error) {
+	rows, err := r.db.Query(`
+		SELECT uuid, name, description, pattern_id, steps, enabled, created_at, updated_at
+		FROM automations
+		WHERE uuid = ?
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("查询自动化失败: %w", err)
+	}
+	defer rows.Close()
+
+	automations, err := r.scanAutomations(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(automations) == 0 {
+		return nil, fmt.Errorf("自动化不存在: %s", id)
+	}
+	return automations[0], nil
+}
+
+/**
+ * FindAll 查询所有自动化，按创建时间倒序
+ *
+ * Returns: []*models.Automation - 自动化列表, error - 错误信息
+ */
+func (r *SQLiteAutomationRepository) FindAll() ([]*models.Automation, error) {
+	rows, err := r.db.Query(`
+		SELECT uuid, name, description, pattern_id, steps, enabled, created_at, updated_at
+		FROM automations
+		ORDER BY created_at DESC, id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("查询所有自动化失败: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanAutomations(rows)
+}
+
+/**
+ * Count 统计自动化数量
+ *
+ * Returns: int - 自动化数量, error - 错误信息
+ */
+func (r *SQLiteAutomationRepository) Count() (int, error) {
+	var count int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM automations").Scan(&count); err != nil {
+		return 0, fmt.Errorf("统计自动化数量失败: %w", err)
+	}
+	return count, nil
+}
+
+/**
+ * scanAutomations 扫描自动化行并转换为自动化对象
+ *
+ * Parameters:
+ *   - rows: 查询结果集
+ *
+ * Returns: []*models.Automation - 自动化列表, error - 错误信息
+ */
+func (r *SQLiteAutomationRepository) scanAutomations(rows *sql.Rows) ([]*models.Automation, error) {
+	var automations []*models.Automation
+
+	for rows.Next() {
+		var automation models.Automation
+		var description, patternID, stepsJSON sql.NullString
+
+		err := rows.Scan(
+			&automation.ID,
+			&automation.Name,
+			&description,
+			&patternID,
+			&stepsJSON,
+			&automation.Enabled,
+			&automation.CreatedAt,
+			&automation.UpdatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("扫描自动化行失败: %w", err)
+		}
+
+		automation.Description = description.String
+		automation.PatternID = patternID.String
+		if stepsJSON.String != "" {
+			if err := json.Unmarshal([]byte(stepsJSON.String), &automation.Steps); err != nil {
+				logger.Warn("反序列化自动化步骤失败",
+					zap.String("automation_id", automation.ID),
+					zap.Error(err))
+			}
+		}
+
+		automations = append(automations, &automation)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历自动化行失败: %w", err)
+	}
+
+	return automations, nil
+}
@@ -0,0 +1,65 @@
+package storage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSQLiteAutomationRepository 测试保存、查询和统计自动化
+func TestSQLiteAutomationRepository(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteAutomationRepository(db)
+	now := time.Now()
+
+	count, err := repo.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 0, count)
+
+	first := &models.Automation{
+		ID:          "automation-1",
+		Name:        "复制终端输出",
+		Description: "从终端复制并粘贴到浏览器",
+		PatternID:   "pattern-1",
+		Steps:       []string{"复制", "切换到浏览器", "粘贴"},
+		Enabled:     true,
+		CreatedAt:   now.Add(-time.Hour),
+		UpdatedAt:   now.Add(-time.Hour),
+	}
+	second := &models.Automation{
+		ID:        "automation-2",
+		Name:      "手动创建",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+	require.NoError(t, repo.Save(first))
+	require.NoError(t, repo.Save(second))
+	assert.Error(t, repo.Save(first), "重复的 ID 应该保存失败")
+
+	got, err := repo.FindByID("automation-1")
+	require.NoError(t, err)
+	assert.Equal(t, "复制终端输出", got.Name)
+	assert.Equal(t, "从终端复制并粘贴到浏览器", got.Description)
+	assert.Equal(t, "pattern-1", got.PatternID)
+	assert.Equal(t, []string{"复制", "切换到浏览器", "粘贴"}, got.Steps)
+	assert.True(t, got.Enabled)
+
+	_, err = repo.FindByID("missing")
+	assert.Error(t, err)
+
+	all, err := repo.FindAll()
+	require.NoError(t, err)
+	require.Len(t, all, 2)
+	assert.Equal(t, "automation-2", all[0].ID)
+	assert.Empty(t, all[0].PatternID)
+	assert.False(t, all[0].Enabled)
+
+	count, err = repo.Count()
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
@@ -51,15 +51,62 @@ type BatchWriterStats struct {
 	// PersistedEvents 成功持久化的事件数
 	PersistedEvents int64
 
-	// FailedEvents 失败的事件数
+	// FailedEvents 失败的事件数（失败的批次保留在缓冲区重试，每次失败都计数）
 	FailedEvents int64
 
 	// AverageLatency 平均延迟
 	AverageLatency time.Duration
 
+	// batches、totalLatency 成功写入的批次数和总耗时，用于计算平均延迟
+	batches      int64
+	totalLatency time.Duration
+
 	mu sync.Mutex
 }
 
+/**
+ * Snapshot 获取统计信息快照
+ *
+ * Returns: TotalEvents, PersistedEvents, FailedEvents, AverageLatency
+ */
+func (s *BatchWriterStats) Snapshot() (int64, int64, int64, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.TotalEvents, s.PersistedEvents, s.FailedEvents, s.AverageLatency
+}
+
+/**
+ * recordReceived 记录进入缓冲区的事件
+ */
+func (s *BatchWriterStats) recordReceived() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.TotalEvents++
+}
+
+/**
+ * recordFlush 记录一次批量写入
+ *
+ * Parameters:
+ *   - count: 批次中的事件数
+ *   - latency: 写入耗时
+ *   - err: 写入错误
+ */
+func (s *BatchWriterStats) recordFlush(count int, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err != nil {
+		s.FailedEvents += int64(count)
+		return
+	}
+
+	s.PersistedEvents += int64(count)
+	s.batches++
+	s.totalLatency += latency
+	s.AverageLatency = s.totalLatency / time.Duration(s.batches)
+}
+
 /**
  * BatchWriter 批量写入器
  *
@@ -247,6 +294,8 @@ func (bw *BatchWriter) processEvents() {
 				return
 			}
 
+			bw.stats.recordReceived()
+
 			bw.mu.Lock()
 			bw.buffer = append(bw.buffer, event)
 
@@ -297,6 +346,7 @@ func (bw *BatchWriter) flush() {
 
 	// 批量写入
 	err := bw.repo.SaveBatch(bw.buffer)
+	bw.stats.recordFlush(eventCount, time.Since(startTime), err)
 	if err != nil {
 		logger.Error("批量写入失败",
 			zap.Int("count", eventCount),
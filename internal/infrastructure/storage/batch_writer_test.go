@@ -516,3 +516,33 @@ func TestBatchWriter_ForceFlush_NotStarted(t *testing.T) {
 	// 验证没有错误发生
 	assert.Equal(t, 0, bw.GetBufferSize())
 }
+
+// TestBatchWriter_Stats 测试写入统计
+func TestBatchWriter_Stats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteEventRepository(db)
+	config := DefaultBatchWriterConfig()
+	config.BatchSize = 100
+	config.FlushInterval = time.Hour
+
+	bw := NewBatchWriter(repo, config)
+	bw.Start()
+	defer bw.Stop()
+
+	for i := 0; i < 4; i++ {
+		assert.True(t, bw.Write(*events.NewEvent(events.EventTypeKeyboard, nil)))
+	}
+
+	require.Eventually(t, func() bool {
+		return bw.GetBufferSize() == 4
+	}, time.Second, 10*time.Millisecond)
+	bw.ForceFlush()
+
+	total, persisted, failed, latency := bw.GetStats().Snapshot()
+	assert.Equal(t, int64(4), total)
+	assert.Equal(t, int64(4), persisted)
+	assert.Equal(t, int64(0), failed)
+	assert.Greater(t, latency, time.Duration(0))
+}
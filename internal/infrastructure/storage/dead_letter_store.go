@@ -0,0 +1,239 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+/**
+ * SQLiteDeadLetterStore SQLite 死信存储实现
+ *
+ * 实现 events.DeadLetterStore，重启后死信仍可查看和重新投递
+ */
+type SQLiteDeadLetterStore struct {
+	db *sql.DB
+}
+
+/**
+ * NewSQLiteDeadLetterStore 创建 SQLite 死信存储
+ *
+ * Parameters:
+ *   - db: 数据库连接
+ *
+ * Returns: *SQLiteDeadLetterStore - 死信存储实例
+ */
+func NewSQLiteDeadLetterStore(db *sql.DB) *SQLiteDeadLetterStore {
+	return &SQLiteDeadLetterStore{db: db}
+}
+
+/**
+ * Save 保存死信
+ *
+ * Parameters:
+ *   - letter: 死信记录
+ *
+ * Returns: error - 错误信息
+ */
+func (s *SQLiteDeadLetterStore) Save(letter events.DeadLetter) error {
+	eventJSON, err := json.Marshal(letter.Event)
+	if err != nil {
+		return fmt.Errorf("序列化死信事件失败: %w", err)
+	}
+
+	_, err = s.db.Exec(`
+		INSERT INTO dead_letters (uuid, subscriber, topic, event, error, attempts, failed_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`,
+		letter.ID,
+		letter.Subscriber,
+		letter.Topic,
+		string(eventJSON),
+		letter.Error,
+		letter.Attempts,
+		letter.FailedAt,
+	)
+	if err != nil {
+		return fmt.Errorf("保存死信失败: %w", err)
+	}
+
+	logger.Debug("死信已保存",
+		zap.String("dead_letter_id", letter.ID),
+		zap.String("subscriber", letter.Subscriber),
+	)
+	return nil
+}
+
+/**
+ * List 按进入时间倒序列出死信
+ *
+ * Parameters:
+ *   - limit: 数量限制，小于等于 0 表示不限
+ *
+ * Returns: []events.DeadLetter - 死信列表, error - 错误信息
+ */
+func (s *SQLiteDeadLetterStore) List(limit int) ([]events.DeadLetter, error) {
+	query := `
+		SELECT uuid, subscriber, topic, event, error, attempts, failed_at
+		FROM dead_letters
+		ORDER BY failed_at DESC, id DESC
+	`
+	var args []interface{}
+	if limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, limit)
+	}
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("查询死信失败: %w", err)
+	}
+	defer rows.Close()
+
+	var letters []events.DeadLetter
+	for rows.Next() {
+		letter, err := s.scanDeadLetter(rows)
+		if err != nil {
+			return nil, err
+		}
+		letters = append(letters, *letter)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历死信行失败: %w", err)
+	}
+
+	return letters, nil
+}
+
+/**
+ * Get 获取单条死信
+ *
+ * Parameters:
+ *   - id: 死信 ID
+ *
+ * Returns: *events.DeadLetter - 死信记录, error - 死信不存在或查询失败时返回错误
+ */
+func (s *SQLiteDeadLetterStore) Get(id string) (*events.DeadLetter, error) {
+	row := s.db.QueryRow(`
+		SELECT uuid, subscriber, topic, event, error, attempts, failed_at
+		FROM dead_letters
+		WHERE uuid = ?
+	`, id)
+
+	letter, err := s.scanDeadLetter(row)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("死信不存在: %s", id)
+	}
+	return letter, err
+}
+
+/**
+ * Delete 删除单条死信
+ *
+ * Parameters:
+ *   - id: 死信 ID
+ *
+ * Returns: error - 死信不存在或删除失败时返回错误
+ */
+func (s *SQLiteDeadLetterStore) Delete(id string) error {
+	result, err := s.db.Exec("DELETE FROM dead_letters WHERE uuid = ?", id)
+	if err != nil {
+		return fmt.Errorf("删除死信失败: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+	if rowsAffected == 0 {
+		return fmt.Errorf("死信不存在: %s", id)
+	}
+	return nil
+}
+
+/**
+ * Purge 清理死信
+ *
+ * Parameters:
+ *   - before: 删除此时间之前的死信，零值表示全部删除
+ *
+ * Returns: int64 - 删除数量, error - 错误信息
+ */
+func (s *SQLiteDeadLetterStore) Purge(before time.Time) (int64, error) {
+	var result sql.Result
+	var err error
+	if before.IsZero() {
+		result, err = s.db.Exec("DELETE FROM dead_letters")
+	} else {
+		result, err = s.db.Exec("DELETE FROM dead_letters WHERE failed_at < ?", before)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("清理死信失败: %w", err)
+	}
+
+	rowsAffected, _ := result.RowsAffected()
+
+	logger.Info("死信已清理", zap.Int64("deleted", rowsAffected))
+	return rowsAffected, nil
+}
+
+/**
+ * rowScanner 可扫描单行的查询结果（*sql.Row 和 *sql.Rows）
+ */
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+/**
+ * scanDeadLetter 扫描死信行
+ *
+ * Parameters:
+ *   - row: 查询结果
+ *
+ * Returns: *events.DeadLetter - 死信记录, error - 错误信息（不存在时为 sql.ErrNoRows）
+ */
+func (s *SQLiteDeadLetterStore) scanDeadLetter(row rowScanner) (*events.DeadLetter, error) {
+	var letter events.DeadLetter
+	var topic, errMsg sql.NullString
+	var eventJSON string
+
+	err := row.Scan(
+		&letter.ID,
+		&letter.Subscriber,
+		&topic,
+		&eventJSON,
+		&errMsg,
+		&letter.Attempts,
+		&letter.FailedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("扫描死信行失败: %w", err)
+	}
+
+	letter.Topic = topic.String
+	letter.Error = errMsg.String
+
+	// 数字按 json.Number 解码，避免整数变成 float64
+	decoder := json.NewDecoder(strings.NewReader(eventJSON))
+	decoder.UseNumber()
+	if err := decoder.Decode(&letter.Event); err != nil {
+		return nil, fmt.Errorf("反序列化死信事件失败: %w", err)
+	}
+
+	// 按类型化结构还原字段类型（int、time.Time），重新投递的事件与实时事件一致
+	if err := events.UpgradeEvent(&letter.Event); err != nil {
+		logger.Warn("还原死信事件数据失败",
+			zap.String("dead_letter_id", letter.ID),
+			zap.Error(err),
+		)
+	}
+
+	return &letter, nil
+}
@@ -0,0 +1,120 @@
+package storage
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDeadLetter 创建测试死信
+func newTestDeadLetter(subscriber string, failedAt time.Time) events.DeadLetter {
+	event := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{
+		"content": "hello",
+	})
+	event.Context = &events.EventContext{Application: "TestApp"}
+
+	return events.DeadLetter{
+		ID:         event.ID + "-dl",
+		Subscriber: subscriber,
+		Topic:      "clipboard",
+		Event:      *event,
+		Error:      "write failed",
+		Attempts:   4,
+		FailedAt:   failedAt,
+	}
+}
+
+// TestSQLiteDeadLetterStore_SaveGet 测试保存和查看死信
+func TestSQLiteDeadLetterStore_SaveGet(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := NewSQLiteDeadLetterStore(db)
+	letter := newTestDeadLetter("persistence", time.Now())
+	require.NoError(t, store.Save(letter))
+
+	got, err := store.Get(letter.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "persistence", got.Subscriber)
+	assert.Equal(t, "clipboard", got.Topic)
+	assert.Equal(t, "write failed", got.Error)
+	assert.Equal(t, 4, got.Attempts)
+	assert.Equal(t, letter.Event.ID, got.Event.ID)
+	assert.Equal(t, "hello", got.Event.Data["content"])
+	assert.Equal(t, "TestApp", got.Event.Context.Application)
+
+	_, err = store.Get("missing")
+	assert.Error(t, err)
+}
+
+// TestSQLiteDeadLetterStore_NumberTypes 测试读出的死信事件保留整数类型
+func TestSQLiteDeadLetterStore_NumberTypes(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := NewSQLiteDeadLetterStore(db)
+	clipboard := events.NewPayloadEvent(events.ClipboardEventData{Content: "hello", Type: "text", Size: 5, Length: 5})
+	custom := events.NewEvent("custom", map[string]interface{}{"count": 3, "ratio": 0.5})
+	for i, event := range []*events.Event{clipboard, custom} {
+		require.NoError(t, store.Save(events.DeadLetter{
+			ID:         fmt.Sprintf("dl-%d", i),
+			Subscriber: "persistence",
+			Event:      *event,
+			FailedAt:   time.Now(),
+		}))
+	}
+
+	// 已注册结构的事件还原为实时事件的类型
+	got, err := store.Get("dl-0")
+	require.NoError(t, err)
+	assert.Equal(t, 5, got.Event.Data["length"])
+	assert.Equal(t, int64(5), got.Event.Data["size"])
+
+	// 其他事件的数字保持精确
+	got, err = store.Get("dl-1")
+	require.NoError(t, err)
+	assert.Equal(t, json.Number("3"), got.Event.Data["count"])
+	assert.Equal(t, json.Number("0.5"), got.Event.Data["ratio"])
+}
+
+// TestSQLiteDeadLetterStore_ListDeletePurge 测试列出、删除和清理死信
+func TestSQLiteDeadLetterStore_ListDeletePurge(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	store := NewSQLiteDeadLetterStore(db)
+	now := time.Now()
+	old := newTestDeadLetter("a", now.Add(-2*time.Hour))
+	middle := newTestDeadLetter("b", now.Add(-time.Hour))
+	recent := newTestDeadLetter("c", now)
+	for _, letter := range []events.DeadLetter{old, middle, recent} {
+		require.NoError(t, store.Save(letter))
+	}
+
+	letters, err := store.List(2)
+	require.NoError(t, err)
+	require.Len(t, letters, 2)
+	assert.Equal(t, recent.ID, letters[0].ID)
+	assert.Equal(t, middle.ID, letters[1].ID)
+
+	require.NoError(t, store.Delete(middle.ID))
+	assert.Error(t, store.Delete(middle.ID), "重复删除应该失败")
+
+	deleted, err := store.Purge(now.Add(-30 * time.Minute))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+
+	letters, err = store.List(0)
+	require.NoError(t, err)
+	require.Len(t, letters, 1)
+	assert.Equal(t, recent.ID, letters[0].ID)
+
+	deleted, err = store.Purge(time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
@@ -37,6 +37,23 @@ type EventRepository interface {
 
 	// GetStats 获取统计信息
 	GetStats() (*EventStats, error)
+
+	// FindAfterCursor 按游标分页查询（用于回放）
+	FindAfterCursor(cursor int64, start, end time.Time, limit int) ([]StoredEvent, error)
+
+	// FindCausalChain 查询事件所在的整条因果链
+	FindCausalChain(eventID string) ([]events.Event, error)
+}
+
+/**
+ * StoredEvent 带存储游标的事件
+ */
+type StoredEvent struct {
+	// Cursor 存储游标（事件的自增主键），按写入顺序单调递增
+	Cursor int64
+
+	// Event 事件对象
+	Event events.Event
 }
 
 /**
@@ -85,8 +102,8 @@ func NewSQLiteEventRepository(db *sql.DB) *SQLiteEventRepository {
  */
 func (r *SQLiteEventRepository) Save(event events.Event) error {
 	query := `
-		INSERT INTO events (uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO events (uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection, schema_version, correlation_id, causation_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
 	// 序列化事件数据为 JSON
@@ -116,6 +133,9 @@ func (r *SQLiteEventRepository) Save(event events.Event) error {
 		windowTitle,
 		filePath,
 		selection,
+		schemaVersion(event),
+		nullString(event.CorrelationID),
+		nullString(event.CausationID),
 	)
 
 	if err != nil {
@@ -153,8 +173,8 @@ func (r *SQLiteEventRepository) SaveBatch(eventList []events.Event) error {
 
 	// 准备语句
 	stmt, err := tx.Prepare(`
-		INSERT INTO events (uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO events (uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection, schema_version, correlation_id, causation_id)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 	`)
 	if err != nil {
 		return fmt.Errorf("准备语句失败: %w", err)
@@ -193,6 +213,9 @@ func (r *SQLiteEventRepository) SaveBatch(eventList []events.Event) error {
 			windowTitle,
 			filePath,
 			selection,
+			schemaVersion(event),
+			nullString(event.CorrelationID),
+			nullString(event.CausationID),
 		)
 
 		if err != nil {
@@ -227,7 +250,7 @@ func (r *SQLiteEventRepository) SaveBatch(eventList []events.Event) error {
  */
 func (r *SQLiteEventRepository) FindByTimeRange(start, end time.Time) ([]events.Event, error) {
 	query := `
-		SELECT uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection
+		SELECT uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection, schema_version, correlation_id, causation_id
 		FROM events
 		WHERE timestamp >= ? AND timestamp <= ?
 		ORDER BY timestamp ASC
@@ -252,7 +275,7 @@ func (r *SQLiteEventRepository) FindByTimeRange(start, end time.Time) ([]events.
  */
 func (r *SQLiteEventRepository) FindRecent(limit int) ([]events.Event, error) {
 	query := `
-		SELECT uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection
+		SELECT uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection, schema_version, correlation_id, causation_id
 		FROM events
 		ORDER BY timestamp DESC
 		LIMIT ?
@@ -288,7 +311,7 @@ func (r *SQLiteEventRepository) FindRecent(limit int) ([]events.Event, error) {
  */
 func (r *SQLiteEventRepository) FindByType(eventType events.EventType, limit int) ([]events.Event, error) {
 	query := `
-		SELECT uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection
+		SELECT uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection, schema_version, correlation_id, causation_id
 		FROM events
 		WHERE type = ?
 		ORDER BY timestamp DESC
@@ -409,6 +432,119 @@ func (r *SQLiteEventRepository) GetStats() (*EventStats, error) {
 	return stats, nil
 }
 
+/**
+ * FindAfterCursor 按游标分页查询事件
+ *
+ * 游标是事件的存储序号（自增主键），按写入顺序单调递增，
+ * 适合回放和断点续传：把上一页最后一个事件的游标作为下一次查询的起点
+ *
+ * Parameters:
+ *   - cursor: 起始游标（不包含），0 表示从头开始
+ *   - start: 开始时间，零值表示不限
+ *   - end: 结束时间，零值表示不限
+ *   - limit: 返回数量限制
+ *
+ * Returns: []StoredEvent - 按游标升序排列的事件, error - 错误信息
+ */
+func (r *SQLiteEventRepository) FindAfterCursor(cursor int64, start, end time.Time, limit int) ([]StoredEvent, error) {
+	query := `
+		SELECT id, uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection, schema_version, correlation_id, causation_id
+		FROM events
+		WHERE id > ?
+	`
+	args := []interface{}{cursor}
+	if !start.IsZero() {
+		query += " AND timestamp >= ?"
+		args = append(args, start)
+	}
+	if !end.IsZero() {
+		query += " AND timestamp <= ?"
+		args = append(args, end)
+	}
+	query += " ORDER BY id ASC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("按游标查询事件失败: %w", err)
+	}
+	defer rows.Close()
+
+	var stored []StoredEvent
+	for rows.Next() {
+		var item StoredEvent
+		event, err := r.scanEventRow(rows, &item.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		item.Event = event
+		stored = append(stored, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历事件行失败: %w", err)
+	}
+
+	return stored, nil
+}
+
+/**
+ * LatestCursor 获取最新事件的存储游标
+ *
+ * 用于从当前位置开始跟踪新写入的事件（如 events tail -f）
+ *
+ * Returns: int64 - 最新游标，没有事件时为 0, error - 错误信息
+ */
+func (r *SQLiteEventRepository) LatestCursor() (int64, error) {
+	var cursor int64
+	if err := r.db.QueryRow("SELECT COALESCE(MAX(id), 0) FROM events").Scan(&cursor); err != nil {
+		return 0, fmt.Errorf("查询最新游标失败: %w", err)
+	}
+	return cursor, nil
+}
+
+/**
+ * FindCausalChain 查询事件所在的整条因果链
+ *
+ * 返回与该事件 CorrelationID 相同的所有事件，包括根事件和所有后续事件。
+ * 通过 CausationID 可以还原事件之间的树形关系
+ *
+ * Parameters:
+ *   - eventID: 因果链上任意事件的 ID
+ *
+ * Returns: []events.Event - 按时间升序排列的事件, error - 事件不存在或查询失败时返回错误
+ */
+func (r *SQLiteEventRepository) FindCausalChain(eventID string) ([]events.Event, error) {
+	var correlationID sql.NullString
+	err := r.db.QueryRow("SELECT correlation_id FROM events WHERE uuid = ?", eventID).Scan(&correlationID)
+	if err == sql.ErrNoRows {
+		return nil, fmt.Errorf("事件不存在: %s", eventID)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询事件关联 ID 失败: %w", err)
+	}
+
+	// 旧数据没有关联 ID，因果链只有事件自身
+	if !correlationID.Valid || correlationID.String == "" {
+		correlationID.String = eventID
+	}
+
+	query := `
+		SELECT uuid, type, timestamp, data, application, bundle_id, window_title, file_path, selection, schema_version, correlation_id, causation_id
+		FROM events
+		WHERE correlation_id = ? OR uuid = ?
+		ORDER BY timestamp ASC, id ASC
+	`
+
+	rows, err := r.db.Query(query, correlationID.String, correlationID.String)
+	if err != nil {
+		return nil, fmt.Errorf("查询因果链失败: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanEvents(rows)
+}
+
 /**
  * scanEvents 扫描事件行并转换为事件对象
  *
@@ -421,56 +557,10 @@ func (r *SQLiteEventRepository) scanEvents(rows *sql.Rows) ([]events.Event, erro
 	var eventList []events.Event
 
 	for rows.Next() {
-		var event events.Event
-		var dataJSON string
-		var application, bundleID, windowTitle, filePath, selection sql.NullString
-
-		err := rows.Scan(
-			&event.ID,
-			&event.Type,
-			&event.Timestamp,
-			&dataJSON,
-			&application,
-			&bundleID,
-			&windowTitle,
-			&filePath,
-			&selection,
-		)
-
+		event, err := r.scanEventRow(rows)
 		if err != nil {
-			return nil, fmt.Errorf("扫描事件行失败: %w", err)
-		}
-
-		// 反序列化数据
-		if err := json.Unmarshal([]byte(dataJSON), &event.Data); err != nil {
-			logger.Error("反序列化事件数据失败",
-				zap.String("event_id", event.ID),
-				zap.Error(err),
-			)
-			event.Data = make(map[string]interface{})
-		}
-
-		// 构建上下文
-		if application.Valid || bundleID.Valid || windowTitle.Valid ||
-			filePath.Valid || selection.Valid {
-			event.Context = &events.EventContext{}
-			if application.Valid {
-				event.Context.Application = application.String
-			}
-			if bundleID.Valid {
-				event.Context.BundleID = bundleID.String
-			}
-			if windowTitle.Valid {
-				event.Context.WindowTitle = windowTitle.String
-			}
-			if filePath.Valid {
-				event.Context.FilePath = filePath.String
-			}
-			if selection.Valid {
-				event.Context.Selection = selection.String
-			}
+			return nil, err
 		}
-
 		eventList = append(eventList, event)
 	}
 
@@ -480,3 +570,111 @@ func (r *SQLiteEventRepository) scanEvents(rows *sql.Rows) ([]events.Event, erro
 
 	return eventList, nil
 }
+
+/**
+ * scanEventRow 扫描单行事件
+ *
+ * Parameters:
+ *   - rows: 查询结果集（已调用 Next）
+ *   - prefix: 事件字段之前的额外列的扫描目标
+ *
+ * Returns: events.Event - 事件对象, error - 错误信息
+ */
+func (r *SQLiteEventRepository) scanEventRow(rows *sql.Rows, prefix ...interface{}) (events.Event, error) {
+	var event events.Event
+	var dataJSON string
+	var application, bundleID, windowTitle, filePath, selection sql.NullString
+	var correlationID, causationID sql.NullString
+
+	dest := append(prefix,
+		&event.ID,
+		&event.Type,
+		&event.Timestamp,
+		&dataJSON,
+		&application,
+		&bundleID,
+		&windowTitle,
+		&filePath,
+		&selection,
+		&event.SchemaVersion,
+		&correlationID,
+		&causationID,
+	)
+
+	if err := rows.Scan(dest...); err != nil {
+		return event, fmt.Errorf("扫描事件行失败: %w", err)
+	}
+
+	// 反序列化数据
+	if err := json.Unmarshal([]byte(dataJSON), &event.Data); err != nil {
+		logger.Error("反序列化事件数据失败",
+			zap.String("event_id", event.ID),
+			zap.Error(err),
+		)
+		event.Data = make(map[string]interface{})
+	}
+
+	event.CorrelationID = correlationID.String
+	event.CausationID = causationID.String
+
+	// 构建上下文
+	if application.Valid || bundleID.Valid || windowTitle.Valid ||
+		filePath.Valid || selection.Valid {
+		event.Context = &events.EventContext{}
+		if application.Valid {
+			event.Context.Application = application.String
+		}
+		if bundleID.Valid {
+			event.Context.BundleID = bundleID.String
+		}
+		if windowTitle.Valid {
+			event.Context.WindowTitle = windowTitle.String
+		}
+		if filePath.Valid {
+			event.Context.FilePath = filePath.String
+		}
+		if selection.Valid {
+			event.Context.Selection = selection.String
+		}
+	}
+
+	// 旧版本的事件升级到当前结构，失败时保留原始数据
+	if err := events.UpgradeEvent(&event); err != nil {
+		logger.Warn("升级事件数据结构失败",
+			zap.String("event_id", event.ID),
+			zap.Int("schema_version", event.SchemaVersion),
+			zap.Error(err),
+		)
+	}
+
+	return event, nil
+}
+
+/**
+ * schemaVersion 获取要写入的事件结构版本
+ *
+ * 没有标记版本的事件由当前代码直接构造，按当前版本保存
+ *
+ * Parameters:
+ *   - event: 事件对象
+ *
+ * Returns: int - 结构版本
+ */
+func schemaVersion(event events.Event) int {
+	if event.SchemaVersion == events.LegacySchemaVersion {
+		return events.CurrentSchemaVersion(event.Type)
+	}
+	return event.SchemaVersion
+}
+
+/**
+ * nullString 空字符串写入为 NULL
+ *
+ * Parameters:
+ *   - value: 字符串值
+ *
+ * Returns: sql.NullString - 可空字符串
+ */
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
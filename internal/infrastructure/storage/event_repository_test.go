@@ -273,7 +273,9 @@ func TestSQLiteEventRepository_ComplexData(t *testing.T) {
 	require.NoError(t, err)
 	assert.Len(t, saved, 1)
 
-	assert.Equal(t, float64(65), saved[0].Data["keycode"])
+	// 键盘数据按类型化结构还原为 int，结构之外的字段原样保留
+	assert.Equal(t, 65, saved[0].Data["keycode"])
+	assert.Equal(t, map[string]interface{}{"key": "value"}, saved[0].Data["nested"])
 	assert.Equal(t, "TestApp", saved[0].Context.Application)
 	assert.Equal(t, "/test/path.txt", saved[0].Context.FilePath)
 }
@@ -653,3 +655,145 @@ func TestSQLiteEventRepository_Save_ContextNil(t *testing.T) {
 	assert.NotNil(t, saved[0].Context)
 	assert.Equal(t, "", saved[0].Context.Application)
 }
+
+// TestSQLiteEventRepository_FindAfterCursor 测试按游标分页查询事件
+func TestSQLiteEventRepository_FindAfterCursor(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteEventRepository(db)
+
+	now := time.Now()
+	var eventList []events.Event
+	for i := 0; i < 5; i++ {
+		event := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{
+			"keycode": float64(i),
+		})
+		event.Timestamp = now.Add(time.Duration(i-5) * time.Minute)
+		eventList = append(eventList, *event)
+	}
+	require.NoError(t, repo.SaveBatch(eventList))
+
+	// 第一页
+	page, err := repo.FindAfterCursor(0, time.Time{}, time.Time{}, 2)
+	require.NoError(t, err)
+	require.Len(t, page, 2)
+	assert.Equal(t, eventList[0].ID, page[0].Event.ID)
+	assert.Equal(t, eventList[1].ID, page[1].Event.ID)
+	assert.Less(t, page[0].Cursor, page[1].Cursor)
+
+	// 从上一页最后的游标继续
+	rest, err := repo.FindAfterCursor(page[1].Cursor, time.Time{}, time.Time{}, 10)
+	require.NoError(t, err)
+	require.Len(t, rest, 3)
+	assert.Equal(t, eventList[2].ID, rest[0].Event.ID)
+	assert.Equal(t, 4, rest[2].Event.Data["keycode"])
+
+	// 叠加时间范围
+	ranged, err := repo.FindAfterCursor(0, now.Add(-3*time.Minute-time.Second), now.Add(-2*time.Minute+time.Second), 10)
+	require.NoError(t, err)
+	require.Len(t, ranged, 2)
+	assert.Equal(t, eventList[2].ID, ranged[0].Event.ID)
+	assert.Equal(t, eventList[3].ID, ranged[1].Event.ID)
+
+	// 游标之后没有事件
+	empty, err := repo.FindAfterCursor(rest[2].Cursor, time.Time{}, time.Time{}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, empty)
+
+	// 最新游标就是最后一个事件的游标
+	latest, err := repo.LatestCursor()
+	require.NoError(t, err)
+	assert.Equal(t, rest[2].Cursor, latest)
+}
+
+// TestSQLiteEventRepository_LegacyRows 测试引入版本号之前写入的事件按当前结构读出
+func TestSQLiteEventRepository_LegacyRows(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteEventRepository(db)
+
+	// 模拟旧版本写入的行：没有 schema_version，剪贴板缺少 type/size
+	_, err := db.Exec(`INSERT INTO events (uuid, type, timestamp, data) VALUES (?, ?, ?, ?)`,
+		"legacy-keyboard", events.EventTypeKeyboard, time.Now().Add(-time.Minute), `{"keycode":122,"modifiers":256}`)
+	require.NoError(t, err)
+	_, err = db.Exec(`INSERT INTO events (uuid, type, timestamp, data) VALUES (?, ?, ?, ?)`,
+		"legacy-clipboard", events.EventTypeClipboard, time.Now(), `{"content":"hello","length":5}`)
+	require.NoError(t, err)
+
+	loaded, err := repo.FindRecent(10)
+	require.NoError(t, err)
+	require.Len(t, loaded, 2)
+
+	byID := make(map[string]events.Event)
+	for _, event := range loaded {
+		assert.Equal(t, events.CurrentSchemaVersion(event.Type), event.SchemaVersion)
+		byID[event.ID] = event
+	}
+
+	keyboard := byID["legacy-keyboard"]
+	assert.Equal(t, 122, keyboard.Data["keycode"])
+	assert.Equal(t, uint64(256), keyboard.Data["modifiers"])
+
+	var clipboard events.ClipboardEventData
+	require.NoError(t, events.DecodePayload(byID["legacy-clipboard"], &clipboard))
+	assert.Equal(t, "hello", clipboard.Content)
+	assert.Equal(t, "public.utf8-plain-text", clipboard.Type)
+	assert.Equal(t, int64(5), clipboard.Size)
+
+	// 新写入的事件记录当前版本
+	require.NoError(t, repo.Save(*events.NewPayloadEvent(events.KeyboardEventData{KeyCode: 1})))
+	var version int
+	require.NoError(t, db.QueryRow(`SELECT schema_version FROM events WHERE type = ? ORDER BY id DESC LIMIT 1`, events.EventTypeKeyboard).Scan(&version))
+	assert.Equal(t, events.CurrentSchemaVersion(events.EventTypeKeyboard), version)
+}
+
+// TestSQLiteEventRepository_FindCausalChain 测试查询整条因果链
+func TestSQLiteEventRepository_FindCausalChain(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteEventRepository(db)
+
+	now := time.Now()
+	root := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"keycode": 122})
+	root.CorrelationID = root.ID
+	root.Timestamp = now.Add(-3 * time.Second)
+
+	hotkey := events.NewEvent("hotkey.trigger", nil).CausedBy(*root)
+	hotkey.Timestamp = now.Add(-2 * time.Second)
+
+	automation := events.NewEvent("automation.run", nil).CausedBy(*hotkey)
+	automation.Timestamp = now.Add(-time.Second)
+
+	unrelated := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"content": "x"})
+	unrelated.CorrelationID = unrelated.ID
+
+	require.NoError(t, repo.SaveBatch([]events.Event{*automation, *root, *hotkey, *unrelated}))
+
+	// 从链上任意事件都能查到整条链
+	chain, err := repo.FindCausalChain(automation.ID)
+	require.NoError(t, err)
+	require.Len(t, chain, 3)
+	assert.Equal(t, root.ID, chain[0].ID)
+	assert.Equal(t, hotkey.ID, chain[1].ID)
+	assert.Equal(t, automation.ID, chain[2].ID)
+	assert.Equal(t, root.ID, chain[1].CausationID)
+	assert.Equal(t, hotkey.ID, chain[2].CausationID)
+	for _, event := range chain {
+		assert.Equal(t, root.ID, event.CorrelationID)
+	}
+
+	// 旧数据没有关联 ID，只返回事件自身
+	_, err = db.Exec(`INSERT INTO events (uuid, type, timestamp, data) VALUES (?, ?, ?, ?)`,
+		"legacy", events.EventTypeAppSwitch, now, `{}`)
+	require.NoError(t, err)
+	chain, err = repo.FindCausalChain("legacy")
+	require.NoError(t, err)
+	require.Len(t, chain, 1)
+	assert.Empty(t, chain[0].CorrelationID)
+
+	_, err = repo.FindCausalChain("missing")
+	assert.Error(t, err)
+}
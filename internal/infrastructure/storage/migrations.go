@@ -101,6 +101,111 @@ CREATE TABLE IF NOT EXISTS patterns (
 CREATE INDEX IF NOT EXISTS idx_patterns_automated ON patterns(is_automated);
 CREATE INDEX IF NOT EXISTS idx_patterns_support ON patterns(support_count);
 CREATE INDEX IF NOT EXISTS idx_patterns_hash ON patterns(sequence_hash);
+`,
+	},
+	{
+		Version: 5,
+		Name:    "add_sessions_event_ids",
+		SQL: `
+ALTER TABLE sessions ADD COLUMN event_ids JSON;
+
+CREATE INDEX IF NOT EXISTS idx_sessions_end_time ON sessions(end_time);
+`,
+	},
+	{
+		Version: 6,
+		Name:    "init_dead_letters_table",
+		SQL: `
+CREATE TABLE IF NOT EXISTS dead_letters (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid TEXT UNIQUE NOT NULL,
+    subscriber TEXT NOT NULL,
+    topic TEXT,
+    event JSON NOT NULL,
+    error TEXT,
+    attempts INTEGER DEFAULT 0,
+    failed_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_dead_letters_failed_at ON dead_letters(failed_at);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_subscriber ON dead_letters(subscriber);
+`,
+	},
+	{
+		Version: 7,
+		Name:    "add_events_schema_version",
+		SQL: `
+ALTER TABLE events ADD COLUMN schema_version INTEGER NOT NULL DEFAULT 0;
+`,
+	},
+	{
+		Version: 8,
+		Name:    "add_events_causation",
+		SQL: `
+ALTER TABLE events ADD COLUMN correlation_id TEXT;
+ALTER TABLE events ADD COLUMN causation_id TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_events_correlation_id ON events(correlation_id);
+CREATE INDEX IF NOT EXISTS idx_events_causation_id ON events(causation_id);
+`,
+	},
+	{
+		Version: 9,
+		Name:    "init_automations_table",
+		SQL: `
+CREATE TABLE IF NOT EXISTS automations (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    uuid TEXT UNIQUE NOT NULL,
+    name TEXT NOT NULL,
+    description TEXT,
+    pattern_id TEXT,
+    steps JSON,
+    enabled BOOLEAN DEFAULT 1,
+    created_at DATETIME NOT NULL,
+    updated_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_automations_pattern_id ON automations(pattern_id);
+CREATE INDEX IF NOT EXISTS idx_automations_created_at ON automations(created_at);
+`,
+	},
+	{
+		Version: 10,
+		Name:    "init_ai_usage_table",
+		SQL: `
+CREATE TABLE IF NOT EXISTS ai_usage (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    provider TEXT NOT NULL,
+    model TEXT NOT NULL,
+    pattern_id TEXT,
+    purpose TEXT NOT NULL,
+    input_tokens INTEGER NOT NULL DEFAULT 0,
+    output_tokens INTEGER NOT NULL DEFAULT 0,
+    cost_usd REAL NOT NULL DEFAULT 0,
+    created_at DATETIME NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_ai_usage_created_at ON ai_usage(created_at);
+CREATE INDEX IF NOT EXISTS idx_ai_usage_pattern_id ON ai_usage(pattern_id);
+`,
+	},
+	{
+		Version: 11,
+		Name:    "init_cache_entries_table",
+		SQL: `
+CREATE TABLE IF NOT EXISTS cache_entries (
+    namespace TEXT NOT NULL,
+    key TEXT NOT NULL,
+    value BLOB NOT NULL,
+    expires_at INTEGER NOT NULL DEFAULT 0,
+    created_at INTEGER NOT NULL,
+    accessed_at INTEGER NOT NULL,
+    PRIMARY KEY (namespace, key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_cache_entries_accessed_at ON cache_entries(namespace, accessed_at);
+CREATE INDEX IF NOT EXISTS idx_cache_entries_expires_at ON cache_entries(expires_at);
 `,
 	},
 }
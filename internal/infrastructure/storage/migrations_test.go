@@ -20,7 +20,7 @@ func TestRunMigrations_DuplicateVersion(t *testing.T) {
 	require.NoError(t, err)
 
 	// 手动插入重复的迁移记录（模拟不完整的状态）
-	_, err = db.Exec("INSERT INTO schema_migrations (version) VALUES (5)")
+	_, err = db.Exec("INSERT INTO schema_migrations (version) VALUES (?)", len(migrations)+1)
 	assert.NoError(t, err)
 
 	// 再次运行迁移应该跳过已应用的
@@ -38,11 +38,11 @@ func TestRunMigrations_AllMigrationsApplied(t *testing.T) {
 	err = RunMigrations(db)
 	require.NoError(t, err)
 
-	// 验证所有4个迁移都已应用
+	// 验证所有迁移都已应用
 	var count int
 	err = db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
 	require.NoError(t, err)
-	assert.Equal(t, 4, count)
+	assert.Equal(t, len(migrations), count)
 
 	// 再次执行应该跳过所有迁移
 	err = RunMigrations(db)
@@ -51,7 +51,7 @@ func TestRunMigrations_AllMigrationsApplied(t *testing.T) {
 	// 验证没有重复的迁移记录
 	err = db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
 	require.NoError(t, err)
-	assert.Equal(t, 4, count)
+	assert.Equal(t, len(migrations), count)
 }
 
 // TestRunMigrations_ConnectionClosed 测试数据库连接关闭的情况
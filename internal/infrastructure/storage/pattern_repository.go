@@ -240,7 +240,7 @@ func (r *SQLitePatternRepository) FindByID(id string) (*models.Pattern, error) {
 	)
 
 	if err == sql.ErrNoRows {
-		return nil, fmt.Errorf("模式不存在: %s", id)
+		return nil, fmt.Errorf("模式不存在: %s: %w", id, err)
 	}
 	if err != nil {
 		return nil, fmt.Errorf("查询模式失败: %w", err)
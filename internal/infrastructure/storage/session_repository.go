@@ -0,0 +1,349 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+// 编译期检查接口实现
+var _ models.SessionRepository = (*SQLiteSessionRepository)(nil)
+
+// sessionUpsertSQL 会话写入语句（按 uuid 覆盖已有会话）
+const sessionUpsertSQL = `
+	INSERT INTO sessions (uuid, application, bundle_id, start_time, end_time,
+		event_count, event_ids)
+	VALUES (?, ?, ?, ?, ?, ?, ?)
+	ON CONFLICT(uuid) DO UPDATE SET
+		application = excluded.application,
+		bundle_id = excluded.bundle_id,
+		start_time = excluded.start_time,
+		end_time = excluded.end_time,
+		event_count = excluded.event_count,
+		event_ids = excluded.event_ids
+`
+
+// sessionSelectSQL 会话查询字段
+const sessionSelectSQL = `
+	SELECT uuid, application, bundle_id, start_time, end_time, event_count, event_ids
+	FROM sessions
+`
+
+/**
+ * SQLiteSessionRepository SQLite 会话仓储实现
+ *
+ * 会话只保存元数据和事件ID列表，完整事件仍由事件仓储负责
+ */
+type SQLiteSessionRepository struct {
+	db *sql.DB
+}
+
+/**
+ * NewSQLiteSessionRepository 创建 SQLite 会话仓储
+ *
+ * Parameters:
+ *   - db: 数据库连接
+ *
+ * Returns: *SQLiteSessionRepository - 会话仓储实例
+ */
+func NewSQLiteSessionRepository(db *sql.DB) *SQLiteSessionRepository {
+	return &SQLiteSessionRepository{db: db}
+}
+
+/**
+ * Save 保存会话
+ *
+ * 会话已存在时覆盖其内容
+ *
+ * Parameters:
+ *   - session: 会话对象
+ *
+ * Returns: error - 错误信息
+ */
+func (r *SQLiteSessionRepository) Save(session *models.Session) error {
+	args, err := r.sessionArgs(session)
+	if err != nil {
+		return err
+	}
+
+	if _, err := r.db.Exec(sessionUpsertSQL, args...); err != nil {
+		logger.Error("保存会话失败",
+			zap.String("session_id", session.ID),
+			zap.Error(err))
+		return fmt.Errorf("保存会话失败: %w", err)
+	}
+
+	logger.Debug("会话已保存",
+		zap.String("session_id", session.ID),
+		zap.Int("event_count", session.EventCount))
+
+	return nil
+}
+
+/**
+ * SaveBatch 批量保存会话
+ *
+ * Parameters:
+ *   - sessions: 会话数组
+ *
+ * Returns: error - 错误信息
+ */
+func (r *SQLiteSessionRepository) SaveBatch(sessions []*models.Session) error {
+	if len(sessions) == 0 {
+		return nil
+	}
+
+	// 开启事务
+	tx, err := r.db.Begin()
+	if err != nil {
+		return fmt.Errorf("开启事务失败: %w", err)
+	}
+	defer tx.Rollback()
+
+	// 准备语句
+	stmt, err := tx.Prepare(sessionUpsertSQL)
+	if err != nil {
+		return fmt.Errorf("准备语句失败: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, session := range sessions {
+		args, err := r.sessionArgs(session)
+		if err != nil {
+			return err
+		}
+
+		if _, err := stmt.Exec(args...); err != nil {
+			return fmt.Errorf("保存会话失败: %w", err)
+		}
+	}
+
+	// 提交事务
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("提交事务失败: %w", err)
+	}
+
+	logger.Debug("批量保存会话成功", zap.Int("count", len(sessions)))
+	return nil
+}
+
+/**
+ * FindByID 根据ID查询会话
+ *
+ * Parameters:
+ *   - id: 会话ID
+ *
+ * Returns: *models.Session - 会话对象, error - 错误信息
+ */
+func (r *SQLiteSessionRepository) FindByID(id string) (*models.Session, error) {
+	rows, err := r.db.Query(sessionSelectSQL+" WHERE uuid = ?", id)
+	if err != nil {
+		return nil, fmt.Errorf("查询会话失败: %w", err)
+	}
+	defer rows.Close()
+
+	sessions, err := r.scanSessions(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(sessions) == 0 {
+		return nil, fmt.Errorf("会话不存在: %s", id)
+	}
+
+	return sessions[0], nil
+}
+
+/**
+ * FindByTimeRange 按时间范围查询会话
+ *
+ * 返回与 [start, end] 有交集的会话，进行中的会话视为持续到当前
+ *
+ * Parameters:
+ *   - start: 开始时间
+ *   - end: 结束时间
+ *
+ * Returns: []*models.Session - 会话列表（按开始时间升序）, error - 错误信息
+ */
+func (r *SQLiteSessionRepository) FindByTimeRange(start, end time.Time) ([]*models.Session, error) {
+	query := sessionSelectSQL + `
+		WHERE start_time <= ? AND (end_time IS NULL OR end_time >= ?)
+		ORDER BY start_time ASC
+	`
+
+	rows, err := r.db.Query(query, end, start)
+	if err != nil {
+		return nil, fmt.Errorf("按时间范围查询会话失败: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanSessions(rows)
+}
+
+/**
+ * FindByApplication 按应用查询会话
+ *
+ * Parameters:
+ *   - application: 应用名称
+ *   - limit: 返回数量上限
+ *
+ * Returns: []*models.Session - 会话列表（按开始时间降序）, error - 错误信息
+ */
+func (r *SQLiteSessionRepository) FindByApplication(application string, limit int) ([]*models.Session, error) {
+	query := sessionSelectSQL + `
+		WHERE application = ?
+		ORDER BY start_time DESC
+		LIMIT ?
+	`
+
+	rows, err := r.db.Query(query, application, limit)
+	if err != nil {
+		return nil, fmt.Errorf("按应用查询会话失败: %w", err)
+	}
+	defer rows.Close()
+
+	return r.scanSessions(rows)
+}
+
+/**
+ * DeleteOlderThan 删除开始时间早于指定时间的会话
+ *
+ * Parameters:
+ *   - cutoff: 截止时间
+ *
+ * Returns: int64 - 删除的记录数, error - 错误信息
+ */
+func (r *SQLiteSessionRepository) DeleteOlderThan(cutoff time.Time) (int64, error) {
+	result, err := r.db.Exec("DELETE FROM sessions WHERE start_time < ?", cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("删除旧会话失败: %w", err)
+	}
+
+	count, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("获取删除行数失败: %w", err)
+	}
+
+	if count > 0 {
+		logger.Info("删除旧会话",
+			zap.Int64("count", count),
+			zap.Time("cutoff", cutoff),
+		)
+	}
+
+	return count, nil
+}
+
+/**
+ * sessionArgs 构造会话写入参数
+ *
+ * 事件ID优先取 EventIDs，为空时从 Events 中提取
+ *
+ * Parameters:
+ *   - session: 会话对象
+ *
+ * Returns: []interface{} - SQL 参数, error - 错误信息
+ */
+func (r *SQLiteSessionRepository) sessionArgs(session *models.Session) ([]interface{}, error) {
+	if session == nil {
+		return nil, fmt.Errorf("会话不能为空")
+	}
+
+	eventIDs := session.EventIDs
+	if len(eventIDs) == 0 && len(session.Events) > 0 {
+		eventIDs = make([]string, 0, len(session.Events))
+		for _, event := range session.Events {
+			eventIDs = append(eventIDs, event.ID)
+		}
+	}
+	if eventIDs == nil {
+		eventIDs = []string{}
+	}
+
+	eventIDsJSON, err := json.Marshal(eventIDs)
+	if err != nil {
+		return nil, fmt.Errorf("序列化会话事件ID失败: %w", err)
+	}
+
+	var bundleID sql.NullString
+	if session.BundleID != "" {
+		bundleID.String = session.BundleID
+		bundleID.Valid = true
+	}
+
+	var endTime sql.NullTime
+	if session.EndTime != nil {
+		endTime.Time = *session.EndTime
+		endTime.Valid = true
+	}
+
+	return []interface{}{
+		session.ID,
+		session.Application,
+		bundleID,
+		session.StartTime,
+		endTime,
+		session.EventCount,
+		string(eventIDsJSON),
+	}, nil
+}
+
+/**
+ * scanSessions 扫描会话行并转换为会话对象
+ *
+ * Parameters:
+ *   - rows: 查询结果集
+ *
+ * Returns: []*models.Session - 会话列表, error - 错误信息
+ */
+func (r *SQLiteSessionRepository) scanSessions(rows *sql.Rows) ([]*models.Session, error) {
+	var sessions []*models.Session
+
+	for rows.Next() {
+		var session models.Session
+		var bundleID, eventIDsJSON sql.NullString
+		var endTime sql.NullTime
+
+		err := rows.Scan(
+			&session.ID,
+			&session.Application,
+			&bundleID,
+			&session.StartTime,
+			&endTime,
+			&session.EventCount,
+			&eventIDsJSON,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("扫描会话行失败: %w", err)
+		}
+
+		if bundleID.Valid {
+			session.BundleID = bundleID.String
+		}
+		if endTime.Valid {
+			t := endTime.Time
+			session.EndTime = &t
+		}
+
+		// 反序列化事件ID列表
+		if eventIDsJSON.Valid && eventIDsJSON.String != "" {
+			if err := json.Unmarshal([]byte(eventIDsJSON.String), &session.EventIDs); err != nil {
+				logger.Warn("反序列化会话事件ID失败",
+					zap.String("session_id", session.ID),
+					zap.Error(err))
+			}
+		}
+
+		sessions = append(sessions, &session)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("遍历会话行失败: %w", err)
+	}
+
+	return sessions, nil
+}
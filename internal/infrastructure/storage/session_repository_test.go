@@ -0,0 +1,195 @@
+package storage
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestSession 创建测试会话
+func newTestSession(id, app string, start time.Time, duration time.Duration, eventIDs ...string) *models.Session {
+	end := start.Add(duration)
+	return &models.Session{
+		ID:          id,
+		StartTime:   start,
+		EndTime:     &end,
+		Application: app,
+		BundleID:    "com.test." + app,
+		EventCount:  len(eventIDs),
+		EventIDs:    eventIDs,
+	}
+}
+
+// TestSQLiteSessionRepository_SaveAndFindByID 测试保存并按ID查询会话
+func TestSQLiteSessionRepository_SaveAndFindByID(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	start := time.Now().Add(-time.Hour).Truncate(time.Second)
+	session := newTestSession("session-1", "VSCode", start, 10*time.Minute, "e1", "e2", "e3")
+
+	require.NoError(t, repo.Save(session))
+
+	found, err := repo.FindByID("session-1")
+	require.NoError(t, err)
+	assert.Equal(t, "VSCode", found.Application)
+	assert.Equal(t, "com.test.VSCode", found.BundleID)
+	assert.Equal(t, 3, found.EventCount)
+	assert.Equal(t, []string{"e1", "e2", "e3"}, found.EventIDs)
+	assert.True(t, start.Equal(found.StartTime))
+	require.NotNil(t, found.EndTime)
+	assert.True(t, session.EndTime.Equal(*found.EndTime))
+}
+
+// TestSQLiteSessionRepository_Save_DerivesEventIDs 测试从事件列表提取事件ID
+func TestSQLiteSessionRepository_Save_DerivesEventIDs(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	evt1 := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{})
+	evt2 := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{})
+
+	session := &models.Session{
+		ID:          "session-events",
+		StartTime:   time.Now(),
+		Application: "Terminal",
+		EventCount:  2,
+		Events:      []events.Event{*evt1, *evt2},
+	}
+	require.NoError(t, repo.Save(session))
+
+	found, err := repo.FindByID("session-events")
+	require.NoError(t, err)
+	assert.Equal(t, []string{evt1.ID, evt2.ID}, found.EventIDs)
+	assert.Nil(t, found.EndTime, "进行中的会话不应有结束时间")
+}
+
+// TestSQLiteSessionRepository_Save_Upsert 测试重复保存会覆盖会话
+func TestSQLiteSessionRepository_Save_Upsert(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	start := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.Save(newTestSession("session-1", "VSCode", start, time.Minute, "e1")))
+	require.NoError(t, repo.Save(newTestSession("session-1", "VSCode", start, 2*time.Minute, "e1", "e2")))
+
+	found, err := repo.FindByID("session-1")
+	require.NoError(t, err)
+	assert.Equal(t, 2, found.EventCount)
+	assert.Equal(t, []string{"e1", "e2"}, found.EventIDs)
+}
+
+// TestSQLiteSessionRepository_FindByID_NotFound 测试查询不存在的会话
+func TestSQLiteSessionRepository_FindByID_NotFound(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	_, err := repo.FindByID("missing")
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "会话不存在")
+}
+
+// TestSQLiteSessionRepository_SaveBatch 测试批量保存会话
+func TestSQLiteSessionRepository_SaveBatch(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	start := time.Now().Add(-24 * time.Hour)
+	var sessions []*models.Session
+	for i := 0; i < 20; i++ {
+		sessions = append(sessions, newTestSession(
+			fmt.Sprintf("session-%d", i), "VSCode",
+			start.Add(time.Duration(i)*time.Minute), 30*time.Second,
+			fmt.Sprintf("e%d", i)))
+	}
+
+	require.NoError(t, repo.SaveBatch(sessions))
+	require.NoError(t, repo.SaveBatch(nil))
+
+	found, err := repo.FindByApplication("VSCode", 100)
+	require.NoError(t, err)
+	assert.Len(t, found, 20)
+}
+
+// TestSQLiteSessionRepository_FindByTimeRange 测试按时间范围查询会话
+func TestSQLiteSessionRepository_FindByTimeRange(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	base := time.Now().Add(-3 * time.Hour)
+	require.NoError(t, repo.SaveBatch([]*models.Session{
+		newTestSession("before", "VSCode", base, 10*time.Minute),
+		newTestSession("overlap", "VSCode", base.Add(50*time.Minute), 20*time.Minute),
+		newTestSession("inside", "Chrome", base.Add(80*time.Minute), 10*time.Minute),
+		newTestSession("after", "Chrome", base.Add(150*time.Minute), 10*time.Minute),
+	}))
+
+	found, err := repo.FindByTimeRange(base.Add(time.Hour), base.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "overlap", found[0].ID)
+	assert.Equal(t, "inside", found[1].ID)
+}
+
+// TestSQLiteSessionRepository_FindByApplication 测试按应用查询会话
+func TestSQLiteSessionRepository_FindByApplication(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	base := time.Now().Add(-time.Hour)
+	require.NoError(t, repo.SaveBatch([]*models.Session{
+		newTestSession("vscode-1", "VSCode", base, time.Minute),
+		newTestSession("vscode-2", "VSCode", base.Add(10*time.Minute), time.Minute),
+		newTestSession("vscode-3", "VSCode", base.Add(20*time.Minute), time.Minute),
+		newTestSession("chrome-1", "Chrome", base.Add(5*time.Minute), time.Minute),
+	}))
+
+	found, err := repo.FindByApplication("VSCode", 2)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, "vscode-3", found[0].ID, "应按开始时间降序返回")
+	assert.Equal(t, "vscode-2", found[1].ID)
+}
+
+// TestSQLiteSessionRepository_DeleteOlderThan 测试删除旧会话
+func TestSQLiteSessionRepository_DeleteOlderThan(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewSQLiteSessionRepository(db)
+
+	now := time.Now()
+	require.NoError(t, repo.SaveBatch([]*models.Session{
+		newTestSession("old-1", "VSCode", now.Add(-48*time.Hour), time.Minute),
+		newTestSession("old-2", "Chrome", now.Add(-36*time.Hour), time.Minute),
+		newTestSession("recent", "VSCode", now.Add(-time.Hour), time.Minute),
+	}))
+
+	count, err := repo.DeleteOlderThan(now.Add(-24 * time.Hour))
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	_, err = repo.FindByID("recent")
+	assert.NoError(t, err)
+	_, err = repo.FindByID("old-1")
+	assert.Error(t, err)
+}
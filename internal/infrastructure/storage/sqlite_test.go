@@ -46,7 +46,7 @@ func TestRunMigrations(t *testing.T) {
 	require.NoError(t, err)
 
 	// 验证表是否创建
-	tables := []string{"events", "sessions", "patterns", "schema_migrations"}
+	tables := []string{"events", "sessions", "patterns", "schema_migrations", "dead_letters"}
 	for _, table := range tables {
 		var count int
 		err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name=?", table).Scan(&count)
@@ -58,7 +58,7 @@ func TestRunMigrations(t *testing.T) {
 	var version int
 	err = db.QueryRow("SELECT MAX(version) FROM schema_migrations").Scan(&version)
 	require.NoError(t, err)
-	assert.Equal(t, 4, version, "应该有 4 个迁移版本")
+	assert.Equal(t, migrations[len(migrations)-1].Version, version, "最大迁移版本应与最后一个迁移一致")
 }
 
 // TestRunMigrations_Idempotent 测试迁移的幂等性
@@ -81,7 +81,7 @@ func TestRunMigrations_Idempotent(t *testing.T) {
 	var count int
 	err = db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
 	require.NoError(t, err)
-	assert.Equal(t, 4, count)
+	assert.Equal(t, len(migrations), count)
 }
 
 // TestNewSQLiteDB_InvalidPath 测试无效路径的错误处理
@@ -208,7 +208,7 @@ func TestRunMigrations_Partial(t *testing.T) {
 	var count int
 	err = db.QueryRow("SELECT COUNT(*) FROM schema_migrations").Scan(&count)
 	require.NoError(t, err)
-	assert.Equal(t, 4, count)
+	assert.Equal(t, len(migrations), count)
 }
 
 // TestMigrations_DataValidation 测试迁移后的数据验证
@@ -229,7 +229,7 @@ func TestMigrations_DataValidation(t *testing.T) {
 	require.NoError(t, err)
 	defer rows.Close()
 
-	expectedColumns := []string{"id", "uuid", "type", "timestamp", "data", "application", "bundle_id", "window_title", "file_path", "selection", "created_at"}
+	expectedColumns := []string{"id", "uuid", "type", "timestamp", "data", "application", "bundle_id", "window_title", "file_path", "selection", "created_at", "schema_version", "correlation_id", "causation_id"}
 	for rows.Next() {
 		var cid int
 		var name, dataType string
@@ -380,7 +380,7 @@ func TestRunMigrations_EmptyDatabase(t *testing.T) {
 	var tableCount int
 	err = db.QueryRow("SELECT COUNT(*) FROM sqlite_master WHERE type='table' AND name NOT LIKE 'sqlite_%'").Scan(&tableCount)
 	require.NoError(t, err)
-	assert.Equal(t, 4, tableCount, "应该创建4个表")
+	assert.Equal(t, 8, tableCount, "应该创建8个表")
 }
 
 // TestRunMigrations_RecoverableError 测试迁移中的可恢复错误
@@ -0,0 +1,155 @@
+package mcp
+
+import "encoding/json"
+
+/**
+ * JSON-RPC 2.0 标准错误码
+ */
+const (
+	codeParseError     = -32700
+	codeInvalidRequest = -32600
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+/**
+ * request JSON-RPC 请求或通知
+ *
+ * 通知没有 id，服务端不回复
+ */
+type request struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+/**
+ * isNotification 判断是否为通知
+ */
+func (r *request) isNotification() bool {
+	return len(r.ID) == 0
+}
+
+/**
+ * response JSON-RPC 响应
+ */
+type response struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  interface{}     `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+}
+
+/**
+ * rpcError JSON-RPC 错误
+ */
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+/**
+ * Error 实现 error 接口
+ */
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+/**
+ * initializeParams initialize 请求参数
+ */
+type initializeParams struct {
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+/**
+ * initializeResult initialize 响应
+ */
+type initializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      implementation         `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+/**
+ * implementation 服务端信息
+ */
+type implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+/**
+ * toolDescriptor tools/list 中的工具描述
+ */
+type toolDescriptor struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+	Annotations map[string]interface{} `json:"annotations,omitempty"`
+}
+
+/**
+ * callToolParams tools/call 请求参数
+ */
+type callToolParams struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+/**
+ * callToolResult tools/call 响应
+ *
+ * 工具执行失败时 IsError 为 true，错误信息放在内容中，让模型能看到并调整参数
+ */
+type callToolResult struct {
+	Content []content `json:"content"`
+	IsError bool      `json:"isError,omitempty"`
+}
+
+/**
+ * content 工具返回的文本内容
+ */
+type content struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+/**
+ * resourceDescriptor resources/list 中的资源描述
+ */
+type resourceDescriptor struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+/**
+ * resourceTemplate resources/templates/list 中的资源模板
+ */
+type resourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+/**
+ * readResourceParams resources/read 请求参数
+ */
+type readResourceParams struct {
+	URI string `json:"uri"`
+}
+
+/**
+ * resourceContents resources/read 返回的资源内容
+ */
+type resourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType,omitempty"`
+	Text     string `json:"text"`
+}
@@ -0,0 +1,179 @@
+package mcp
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/domain/privacy"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+)
+
+/**
+ * 会话资源的 URI
+ */
+const (
+	recentSessionsURI = "flowmind://sessions/recent"
+	sessionURIPrefix  = "flowmind://sessions/"
+)
+
+/**
+ * sessionView 会话的资源数据结构
+ */
+type sessionView struct {
+	ID              string         `json:"id"`
+	Application     string         `json:"application"`
+	BundleID        string         `json:"bundle_id,omitempty"`
+	StartTime       time.Time      `json:"start_time"`
+	EndTime         *time.Time     `json:"end_time,omitempty"`
+	DurationSeconds int64          `json:"duration_seconds"`
+	EventCount      int            `json:"event_count"`
+	Events          []events.Event `json:"events,omitempty"`
+}
+
+/**
+ * listResources 列出已授予权限范围的资源
+ */
+func (s *Server) listResources() interface{} {
+	resources := make([]resourceDescriptor, 0, 1)
+	if s.scopes[ScopeSessions] && s.deps.Sessions != nil {
+		resources = append(resources, resourceDescriptor{
+			URI:         recentSessionsURI,
+			Name:        "最近的会话",
+			Description: "最近 24 小时内的应用会话（应用、起止时间、事件数）",
+			MimeType:    "application/json",
+		})
+	}
+	return map[string]interface{}{"resources": resources}
+}
+
+/**
+ * listResourceTemplates 列出已授予权限范围的资源模板
+ */
+func (s *Server) listResourceTemplates() interface{} {
+	templates := make([]resourceTemplate, 0, 1)
+	if s.scopes[ScopeSessions] && s.deps.Sessions != nil {
+		templates = append(templates, resourceTemplate{
+			URITemplate: sessionURIPrefix + "{id}",
+			Name:        "会话详情",
+			Description: "单个会话及其包含的事件",
+			MimeType:    "application/json",
+		})
+	}
+	return map[string]interface{}{"resourceTemplates": templates}
+}
+
+/**
+ * readResource 读取资源
+ */
+func (s *Server) readResource(params json.RawMessage) (interface{}, error) {
+	var p readResourceParams
+	if err := json.Unmarshal(params, &p); err != nil || p.URI == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "无效的 resources/read 参数"}
+	}
+	if !s.scopes[ScopeSessions] || s.deps.Sessions == nil || !strings.HasPrefix(p.URI, sessionURIPrefix) {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("未知资源: %s", p.URI)}
+	}
+
+	var value interface{}
+	var err error
+	if p.URI == recentSessionsURI {
+		value, err = s.recentSessions()
+	} else {
+		value, err = s.sessionDetail(strings.TrimPrefix(p.URI, sessionURIPrefix))
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化资源失败: %w", err)
+	}
+	return map[string]interface{}{
+		"contents": []resourceContents{{URI: p.URI, MimeType: "application/json", Text: string(text)}},
+	}, nil
+}
+
+/**
+ * recentSessions 最近 24 小时的会话，按开始时间升序
+ */
+func (s *Server) recentSessions() (interface{}, error) {
+	now := s.now()
+	sessions, err := s.deps.Sessions.FindByTimeRange(now.Add(-defaultLookback), now)
+	if err != nil {
+		return nil, err
+	}
+
+	views := make([]sessionView, 0, len(sessions))
+	for _, session := range sessions {
+		if s.sessionHidden(session) {
+			continue
+		}
+		views = append(views, s.newSessionView(session))
+	}
+	return map[string]interface{}{"sessions": views}, nil
+}
+
+/**
+ * sessionDetail 单个会话及其事件
+ */
+func (s *Server) sessionDetail(id string) (interface{}, error) {
+	session, err := s.deps.Sessions.FindByID(id)
+	if err != nil || s.sessionHidden(session) {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("会话不存在: %s", id)}
+	}
+
+	view := s.newSessionView(session)
+	end := s.now()
+	if session.EndTime != nil {
+		end = *session.EndTime
+	}
+
+	ids := make(map[string]bool, len(session.EventIDs))
+	for _, eventID := range session.EventIDs {
+		ids[eventID] = true
+	}
+	all, err := s.deps.Events.FindByTimeRange(session.StartTime, end)
+	if err != nil {
+		return nil, err
+	}
+	view.Events = make([]events.Event, 0, len(session.EventIDs))
+	for _, event := range all {
+		if !ids[event.ID] {
+			continue
+		}
+		if visible, ok := s.visibleEvent(event); ok {
+			view.Events = append(view.Events, visible)
+		}
+	}
+	return view, nil
+}
+
+/**
+ * sessionHidden 会话所在应用是否被隐私规则禁止采集
+ */
+func (s *Server) sessionHidden(session *models.Session) bool {
+	return s.deps.Policy.LevelFor(session.BundleID, session.Application, "") == privacy.CaptureNone
+}
+
+func (s *Server) newSessionView(session *models.Session) sessionView {
+	view := sessionView{
+		ID:          session.ID,
+		Application: session.Application,
+		BundleID:    session.BundleID,
+		StartTime:   session.StartTime,
+		EndTime:     session.EndTime,
+		EventCount:  session.EventCount,
+	}
+	end := s.now()
+	if session.EndTime != nil {
+		end = *session.EndTime
+	}
+	if end.After(session.StartTime) {
+		view.DurationSeconds = int64(end.Sub(session.StartTime).Seconds())
+	}
+	return view
+}
@@ -0,0 +1,295 @@
+/**
+ * Package mcp 提供 Model Context Protocol 服务
+ *
+ * 让编码智能体通过 MCP 查询用户的工作流历史：搜索事件、列出模式、统计应用使用、
+ * 查看剪贴板历史，以及读取最近的会话。所有操作都是只读的，返回前按隐私策略
+ * 重新过滤事件；每个工具和资源需要对应的权限范围，未授予的不会暴露给客户端。
+ * 支持 stdio 和本机 HTTP 两种传输
+ */
+
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/domain/privacy"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"go.uber.org/zap"
+)
+
+/**
+ * ProtocolVersion 服务端支持的最新协议版本
+ */
+const ProtocolVersion = "2025-03-26"
+
+/**
+ * supportedVersions 支持的协议版本，客户端请求其中之一时按客户端版本响应
+ */
+var supportedVersions = map[string]bool{
+	"2024-11-05": true,
+	"2025-03-26": true,
+	"2025-06-18": true,
+}
+
+/**
+ * Scope 只读权限范围
+ */
+type Scope string
+
+const (
+	// ScopeEvents 搜索事件
+	ScopeEvents Scope = "events:read"
+
+	// ScopePatterns 列出模式
+	ScopePatterns Scope = "patterns:read"
+
+	// ScopeAppUsage 应用使用统计
+	ScopeAppUsage Scope = "app_usage:read"
+
+	// ScopeClipboard 剪贴板历史（包含剪贴板内容）
+	ScopeClipboard Scope = "clipboard:read"
+
+	// ScopeSessions 会话资源
+	ScopeSessions Scope = "sessions:read"
+)
+
+/**
+ * DefaultScopes 默认授予的权限范围
+ *
+ * 剪贴板历史包含剪贴板内容，需要显式授予
+ */
+var DefaultScopes = []Scope{ScopeEvents, ScopePatterns, ScopeAppUsage, ScopeSessions}
+
+/**
+ * ParseScopes 解析配置中的权限范围
+ *
+ * Parameters:
+ *   - values: 权限范围名称，为 nil 时返回 DefaultScopes
+ *
+ * Returns:
+ *   - []Scope: 权限范围
+ *   - error: 包含未知权限范围时返回错误
+ */
+func ParseScopes(values []string) ([]Scope, error) {
+	if values == nil {
+		return append([]Scope(nil), DefaultScopes...), nil
+	}
+
+	known := map[Scope]bool{
+		ScopeEvents: true, ScopePatterns: true, ScopeAppUsage: true,
+		ScopeClipboard: true, ScopeSessions: true,
+	}
+	scopes := make([]Scope, 0, len(values))
+	for _, value := range values {
+		scope := Scope(value)
+		if !known[scope] {
+			return nil, fmt.Errorf("未知的 MCP 权限范围: %s", value)
+		}
+		scopes = append(scopes, scope)
+	}
+	return scopes, nil
+}
+
+/**
+ * Deps MCP 服务的数据来源
+ */
+type Deps struct {
+	// Events 事件仓储
+	Events storage.EventRepository
+
+	// Patterns 模式仓储
+	Patterns models.PatternRepository
+
+	// Sessions 会话仓储
+	Sessions models.SessionRepository
+
+	// Policy 隐私策略，为 nil 时使用内置规则
+	Policy *privacy.Policy
+}
+
+/**
+ * Server MCP 服务
+ *
+ * 与传输无关，Handle 处理一条 JSON-RPC 消息，可并发调用
+ */
+type Server struct {
+	deps    Deps
+	scopes  map[Scope]bool
+	version string
+	tools   []*tool
+
+	// now 当前时间，测试时可替换
+	now func() time.Time
+}
+
+/**
+ * NewServer 创建 MCP 服务
+ *
+ * Parameters:
+ *   - deps: 数据来源
+ *   - scopes: 授予的权限范围
+ *   - version: 服务端版本号，在 initialize 响应中返回
+ *
+ * Returns:
+ *   - *Server: MCP 服务
+ */
+func NewServer(deps Deps, scopes []Scope, version string) *Server {
+	if deps.Policy == nil {
+		deps.Policy = privacy.NewDefaultPolicy()
+	}
+	s := &Server{
+		deps:    deps,
+		scopes:  make(map[Scope]bool, len(scopes)),
+		version: version,
+		now:     time.Now,
+	}
+	for _, scope := range scopes {
+		s.scopes[scope] = true
+	}
+	s.tools = s.builtinTools()
+	return s
+}
+
+/**
+ * Scopes 获取已授予的权限范围
+ *
+ * Returns:
+ *   - []Scope: 按名称排序的权限范围
+ */
+func (s *Server) Scopes() []Scope {
+	scopes := make([]Scope, 0, len(s.scopes))
+	for scope := range s.scopes {
+		scopes = append(scopes, scope)
+	}
+	sort.Slice(scopes, func(i, j int) bool { return scopes[i] < scopes[j] })
+	return scopes
+}
+
+/**
+ * Handle 处理一条 JSON-RPC 消息
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - message: 请求或通知的 JSON
+ *
+ * Returns:
+ *   - []byte: 响应的 JSON，通知返回 nil
+ */
+func (s *Server) Handle(ctx context.Context, message []byte) []byte {
+	var req request
+	if err := json.Unmarshal(message, &req); err != nil {
+		return encodeResponse(response{
+			JSONRPC: "2.0",
+			ID:      json.RawMessage("null"),
+			Error:   &rpcError{Code: codeParseError, Message: "无法解析 JSON-RPC 消息"},
+		})
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		if req.isNotification() {
+			return nil
+		}
+		return encodeResponse(response{
+			JSONRPC: "2.0",
+			ID:      req.ID,
+			Error:   &rpcError{Code: codeInvalidRequest, Message: "无效的 JSON-RPC 请求"},
+		})
+	}
+
+	result, err := s.dispatch(ctx, &req)
+	if req.isNotification() {
+		return nil
+	}
+
+	resp := response{JSONRPC: "2.0", ID: req.ID, Result: result}
+	if err != nil {
+		rpcErr, ok := err.(*rpcError)
+		if !ok {
+			logger.Warn("处理 MCP 请求失败",
+				zap.String("component", "mcp"),
+				zap.String("method", req.Method),
+				zap.Error(err),
+			)
+			rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		resp.Result = nil
+		resp.Error = rpcErr
+	}
+	return encodeResponse(resp)
+}
+
+/**
+ * dispatch 按方法名分发请求
+ */
+func (s *Server) dispatch(ctx context.Context, req *request) (interface{}, error) {
+	switch req.Method {
+	case "initialize":
+		return s.initialize(req.Params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return s.listTools(), nil
+	case "tools/call":
+		return s.callTool(ctx, req.Params)
+	case "resources/list":
+		return s.listResources(), nil
+	case "resources/templates/list":
+		return s.listResourceTemplates(), nil
+	case "resources/read":
+		return s.readResource(req.Params)
+	default:
+		if req.isNotification() {
+			// notifications/initialized 等通知无需处理
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: fmt.Sprintf("未知方法: %s", req.Method)}
+	}
+}
+
+/**
+ * initialize 协商协议版本并返回服务端能力
+ */
+func (s *Server) initialize(params json.RawMessage) (interface{}, error) {
+	var p initializeParams
+	if len(params) > 0 {
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "无效的 initialize 参数"}
+		}
+	}
+
+	version := ProtocolVersion
+	if supportedVersions[p.ProtocolVersion] {
+		version = p.ProtocolVersion
+	}
+
+	return initializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]interface{}{
+			"tools":     map[string]interface{}{},
+			"resources": map[string]interface{}{},
+		},
+		ServerInfo: implementation{Name: "flowmind", Version: s.version},
+		Instructions: "FlowMind 记录用户在本机的键盘、剪贴板和应用切换等操作。" +
+			"可以用这些工具了解用户最近在做什么；所有数据只读，已按用户的隐私规则过滤。",
+	}, nil
+}
+
+/**
+ * encodeResponse 序列化响应
+ */
+func encodeResponse(resp response) []byte {
+	data, err := json.Marshal(resp)
+	if err != nil {
+		data, _ = json.Marshal(response{
+			JSONRPC: "2.0",
+			ID:      resp.ID,
+			Error:   &rpcError{Code: codeInternalError, Message: "序列化响应失败"},
+		})
+	}
+	return data
+}
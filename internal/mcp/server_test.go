@@ -0,0 +1,388 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/domain/privacy"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// allScopes 所有权限范围
+var allScopes = []Scope{ScopeEvents, ScopePatterns, ScopeAppUsage, ScopeClipboard, ScopeSessions}
+
+// newTestServer 创建使用临时数据库的 MCP 服务
+//
+// 数据包括终端、Slack（metadata 级别）和 1Password（禁止采集）的事件和会话，
+// 以及按 Bundle ID 禁止采集的 Bitwarden 模式
+func newTestServer(t *testing.T, scopes []Scope) *Server {
+	t.Helper()
+
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/test.db"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, storage.RunMigrations(db))
+
+	eventRepo := storage.NewSQLiteEventRepository(db)
+	patternRepo := storage.NewSQLitePatternRepository(db)
+	sessionRepo := storage.NewSQLiteSessionRepository(db)
+
+	now := time.Now()
+	newEvent := func(eventType events.EventType, app string, offset time.Duration, data map[string]interface{}) events.Event {
+		event := events.NewEvent(eventType, data)
+		event.Timestamp = now.Add(offset)
+		event.WithContext(&events.EventContext{Application: app, WindowTitle: app + " window"})
+		return *event
+	}
+	list := []events.Event{
+		newEvent(events.EventTypeKeyboard, "Terminal", -50*time.Minute, map[string]interface{}{"keycode": 36}),
+		newEvent(events.EventTypeClipboard, "Terminal", -40*time.Minute, map[string]interface{}{"content": "go test ./...", "type": "public.utf8-plain-text"}),
+		newEvent(events.EventTypeClipboard, "Slack", -30*time.Minute, map[string]interface{}{"content": "secret plan", "type": "public.utf8-plain-text"}),
+		newEvent(events.EventTypeClipboard, "1Password", -20*time.Minute, map[string]interface{}{"content": "hunter2"}),
+		newEvent(events.EventTypeKeyboard, "Terminal", -10*time.Minute, map[string]interface{}{"keycode": 36}),
+		newEvent(events.EventTypeKeyboard, "Terminal", -3*24*time.Hour, map[string]interface{}{"keycode": 1}),
+	}
+	require.NoError(t, eventRepo.SaveBatch(list))
+
+	sessionEnd := now.Add(-20 * time.Minute)
+	hiddenEnd := now.Add(-15 * time.Minute)
+	require.NoError(t, sessionRepo.SaveBatch([]*models.Session{
+		{
+			ID: "session-terminal", Application: "Terminal", StartTime: now.Add(-time.Hour), EndTime: &sessionEnd,
+			EventCount: 2, EventIDs: []string{list[0].ID, list[1].ID},
+		},
+		{
+			ID: "session-1password", Application: "1Password", StartTime: now.Add(-25 * time.Minute), EndTime: &hiddenEnd,
+			EventCount: 1, EventIDs: []string{list[3].ID},
+		},
+	}))
+
+	require.NoError(t, patternRepo.Save(&models.Pattern{
+		ID: "pattern-terminal",
+		Sequence: []models.EventStep{
+			{Type: events.EventTypeKeyboard, Action: "keypress", Context: &models.StepContext{Application: "Terminal"}},
+			{Type: events.EventTypeClipboard, Action: "clipboard_copy", Context: &models.StepContext{Application: "Terminal"}},
+		},
+		SupportCount: 8,
+		FirstSeen:    now.Add(-time.Hour),
+		LastSeen:     now,
+		AIAnalysis:   &models.AIAnalysis{ShouldAutomate: true, SuggestedName: "运行测试"},
+	}))
+	require.NoError(t, patternRepo.Save(&models.Pattern{
+		ID:           "pattern-1password",
+		Sequence:     []models.EventStep{{Type: events.EventTypeClipboard, Action: "clipboard_copy", Context: &models.StepContext{Application: "1Password"}}},
+		SupportCount: 20,
+		FirstSeen:    now.Add(-time.Hour),
+		LastSeen:     now,
+	}))
+
+	// 只能按 Bundle ID 识别的应用
+	require.NoError(t, patternRepo.Save(&models.Pattern{
+		ID: "pattern-bitwarden",
+		Sequence: []models.EventStep{
+			{Type: events.EventTypeAppSwitch, Action: "switch",
+				Context: &models.StepContext{Application: "Vault", BundleID: "com.bitwarden.desktop"}},
+			{Type: events.EventTypeClipboard, Action: "clipboard_copy",
+				Context: &models.StepContext{Application: "Vault", BundleID: "com.bitwarden.desktop"}},
+		},
+		SupportCount: 15,
+		FirstSeen:    now.Add(-time.Hour),
+		LastSeen:     now,
+	}))
+
+	policy := privacy.NewPolicy(privacy.CaptureFull,
+		privacy.PolicyRule{Name: "slack", App: "Slack", Level: privacy.CaptureMetadata},
+		privacy.PolicyRule{Name: "1password", App: "1Password", Level: privacy.CaptureNone},
+		privacy.PolicyRule{Name: "bitwarden", BundleID: "com.bitwarden.desktop", Level: privacy.CaptureNone},
+	)
+	return NewServer(Deps{
+		Events:   eventRepo,
+		Patterns: patternRepo,
+		Sessions: sessionRepo,
+		Policy:   policy,
+	}, scopes, "test")
+}
+
+// call 发送请求并返回响应
+func call(t *testing.T, server *Server, method string, params interface{}) map[string]interface{} {
+	t.Helper()
+	message := map[string]interface{}{"jsonrpc": "2.0", "id": 1, "method": method}
+	if params != nil {
+		message["params"] = params
+	}
+	data, err := json.Marshal(message)
+	require.NoError(t, err)
+
+	var resp map[string]interface{}
+	require.NoError(t, json.Unmarshal(server.Handle(context.Background(), data), &resp))
+	return resp
+}
+
+// callTool 调用工具并解析文本内容中的 JSON
+func callTool(t *testing.T, server *Server, name string, args map[string]interface{}) (map[string]interface{}, bool) {
+	t.Helper()
+	resp := call(t, server, "tools/call", map[string]interface{}{"name": name, "arguments": args})
+	require.Nil(t, resp["error"], "%v", resp["error"])
+
+	result := resp["result"].(map[string]interface{})
+	text := result["content"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	isError, _ := result["isError"].(bool)
+	if isError {
+		return map[string]interface{}{"error": text}, true
+	}
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(text), &out), text)
+	return out, false
+}
+
+// TestServer_Protocol 测试初始化、心跳、通知和错误处理
+func TestServer_Protocol(t *testing.T) {
+	server := newTestServer(t, DefaultScopes)
+
+	resp := call(t, server, "initialize", map[string]interface{}{
+		"protocolVersion": "2024-11-05",
+		"capabilities":    map[string]interface{}{},
+		"clientInfo":      map[string]interface{}{"name": "test", "version": "1"},
+	})
+	result := resp["result"].(map[string]interface{})
+	assert.Equal(t, "2024-11-05", result["protocolVersion"])
+	assert.Equal(t, "flowmind", result["serverInfo"].(map[string]interface{})["name"])
+	assert.Contains(t, result["capabilities"], "tools")
+	assert.Contains(t, result["capabilities"], "resources")
+
+	resp = call(t, server, "initialize", map[string]interface{}{"protocolVersion": "1999-01-01"})
+	assert.Equal(t, ProtocolVersion, resp["result"].(map[string]interface{})["protocolVersion"])
+
+	resp = call(t, server, "ping", nil)
+	assert.Equal(t, map[string]interface{}{}, resp["result"])
+
+	assert.Nil(t, server.Handle(context.Background(), []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)))
+
+	resp = call(t, server, "unknown/method", nil)
+	assert.Equal(t, float64(codeMethodNotFound), resp["error"].(map[string]interface{})["code"])
+
+	var parseErr map[string]interface{}
+	require.NoError(t, json.Unmarshal(server.Handle(context.Background(), []byte("{not json")), &parseErr))
+	assert.Equal(t, float64(codeParseError), parseErr["error"].(map[string]interface{})["code"])
+	assert.Nil(t, parseErr["id"])
+}
+
+// TestServer_Scopes 测试只暴露已授予权限范围的工具和资源
+func TestServer_Scopes(t *testing.T) {
+	server := newTestServer(t, DefaultScopes)
+
+	tools := call(t, server, "tools/list", nil)["result"].(map[string]interface{})["tools"].([]interface{})
+	var names []string
+	for _, item := range tools {
+		tool := item.(map[string]interface{})
+		names = append(names, tool["name"].(string))
+		assert.Equal(t, true, tool["annotations"].(map[string]interface{})["readOnlyHint"])
+	}
+	assert.Equal(t, []string{"search_events", "list_patterns", "get_app_usage"}, names)
+
+	// 未授予 clipboard:read 时剪贴板工具不可调用
+	resp := call(t, server, "tools/call", map[string]interface{}{"name": "get_clipboard_history"})
+	assert.Equal(t, float64(codeInvalidParams), resp["error"].(map[string]interface{})["code"])
+
+	// 只授予 patterns:read 时没有会话资源
+	limited := newTestServer(t, []Scope{ScopePatterns})
+	resources := call(t, limited, "resources/list", nil)["result"].(map[string]interface{})["resources"].([]interface{})
+	assert.Empty(t, resources)
+	resp = call(t, limited, "resources/read", map[string]interface{}{"uri": recentSessionsURI})
+	assert.NotNil(t, resp["error"])
+	resp = call(t, limited, "tools/call", map[string]interface{}{"name": "search_events"})
+	assert.NotNil(t, resp["error"])
+
+	scopes, err := ParseScopes(nil)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultScopes, scopes)
+	scopes, err = ParseScopes([]string{})
+	require.NoError(t, err)
+	assert.Empty(t, scopes)
+	_, err = ParseScopes([]string{"events:write"})
+	assert.Error(t, err)
+}
+
+// TestServer_SearchEvents 测试搜索事件并按隐私策略过滤
+func TestServer_SearchEvents(t *testing.T) {
+	server := newTestServer(t, allScopes)
+
+	out, isError := callTool(t, server, "search_events", nil)
+	require.False(t, isError)
+	// 默认查询最近 24 小时，1Password 的事件被隐私规则过滤
+	assert.Equal(t, float64(4), out["matched"])
+
+	out, _ = callTool(t, server, "search_events", map[string]interface{}{"query": "GO TEST"})
+	require.Equal(t, float64(1), out["matched"])
+	event := out["events"].([]interface{})[0].(map[string]interface{})
+	assert.Equal(t, "go test ./...", event["data"].(map[string]interface{})["content"])
+
+	// metadata 级别的应用内容已移除，无法按内容搜索
+	out, _ = callTool(t, server, "search_events", map[string]interface{}{"query": "secret"})
+	assert.Equal(t, float64(0), out["matched"])
+	out, _ = callTool(t, server, "search_events", map[string]interface{}{"app": "slack"})
+	require.Equal(t, float64(1), out["matched"])
+	data := out["events"].([]interface{})[0].(map[string]interface{})["data"].(map[string]interface{})
+	assert.NotContains(t, data, "content")
+	assert.Equal(t, "metadata", data["privacy_level"])
+
+	out, _ = callTool(t, server, "search_events", map[string]interface{}{"type": "keyboard", "from": "7d", "limit": 2})
+	assert.Equal(t, float64(3), out["matched"])
+	assert.Len(t, out["events"], 2)
+
+	out, isError = callTool(t, server, "search_events", map[string]interface{}{"from": "last week"})
+	assert.True(t, isError)
+	assert.Contains(t, out["error"], "无法解析时间")
+
+	out, isError = callTool(t, server, "search_events", map[string]interface{}{"unknown": 1})
+	assert.True(t, isError)
+	assert.Contains(t, out["error"], "无效的参数")
+}
+
+// TestServer_SearchEvents_DefaultScopes 测试未授予 clipboard:read 时不返回也不搜索剪贴板内容
+func TestServer_SearchEvents_DefaultScopes(t *testing.T) {
+	server := newTestServer(t, DefaultScopes)
+
+	out, isError := callTool(t, server, "search_events", map[string]interface{}{"query": "go test"})
+	require.False(t, isError)
+	assert.Equal(t, float64(0), out["matched"])
+
+	out, _ = callTool(t, server, "search_events", map[string]interface{}{"type": "clipboard", "app": "Terminal"})
+	require.Equal(t, float64(1), out["matched"])
+	data := out["events"].([]interface{})[0].(map[string]interface{})["data"].(map[string]interface{})
+	assert.NotContains(t, data, "content")
+	assert.Equal(t, "public.utf8-plain-text", data["type"])
+
+	// 会话资源中的剪贴板事件同样不含内容
+	resp := call(t, server, "resources/read", map[string]interface{}{"uri": "flowmind://sessions/session-terminal"})
+	text := resp["result"].(map[string]interface{})["contents"].([]interface{})[0].(map[string]interface{})["text"].(string)
+	assert.NotContains(t, text, "go test")
+}
+
+// TestServer_ListPatterns 测试列出模式并隐藏禁止采集应用的模式
+func TestServer_ListPatterns(t *testing.T) {
+	server := newTestServer(t, allScopes)
+
+	out, isError := callTool(t, server, "list_patterns", nil)
+	require.False(t, isError)
+	patterns := out["patterns"].([]interface{})
+	require.Len(t, patterns, 1)
+	assert.Equal(t, "pattern-terminal", patterns[0].(map[string]interface{})["id"])
+
+	out, _ = callTool(t, server, "list_patterns", map[string]interface{}{"automatable_only": true})
+	assert.Len(t, out["patterns"], 1)
+}
+
+// TestServer_AppUsage 测试应用使用统计
+func TestServer_AppUsage(t *testing.T) {
+	server := newTestServer(t, allScopes)
+
+	out, isError := callTool(t, server, "get_app_usage", nil)
+	require.False(t, isError)
+	apps := out["apps"].([]interface{})
+	require.Len(t, apps, 2)
+
+	terminal := apps[0].(map[string]interface{})
+	assert.Equal(t, "Terminal", terminal["application"])
+	assert.Equal(t, float64(1), terminal["sessions"])
+	assert.InDelta(t, 40*60, terminal["active_seconds"], 2)
+	assert.Equal(t, float64(3), terminal["events"])
+
+	slack := apps[1].(map[string]interface{})
+	assert.Equal(t, "Slack", slack["application"])
+	assert.Equal(t, float64(1), slack["events"])
+
+	for _, app := range apps {
+		assert.NotEqual(t, "1Password", app.(map[string]interface{})["application"])
+	}
+}
+
+// TestServer_ClipboardHistory 测试剪贴板历史
+func TestServer_ClipboardHistory(t *testing.T) {
+	server := newTestServer(t, allScopes)
+
+	out, isError := callTool(t, server, "get_clipboard_history", nil)
+	require.False(t, isError)
+	entries := out["entries"].([]interface{})
+	require.Len(t, entries, 2)
+
+	first := entries[0].(map[string]interface{})
+	assert.Equal(t, "Terminal", first["application"])
+	assert.Equal(t, "go test ./...", first["content"])
+	assert.Equal(t, "public.utf8-plain-text", first["content_type"])
+
+	second := entries[1].(map[string]interface{})
+	assert.Equal(t, "Slack", second["application"])
+	assert.NotContains(t, second, "content")
+	assert.Equal(t, "metadata", second["privacy_level"])
+
+	out, _ = callTool(t, server, "get_clipboard_history", map[string]interface{}{"limit": 1})
+	entries = out["entries"].([]interface{})
+	require.Len(t, entries, 1)
+	assert.Equal(t, "Slack", entries[0].(map[string]interface{})["application"])
+}
+
+// TestServer_Resources 测试会话资源
+func TestServer_Resources(t *testing.T) {
+	server := newTestServer(t, allScopes)
+
+	resources := call(t, server, "resources/list", nil)["result"].(map[string]interface{})["resources"].([]interface{})
+	require.Len(t, resources, 1)
+	assert.Equal(t, recentSessionsURI, resources[0].(map[string]interface{})["uri"])
+
+	templates := call(t, server, "resources/templates/list", nil)["result"].(map[string]interface{})["resourceTemplates"].([]interface{})
+	require.Len(t, templates, 1)
+	assert.Equal(t, "flowmind://sessions/{id}", templates[0].(map[string]interface{})["uriTemplate"])
+
+	read := func(uri string) map[string]interface{} {
+		resp := call(t, server, "resources/read", map[string]interface{}{"uri": uri})
+		require.Nil(t, resp["error"], "%v", resp["error"])
+		contents := resp["result"].(map[string]interface{})["contents"].([]interface{})
+		require.Len(t, contents, 1)
+		var out map[string]interface{}
+		require.NoError(t, json.Unmarshal([]byte(contents[0].(map[string]interface{})["text"].(string)), &out))
+		return out
+	}
+
+	recent := read(recentSessionsURI)
+	sessions := recent["sessions"].([]interface{})
+	require.Len(t, sessions, 1, "1Password 的会话被隐私规则过滤")
+	assert.Equal(t, "session-terminal", sessions[0].(map[string]interface{})["id"])
+	assert.InDelta(t, 40*60, sessions[0].(map[string]interface{})["duration_seconds"], 2)
+
+	detail := read(sessionURIPrefix + "session-terminal")
+	assert.Len(t, detail["events"], 2)
+
+	for _, uri := range []string{sessionURIPrefix + "session-1password", sessionURIPrefix + "missing", "file:///etc/passwd"} {
+		resp := call(t, server, "resources/read", map[string]interface{}{"uri": uri})
+		assert.NotNil(t, resp["error"], uri)
+	}
+}
+
+// TestParseTime 测试时间参数解析
+func TestParseTime(t *testing.T) {
+	now := time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := map[string]time.Time{
+		"now":                  now,
+		"30m":                  now.Add(-30 * time.Minute),
+		"7d":                   now.AddDate(0, 0, -7),
+		"2026-03-01T08:00:00Z": time.Date(2026, 3, 1, 8, 0, 0, 0, time.UTC),
+	}
+	for value, want := range tests {
+		got, err := parseTime(value, now)
+		require.NoError(t, err, value)
+		assert.True(t, want.Equal(got), fmt.Sprintf("%s: got %v", value, got))
+	}
+
+	_, err := parseTime("yesterday", now)
+	assert.Error(t, err)
+}
@@ -0,0 +1,610 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/privacy"
+	"github.com/chenyang-zz/flowmind/internal/services"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+)
+
+/**
+ * 工具返回条数的默认值和上限
+ */
+const (
+	defaultEventLimit     = 50
+	maxEventLimit         = 500
+	defaultPatternLimit   = 20
+	defaultAppLimit       = 20
+	defaultClipboardLimit = 20
+	maxClipboardLimit     = 200
+
+	// defaultLookback 未指定 from 时向前查询的时长
+	defaultLookback = 24 * time.Hour
+)
+
+/**
+ * tool MCP 工具
+ */
+type tool struct {
+	name        string
+	description string
+	scope       Scope
+	schema      map[string]interface{}
+	handler     func(ctx context.Context, args json.RawMessage) (interface{}, error)
+}
+
+/**
+ * builtinTools 内置工具
+ */
+func (s *Server) builtinTools() []*tool {
+	timeProps := map[string]interface{}{
+		"from": stringProp("开始时间：RFC3339 时间，或相对时长如 30m、24h、7d（默认 24h 前）"),
+		"to":   stringProp("结束时间：RFC3339 时间，或相对时长（默认现在）"),
+	}
+
+	return []*tool{
+		{
+			name:        "search_events",
+			description: "按关键词、事件类型和应用搜索用户最近的操作事件（键盘、剪贴板、应用切换等），按时间升序返回最近的匹配项",
+			scope:       ScopeEvents,
+			schema: objectSchema(timeProps, map[string]interface{}{
+				"query": stringProp("关键词，匹配应用名、窗口标题、文件路径和事件数据，不区分大小写；剪贴板内容仅在授予 clipboard:read 时返回和参与匹配"),
+				"type":  stringProp("事件类型，如 keyboard、clipboard、app_switch、app_session"),
+				"app":   stringProp("应用名称，精确匹配，不区分大小写"),
+				"limit": intProp("最多返回的事件数", defaultEventLimit, maxEventLimit),
+			}),
+			handler: s.searchEvents,
+		},
+		{
+			name:        "list_patterns",
+			description: "列出从用户操作中挖掘出的重复工作流模式，按出现次数降序，包含步骤和 AI 分析结果",
+			scope:       ScopePatterns,
+			schema: objectSchema(map[string]interface{}{
+				"automatable_only": map[string]interface{}{
+					"type":        "boolean",
+					"description": "只返回 AI 判断值得自动化的模式",
+				},
+				"limit": intProp("最多返回的模式数", defaultPatternLimit, 0),
+			}),
+			handler: s.listPatterns,
+		},
+		{
+			name:        "get_app_usage",
+			description: "统计时间范围内各应用的使用时长、会话数和事件数，按使用时长降序",
+			scope:       ScopeAppUsage,
+			schema: objectSchema(timeProps, map[string]interface{}{
+				"limit": intProp("最多返回的应用数", defaultAppLimit, 0),
+			}),
+			handler: s.getAppUsage,
+		},
+		{
+			name:        "get_clipboard_history",
+			description: "获取用户最近的剪贴板记录，按时间升序；敏感内容在采集时已脱敏，受隐私规则限制的应用只返回元数据",
+			scope:       ScopeClipboard,
+			schema: objectSchema(timeProps, map[string]interface{}{
+				"limit": intProp("最多返回的记录数", defaultClipboardLimit, maxClipboardLimit),
+			}),
+			handler: s.getClipboardHistory,
+		},
+	}
+}
+
+/**
+ * listTools 列出已授予权限范围的工具
+ */
+func (s *Server) listTools() interface{} {
+	tools := make([]toolDescriptor, 0, len(s.tools))
+	for _, t := range s.tools {
+		if !s.scopes[t.scope] {
+			continue
+		}
+		tools = append(tools, toolDescriptor{
+			Name:        t.name,
+			Description: t.description,
+			InputSchema: t.schema,
+			Annotations: map[string]interface{}{
+				"readOnlyHint":  true,
+				"openWorldHint": false,
+			},
+		})
+	}
+	return map[string]interface{}{"tools": tools}
+}
+
+/**
+ * callTool 调用工具
+ *
+ * 未知工具或未授予权限范围的工具返回协议错误；
+ * 参数无效或执行失败时返回 isError 结果，让模型看到错误原因
+ */
+func (s *Server) callTool(ctx context.Context, params json.RawMessage) (interface{}, error) {
+	var p callToolParams
+	if err := json.Unmarshal(params, &p); err != nil || p.Name == "" {
+		return nil, &rpcError{Code: codeInvalidParams, Message: "无效的 tools/call 参数"}
+	}
+
+	var found *tool
+	for _, t := range s.tools {
+		if t.name == p.Name && s.scopes[t.scope] {
+			found = t
+			break
+		}
+	}
+	if found == nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: fmt.Sprintf("未知工具: %s", p.Name)}
+	}
+
+	args := p.Arguments
+	if len(args) == 0 || string(args) == "null" {
+		args = json.RawMessage("{}")
+	}
+	result, err := found.handler(ctx, args)
+	if err != nil {
+		return callToolResult{Content: []content{{Type: "text", Text: err.Error()}}, IsError: true}, nil
+	}
+
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("序列化工具结果失败: %w", err)
+	}
+	return callToolResult{Content: []content{{Type: "text", Text: string(text)}}}, nil
+}
+
+/**
+ * timeRangeArgs 时间范围参数
+ */
+type timeRangeArgs struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+/**
+ * resolve 解析时间范围，from 默认为 to 之前 defaultLookback
+ */
+func (a timeRangeArgs) resolve(now time.Time) (time.Time, time.Time, error) {
+	end := now
+	if a.To != "" {
+		parsed, err := parseTime(a.To, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = parsed
+	}
+	start := end.Add(-defaultLookback)
+	if a.From != "" {
+		parsed, err := parseTime(a.From, now)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = parsed
+	}
+	if start.After(end) {
+		return time.Time{}, time.Time{}, fmt.Errorf("开始时间晚于结束时间")
+	}
+	return start, end, nil
+}
+
+/**
+ * searchEventsArgs search_events 参数
+ */
+type searchEventsArgs struct {
+	timeRangeArgs
+	Query string `json:"query"`
+	Type  string `json:"type"`
+	App   string `json:"app"`
+	Limit int    `json:"limit"`
+}
+
+func (s *Server) searchEvents(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args searchEventsArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	start, end, err := args.resolve(s.now())
+	if err != nil {
+		return nil, err
+	}
+	limit := clampLimit(args.Limit, defaultEventLimit, maxEventLimit)
+
+	all, err := s.deps.Events.FindByTimeRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	query := strings.ToLower(strings.TrimSpace(args.Query))
+	matched := make([]events.Event, 0)
+	for _, event := range all {
+		visible, ok := s.visibleEvent(event)
+		if !ok {
+			continue
+		}
+		if args.Type != "" && string(visible.Type) != args.Type {
+			continue
+		}
+		if args.App != "" && !strings.EqualFold(eventApplication(visible), args.App) {
+			continue
+		}
+		if query != "" && !strings.Contains(searchText(visible), query) {
+			continue
+		}
+		matched = append(matched, visible)
+	}
+
+	total := len(matched)
+	if len(matched) > limit {
+		matched = matched[len(matched)-limit:]
+	}
+	return map[string]interface{}{
+		"from":    start,
+		"to":      end,
+		"matched": total,
+		"events":  matched,
+	}, nil
+}
+
+/**
+ * listPatternsArgs list_patterns 参数
+ */
+type listPatternsArgs struct {
+	AutomatableOnly bool `json:"automatable_only"`
+	Limit           int  `json:"limit"`
+}
+
+func (s *Server) listPatterns(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args listPatternsArgs
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	limit := clampLimit(args.Limit, defaultPatternLimit, 0)
+
+	patterns, err := s.deps.Patterns.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].SupportCount > patterns[j].SupportCount
+	})
+
+	views := make([]services.PatternView, 0)
+	for _, pattern := range patterns {
+		if args.AutomatableOnly && (pattern.AIAnalysis == nil || !pattern.AIAnalysis.ShouldAutomate) {
+			continue
+		}
+
+		// 模式中任一步骤所在应用被隐私规则禁止采集时不返回
+		hidden := false
+		for _, step := range pattern.Sequence {
+			if step.Context != nil &&
+				s.deps.Policy.LevelFor(step.Context.BundleID, step.Context.Application, "") == privacy.CaptureNone {
+				hidden = true
+				break
+			}
+		}
+		if hidden {
+			continue
+		}
+
+		views = append(views, services.NewPatternView(pattern))
+		if len(views) >= limit {
+			break
+		}
+	}
+	return map[string]interface{}{"patterns": views}, nil
+}
+
+/**
+ * appUsage 单个应用的使用统计
+ */
+type appUsage struct {
+	Application   string `json:"application"`
+	BundleID      string `json:"bundle_id,omitempty"`
+	Sessions      int    `json:"sessions"`
+	ActiveSeconds int64  `json:"active_seconds"`
+	Events        int    `json:"events"`
+}
+
+func (s *Server) getAppUsage(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		timeRangeArgs
+		Limit int `json:"limit"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	start, end, err := args.resolve(s.now())
+	if err != nil {
+		return nil, err
+	}
+	limit := clampLimit(args.Limit, defaultAppLimit, 0)
+
+	usage := make(map[string]*appUsage)
+	entry := func(app, bundleID string) *appUsage {
+		key := strings.ToLower(app)
+		u, ok := usage[key]
+		if !ok {
+			u = &appUsage{Application: app}
+			usage[key] = u
+		}
+		if u.BundleID == "" {
+			u.BundleID = bundleID
+		}
+		return u
+	}
+
+	if s.deps.Sessions != nil {
+		sessions, err := s.deps.Sessions.FindByTimeRange(start, end)
+		if err != nil {
+			return nil, err
+		}
+		for _, session := range sessions {
+			if session.Application == "" ||
+				s.deps.Policy.LevelFor(session.BundleID, session.Application, "") == privacy.CaptureNone {
+				continue
+			}
+			sessionEnd := end
+			if session.EndTime != nil && session.EndTime.Before(end) {
+				sessionEnd = *session.EndTime
+			}
+			sessionStart := session.StartTime
+			if sessionStart.Before(start) {
+				sessionStart = start
+			}
+
+			u := entry(session.Application, session.BundleID)
+			u.Sessions++
+			if sessionEnd.After(sessionStart) {
+				u.ActiveSeconds += int64(sessionEnd.Sub(sessionStart).Seconds())
+			}
+		}
+	}
+
+	all, err := s.deps.Events.FindByTimeRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range all {
+		visible, ok := s.visibleEvent(event)
+		if !ok {
+			continue
+		}
+		app := eventApplication(visible)
+		if app == "" {
+			continue
+		}
+		bundleID := ""
+		if visible.Context != nil {
+			bundleID = visible.Context.BundleID
+		}
+		entry(app, bundleID).Events++
+	}
+
+	apps := make([]appUsage, 0, len(usage))
+	for _, u := range usage {
+		apps = append(apps, *u)
+	}
+	sort.Slice(apps, func(i, j int) bool {
+		if apps[i].ActiveSeconds != apps[j].ActiveSeconds {
+			return apps[i].ActiveSeconds > apps[j].ActiveSeconds
+		}
+		if apps[i].Events != apps[j].Events {
+			return apps[i].Events > apps[j].Events
+		}
+		return apps[i].Application < apps[j].Application
+	})
+	if len(apps) > limit {
+		apps = apps[:limit]
+	}
+
+	return map[string]interface{}{
+		"from": start,
+		"to":   end,
+		"apps": apps,
+	}, nil
+}
+
+/**
+ * clipboardEntry 剪贴板记录
+ */
+type clipboardEntry struct {
+	Timestamp    time.Time      `json:"timestamp"`
+	Application  string         `json:"application,omitempty"`
+	WindowTitle  string         `json:"window_title,omitempty"`
+	Content      string         `json:"content,omitempty"`
+	ContentType  string         `json:"content_type,omitempty"`
+	Redactions   map[string]int `json:"redactions,omitempty"`
+	PrivacyLevel string         `json:"privacy_level,omitempty"`
+}
+
+func (s *Server) getClipboardHistory(ctx context.Context, raw json.RawMessage) (interface{}, error) {
+	var args struct {
+		timeRangeArgs
+		Limit int `json:"limit"`
+	}
+	if err := decodeArgs(raw, &args); err != nil {
+		return nil, err
+	}
+	start, end, err := args.resolve(s.now())
+	if err != nil {
+		return nil, err
+	}
+	limit := clampLimit(args.Limit, defaultClipboardLimit, maxClipboardLimit)
+
+	all, err := s.deps.Events.FindByTimeRange(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]clipboardEntry, 0)
+	for _, event := range all {
+		if event.Type != events.EventTypeClipboard {
+			continue
+		}
+		visible, ok := s.visibleEvent(event)
+		if !ok {
+			continue
+		}
+
+		item := clipboardEntry{Timestamp: visible.Timestamp, Application: eventApplication(visible)}
+		if visible.Context != nil {
+			item.WindowTitle = visible.Context.WindowTitle
+		}
+		var data events.ClipboardEventData
+		if err := events.DecodePayload(visible, &data); err == nil {
+			item.Content = data.Content
+			item.ContentType = data.Type
+			item.Redactions = data.Redactions
+		}
+		item.PrivacyLevel, _ = visible.Data["privacy_level"].(string)
+		entries = append(entries, item)
+	}
+
+	if len(entries) > limit {
+		entries = entries[len(entries)-limit:]
+	}
+	return map[string]interface{}{"entries": entries}, nil
+}
+
+/**
+ * clipboardContentKeys 未授予 clipboard:read 时从剪贴板事件中移除的字段
+ */
+var clipboardContentKeys = []string{"content", "redactions"}
+
+/**
+ * visibleEvent 按当前隐私策略和权限范围重新过滤事件
+ *
+ * 规则可能在事件写入后才加上，因此读取时再检查一次。
+ * 返回的事件是副本，metadata 级别下已移除内容；
+ * 未授予 clipboard:read 时剪贴板事件只保留元数据，内容也不参与搜索
+ *
+ * Returns: events.Event - 过滤后的事件, bool - false 表示事件不可见
+ */
+func (s *Server) visibleEvent(event events.Event) (events.Event, bool) {
+	visible := event
+	if !s.deps.Policy.Apply(&visible) {
+		return events.Event{}, false
+	}
+	if visible.Type == events.EventTypeClipboard && !s.scopes[ScopeClipboard] {
+		data := make(map[string]interface{}, len(visible.Data))
+		for key, value := range visible.Data {
+			data[key] = value
+		}
+		for _, key := range clipboardContentKeys {
+			delete(data, key)
+		}
+		visible.Data = data
+	}
+	return visible, true
+}
+
+/**
+ * eventApplication 获取事件所在的应用
+ */
+func eventApplication(event events.Event) string {
+	if event.Context != nil && event.Context.Application != "" {
+		return event.Context.Application
+	}
+	app, _ := event.Data["app_name"].(string)
+	return app
+}
+
+/**
+ * searchText 事件中可搜索的文本（小写）
+ */
+func searchText(event events.Event) string {
+	var parts []string
+	if event.Context != nil {
+		parts = append(parts, event.Context.Application, event.Context.WindowTitle, event.Context.FilePath)
+	}
+	if data, err := json.Marshal(event.Data); err == nil {
+		parts = append(parts, string(data))
+	}
+	return strings.ToLower(strings.Join(parts, "\n"))
+}
+
+/**
+ * decodeArgs 解析工具参数，拒绝未知字段
+ */
+func decodeArgs(raw json.RawMessage, out interface{}) error {
+	decoder := json.NewDecoder(strings.NewReader(string(raw)))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		return fmt.Errorf("无效的参数: %w", err)
+	}
+	return nil
+}
+
+/**
+ * clampLimit 限制返回条数，maxValue 为 0 表示不设上限
+ */
+func clampLimit(value, defaultValue, maxValue int) int {
+	if value <= 0 {
+		value = defaultValue
+	}
+	if maxValue > 0 && value > maxValue {
+		value = maxValue
+	}
+	return value
+}
+
+/**
+ * parseTime 解析 RFC3339 时间或相对时长（30m、24h、7d）
+ */
+func parseTime(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "now" {
+		return now, nil
+	}
+	if strings.HasSuffix(value, "d") {
+		if days, err := strconv.Atoi(strings.TrimSuffix(value, "d")); err == nil && days >= 0 {
+			return now.AddDate(0, 0, -days), nil
+		}
+	}
+	if duration, err := time.ParseDuration(value); err == nil && duration >= 0 {
+		return now.Add(-duration), nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("无法解析时间 %q，请使用 RFC3339 时间或 30m、24h、7d 这样的相对时长", value)
+}
+
+/**
+ * objectSchema 合并属性并生成对象类型的 JSON Schema
+ */
+func objectSchema(propSets ...map[string]interface{}) map[string]interface{} {
+	properties := make(map[string]interface{})
+	for _, props := range propSets {
+		for name, prop := range props {
+			properties[name] = prop
+		}
+	}
+	return map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+}
+
+func stringProp(description string) map[string]interface{} {
+	return map[string]interface{}{"type": "string", "description": description}
+}
+
+func intProp(description string, defaultValue, maxValue int) map[string]interface{} {
+	prop := map[string]interface{}{
+		"type":        "integer",
+		"description": description,
+		"minimum":     1,
+		"default":     defaultValue,
+	}
+	if maxValue > 0 {
+		prop["maximum"] = maxValue
+	}
+	return prop
+}
@@ -0,0 +1,260 @@
+package mcp
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * DefaultAddr HTTP 传输的默认监听地址
+ */
+const DefaultAddr = "127.0.0.1:9466"
+
+/**
+ * maxMessageSize 单条消息大小上限
+ */
+const maxMessageSize = 4 << 20
+
+/**
+ * ServeStdio 通过标准输入输出提供服务
+ *
+ * 每行一条 JSON-RPC 消息，响应按行写出。读到 EOF 或上下文取消时返回
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - r: 消息输入（通常是 os.Stdin）
+ *   - w: 响应输出（通常是 os.Stdout），不能再写入日志等其他内容
+ *
+ * Returns:
+ *   - error: 读取或写入失败时返回错误，正常结束返回 nil
+ */
+func (s *Server) ServeStdio(ctx context.Context, r io.Reader, w io.Writer) error {
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxMessageSize)
+		for scanner.Scan() {
+			line := bytes.TrimSpace(scanner.Bytes())
+			if len(line) == 0 {
+				continue
+			}
+			select {
+			case lines <- append([]byte(nil), line...):
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case err := <-readErr:
+			if err != nil {
+				return fmt.Errorf("读取 MCP 消息失败: %w", err)
+			}
+			return nil
+		case line := <-lines:
+			resp := s.Handle(ctx, line)
+			if resp == nil {
+				continue
+			}
+			if _, err := w.Write(append(resp, '\n')); err != nil {
+				return fmt.Errorf("写入 MCP 响应失败: %w", err)
+			}
+		}
+	}
+}
+
+/**
+ * HTTPServer 本机 HTTP 传输
+ *
+ * 在 /mcp 路径接收 POST 的 JSON-RPC 消息并直接返回 JSON 响应，
+ * 只允许监听本机回环地址，请求需要携带访问令牌，且拒绝来自非本机网页的跨域请求
+ */
+type HTTPServer struct {
+	addr     string
+	token    string
+	mcp      *Server
+	server   *http.Server
+	listener net.Listener
+	mu       sync.Mutex
+}
+
+/**
+ * NewHTTPServer 创建本机 HTTP 传输
+ *
+ * Parameters:
+ *   - addr: 监听地址，为空时使用 DefaultAddr，必须是回环地址
+ *   - token: 访问令牌，不能为空
+ *   - server: MCP 服务
+ *
+ * Returns:
+ *   - *HTTPServer: HTTP 传输
+ *   - error: 地址不是回环地址或令牌为空时返回错误
+ */
+func NewHTTPServer(addr, token string, server *Server) (*HTTPServer, error) {
+	if addr == "" {
+		addr = DefaultAddr
+	}
+	if token == "" {
+		return nil, errors.New("MCP 访问令牌不能为空")
+	}
+
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, fmt.Errorf("解析 MCP 监听地址失败: %w", err)
+	}
+	if !isLoopbackHost(host) {
+		return nil, fmt.Errorf("MCP 只能监听本机回环地址: %s", addr)
+	}
+
+	h := &HTTPServer{addr: addr, token: token, mcp: server}
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", h)
+	h.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	return h, nil
+}
+
+/**
+ * ServeHTTP 处理一条 JSON-RPC 消息
+ */
+func (h *HTTPServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if origin := r.Header.Get("Origin"); origin != "" && !isLoopbackOrigin(origin) {
+		http.Error(w, "forbidden origin", http.StatusForbidden)
+		return
+	}
+
+	bearer, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || subtle.ConstantTimeCompare([]byte(bearer), []byte(h.token)) != 1 {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="flowmind-mcp"`)
+		http.Error(w, "invalid token", http.StatusUnauthorized)
+		return
+	}
+
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxMessageSize))
+	if err != nil {
+		http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	resp := h.mcp.Handle(r.Context(), body)
+	if resp == nil {
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if _, err := w.Write(resp); err != nil {
+		logger.Debug("写入 MCP 响应失败",
+			zap.String("component", "mcp"),
+			zap.Error(err),
+		)
+	}
+}
+
+/**
+ * Start 开始监听并在后台提供服务
+ *
+ * Returns:
+ *   - error: 监听失败时返回错误
+ */
+func (h *HTTPServer) Start() error {
+	listener, err := net.Listen("tcp", h.addr)
+	if err != nil {
+		return fmt.Errorf("监听 MCP 端口失败: %w", err)
+	}
+	h.mu.Lock()
+	h.listener = listener
+	h.mu.Unlock()
+
+	go func() {
+		if err := h.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("MCP 服务异常退出",
+				zap.String("component", "mcp"),
+				zap.Error(err),
+			)
+		}
+	}()
+
+	logger.Info("MCP 服务已启动",
+		zap.String("component", "mcp"),
+		zap.String("addr", listener.Addr().String()),
+	)
+	return nil
+}
+
+/**
+ * Addr 获取实际监听地址
+ *
+ * Returns:
+ *   - string: 监听地址，未启动时为配置的地址
+ */
+func (h *HTTPServer) Addr() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.listener != nil {
+		return h.listener.Addr().String()
+	}
+	return h.addr
+}
+
+/**
+ * Stop 停止服务
+ *
+ * Parameters:
+ *   - ctx: 上下文，用于限制等待时间
+ *
+ * Returns:
+ *   - error: 关闭失败时返回错误
+ */
+func (h *HTTPServer) Stop(ctx context.Context) error {
+	return h.server.Shutdown(ctx)
+}
+
+/**
+ * isLoopbackHost 判断主机名是否为本机回环地址
+ */
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+/**
+ * isLoopbackOrigin 判断请求来源是否为本机网页，防止 DNS 重绑定攻击
+ */
+func isLoopbackOrigin(origin string) bool {
+	parsed, err := url.Parse(origin)
+	if err != nil {
+		return false
+	}
+	return isLoopbackHost(parsed.Hostname())
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestServeStdio 测试按行读取消息并按行写出响应
+func TestServeStdio(t *testing.T) {
+	server := NewServer(Deps{}, nil, "test")
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"ping"}`,
+	}, "\n") + "\n"
+
+	var out bytes.Buffer
+	require.NoError(t, server.ServeStdio(context.Background(), strings.NewReader(input), &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	require.Len(t, lines, 2, "通知没有响应")
+
+	var first, second map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &first))
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &second))
+	assert.Equal(t, float64(1), first["id"])
+	assert.Equal(t, float64(2), second["id"])
+}
+
+// TestNewHTTPServer_Validation 测试监听地址和令牌校验
+func TestNewHTTPServer_Validation(t *testing.T) {
+	server := NewServer(Deps{}, nil, "test")
+
+	_, err := NewHTTPServer("0.0.0.0:9466", "token", server)
+	assert.Error(t, err)
+
+	_, err = NewHTTPServer("127.0.0.1:9466", "", server)
+	assert.Error(t, err)
+
+	h, err := NewHTTPServer("", "token", server)
+	require.NoError(t, err)
+	assert.Equal(t, DefaultAddr, h.Addr())
+}
+
+// TestHTTPServer_ServeHTTP 测试 HTTP 传输的鉴权和请求处理
+func TestHTTPServer_ServeHTTP(t *testing.T) {
+	h, err := NewHTTPServer("127.0.0.1:0", "secret", NewServer(Deps{}, nil, "test"))
+	require.NoError(t, err)
+
+	send := func(method, body string, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, "/mcp", strings.NewReader(body))
+		for key, value := range headers {
+			req.Header.Set(key, value)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec
+	}
+	auth := map[string]string{"Authorization": "Bearer secret"}
+	ping := `{"jsonrpc":"2.0","id":1,"method":"ping"}`
+
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, ping, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, send(http.MethodPost, ping, map[string]string{"Authorization": "secret"}).Code)
+	assert.Equal(t, http.StatusForbidden, send(http.MethodPost, ping, map[string]string{
+		"Authorization": "Bearer secret",
+		"Origin":        "https://evil.example.com",
+	}).Code)
+	assert.Equal(t, http.StatusMethodNotAllowed, send(http.MethodGet, "", auth).Code)
+
+	rec := send(http.MethodPost, `{"jsonrpc":"2.0","method":"notifications/initialized"}`, auth)
+	assert.Equal(t, http.StatusAccepted, rec.Code)
+
+	rec = send(http.MethodPost, ping, map[string]string{
+		"Authorization": "Bearer secret",
+		"Origin":        "http://localhost:3000",
+	})
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.JSONEq(t, `{"jsonrpc":"2.0","id":1,"result":{}}`, rec.Body.String())
+}
+
+// TestHTTPServer_StartStop 测试启动和停止
+func TestHTTPServer_StartStop(t *testing.T) {
+	h, err := NewHTTPServer("127.0.0.1:0", "secret", NewServer(Deps{}, nil, "test"))
+	require.NoError(t, err)
+	require.NoError(t, h.Start())
+
+	req, err := http.NewRequest(http.MethodPost, "http://"+h.Addr()+"/mcp",
+		strings.NewReader(`{"jsonrpc":"2.0","id":"a","method":"ping"}`))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer secret")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, h.Stop(context.Background()))
+}
@@ -7,7 +7,7 @@
 package monitor
 
 import (
-	"time"
+	"fmt"
 
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
 	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
@@ -25,11 +25,41 @@ type PersistenceConfig struct {
 	// AsyncMode 是否异步持久化
 	AsyncMode bool
 
-	// RetryOnError 错误重试
+	// RetryOnError 错误重试（仅订阅者模式，由事件总线按 Retry 策略重试，中间件不重试）
 	RetryOnError bool
 
-	// MaxRetries 最大重试次数
+	// MaxRetries 最大重试次数，覆盖 Retry.MaxAttempts（不含第一次写入）
 	MaxRetries int
+
+	// Retry 重试退避策略，重试耗尽的事件进入事件总线的死信存储
+	Retry events.RetryPolicy
+}
+
+/**
+ * PersistenceSubscriberName 持久化订阅者名称
+ *
+ * 死信按此名称关联，重启后仍可重新投递给持久化订阅者
+ */
+const PersistenceSubscriberName = "persistence"
+
+/**
+ * RetryPolicy 获取持久化失败的重试策略
+ *
+ * Returns: events.RetryPolicy - 重试策略，RetryOnError 为 false 时不重试
+ */
+func (c PersistenceConfig) RetryPolicy() events.RetryPolicy {
+	if !c.RetryOnError {
+		return events.RetryPolicy{}
+	}
+
+	policy := c.Retry
+	if policy.MaxAttempts == 0 && policy.InitialBackoff == 0 {
+		policy = events.DefaultRetryPolicy()
+	}
+	if c.MaxRetries > 0 {
+		policy.MaxAttempts = c.MaxRetries + 1
+	}
+	return policy
 }
 
 /**
@@ -42,10 +72,12 @@ func DefaultPersistenceConfig() PersistenceConfig {
 			events.EventTypeClipboard:  true,
 			events.EventTypeAppSwitch:  true,
 			events.EventTypeAppSession: true,
+			events.EventTypeFileSystem: true,
 		},
 		AsyncMode:    true,
 		RetryOnError: true,
 		MaxRetries:   3,
+		Retry:        events.DefaultRetryPolicy(),
 	}
 }
 
@@ -62,6 +94,9 @@ type PersistenceMiddleware struct {
 /**
  * NewPersistenceMiddleware 创建持久化中间件
  *
+ * 中间件在发布路径上同步写入，失败时只记录日志，不重试也不进入死信。
+ * 需要重试和死信时使用 SubscribePersistence
+ *
  * Parameters:
  *   - batchWriter: 批量写入器
  *   - config: 持久化配置
@@ -88,13 +123,11 @@ func NewPersistenceMiddleware(
 				return next(event)
 			}
 
-			// 持久化事件
-			success := pm.persistEvent(event)
-			if !success && pm.config.RetryOnError {
-				logger.Warn("事件持久化失败，准备重试",
+			// 持久化事件，失败只记录日志
+			if !pm.persistEvent(event) {
+				logger.Error("事件持久化失败",
 					zap.String("event_id", event.ID),
 					zap.String("event_type", string(event.Type)))
-				go pm.retryPersist(event)
 			}
 
 			// 继续处理事件链
@@ -103,6 +136,65 @@ func NewPersistenceMiddleware(
 	}
 }
 
+/**
+ * SubscribePersistence 以订阅者方式持久化事件
+ *
+ * 订阅所有事件，写入失败时由事件总线按配置的策略退避重试，
+ * 重试耗尽的事件进入死信存储，可通过 EventBus.RequeueDeadLetter 重新投递。
+ * 缓冲区满时短暂阻塞发布者，而不是直接丢弃事件
+ *
+ * Parameters:
+ *   - bus: 事件总线
+ *   - batchWriter: 批量写入器
+ *   - config: 持久化配置
+ *
+ * Returns: *PersistenceMiddleware - 持久化组件, string - 订阅者 ID
+ */
+func SubscribePersistence(
+	bus *events.EventBus,
+	batchWriter *storage.BatchWriter,
+	config PersistenceConfig,
+) (*PersistenceMiddleware, string) {
+	pm := &PersistenceMiddleware{
+		batchWriter: batchWriter,
+		config:      config,
+	}
+
+	policy := config.RetryPolicy()
+	subscriberID := bus.SubscribeWithOptions("*", pm.Handle,
+		events.WithName(PersistenceSubscriberName),
+		events.WithBlockTimeout(events.DefaultBlockTimeout),
+		events.WithRetry(policy),
+	)
+
+	logger.Info("创建持久化订阅者",
+		zap.Int("enabled_types", len(config.EnabledEventTypes)),
+		zap.Int("max_attempts", policy.MaxAttempts))
+
+	return pm, subscriberID
+}
+
+/**
+ * Handle 持久化事件处理函数
+ *
+ * 未启用持久化的事件类型直接跳过
+ *
+ * Parameters:
+ *   - event: 事件对象
+ *
+ * Returns: error - 写入失败时返回错误，由事件总线重试
+ */
+func (pm *PersistenceMiddleware) Handle(event events.Event) error {
+	if !pm.config.EnabledEventTypes[event.Type] {
+		return nil
+	}
+
+	if !pm.persistEvent(event) {
+		return fmt.Errorf("事件持久化失败: %s", event.ID)
+	}
+	return nil
+}
+
 /**
  * persistEvent 持久化单个事件
  *
@@ -133,36 +225,6 @@ func (pm *PersistenceMiddleware) persistEvent(event events.Event) bool {
 	return true
 }
 
-/**
- * retryPersist 重试持久化
- *
- * Parameters:
- *   - event: 事件对象
- */
-func (pm *PersistenceMiddleware) retryPersist(event events.Event) {
-	maxRetries := pm.config.MaxRetries
-	if maxRetries <= 0 {
-		maxRetries = 3
-	}
-
-	for i := 0; i < maxRetries; i++ {
-		// 指数退避
-		backoff := time.Duration(1<<uint(i)) * time.Second
-		time.Sleep(backoff)
-
-		if pm.persistEvent(event) {
-			logger.Info("事件重试持久化成功",
-				zap.String("event_id", event.ID),
-				zap.Int("attempt", i+1))
-			return
-		}
-	}
-
-	logger.Error("事件持久化重试失败",
-		zap.String("event_id", event.ID),
-		zap.Int("max_retries", maxRetries))
-}
-
 /**
  * Stop 停止中间件
  *
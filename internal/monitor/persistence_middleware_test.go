@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/analyzer"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPersistenceConfig_RetryPolicy 测试持久化重试策略
+func TestPersistenceConfig_RetryPolicy(t *testing.T) {
+	cfg := DefaultPersistenceConfig()
+	assert.Equal(t, 4, cfg.RetryPolicy().MaxAttempts, "MaxRetries=3 表示最多尝试 4 次")
+
+	cfg.RetryOnError = false
+	assert.Equal(t, 0, cfg.RetryPolicy().MaxAttempts)
+}
+
+// TestSubscribePersistence_FileSystem 测试文件系统事件被持久化并参与会话分析
+func TestSubscribePersistence_FileSystem(t *testing.T) {
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/persist.db"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, storage.RunMigrations(db))
+
+	repo := storage.NewSQLiteEventRepository(db)
+	writer := storage.NewBatchWriter(repo, storage.DefaultBatchWriterConfig())
+	writer.Start()
+	defer writer.Stop()
+
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+	SubscribePersistence(bus, writer, DefaultPersistenceConfig())
+
+	// 在两个应用间交替，每 6 个事件形成一个会话
+	start := time.Now().Add(-time.Minute)
+	operations := []string{"create", "write", "rename"}
+	apps := []string{"Finder", "TextEdit"}
+	for i := 0; i < 30; i++ {
+		event := events.NewEvent(events.EventTypeFileSystem, map[string]interface{}{
+			"path":      "/tmp/report.txt",
+			"operation": operations[i%len(operations)],
+		})
+		event.Timestamp = start.Add(time.Duration(i) * time.Second)
+		event.WithContext(&events.EventContext{Application: apps[i/6%len(apps)]})
+		require.NoError(t, bus.Publish(string(event.Type), *event))
+	}
+
+	end := start.Add(time.Minute)
+	require.Eventually(t, func() bool {
+		writer.ForceFlush()
+		saved, err := repo.FindByTimeRange(start, end)
+		return err == nil && len(saved) == 30
+	}, 2*time.Second, 10*time.Millisecond)
+
+	config := analyzer.DefaultAnalyzerEngineConfig()
+	config.EnableAIAnalysis = false
+	engine, err := analyzer.NewAnalyzerEngine(config, repo,
+		storage.NewSQLitePatternRepository(db), storage.NewSQLiteSessionRepository(db), bus)
+	require.NoError(t, err)
+	defer engine.Close()
+
+	result, err := engine.AnalyzeRange(context.Background(), start, end)
+	require.NoError(t, err)
+	assert.Equal(t, 30, result.EventCount)
+	assert.Equal(t, 5, result.SessionCount)
+}
+
+// TestSubscribePersistence_DeadLetter 测试持久化失败重试耗尽后进入死信，恢复后重新投递
+func TestSubscribePersistence_DeadLetter(t *testing.T) {
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/persist.db"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, storage.RunMigrations(db))
+
+	repo := storage.NewSQLiteEventRepository(db)
+	deadLetters := storage.NewSQLiteDeadLetterStore(db)
+
+	// 未启动且通道容量为 1：第一个事件占满通道，之后的写入都会失败
+	writer := storage.NewBatchWriter(repo, storage.BatchWriterConfig{
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+		EventBuffer:   1,
+	})
+	defer writer.Stop()
+
+	bus := events.NewEventBus(events.WithDeadLetterStore(deadLetters))
+	defer bus.Stop(time.Second)
+
+	cfg := DefaultPersistenceConfig()
+	cfg.MaxRetries = 1
+	cfg.Retry = events.RetryPolicy{InitialBackoff: time.Millisecond}
+	_, subscriberID := SubscribePersistence(bus, writer, cfg)
+
+	first := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": 1})
+	second := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": 2})
+	require.NoError(t, bus.Publish(string(first.Type), *first))
+	require.NoError(t, bus.Publish(string(second.Type), *second))
+
+	var letters []events.DeadLetter
+	require.Eventually(t, func() bool {
+		letters, _ = bus.ListDeadLetters(0)
+		return len(letters) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, PersistenceSubscriberName, letters[0].Subscriber)
+	assert.Equal(t, second.ID, letters[0].Event.ID)
+	assert.Equal(t, 2, letters[0].Attempts)
+
+	stats, ok := bus.SubscriberStats(subscriberID)
+	require.True(t, ok)
+	assert.Equal(t, uint64(1), stats.Retried)
+
+	// 写入器恢复后重新投递死信
+	writer.Start()
+	require.Eventually(t, func() bool {
+		return bus.RequeueDeadLetter(letters[0].ID) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	require.Eventually(t, func() bool {
+		writer.ForceFlush()
+		saved, err := repo.FindRecent(10)
+		return err == nil && len(saved) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	letters, err = bus.ListDeadLetters(0)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+}
+
+// TestPersistenceMiddleware_NoRetry 测试中间件写入失败后只记录日志，不在后台重试
+func TestPersistenceMiddleware_NoRetry(t *testing.T) {
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/persist.db"})
+	require.NoError(t, err)
+	defer db.Close()
+	require.NoError(t, storage.RunMigrations(db))
+
+	repo := storage.NewSQLiteEventRepository(db)
+	writer := storage.NewBatchWriter(repo, storage.BatchWriterConfig{
+		BatchSize:     10,
+		FlushInterval: time.Hour,
+		EventBuffer:   1,
+	})
+	defer writer.Stop()
+
+	cfg := DefaultPersistenceConfig()
+	cfg.Retry = events.RetryPolicy{InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+	handler := NewPersistenceMiddleware(writer, cfg)(func(events.Event) error { return nil })
+
+	// 第一个事件占满通道，第二个写入失败后被丢弃
+	first := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": 1})
+	second := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": 2})
+	require.NoError(t, handler(*first))
+	require.NoError(t, handler(*second))
+
+	writer.Start()
+	time.Sleep(50 * time.Millisecond)
+	writer.ForceFlush()
+	saved, err := repo.FindRecent(10)
+	require.NoError(t, err)
+	require.Len(t, saved, 1)
+	assert.Equal(t, first.ID, saved[0].ID)
+}
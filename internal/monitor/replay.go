@@ -0,0 +1,417 @@
+/**
+ * Package monitor 监控组件
+ *
+ * 事件回放，从存储中读取历史事件交付给订阅者，再无缝切换到实时事件
+ */
+
+package monitor
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultReplayPageSize 每次从存储读取的事件数量
+	DefaultReplayPageSize = 500
+
+	// replayOverlapWindow 去重窗口
+	//
+	// 订阅实时事件之前这段时间内的历史事件会记录 ID，
+	// 用于剔除同时出现在历史和实时缓冲中的事件；切换到实时模式后
+	// 再保留同样长的时间，剔除回放之后才到达的实时副本
+	replayOverlapWindow = time.Minute
+)
+
+/**
+ * Flusher 可强制刷新的写入器
+ *
+ * storage.BatchWriter 实现了此接口。回放前刷新缓冲区，
+ * 保证已经发布但尚未落盘的事件能被读到
+ */
+type Flusher interface {
+	// ForceFlush 立即写入缓冲区中的事件
+	ForceFlush()
+}
+
+/**
+ * ReplayRequest 回放请求
+ */
+type ReplayRequest struct {
+	// EventType 事件类型，空或 "*" 表示所有类型，支持 hotkey.* 等主题模式
+	EventType string
+
+	// Start 开始时间，零值表示不限
+	Start time.Time
+
+	// End 结束时间，零值表示不限；设置后只回放历史，不能与 Live 同时使用
+	End time.Time
+
+	// Cursor 起始游标（不包含），用于断点续传
+	Cursor int64
+
+	// Live 历史回放完成后是否继续接收实时事件
+	Live bool
+
+	// Filter 事件过滤器（可选），同时作用于历史和实时事件
+	Filter events.EventFilter
+
+	// PageSize 每次从存储读取的事件数量，小于等于 0 时使用 DefaultReplayPageSize
+	PageSize int
+}
+
+/**
+ * matches 判断事件是否符合回放请求
+ */
+func (r ReplayRequest) matches(event events.Event) bool {
+	if r.EventType != "" && !events.MatchTopic(r.EventType, string(event.Type)) {
+		return false
+	}
+	return r.Filter == nil || r.Filter(event)
+}
+
+/**
+ * Replayer 事件回放器
+ *
+ * 回放的事件直接交给订阅者的处理函数，不经过事件总线，
+ * 因此不会再次触发持久化等中间件。每个回放事件都带有
+ * events.MetadataReplay 标记和 events.MetadataReplayCursor 游标。
+ */
+type Replayer struct {
+	repo    storage.EventRepository
+	bus     *events.EventBus
+	flusher Flusher
+}
+
+/**
+ * NewReplayer 创建事件回放器
+ *
+ * Parameters:
+ *   - repo: 事件仓储
+ *   - bus: 事件总线，用于订阅实时事件
+ *   - flusher: 持久化写入器（可选），回放前强制刷新
+ *
+ * Returns: *Replayer - 事件回放器
+ */
+func NewReplayer(repo storage.EventRepository, bus *events.EventBus, flusher Flusher) *Replayer {
+	return &Replayer{
+		repo:    repo,
+		bus:     bus,
+		flusher: flusher,
+	}
+}
+
+/**
+ * ReplaySubscription 回放订阅
+ *
+ * 实时模式下先订阅事件总线并缓冲实时事件，历史回放完成后
+ * 按顺序交付缓冲中未回放过的事件，之后实时事件直接交付。
+ * 持久化和实时订阅在不同的 goroutine 中处理同一个事件，
+ * 切换后的去重窗口内仍会剔除已经回放过的事件
+ */
+type ReplaySubscription struct {
+	bus          *events.EventBus
+	subscriberID string
+	handler      events.EventHandler
+
+	// mu 保护以下字段
+	mu       sync.Mutex
+	switched bool
+	pending  []events.Event
+	replayed map[string]struct{}
+	cursor   int64
+
+	// dedupUntil 切换后去重窗口的结束时间，之后不再检查 replayed
+	dedupUntil time.Time
+
+	cancelOnce sync.Once
+}
+
+/**
+ * Cursor 获取最后一个回放事件的游标
+ *
+ * 可作为下一次回放的 ReplayRequest.Cursor 实现断点续传
+ *
+ * Returns: int64 - 游标，没有回放任何事件时为请求中的起始游标
+ */
+func (s *ReplaySubscription) Cursor() int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.cursor
+}
+
+/**
+ * IsLive 判断是否已经切换到实时事件
+ *
+ * Returns: bool - true 表示历史回放已完成并切换到实时事件
+ */
+func (s *ReplaySubscription) IsLive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.switched
+}
+
+/**
+ * Cancel 取消订阅
+ *
+ * 重复调用是安全的
+ */
+func (s *ReplaySubscription) Cancel() {
+	s.cancelOnce.Do(func() {
+		if s.subscriberID != "" {
+			s.bus.Unsubscribe(s.subscriberID)
+		}
+	})
+}
+
+/**
+ * handleLive 处理实时事件
+ *
+ * 切换前缓冲，切换后跳过已回放的事件后直接交付
+ */
+func (s *ReplaySubscription) handleLive(event events.Event) error {
+	s.mu.Lock()
+	if !s.switched {
+		s.pending = append(s.pending, event)
+		s.mu.Unlock()
+		return nil
+	}
+	duplicate := s.takeReplayed(event.ID)
+	s.mu.Unlock()
+
+	if duplicate {
+		return nil
+	}
+	return s.handler(event)
+}
+
+/**
+ * takeReplayed 判断事件是否已经回放过，调用方持有 mu
+ *
+ * 命中的 ID 会被移除，同一事件的实时副本最多剔除一次；
+ * 去重窗口结束后释放整个集合
+ *
+ * Returns: bool - true 表示事件已回放过，应跳过
+ */
+func (s *ReplaySubscription) takeReplayed(eventID string) bool {
+	if s.replayed == nil {
+		return false
+	}
+	if s.switched && time.Now().After(s.dedupUntil) {
+		s.replayed = nil
+		return false
+	}
+	if _, ok := s.replayed[eventID]; !ok {
+		return false
+	}
+	delete(s.replayed, eventID)
+	return true
+}
+
+/**
+ * Replay 回放历史事件
+ *
+ * 同步执行：返回时历史事件已全部交付，实时模式下已切换到实时事件。
+ * 处理函数返回的错误只记录日志，不会中断回放。
+ *
+ * Parameters:
+ *   - ctx: 上下文，取消后停止回放并取消订阅
+ *   - req: 回放请求
+ *   - handler: 事件处理函数
+ *
+ * Returns: *ReplaySubscription - 回放订阅, error - 请求无效、查询失败或上下文取消时返回错误
+ */
+func (r *Replayer) Replay(ctx context.Context, req ReplayRequest, handler events.EventHandler) (*ReplaySubscription, error) {
+	if handler == nil {
+		return nil, fmt.Errorf("回放处理函数不能为空")
+	}
+	if req.Live && !req.End.IsZero() {
+		return nil, fmt.Errorf("实时回放不能指定结束时间")
+	}
+	if req.Live && r.bus == nil {
+		return nil, fmt.Errorf("实时回放需要事件总线")
+	}
+	if req.PageSize <= 0 {
+		req.PageSize = DefaultReplayPageSize
+	}
+
+	sub := &ReplaySubscription{
+		bus:      r.bus,
+		handler:  handler,
+		replayed: make(map[string]struct{}),
+		cursor:   req.Cursor,
+	}
+
+	// 先订阅再读历史：订阅之后的事件一定在实时缓冲中，不会出现空档。
+	// 缓冲区满时阻塞发布者而不是丢弃，避免回放期间的突发事件丢失
+	var overlapFrom time.Time
+	if req.Live {
+		eventType := req.EventType
+		if eventType == "" {
+			eventType = "*"
+		}
+		overlapFrom = time.Now().Add(-replayOverlapWindow)
+		sub.subscriberID = r.bus.SubscribeWithOptions(eventType, sub.handleLive,
+			events.WithFilter(req.Filter),
+			events.WithBlockTimeout(events.DefaultBlockTimeout),
+		)
+	}
+
+	if r.flusher != nil {
+		r.flusher.ForceFlush()
+	}
+
+	count, err := r.replayHistory(ctx, req, sub, overlapFrom)
+	if err != nil {
+		sub.Cancel()
+		return nil, err
+	}
+
+	if req.Live {
+		// 再刷新一次，读取回放期间落盘的事件，尽量让这些事件以回放形式按存储顺序交付
+		if r.flusher != nil {
+			r.flusher.ForceFlush()
+		}
+		more, err := r.replayHistory(ctx, req, sub, overlapFrom)
+		if err != nil {
+			sub.Cancel()
+			return nil, err
+		}
+		count += more
+
+		if err := r.switchToLive(ctx, sub); err != nil {
+			sub.Cancel()
+			return nil, err
+		}
+	}
+
+	logger.Info("事件回放完成",
+		zap.String("component", "replayer"),
+		zap.String("event_type", req.EventType),
+		zap.Int("replayed", count),
+		zap.Int64("cursor", sub.Cursor()),
+		zap.Bool("live", req.Live),
+	)
+
+	return sub, nil
+}
+
+/**
+ * replayHistory 分页读取并交付历史事件
+ *
+ * Parameters:
+ *   - ctx: 上下文
+ *   - req: 回放请求
+ *   - sub: 回放订阅，从其游标之后开始读取
+ *   - overlapFrom: 去重窗口起点，此后的事件记录 ID；零值表示不记录
+ *
+ * Returns: int - 交付的事件数量, error - 查询失败或上下文取消时返回错误
+ */
+func (r *Replayer) replayHistory(ctx context.Context, req ReplayRequest, sub *ReplaySubscription, overlapFrom time.Time) (int, error) {
+	count := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return count, err
+		}
+
+		page, err := r.repo.FindAfterCursor(sub.Cursor(), req.Start, req.End, req.PageSize)
+		if err != nil {
+			return count, fmt.Errorf("读取回放事件失败: %w", err)
+		}
+
+		for _, stored := range page {
+			if err := ctx.Err(); err != nil {
+				return count, err
+			}
+
+			sub.mu.Lock()
+			sub.cursor = stored.Cursor
+			if !overlapFrom.IsZero() && !stored.Event.Timestamp.Before(overlapFrom) {
+				sub.replayed[stored.Event.ID] = struct{}{}
+			}
+			sub.mu.Unlock()
+
+			if !req.matches(stored.Event) {
+				continue
+			}
+
+			if err := sub.handler(markReplay(stored)); err != nil {
+				logger.Error("回放事件处理错误",
+					zap.String("component", "replayer"),
+					zap.String("event_id", stored.Event.ID),
+					zap.Error(err),
+				)
+			}
+			count++
+		}
+
+		if len(page) < req.PageSize {
+			return count, nil
+		}
+	}
+}
+
+/**
+ * switchToLive 交付缓冲的实时事件并切换到实时模式
+ *
+ * 缓冲为空时才在锁内标记切换，保证实时事件不会越过缓冲中较早的事件；
+ * 已回放的事件 ID 保留到去重窗口结束
+ */
+func (r *Replayer) switchToLive(ctx context.Context, sub *ReplaySubscription) error {
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		sub.mu.Lock()
+		if len(sub.pending) == 0 {
+			sub.switched = true
+			sub.dedupUntil = time.Now().Add(replayOverlapWindow)
+			sub.mu.Unlock()
+			return nil
+		}
+		pending := make([]events.Event, 0, len(sub.pending))
+		for _, event := range sub.pending {
+			if !sub.takeReplayed(event.ID) {
+				pending = append(pending, event)
+			}
+		}
+		sub.pending = nil
+		sub.mu.Unlock()
+
+		for _, event := range pending {
+			if err := sub.handler(event); err != nil {
+				logger.Error("实时事件处理错误",
+					zap.String("component", "replayer"),
+					zap.String("event_id", event.ID),
+					zap.Error(err),
+				)
+			}
+		}
+	}
+}
+
+/**
+ * markReplay 为回放事件添加回放标记
+ *
+ * 复制元数据，避免修改存储层返回的事件
+ */
+func markReplay(stored storage.StoredEvent) events.Event {
+	event := stored.Event
+	metadata := make(map[string]string, len(event.Metadata)+2)
+	for key, value := range event.Metadata {
+		metadata[key] = value
+	}
+	metadata[events.MetadataReplay] = "true"
+	metadata[events.MetadataReplayCursor] = strconv.FormatInt(stored.Cursor, 10)
+	event.Metadata = metadata
+	return event
+}
@@ -0,0 +1,283 @@
+package monitor
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// setupReplayRepo 创建带迁移的测试事件仓储
+func setupReplayRepo(t *testing.T) storage.EventRepository {
+	t.Helper()
+
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/replay.db"})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = db.Close() })
+	require.NoError(t, storage.RunMigrations(db))
+
+	return storage.NewSQLiteEventRepository(db)
+}
+
+// saveSeqEvents 保存 n 个带序号的事件
+func saveSeqEvents(t *testing.T, repo storage.EventRepository, eventType events.EventType, from, n int) []events.Event {
+	t.Helper()
+
+	var saved []events.Event
+	for i := from; i < from+n; i++ {
+		event := events.NewEvent(eventType, map[string]interface{}{"seq": float64(i)})
+		saved = append(saved, *event)
+	}
+	require.NoError(t, repo.SaveBatch(saved))
+	return saved
+}
+
+// replayCollector 收集回放处理函数收到的事件
+type replayCollector struct {
+	mu       sync.Mutex
+	received []events.Event
+}
+
+func (c *replayCollector) handle(event events.Event) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.received = append(c.received, event)
+	return nil
+}
+
+func (c *replayCollector) events() []events.Event {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]events.Event(nil), c.received...)
+}
+
+// seqs 提取事件序号
+func seqs(list []events.Event) []int {
+	result := make([]int, 0, len(list))
+	for _, event := range list {
+		switch seq := event.Data["seq"].(type) {
+		case float64:
+			result = append(result, int(seq))
+		case int:
+			result = append(result, seq)
+		}
+	}
+	return result
+}
+
+// TestReplayer_History 测试回放历史事件并带有回放标记
+func TestReplayer_History(t *testing.T) {
+	repo := setupReplayRepo(t)
+	saveSeqEvents(t, repo, events.EventTypeClipboard, 0, 5)
+	saveSeqEvents(t, repo, events.EventTypeKeyboard, 5, 2)
+
+	collector := &replayCollector{}
+	replayer := NewReplayer(repo, nil, nil)
+	sub, err := replayer.Replay(context.Background(), ReplayRequest{
+		EventType: string(events.EventTypeClipboard),
+		PageSize:  2,
+	}, collector.handle)
+	require.NoError(t, err)
+
+	received := collector.events()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, seqs(received))
+	for _, event := range received {
+		assert.True(t, event.IsReplay())
+		assert.NotEmpty(t, event.Metadata[events.MetadataReplayCursor])
+	}
+
+	// 游标指向最后读取的存储记录，包括被类型过滤掉的事件
+	assert.Equal(t, int64(7), sub.Cursor())
+	assert.False(t, sub.IsLive())
+}
+
+// TestReplayer_ResumeFromCursor 测试从游标继续回放
+func TestReplayer_ResumeFromCursor(t *testing.T) {
+	repo := setupReplayRepo(t)
+	saveSeqEvents(t, repo, events.EventTypeClipboard, 0, 3)
+
+	replayer := NewReplayer(repo, nil, nil)
+	first := &replayCollector{}
+	sub, err := replayer.Replay(context.Background(), ReplayRequest{}, first.handle)
+	require.NoError(t, err)
+	require.Len(t, first.events(), 3)
+
+	cursor, err := strconv.ParseInt(first.events()[2].Metadata[events.MetadataReplayCursor], 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, sub.Cursor(), cursor)
+
+	saveSeqEvents(t, repo, events.EventTypeClipboard, 3, 2)
+
+	second := &replayCollector{}
+	_, err = replayer.Replay(context.Background(), ReplayRequest{Cursor: cursor}, second.handle)
+	require.NoError(t, err)
+	assert.Equal(t, []int{3, 4}, seqs(second.events()))
+}
+
+// TestReplayer_TimeRange 测试按时间范围回放
+func TestReplayer_TimeRange(t *testing.T) {
+	repo := setupReplayRepo(t)
+
+	now := time.Now()
+	var list []events.Event
+	for i := 0; i < 4; i++ {
+		event := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": float64(i)})
+		event.Timestamp = now.Add(time.Duration(i-4) * time.Hour)
+		list = append(list, *event)
+	}
+	require.NoError(t, repo.SaveBatch(list))
+
+	collector := &replayCollector{}
+	_, err := NewReplayer(repo, nil, nil).Replay(context.Background(), ReplayRequest{
+		Start: now.Add(-3*time.Hour - time.Minute),
+		End:   now.Add(-2*time.Hour + time.Minute),
+	}, collector.handle)
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, seqs(collector.events()))
+}
+
+// TestReplayer_LiveSwitch 测试回放后切换到实时事件，没有重复和遗漏
+func TestReplayer_LiveSwitch(t *testing.T) {
+	repo := setupReplayRepo(t)
+	saveSeqEvents(t, repo, events.EventTypeClipboard, 0, 3)
+
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+
+	// 第一个历史事件交付时发布一个实时事件并落盘，模拟回放期间到达的事件
+	var once sync.Once
+	collector := &replayCollector{}
+	handler := func(event events.Event) error {
+		once.Do(func() {
+			live := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": float64(3)})
+			require.NoError(t, bus.Publish(string(events.EventTypeClipboard), *live))
+			require.NoError(t, repo.Save(*live))
+		})
+		// 等待实时事件进入缓冲后再继续回放
+		time.Sleep(20 * time.Millisecond)
+		return collector.handle(event)
+	}
+
+	sub, err := NewReplayer(repo, bus, nil).Replay(context.Background(), ReplayRequest{
+		EventType: string(events.EventTypeClipboard),
+		Live:      true,
+	}, handler)
+	require.NoError(t, err)
+	defer sub.Cancel()
+	assert.True(t, sub.IsLive())
+
+	live := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": float64(4)})
+	require.NoError(t, bus.Publish(string(events.EventTypeClipboard), *live))
+
+	require.Eventually(t, func() bool {
+		return len(collector.events()) >= 5
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	received := collector.events()
+	assert.Equal(t, []int{0, 1, 2, 3, 4}, seqs(received))
+	assert.True(t, received[3].IsReplay(), "回放期间落盘的事件以回放形式交付")
+	assert.False(t, received[4].IsReplay())
+}
+
+// TestReplayer_LateLiveCopy 测试切换后才到达的已回放事件不会重复交付
+func TestReplayer_LateLiveCopy(t *testing.T) {
+	repo := setupReplayRepo(t)
+	saved := saveSeqEvents(t, repo, events.EventTypeClipboard, 0, 3)
+
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+
+	collector := &replayCollector{}
+	sub, err := NewReplayer(repo, bus, nil).Replay(context.Background(), ReplayRequest{
+		EventType: string(events.EventTypeClipboard),
+		Live:      true,
+	}, collector.handle)
+	require.NoError(t, err)
+	defer sub.Cancel()
+	require.True(t, sub.IsLive())
+
+	// 持久化先于实时订阅处理：事件已经回放，实时副本在切换之后才到达
+	require.NoError(t, bus.Publish(string(events.EventTypeClipboard), saved[2]))
+	live := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": float64(3)})
+	require.NoError(t, bus.Publish(string(events.EventTypeClipboard), *live))
+
+	require.Eventually(t, func() bool {
+		return len(collector.events()) >= 4
+	}, time.Second, 10*time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, []int{0, 1, 2, 3}, seqs(collector.events()))
+}
+
+// TestReplayer_LiveBurst 测试回放期间的突发实时事件不会被丢弃
+func TestReplayer_LiveBurst(t *testing.T) {
+	repo := setupReplayRepo(t)
+	saveSeqEvents(t, repo, events.EventTypeClipboard, 0, 1)
+
+	bus := events.NewEventBus(events.WithAsyncBufferSize(1))
+	defer bus.Stop(time.Second)
+
+	const burst = 500
+	var once sync.Once
+	collector := &replayCollector{}
+	handler := func(event events.Event) error {
+		once.Do(func() {
+			for i := 1; i <= burst; i++ {
+				live := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"seq": float64(i)})
+				require.NoError(t, bus.Publish(string(events.EventTypeClipboard), *live))
+			}
+		})
+		return collector.handle(event)
+	}
+
+	sub, err := NewReplayer(repo, bus, nil).Replay(context.Background(), ReplayRequest{
+		EventType: string(events.EventTypeClipboard),
+		Live:      true,
+	}, handler)
+	require.NoError(t, err)
+	defer sub.Cancel()
+
+	require.Eventually(t, func() bool {
+		return len(collector.events()) >= burst+1
+	}, 2*time.Second, 10*time.Millisecond)
+
+	stats, ok := bus.SubscriberStats(sub.subscriberID)
+	require.True(t, ok)
+	assert.Equal(t, events.BackpressureBlock, stats.Policy)
+	assert.Zero(t, stats.Dropped)
+	assert.Len(t, collector.events(), burst+1)
+}
+
+// TestReplayer_LiveWithEnd 测试实时回放不能指定结束时间
+func TestReplayer_LiveWithEnd(t *testing.T) {
+	repo := setupReplayRepo(t)
+	bus := events.NewEventBus()
+	defer bus.Stop(time.Second)
+
+	_, err := NewReplayer(repo, bus, nil).Replay(context.Background(), ReplayRequest{
+		Live: true,
+		End:  time.Now(),
+	}, func(events.Event) error { return nil })
+	assert.Error(t, err)
+}
+
+// TestReplayer_ContextCanceled 测试上下文取消时停止回放
+func TestReplayer_ContextCanceled(t *testing.T) {
+	repo := setupReplayRepo(t)
+	saveSeqEvents(t, repo, events.EventTypeClipboard, 0, 3)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	collector := &replayCollector{}
+	_, err := NewReplayer(repo, nil, nil).Replay(ctx, ReplayRequest{}, func(event events.Event) error {
+		cancel()
+		return collector.handle(event)
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Len(t, collector.events(), 1)
+}
@@ -0,0 +1,420 @@
+package services
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultEventLimit 未指定数量时返回的事件数
+	DefaultEventLimit = 100
+
+	// MaxEventLimit 单次最多返回的事件数
+	MaxEventLimit = 1000
+
+	// dashboardRecentEvents 仪表板中显示的最近事件数
+	dashboardRecentEvents = 10
+)
+
+var (
+	// ErrInvalidRequest 请求参数无效
+	ErrInvalidRequest = errors.New("请求参数无效")
+
+	// ErrNotFound 请求的对象不存在
+	ErrNotFound = errors.New("对象不存在")
+)
+
+// QueryService 查询服务
+//
+// 基于事件、模式和自动化仓储实现仪表板、模式列表、事件列表和创建自动化，
+// Wails 绑定和本地 HTTP API 共用同一套实现，保证两边返回的数据一致。
+type QueryService struct {
+	// eventRepo 事件仓储
+	eventRepo storage.EventRepository
+
+	// patternRepo 模式仓储
+	patternRepo models.PatternRepository
+
+	// automationRepo 自动化仓储
+	automationRepo models.AutomationRepository
+
+	// now 当前时间，测试时可替换
+	now func() time.Time
+}
+
+// NewQueryService 创建查询服务
+//
+// Parameters:
+//   - eventRepo: 事件仓储
+//   - patternRepo: 模式仓储
+//   - automationRepo: 自动化仓储
+//
+// Returns:
+//   - *QueryService: 查询服务实例
+func NewQueryService(eventRepo storage.EventRepository, patternRepo models.PatternRepository,
+	automationRepo models.AutomationRepository) *QueryService {
+	return &QueryService{
+		eventRepo:      eventRepo,
+		patternRepo:    patternRepo,
+		automationRepo: automationRepo,
+		now:            time.Now,
+	}
+}
+
+// DashboardData 仪表板数据
+type DashboardData struct {
+	TotalEvents         int64            `json:"total_events"`
+	EventsByType        map[string]int64 `json:"events_by_type"`
+	OldestEvent         *time.Time       `json:"oldest_event,omitempty"`
+	NewestEvent         *time.Time       `json:"newest_event,omitempty"`
+	TotalPatterns       int              `json:"total_patterns"`
+	AnalyzedPatterns    int              `json:"analyzed_patterns"`
+	AutomatablePatterns int              `json:"automatable_patterns"`
+	TotalAutomations    int              `json:"total_automations"`
+	EstimatedTimeSaving int64            `json:"estimated_time_saving"`
+	RecentEvents        []events.Event   `json:"recent_events"`
+	GeneratedAt         time.Time        `json:"generated_at"`
+}
+
+// PatternView 模式的对外数据结构
+type PatternView struct {
+	ID           string             `json:"id"`
+	Description  string             `json:"description,omitempty"`
+	SupportCount int                `json:"support_count"`
+	Confidence   float64            `json:"confidence"`
+	Frequency    float64            `json:"frequency_per_hour"`
+	FirstSeen    time.Time          `json:"first_seen"`
+	LastSeen     time.Time          `json:"last_seen"`
+	IsAutomated  bool               `json:"is_automated"`
+	Steps        []StepView         `json:"steps"`
+	AIAnalysis   *models.AIAnalysis `json:"ai_analysis,omitempty"`
+}
+
+// StepView 模式步骤的对外数据结构
+type StepView struct {
+	Type        string `json:"type"`
+	Action      string `json:"action,omitempty"`
+	Application string `json:"application,omitempty"`
+	Value       string `json:"value,omitempty"`
+}
+
+// AutomationView 自动化的对外数据结构
+type AutomationView struct {
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Description string    `json:"description,omitempty"`
+	PatternID   string    `json:"pattern_id,omitempty"`
+	Steps       []string  `json:"steps"`
+	Enabled     bool      `json:"enabled"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// CreateAutomationRequest 创建自动化的请求
+//
+// 指定 PatternID 时，名称和步骤为空则使用模式的 AI 分析建议
+type CreateAutomationRequest struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	PatternID   string   `json:"pattern_id,omitempty"`
+	Steps       []string `json:"steps,omitempty"`
+	Enabled     *bool    `json:"enabled,omitempty"`
+}
+
+// Dashboard 获取仪表板数据
+//
+// Returns:
+//   - *DashboardData: 事件、模式和自动化的汇总统计以及最近事件
+//   - error: 查询失败时返回错误
+func (s *QueryService) Dashboard() (*DashboardData, error) {
+	stats, err := s.eventRepo.GetStats()
+	if err != nil {
+		return nil, fmt.Errorf("获取事件统计失败: %w", err)
+	}
+	patterns, err := s.patternRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("查询模式失败: %w", err)
+	}
+	automations, err := s.automationRepo.Count()
+	if err != nil {
+		return nil, fmt.Errorf("统计自动化失败: %w", err)
+	}
+	recent, err := s.eventRepo.FindRecent(dashboardRecentEvents)
+	if err != nil {
+		return nil, fmt.Errorf("查询最近事件失败: %w", err)
+	}
+
+	data := &DashboardData{
+		TotalEvents:      stats.TotalCount,
+		EventsByType:     stats.CountByType,
+		OldestEvent:      stats.OldestEvent,
+		NewestEvent:      stats.NewestEvent,
+		TotalPatterns:    len(patterns),
+		TotalAutomations: automations,
+		RecentEvents:     nonNilEvents(recent),
+		GeneratedAt:      s.now(),
+	}
+	if data.EventsByType == nil {
+		data.EventsByType = map[string]int64{}
+	}
+	for _, pattern := range patterns {
+		if pattern.AIAnalysis == nil {
+			continue
+		}
+		data.AnalyzedPatterns++
+		if pattern.AIAnalysis.ShouldAutomate {
+			data.AutomatablePatterns++
+			data.EstimatedTimeSaving += pattern.AIAnalysis.EstimatedTimeSaving
+		}
+	}
+
+	return data, nil
+}
+
+// Patterns 获取已识别的模式，按出现次数降序
+//
+// Returns:
+//   - []PatternView: 模式列表
+//   - error: 查询失败时返回错误
+func (s *QueryService) Patterns() ([]PatternView, error) {
+	patterns, err := s.patternRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("查询模式失败: %w", err)
+	}
+
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].SupportCount > patterns[j].SupportCount
+	})
+
+	views := make([]PatternView, 0, len(patterns))
+	for _, pattern := range patterns {
+		views = append(views, NewPatternView(pattern))
+	}
+	return views, nil
+}
+
+// Pattern 获取单个模式
+//
+// Parameters:
+//   - id: 模式ID
+//
+// Returns:
+//   - *PatternView: 模式
+//   - error: 模式不存在时返回 ErrNotFound，查询失败时返回其他错误
+func (s *QueryService) Pattern(id string) (*PatternView, error) {
+	pattern, err := s.findPattern(id)
+	if err != nil {
+		return nil, err
+	}
+	view := NewPatternView(pattern)
+	return &view, nil
+}
+
+// findPattern 按 ID 查询模式
+//
+// Parameters:
+//   - id: 模式ID
+//
+// Returns:
+//   - *models.Pattern: 模式
+//   - error: 模式不存在时返回 ErrNotFound，查询失败时返回其他错误
+func (s *QueryService) findPattern(id string) (*models.Pattern, error) {
+	pattern, err := s.patternRepo.FindByID(id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("%w: 模式 %s", ErrNotFound, id)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("查询模式失败: %w", err)
+	}
+	return pattern, nil
+}
+
+// RecentEvents 获取最近的事件，按时间升序
+//
+// Parameters:
+//   - limit: 返回的最大事件数，小于等于 0 时使用 DefaultEventLimit，最多 MaxEventLimit
+//
+// Returns:
+//   - []events.Event: 事件列表
+//   - error: 查询失败时返回错误
+func (s *QueryService) RecentEvents(limit int) ([]events.Event, error) {
+	if limit <= 0 {
+		limit = DefaultEventLimit
+	}
+	if limit > MaxEventLimit {
+		limit = MaxEventLimit
+	}
+
+	recent, err := s.eventRepo.FindRecent(limit)
+	if err != nil {
+		return nil, fmt.Errorf("查询最近事件失败: %w", err)
+	}
+	return nonNilEvents(recent), nil
+}
+
+// Automations 获取所有自动化，按创建时间倒序
+//
+// Returns:
+//   - []AutomationView: 自动化列表
+//   - error: 查询失败时返回错误
+func (s *QueryService) Automations() ([]AutomationView, error) {
+	automations, err := s.automationRepo.FindAll()
+	if err != nil {
+		return nil, fmt.Errorf("查询自动化失败: %w", err)
+	}
+
+	views := make([]AutomationView, 0, len(automations))
+	for _, automation := range automations {
+		views = append(views, newAutomationView(automation))
+	}
+	return views, nil
+}
+
+// CreateAutomation 创建自动化
+//
+// 指定模式时校验模式存在，名称和步骤为空则取模式的 AI 分析建议，
+// 创建后把模式标记为已自动化。未指定 Enabled 时默认启用
+//
+// Parameters:
+//   - req: 创建请求
+//
+// Returns:
+//   - *AutomationView: 创建的自动化
+//   - error: 参数无效时返回 ErrInvalidRequest，模式不存在时返回 ErrNotFound
+func (s *QueryService) CreateAutomation(req CreateAutomationRequest) (*AutomationView, error) {
+	name := strings.TrimSpace(req.Name)
+	steps := req.Steps
+
+	var pattern *models.Pattern
+	if req.PatternID != "" {
+		found, err := s.findPattern(req.PatternID)
+		if err != nil {
+			return nil, err
+		}
+		pattern = found
+
+		if analysis := pattern.AIAnalysis; analysis != nil {
+			if name == "" {
+				name = analysis.SuggestedName
+			}
+			if len(steps) == 0 {
+				steps = analysis.SuggestedSteps
+			}
+		}
+	}
+
+	if name == "" {
+		return nil, fmt.Errorf("%w: 自动化名称不能为空", ErrInvalidRequest)
+	}
+	if steps == nil {
+		steps = []string{}
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	now := s.now()
+	automation := &models.Automation{
+		ID:          uuid.New().String(),
+		Name:        name,
+		Description: req.Description,
+		PatternID:   req.PatternID,
+		Steps:       steps,
+		Enabled:     enabled,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := s.automationRepo.Save(automation); err != nil {
+		return nil, err
+	}
+
+	if pattern != nil && !pattern.IsAutomated {
+		pattern.IsAutomated = true
+		if err := s.patternRepo.Update(pattern); err != nil {
+			logger.Warn("标记模式为已自动化失败",
+				zap.String("component", "query_service"),
+				zap.String("pattern_id", pattern.ID),
+				zap.Error(err),
+			)
+		}
+	}
+
+	logger.Info("已创建自动化",
+		zap.String("component", "query_service"),
+		zap.String("automation_id", automation.ID),
+		zap.String("pattern_id", automation.PatternID),
+	)
+
+	view := newAutomationView(automation)
+	return &view, nil
+}
+
+// NewPatternView 把模式转换为对外数据结构
+//
+// Parameters:
+//   - pattern: 模式
+//
+// Returns:
+//   - PatternView: 模式的对外数据结构
+func NewPatternView(pattern *models.Pattern) PatternView {
+	view := PatternView{
+		ID:           pattern.ID,
+		Description:  pattern.Description,
+		SupportCount: pattern.SupportCount,
+		Confidence:   pattern.Confidence,
+		Frequency:    pattern.Frequency(),
+		FirstSeen:    pattern.FirstSeen,
+		LastSeen:     pattern.LastSeen,
+		IsAutomated:  pattern.IsAutomated,
+		Steps:        make([]StepView, 0, len(pattern.Sequence)),
+		AIAnalysis:   pattern.AIAnalysis,
+	}
+	for _, step := range pattern.Sequence {
+		item := StepView{Type: string(step.Type), Action: step.Action}
+		if step.Context != nil {
+			item.Application = step.Context.Application
+			item.Value = step.Context.PatternValue
+		}
+		view.Steps = append(view.Steps, item)
+	}
+	return view
+}
+
+// newAutomationView 把自动化转换为对外数据结构
+func newAutomationView(automation *models.Automation) AutomationView {
+	steps := automation.Steps
+	if steps == nil {
+		steps = []string{}
+	}
+	return AutomationView{
+		ID:          automation.ID,
+		Name:        automation.Name,
+		Description: automation.Description,
+		PatternID:   automation.PatternID,
+		Steps:       steps,
+		Enabled:     automation.Enabled,
+		CreatedAt:   automation.CreatedAt,
+		UpdatedAt:   automation.UpdatedAt,
+	}
+}
+
+// nonNilEvents 把 nil 切片转换为空切片，保证 JSON 输出为 []
+func nonNilEvents(list []events.Event) []events.Event {
+	if list == nil {
+		return []events.Event{}
+	}
+	return list
+}
@@ -0,0 +1,174 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/chenyang-zz/flowmind/pkg/events"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestQueryService 创建使用临时数据库的查询服务，并写入事件和模式
+func newTestQueryService(t *testing.T) (*QueryService, *storage.SQLitePatternRepository) {
+	t.Helper()
+
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/test.db"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, storage.RunMigrations(db))
+
+	eventRepo := storage.NewSQLiteEventRepository(db)
+	patternRepo := storage.NewSQLitePatternRepository(db)
+	automationRepo := storage.NewSQLiteAutomationRepository(db)
+
+	now := time.Now()
+	var list []events.Event
+	for i := 0; i < 3; i++ {
+		event := events.NewEvent(events.EventTypeKeyboard, map[string]interface{}{"keycode": i})
+		event.Timestamp = now.Add(time.Duration(i-3) * time.Minute)
+		list = append(list, *event)
+	}
+	clipboard := events.NewEvent(events.EventTypeClipboard, map[string]interface{}{"content": "hello"})
+	clipboard.Timestamp = now.Add(-30 * time.Second)
+	list = append(list, *clipboard)
+	require.NoError(t, eventRepo.SaveBatch(list))
+
+	require.NoError(t, patternRepo.Save(&models.Pattern{
+		ID: "pattern-copy",
+		Sequence: []models.EventStep{
+			{Type: events.EventTypeKeyboard, Action: "keypress", Context: &models.StepContext{Application: "Terminal"}},
+			{Type: events.EventTypeClipboard, Action: "clipboard_copy"},
+		},
+		SupportCount: 12,
+		Confidence:   0.8,
+		FirstSeen:    now.Add(-2 * time.Hour),
+		LastSeen:     now,
+		AIAnalysis: &models.AIAnalysis{
+			ShouldAutomate:      true,
+			SuggestedName:       "复制终端输出",
+			SuggestedSteps:      []string{"复制", "粘贴"},
+			EstimatedTimeSaving: 120,
+			AnalyzedAt:          now,
+		},
+	}))
+	require.NoError(t, patternRepo.Save(&models.Pattern{
+		ID:           "pattern-rare",
+		Sequence:     []models.EventStep{{Type: events.EventTypeAppSwitch, Action: "switch"}},
+		SupportCount: 3,
+		Confidence:   0.4,
+		FirstSeen:    now.Add(-time.Hour),
+		LastSeen:     now,
+	}))
+
+	return NewQueryService(eventRepo, patternRepo, automationRepo), patternRepo
+}
+
+// TestQueryService_Dashboard 测试仪表板汇总统计
+func TestQueryService_Dashboard(t *testing.T) {
+	query, _ := newTestQueryService(t)
+
+	data, err := query.Dashboard()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), data.TotalEvents)
+	assert.Equal(t, int64(3), data.EventsByType["keyboard"])
+	assert.Equal(t, 2, data.TotalPatterns)
+	assert.Equal(t, 1, data.AnalyzedPatterns)
+	assert.Equal(t, 1, data.AutomatablePatterns)
+	assert.Equal(t, int64(120), data.EstimatedTimeSaving)
+	assert.Equal(t, 0, data.TotalAutomations)
+	require.Len(t, data.RecentEvents, 4)
+	assert.Equal(t, events.EventTypeClipboard, data.RecentEvents[3].Type)
+}
+
+// TestQueryService_PatternStorageError 测试查询失败时不当作模式不存在
+func TestQueryService_PatternStorageError(t *testing.T) {
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/test.db"})
+	require.NoError(t, err)
+	require.NoError(t, storage.RunMigrations(db))
+	query := NewQueryService(storage.NewSQLiteEventRepository(db),
+		storage.NewSQLitePatternRepository(db), storage.NewSQLiteAutomationRepository(db))
+	require.NoError(t, db.Close())
+
+	_, err = query.Pattern("pattern-copy")
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+
+	_, err = query.CreateAutomation(CreateAutomationRequest{Name: "a", PatternID: "pattern-copy"})
+	require.Error(t, err)
+	assert.NotErrorIs(t, err, ErrNotFound)
+}
+
+// TestQueryService_PatternsAndEvents 测试模式列表和最近事件
+func TestQueryService_PatternsAndEvents(t *testing.T) {
+	query, _ := newTestQueryService(t)
+
+	patterns, err := query.Patterns()
+	require.NoError(t, err)
+	require.Len(t, patterns, 2)
+	assert.Equal(t, "pattern-copy", patterns[0].ID)
+	assert.Equal(t, "Terminal", patterns[0].Steps[0].Application)
+
+	pattern, err := query.Pattern("pattern-rare")
+	require.NoError(t, err)
+	assert.Equal(t, 3, pattern.SupportCount)
+
+	_, err = query.Pattern("missing")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	recent, err := query.RecentEvents(2)
+	require.NoError(t, err)
+	require.Len(t, recent, 2)
+	assert.Equal(t, events.EventTypeClipboard, recent[1].Type)
+
+	recent, err = query.RecentEvents(0)
+	require.NoError(t, err)
+	assert.Len(t, recent, 4)
+}
+
+// TestQueryService_CreateAutomation 测试创建自动化
+func TestQueryService_CreateAutomation(t *testing.T) {
+	query, patternRepo := newTestQueryService(t)
+
+	// 从模式创建时使用 AI 建议的名称和步骤，并标记模式已自动化
+	created, err := query.CreateAutomation(CreateAutomationRequest{PatternID: "pattern-copy"})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.ID)
+	assert.Equal(t, "复制终端输出", created.Name)
+	assert.Equal(t, []string{"复制", "粘贴"}, created.Steps)
+	assert.True(t, created.Enabled)
+
+	pattern, err := patternRepo.FindByID("pattern-copy")
+	require.NoError(t, err)
+	assert.True(t, pattern.IsAutomated)
+
+	// 手动创建
+	disabled := false
+	manual, err := query.CreateAutomation(CreateAutomationRequest{
+		Name:    "  整理下载目录 ",
+		Steps:   []string{"移动文件"},
+		Enabled: &disabled,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "整理下载目录", manual.Name)
+	assert.False(t, manual.Enabled)
+
+	_, err = query.CreateAutomation(CreateAutomationRequest{})
+	assert.ErrorIs(t, err, ErrInvalidRequest)
+
+	_, err = query.CreateAutomation(CreateAutomationRequest{PatternID: "pattern-rare"})
+	assert.ErrorIs(t, err, ErrInvalidRequest, "模式没有 AI 建议名称时必须指定名称")
+
+	_, err = query.CreateAutomation(CreateAutomationRequest{Name: "x", PatternID: "missing"})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	automations, err := query.Automations()
+	require.NoError(t, err)
+	require.Len(t, automations, 2)
+
+	data, err := query.Dashboard()
+	require.NoError(t, err)
+	assert.Equal(t, 2, data.TotalAutomations)
+}
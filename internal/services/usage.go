@@ -0,0 +1,325 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/ai"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+const (
+	// DefaultUsageDays 未指定天数时统计的天数
+	DefaultUsageDays = 30
+
+	// MaxUsageDays 最多统计的天数
+	MaxUsageDays = 366
+)
+
+// BudgetConfig AI 花费预算
+type BudgetConfig struct {
+	// DailyUSD 每日预算（美元），0 表示不限制
+	DailyUSD float64
+
+	// MonthlyUSD 每月预算（美元），0 表示不限制
+	MonthlyUSD float64
+}
+
+// UsageService AI 用量服务
+//
+// 按价格表计算每次 AI 调用的费用并持久化，检查每日和每月预算，
+// 按天和按提供商统计花费。Record 可直接作为 ai.UsageRecorder 使用。
+// 设置了预算时，使用过（或登记过）没有价格的付费模型视为超出预算，避免花费按 0 记录后预算永远不触发
+type UsageService struct {
+	// repo AI 用量仓储
+	repo models.AIUsageRepository
+
+	// prices 模型价格表
+	prices *ai.PriceTable
+
+	// budget 预算
+	budget BudgetConfig
+
+	// now 当前时间，测试时可替换
+	now func() time.Time
+
+	// mu 保护 unpriced
+	mu sync.Mutex
+
+	// unpriced 没有价格的付费模型，每个模型只提示一次
+	unpriced map[string]bool
+}
+
+// NewUsageService 创建 AI 用量服务
+//
+// Parameters:
+//   - repo: AI 用量仓储
+//   - prices: 模型价格表，为 nil 时使用内置价格
+//   - budget: 预算
+//
+// Returns:
+//   - *UsageService: 用量服务实例
+func NewUsageService(repo models.AIUsageRepository, prices *ai.PriceTable, budget BudgetConfig) *UsageService {
+	if prices == nil {
+		prices = ai.NewPriceTable(nil)
+	}
+	return &UsageService{
+		repo:     repo,
+		prices:   prices,
+		budget:   budget,
+		now:      time.Now,
+		unpriced: make(map[string]bool),
+	}
+}
+
+// UsageTotal 一组调用的用量汇总
+type UsageTotal struct {
+	Key          string  `json:"key"`
+	Calls        int     `json:"calls"`
+	InputTokens  int64   `json:"input_tokens"`
+	OutputTokens int64   `json:"output_tokens"`
+	CostUSD      float64 `json:"cost_usd"`
+}
+
+// BudgetStatus 预算使用情况
+type BudgetStatus struct {
+	DailyLimitUSD   float64 `json:"daily_limit_usd"`
+	DailySpentUSD   float64 `json:"daily_spent_usd"`
+	MonthlyLimitUSD float64 `json:"monthly_limit_usd"`
+	MonthlySpentUSD float64 `json:"monthly_spent_usd"`
+	Exceeded        bool    `json:"exceeded"`
+}
+
+// UsageReport 一段时间内的 AI 花费
+type UsageReport struct {
+	From       time.Time    `json:"from"`
+	To         time.Time    `json:"to"`
+	Total      UsageTotal   `json:"total"`
+	ByDay      []UsageTotal `json:"by_day"`
+	ByProvider []UsageTotal `json:"by_provider"`
+	Budget     BudgetStatus `json:"budget"`
+}
+
+// Record 计算一次调用的费用并保存
+//
+// 保存失败只记录日志，不影响 AI 调用
+//
+// Parameters:
+//   - ctx: 调用的上下文
+//   - usage: 调用的用量
+func (s *UsageService) Record(ctx context.Context, usage ai.Usage) {
+	cost, priced := s.prices.Cost(usage.Provider, usage.Model, usage.InputTokens, usage.OutputTokens)
+	if !priced {
+		s.markUnpriced(usage.Model)
+	}
+
+	record := &models.AIUsage{
+		Provider:     usage.Provider,
+		Model:        usage.Model,
+		PatternID:    usage.PatternID,
+		Purpose:      usage.Purpose,
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		CostUSD:      cost,
+		CreatedAt:    usage.CreatedAt,
+	}
+	if record.CreatedAt.IsZero() {
+		record.CreatedAt = s.now()
+	}
+	if err := s.repo.Save(record); err != nil {
+		logger.Warn("记录 AI 用量失败",
+			zap.String("component", "usage_service"),
+			zap.String("provider", usage.Provider),
+			zap.Error(err),
+		)
+	}
+}
+
+// RegisterModel 登记将要调用的模型
+//
+// 启动时登记配置的模型，没有价格的付费模型在第一次调用前就按超出预算处理
+//
+// Parameters:
+//   - provider: 提供商
+//   - model: 模型名称
+func (s *UsageService) RegisterModel(provider, model string) {
+	if _, priced := s.prices.Cost(provider, model, 0, 0); !priced {
+		s.markUnpriced(model)
+	}
+}
+
+// CheckBudget 检查是否超出每日或每月预算
+//
+// 设置了预算但有付费模型没有价格时无法核算花费，视为超出预算
+//
+// Parameters:
+//   - ctx: 上下文
+//
+// Returns:
+//   - error: 超出预算时返回包装了 ai.ErrBudgetExceeded 的错误，查询失败时返回其他错误
+func (s *UsageService) CheckBudget(ctx context.Context) error {
+	if s.budget.DailyUSD <= 0 && s.budget.MonthlyUSD <= 0 {
+		return nil
+	}
+	if model := s.unpricedModel(); model != "" {
+		return fmt.Errorf("%w: 模型 %s 没有价格，无法核算花费，请在 ai.budget.prices 中配置",
+			ai.ErrBudgetExceeded, model)
+	}
+
+	status, err := s.budgetStatus()
+	if err != nil {
+		return err
+	}
+	if s.budget.DailyUSD > 0 && status.DailySpentUSD >= s.budget.DailyUSD {
+		return fmt.Errorf("%w: 今日已花费 $%.4f，每日预算 $%.2f",
+			ai.ErrBudgetExceeded, status.DailySpentUSD, s.budget.DailyUSD)
+	}
+	if s.budget.MonthlyUSD > 0 && status.MonthlySpentUSD >= s.budget.MonthlyUSD {
+		return fmt.Errorf("%w: 本月已花费 $%.4f，每月预算 $%.2f",
+			ai.ErrBudgetExceeded, status.MonthlySpentUSD, s.budget.MonthlyUSD)
+	}
+	return nil
+}
+
+// Report 统计最近若干天的花费
+//
+// Parameters:
+//   - days: 包含今天在内的天数，小于等于 0 时使用 DefaultUsageDays，最多 MaxUsageDays
+//
+// Returns:
+//   - *UsageReport: 总计、按天（升序）和按提供商（按费用降序）的花费，以及预算使用情况
+//   - error: 查询失败时返回错误
+func (s *UsageService) Report(days int) (*UsageReport, error) {
+	if days <= 0 {
+		days = DefaultUsageDays
+	}
+	if days > MaxUsageDays {
+		days = MaxUsageDays
+	}
+
+	now := s.now()
+	from := startOfDay(now).AddDate(0, 0, -(days - 1))
+	usages, err := s.repo.FindByTimeRange(from, now)
+	if err != nil {
+		return nil, err
+	}
+	budget, err := s.budgetStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	report := &UsageReport{
+		From:       from,
+		To:         now,
+		Total:      UsageTotal{Key: "total"},
+		ByDay:      []UsageTotal{},
+		ByProvider: []UsageTotal{},
+		Budget:     *budget,
+	}
+	byDay := make(map[string]*UsageTotal)
+	byProvider := make(map[string]*UsageTotal)
+	for _, usage := range usages {
+		report.Total.add(usage)
+		addTo(byDay, usage.CreatedAt.In(now.Location()).Format("2006-01-02"), usage)
+		addTo(byProvider, usage.Provider, usage)
+	}
+
+	for _, total := range byDay {
+		report.ByDay = append(report.ByDay, *total)
+	}
+	sort.Slice(report.ByDay, func(i, j int) bool {
+		return report.ByDay[i].Key < report.ByDay[j].Key
+	})
+	for _, total := range byProvider {
+		report.ByProvider = append(report.ByProvider, *total)
+	}
+	sort.Slice(report.ByProvider, func(i, j int) bool {
+		if report.ByProvider[i].CostUSD != report.ByProvider[j].CostUSD {
+			return report.ByProvider[i].CostUSD > report.ByProvider[j].CostUSD
+		}
+		return report.ByProvider[i].Key < report.ByProvider[j].Key
+	})
+
+	return report, nil
+}
+
+// budgetStatus 统计今日和本月的花费
+func (s *UsageService) budgetStatus() (*BudgetStatus, error) {
+	now := s.now()
+	today := startOfDay(now)
+	daily, err := s.repo.TotalCost(today, now)
+	if err != nil {
+		return nil, err
+	}
+	monthly, err := s.repo.TotalCost(today.AddDate(0, 0, 1-today.Day()), now)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &BudgetStatus{
+		DailyLimitUSD:   s.budget.DailyUSD,
+		DailySpentUSD:   daily,
+		MonthlyLimitUSD: s.budget.MonthlyUSD,
+		MonthlySpentUSD: monthly,
+	}
+	status.Exceeded = (s.budget.DailyUSD > 0 && daily >= s.budget.DailyUSD) ||
+		(s.budget.MonthlyUSD > 0 && monthly >= s.budget.MonthlyUSD) ||
+		((s.budget.DailyUSD > 0 || s.budget.MonthlyUSD > 0) && s.unpricedModel() != "")
+	return status, nil
+}
+
+// markUnpriced 记录没有价格的付费模型，费用按 0 记录，设置了预算时视为超出预算
+func (s *UsageService) markUnpriced(model string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.unpriced[model] {
+		return
+	}
+	s.unpriced[model] = true
+	logger.Warn("模型没有价格，费用按 0 记录，设置了预算时视为超出预算，可在 ai.budget.prices 中配置",
+		zap.String("component", "usage_service"),
+		zap.String("model", model),
+	)
+}
+
+// unpricedModel 获取名称最小的没有价格的付费模型，没有时返回空字符串
+func (s *UsageService) unpricedModel() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var first string
+	for model := range s.unpriced {
+		if first == "" || model < first {
+			first = model
+		}
+	}
+	return first
+}
+
+// add 累加一次调用的用量
+func (t *UsageTotal) add(usage *models.AIUsage) {
+	t.Calls++
+	t.InputTokens += int64(usage.InputTokens)
+	t.OutputTokens += int64(usage.OutputTokens)
+	t.CostUSD += usage.CostUSD
+}
+
+// addTo 把用量累加到指定分组
+func addTo(groups map[string]*UsageTotal, key string, usage *models.AIUsage) {
+	total, ok := groups[key]
+	if !ok {
+		total = &UsageTotal{Key: key}
+		groups[key] = total
+	}
+	total.add(usage)
+}
+
+// startOfDay 获取当天零点
+func startOfDay(t time.Time) time.Time {
+	year, month, day := t.Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
@@ -0,0 +1,180 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/domain/models"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/ai"
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/storage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestUsageService 创建使用临时数据库的用量服务，当前时间固定为 2026-03-15 12:00
+func newTestUsageService(t *testing.T, budget BudgetConfig) (*UsageService, *storage.SQLiteAIUsageRepository, time.Time) {
+	t.Helper()
+
+	db, err := storage.NewSQLiteDB(storage.SQLiteConfig{Path: t.TempDir() + "/test.db"})
+	require.NoError(t, err)
+	t.Cleanup(func() { db.Close() })
+	require.NoError(t, storage.RunMigrations(db))
+
+	repo := storage.NewSQLiteAIUsageRepository(db)
+	service := NewUsageService(repo, ai.NewPriceTable(nil), budget)
+	now := time.Date(2026, 3, 15, 12, 0, 0, 0, time.Local)
+	service.now = func() time.Time { return now }
+	return service, repo, now
+}
+
+// TestUsageService_Record 测试按价格表计算费用并保存
+func TestUsageService_Record(t *testing.T) {
+	service, repo, now := newTestUsageService(t, BudgetConfig{})
+
+	service.Record(context.Background(), ai.Usage{
+		Provider:     "claude",
+		Model:        "claude-3-5-sonnet-20241022",
+		PatternID:    "pattern-1",
+		Purpose:      ai.PurposePatternAnalysis,
+		InputTokens:  2000,
+		OutputTokens: 500,
+		CreatedAt:    now.Add(-time.Minute),
+	})
+	service.Record(context.Background(), ai.Usage{
+		Provider:     "ollama",
+		Model:        "llama3.2",
+		Purpose:      ai.PurposeOutputRepair,
+		InputTokens:  3000,
+		OutputTokens: 300,
+	})
+
+	usages, err := repo.FindByTimeRange(now.Add(-time.Hour), now.Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, usages, 2)
+	assert.InDelta(t, 0.0135, usages[0].CostUSD, 1e-9)
+	assert.Equal(t, "pattern-1", usages[0].PatternID)
+	assert.Zero(t, usages[1].CostUSD)
+	assert.True(t, now.Equal(usages[1].CreatedAt))
+}
+
+// TestUsageService_CheckBudget 测试每日和每月预算
+func TestUsageService_CheckBudget(t *testing.T) {
+	service, repo, now := newTestUsageService(t, BudgetConfig{DailyUSD: 1, MonthlyUSD: 5})
+	require.NoError(t, service.CheckBudget(context.Background()))
+
+	// 本月早些时候的花费只计入每月预算
+	require.NoError(t, repo.Save(&models.AIUsage{Provider: "openai", Model: "gpt-4o", Purpose: "pattern_analysis",
+		CostUSD: 4.5, CreatedAt: now.AddDate(0, 0, -3)}))
+	// 上个月的花费不计入
+	require.NoError(t, repo.Save(&models.AIUsage{Provider: "openai", Model: "gpt-4o", Purpose: "pattern_analysis",
+		CostUSD: 100, CreatedAt: now.AddDate(0, -1, 0)}))
+	require.NoError(t, service.CheckBudget(context.Background()))
+
+	require.NoError(t, repo.Save(&models.AIUsage{Provider: "openai", Model: "gpt-4o", Purpose: "pattern_analysis",
+		CostUSD: 0.6, CreatedAt: now.Add(-time.Hour)}))
+	err := service.CheckBudget(context.Background())
+	assert.ErrorIs(t, err, ai.ErrBudgetExceeded)
+	assert.ErrorContains(t, err, "本月")
+
+	// 未设置预算时不限制
+	service.budget = BudgetConfig{}
+	assert.NoError(t, service.CheckBudget(context.Background()))
+
+	service.budget = BudgetConfig{DailyUSD: 0.5}
+	err = service.CheckBudget(context.Background())
+	assert.ErrorIs(t, err, ai.ErrBudgetExceeded)
+	assert.ErrorContains(t, err, "今日")
+}
+
+// TestUsageService_UnpricedModel 测试设置预算时没有价格的付费模型视为超出预算
+func TestUsageService_UnpricedModel(t *testing.T) {
+	service, repo, now := newTestUsageService(t, BudgetConfig{DailyUSD: 1})
+
+	// 免费提供商和有价格的模型不影响预算
+	service.RegisterModel("ollama", "llama3.2")
+	service.RegisterModel("claude", "claude-3-5-sonnet-20241022")
+	require.NoError(t, service.CheckBudget(context.Background()))
+
+	service.Record(context.Background(), ai.Usage{
+		Provider:     "openai",
+		Model:        "gpt-5-preview",
+		InputTokens:  100000,
+		OutputTokens: 20000,
+	})
+	usages, err := repo.FindByTimeRange(now.Add(-time.Hour), now.Add(time.Second))
+	require.NoError(t, err)
+	require.Len(t, usages, 1)
+	assert.Zero(t, usages[0].CostUSD)
+
+	err = service.CheckBudget(context.Background())
+	assert.ErrorIs(t, err, ai.ErrBudgetExceeded)
+	assert.ErrorContains(t, err, "gpt-5-preview")
+	report, err := service.Report(1)
+	require.NoError(t, err)
+	assert.True(t, report.Budget.Exceeded)
+
+	// 启动时登记的模型在第一次调用前就生效
+	registered, _, _ := newTestUsageService(t, BudgetConfig{MonthlyUSD: 10})
+	registered.RegisterModel("claude", "claude-4-custom")
+	assert.ErrorIs(t, registered.CheckBudget(context.Background()), ai.ErrBudgetExceeded)
+
+	// 配置价格后正常核算
+	priced := NewUsageService(repo, ai.NewPriceTable(map[string]ai.ModelPrice{
+		"claude-4-custom": {Input: 3, Output: 15},
+	}), BudgetConfig{DailyUSD: 1})
+	priced.now = service.now
+	priced.RegisterModel("claude", "claude-4-custom")
+	assert.NoError(t, priced.CheckBudget(context.Background()))
+
+	// 未设置预算时不限制
+	unlimited, _, _ := newTestUsageService(t, BudgetConfig{})
+	unlimited.RegisterModel("claude", "claude-4-custom")
+	assert.NoError(t, unlimited.CheckBudget(context.Background()))
+}
+
+// TestUsageService_Report 测试按天和按提供商统计花费
+func TestUsageService_Report(t *testing.T) {
+	service, repo, now := newTestUsageService(t, BudgetConfig{DailyUSD: 1})
+
+	records := []*models.AIUsage{
+		{Provider: "claude", Model: "claude-3-5-sonnet", Purpose: "pattern_analysis", InputTokens: 100, OutputTokens: 10,
+			CostUSD: 0.2, CreatedAt: now.Add(-time.Hour)},
+		{Provider: "openai", Model: "gpt-4o-mini", Purpose: "pattern_analysis", InputTokens: 200, OutputTokens: 20,
+			CostUSD: 0.05, CreatedAt: now.Add(-2 * time.Hour)},
+		{Provider: "claude", Model: "claude-3-5-sonnet", Purpose: "output_repair", InputTokens: 300, OutputTokens: 30,
+			CostUSD: 0.3, CreatedAt: now.AddDate(0, 0, -2)},
+		{Provider: "claude", Model: "claude-3-5-sonnet", Purpose: "pattern_analysis", InputTokens: 400, OutputTokens: 40,
+			CostUSD: 9, CreatedAt: now.AddDate(0, 0, -10)},
+	}
+	for _, record := range records {
+		require.NoError(t, repo.Save(record))
+	}
+
+	report, err := service.Report(7)
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 3, 9, 0, 0, 0, 0, time.Local), report.From)
+	assert.Equal(t, 3, report.Total.Calls)
+	assert.Equal(t, int64(600), report.Total.InputTokens)
+	assert.Equal(t, int64(60), report.Total.OutputTokens)
+	assert.InDelta(t, 0.55, report.Total.CostUSD, 1e-9)
+
+	require.Len(t, report.ByDay, 2)
+	assert.Equal(t, "2026-03-13", report.ByDay[0].Key)
+	assert.Equal(t, "2026-03-15", report.ByDay[1].Key)
+	assert.Equal(t, 2, report.ByDay[1].Calls)
+
+	require.Len(t, report.ByProvider, 2)
+	assert.Equal(t, "claude", report.ByProvider[0].Key)
+	assert.InDelta(t, 0.5, report.ByProvider[0].CostUSD, 1e-9)
+	assert.Equal(t, "openai", report.ByProvider[1].Key)
+
+	assert.InDelta(t, 0.25, report.Budget.DailySpentUSD, 1e-9)
+	assert.InDelta(t, 9.55, report.Budget.MonthlySpentUSD, 1e-9)
+	assert.False(t, report.Budget.Exceeded)
+
+	// 默认统计 30 天
+	report, err = service.Report(0)
+	require.NoError(t, err)
+	assert.Equal(t, 4, report.Total.Calls)
+}
@@ -0,0 +1,395 @@
+package events
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * BackpressurePolicy 背压策略
+ *
+ * 决定订阅者缓冲区满时如何处理新事件
+ */
+type BackpressurePolicy string
+
+const (
+	// BackpressureDropNewest 丢弃新事件（默认，与之前的行为一致）
+	BackpressureDropNewest BackpressurePolicy = "drop_newest"
+
+	// BackpressureDropOldest 丢弃缓冲区中最旧的事件，为新事件腾出位置
+	BackpressureDropOldest BackpressurePolicy = "drop_oldest"
+
+	// BackpressureBlock 阻塞发布者直到缓冲区有空位或超时，超时后丢弃新事件
+	BackpressureBlock BackpressurePolicy = "block"
+
+	// BackpressureCoalesce 缓冲区满后按键合并：同一个键只保留最新的事件
+	BackpressureCoalesce BackpressurePolicy = "coalesce"
+)
+
+/**
+ * DefaultBlockTimeout 阻塞策略的默认等待时间
+ */
+const DefaultBlockTimeout = 100 * time.Millisecond
+
+/**
+ * SubscribeOption 订阅选项
+ */
+type SubscribeOption func(*Subscriber)
+
+/**
+ * WithFilter 设置事件过滤器
+ *
+ * Parameters:
+ *   - filter: 事件过滤器
+ */
+func WithFilter(filter EventFilter) SubscribeOption {
+	return func(s *Subscriber) {
+		s.Filter = filter
+	}
+}
+
+/**
+ * WithBufferSize 设置订阅者的缓冲区大小
+ *
+ * Parameters:
+ *   - size: 缓冲区大小，小于等于 0 时使用事件总线的默认值
+ */
+func WithBufferSize(size int) SubscribeOption {
+	return func(s *Subscriber) {
+		if size > 0 {
+			s.bufferSize = size
+		}
+	}
+}
+
+/**
+ * WithDropNewest 缓冲区满时丢弃新事件
+ */
+func WithDropNewest() SubscribeOption {
+	return func(s *Subscriber) {
+		s.Policy = BackpressureDropNewest
+	}
+}
+
+/**
+ * WithDropOldest 缓冲区满时丢弃最旧的事件
+ *
+ * 适合只关心最新状态的订阅者，如 UI 转发
+ */
+func WithDropOldest() SubscribeOption {
+	return func(s *Subscriber) {
+		s.Policy = BackpressureDropOldest
+	}
+}
+
+/**
+ * WithBlockTimeout 缓冲区满时阻塞发布者
+ *
+ * 适合不能丢事件的订阅者，如持久化。阻塞会拖慢发布者，超时时间应尽量短
+ *
+ * Parameters:
+ *   - timeout: 最长等待时间，小于等于 0 时使用 DefaultBlockTimeout
+ */
+func WithBlockTimeout(timeout time.Duration) SubscribeOption {
+	return func(s *Subscriber) {
+		if timeout <= 0 {
+			timeout = DefaultBlockTimeout
+		}
+		s.Policy = BackpressureBlock
+		s.BlockTimeout = timeout
+	}
+}
+
+/**
+ * WithCoalesce 缓冲区满后按键合并事件
+ *
+ * 积压期间同一个键只保留最新的事件，键为空的事件不合并。
+ * 积压的事件最多保留缓冲区大小个，超出后丢弃新事件
+ *
+ * Parameters:
+ *   - key: 合并键函数，例如按应用名合并应用切换事件
+ */
+func WithCoalesce(key func(Event) string) SubscribeOption {
+	return func(s *Subscriber) {
+		s.Policy = BackpressureCoalesce
+		s.CoalesceKey = key
+	}
+}
+
+/**
+ * SubscriberStats 订阅者统计信息
+ */
+type SubscriberStats struct {
+	// ID 订阅者 ID
+	ID string `json:"id"`
+
+	// Name 订阅者名称
+	Name string `json:"name"`
+
+	// Topic 订阅主题
+	Topic string `json:"topic"`
+
+	// Policy 背压策略
+	Policy BackpressurePolicy `json:"policy"`
+
+	// BufferSize 缓冲区大小
+	BufferSize int `json:"buffer_size"`
+
+	// Delivered 已交付给处理函数的事件数
+	Delivered uint64 `json:"delivered"`
+
+	// Dropped 因缓冲区满而丢弃的事件数
+	Dropped uint64 `json:"dropped"`
+
+	// Coalesced 被同键新事件合并掉的事件数
+	Coalesced uint64 `json:"coalesced"`
+
+	// Lagging 已接收但尚未交付的事件数
+	Lagging int `json:"lagging"`
+
+	// Retried 处理失败后的重试次数
+	Retried uint64 `json:"retried"`
+
+	// DeadLettered 重试耗尽后进入死信的事件数
+	DeadLettered uint64 `json:"dead_lettered"`
+}
+
+/**
+ * stats 生成订阅者的统计快照
+ */
+func (s *Subscriber) stats(topic string) SubscriberStats {
+	return SubscriberStats{
+		ID:           s.ID,
+		Name:         s.Name,
+		Topic:        topic,
+		Policy:       s.Policy,
+		BufferSize:   cap(s.Chan),
+		Delivered:    s.delivered.Load(),
+		Dropped:      s.dropped.Load(),
+		Coalesced:    s.coalesced.Load(),
+		Lagging:      len(s.Chan) + s.backlog.size(),
+		Retried:      s.retried.Load(),
+		DeadLettered: s.deadLettered.Load(),
+	}
+}
+
+/**
+ * SubscriberStats 获取单个订阅者的统计信息
+ *
+ * Parameters:
+ *   - subscriberID: 订阅者 ID
+ *
+ * Returns:
+ *   - SubscriberStats: 统计信息
+ *   - bool: 订阅者不存在时为 false
+ */
+func (bus *EventBus) SubscriberStats(subscriberID string) (SubscriberStats, bool) {
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+
+	for topic, subscribers := range bus.subscribers {
+		for _, sub := range subscribers {
+			if sub.ID == subscriberID {
+				return sub.stats(topic), true
+			}
+		}
+	}
+	return SubscriberStats{}, false
+}
+
+/**
+ * Stats 获取所有订阅者的统计信息
+ *
+ * Returns:
+ *   - []SubscriberStats: 统计信息列表
+ */
+func (bus *EventBus) Stats() []SubscriberStats {
+	bus.mutex.RLock()
+	defer bus.mutex.RUnlock()
+
+	var stats []SubscriberStats
+	for topic, subscribers := range bus.subscribers {
+		for _, sub := range subscribers {
+			stats = append(stats, sub.stats(topic))
+		}
+	}
+	return stats
+}
+
+/**
+ * enqueue 按背压策略把事件放入订阅者缓冲区
+ *
+ * Parameters:
+ *   - subscriber: 订阅者
+ *   - event: 事件对象
+ *
+ * Returns:
+ *   - bool: false 表示事件被丢弃
+ */
+func (bus *EventBus) enqueue(subscriber *Subscriber, event Event) bool {
+	subscriber.mu.RLock()
+	defer subscriber.mu.RUnlock()
+
+	if subscriber.closed {
+		return false
+	}
+
+	var ok bool
+	switch subscriber.Policy {
+	case BackpressureDropOldest:
+		ok = subscriber.enqueueDropOldest(event)
+	case BackpressureBlock:
+		ok = subscriber.enqueueBlock(event)
+	case BackpressureCoalesce:
+		ok = subscriber.enqueueCoalesce(event)
+	default:
+		select {
+		case subscriber.Chan <- event:
+			ok = true
+		default:
+		}
+	}
+
+	if !ok {
+		subscriber.dropped.Add(1)
+		logger.Warn("事件缓冲区满，丢弃事件",
+			zap.String("subscriber_id", subscriber.ID),
+			zap.String("event_type", string(event.Type)),
+			zap.String("policy", string(subscriber.Policy)),
+		)
+	}
+	return ok
+}
+
+/**
+ * enqueueDropOldest 丢弃最旧的事件后放入新事件
+ */
+func (s *Subscriber) enqueueDropOldest(event Event) bool {
+	for attempt := 0; attempt < 2; attempt++ {
+		select {
+		case s.Chan <- event:
+			return true
+		default:
+		}
+
+		select {
+		case <-s.Chan:
+			s.dropped.Add(1)
+		default:
+		}
+	}
+	return false
+}
+
+/**
+ * enqueueBlock 等待缓冲区空位，超时后放弃
+ */
+func (s *Subscriber) enqueueBlock(event Event) bool {
+	select {
+	case s.Chan <- event:
+		return true
+	default:
+	}
+
+	timer := time.NewTimer(s.BlockTimeout)
+	defer timer.Stop()
+
+	select {
+	case s.Chan <- event:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
+/**
+ * enqueueCoalesce 缓冲区满或已有积压时放入合并队列
+ *
+ * 有积压时新事件也进入合并队列，保证积压的旧事件先于新事件交付
+ */
+func (s *Subscriber) enqueueCoalesce(event Event) bool {
+	s.backlog.mu.Lock()
+	defer s.backlog.mu.Unlock()
+
+	if len(s.backlog.keys) == 0 {
+		select {
+		case s.Chan <- event:
+			return true
+		default:
+		}
+	}
+
+	key := ""
+	if s.CoalesceKey != nil {
+		key = s.CoalesceKey(event)
+	}
+	if key == "" {
+		// 不合并的事件使用唯一键
+		s.backlog.seq++
+		key = "\x00" + strconv.FormatUint(s.backlog.seq, 10)
+	}
+
+	if _, exists := s.backlog.events[key]; exists {
+		s.backlog.events[key] = event
+		s.coalesced.Add(1)
+		return true
+	}
+	if len(s.backlog.keys) >= cap(s.Chan) {
+		return false
+	}
+
+	if s.backlog.events == nil {
+		s.backlog.events = make(map[string]Event)
+	}
+	s.backlog.keys = append(s.backlog.keys, key)
+	s.backlog.events[key] = event
+	return true
+}
+
+/**
+ * takeBacklog 缓冲区为空时取出合并队列中的全部事件
+ *
+ * 与 enqueueCoalesce 共用锁，保证取出时没有新事件进入缓冲区
+ *
+ * Returns:
+ *   - []Event: 按首次进入积压的顺序排列的事件
+ */
+func (s *Subscriber) takeBacklog() []Event {
+	s.backlog.mu.Lock()
+	defer s.backlog.mu.Unlock()
+
+	if len(s.backlog.keys) == 0 || len(s.Chan) > 0 {
+		return nil
+	}
+
+	backlog := make([]Event, 0, len(s.backlog.keys))
+	for _, key := range s.backlog.keys {
+		backlog = append(backlog, s.backlog.events[key])
+	}
+	s.backlog.keys = nil
+	s.backlog.events = nil
+	return backlog
+}
+
+/**
+ * coalesceBacklog 合并策略的积压队列
+ */
+type coalesceBacklog struct {
+	mu     sync.Mutex
+	keys   []string
+	events map[string]Event
+	seq    uint64
+}
+
+/**
+ * size 积压的事件数
+ */
+func (b *coalesceBacklog) size() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return len(b.keys)
+}
@@ -0,0 +1,212 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingHandler 处理第一个事件时阻塞，直到 release 被调用
+type blockingHandler struct {
+	started  chan struct{}
+	released chan struct{}
+	once     sync.Once
+
+	mu       sync.Mutex
+	received []int
+}
+
+func newBlockingHandler() *blockingHandler {
+	return &blockingHandler{
+		started:  make(chan struct{}),
+		released: make(chan struct{}),
+	}
+}
+
+func (h *blockingHandler) handle(event Event) error {
+	h.once.Do(func() {
+		close(h.started)
+		<-h.released
+	})
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.received = append(h.received, event.Data["seq"].(int))
+	return nil
+}
+
+func (h *blockingHandler) release() {
+	close(h.released)
+}
+
+func (h *blockingHandler) seqs() []int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]int(nil), h.received...)
+}
+
+// publishSeq 发布带序号和键的测试事件
+func publishSeq(t *testing.T, bus *EventBus, seq int, key string) {
+	t.Helper()
+	event := NewEvent("test", map[string]interface{}{"seq": seq, "key": key})
+	require.NoError(t, bus.Publish("test", *event))
+}
+
+// fillSubscriber 发布第一个事件并等待处理函数阻塞，之后的事件都会进入缓冲区
+func fillSubscriber(t *testing.T, bus *EventBus, handler *blockingHandler) {
+	t.Helper()
+	publishSeq(t, bus, 1, "")
+	select {
+	case <-handler.started:
+	case <-time.After(time.Second):
+		t.Fatal("处理函数没有开始处理第一个事件")
+	}
+}
+
+// TestBackpressure_DropNewest 测试默认策略丢弃新事件
+func TestBackpressure_DropNewest(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	handler := newBlockingHandler()
+	id := bus.SubscribeWithOptions("test", handler.handle, WithBufferSize(2))
+	fillSubscriber(t, bus, handler)
+
+	publishSeq(t, bus, 2, "")
+	publishSeq(t, bus, 3, "")
+	publishSeq(t, bus, 4, "")
+
+	stats, ok := bus.SubscriberStats(id)
+	require.True(t, ok)
+	assert.Equal(t, BackpressureDropNewest, stats.Policy)
+	assert.Equal(t, uint64(1), stats.Dropped)
+	assert.Equal(t, 2, stats.Lagging)
+
+	handler.release()
+	require.Eventually(t, func() bool { return len(handler.seqs()) == 3 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3}, handler.seqs())
+
+	stats, _ = bus.SubscriberStats(id)
+	assert.Equal(t, uint64(3), stats.Delivered)
+	assert.Equal(t, 0, stats.Lagging)
+}
+
+// TestBackpressure_DropOldest 测试丢弃最旧的事件
+func TestBackpressure_DropOldest(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	handler := newBlockingHandler()
+	id := bus.SubscribeWithOptions("test", handler.handle, WithBufferSize(2), WithDropOldest())
+	fillSubscriber(t, bus, handler)
+
+	publishSeq(t, bus, 2, "")
+	publishSeq(t, bus, 3, "")
+	publishSeq(t, bus, 4, "")
+
+	handler.release()
+	require.Eventually(t, func() bool { return len(handler.seqs()) == 3 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1, 3, 4}, handler.seqs())
+
+	stats, _ := bus.SubscriberStats(id)
+	assert.Equal(t, uint64(1), stats.Dropped)
+	assert.Equal(t, uint64(3), stats.Delivered)
+}
+
+// TestBackpressure_BlockTimeout 测试阻塞策略等待空位并在超时后丢弃
+func TestBackpressure_BlockTimeout(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	handler := newBlockingHandler()
+	id := bus.SubscribeWithOptions("test", handler.handle, WithBufferSize(1), WithBlockTimeout(30*time.Millisecond))
+	fillSubscriber(t, bus, handler)
+
+	publishSeq(t, bus, 2, "")
+
+	// 缓冲区满且没有空位，超时后丢弃
+	start := time.Now()
+	publishSeq(t, bus, 3, "")
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+
+	stats, _ := bus.SubscriberStats(id)
+	assert.Equal(t, BackpressureBlock, stats.Policy)
+	assert.Equal(t, uint64(1), stats.Dropped)
+
+	// 等待期间腾出空位则不丢弃
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		handler.release()
+	}()
+	publishSeq(t, bus, 4, "")
+
+	require.Eventually(t, func() bool { return len(handler.seqs()) == 3 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1, 2, 4}, handler.seqs())
+
+	stats, _ = bus.SubscriberStats(id)
+	assert.Equal(t, uint64(1), stats.Dropped)
+}
+
+// TestBackpressure_Coalesce 测试积压期间按键合并事件
+func TestBackpressure_Coalesce(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	handler := newBlockingHandler()
+	id := bus.SubscribeWithOptions("test", handler.handle, WithBufferSize(2), WithCoalesce(func(event Event) string {
+		return event.Data["key"].(string)
+	}))
+	fillSubscriber(t, bus, handler)
+
+	publishSeq(t, bus, 2, "a")
+	publishSeq(t, bus, 3, "b")
+	publishSeq(t, bus, 4, "a") // 缓冲区满，进入积压
+	publishSeq(t, bus, 5, "a") // 与 4 合并
+	publishSeq(t, bus, 6, "c")
+
+	stats, _ := bus.SubscriberStats(id)
+	assert.Equal(t, BackpressureCoalesce, stats.Policy)
+	assert.Equal(t, uint64(1), stats.Coalesced)
+	assert.Equal(t, uint64(0), stats.Dropped)
+	assert.Equal(t, 4, stats.Lagging)
+
+	handler.release()
+	require.Eventually(t, func() bool { return len(handler.seqs()) == 5 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, []int{1, 2, 3, 5, 6}, handler.seqs())
+
+	// 积压清空后恢复直接进入缓冲区
+	publishSeq(t, bus, 7, "a")
+	require.Eventually(t, func() bool { return len(handler.seqs()) == 6 }, time.Second, 10*time.Millisecond)
+
+	stats, _ = bus.SubscriberStats(id)
+	assert.Equal(t, uint64(6), stats.Delivered)
+	assert.Equal(t, 0, stats.Lagging)
+}
+
+// TestEventBus_Stats 测试获取所有订阅者的统计信息
+func TestEventBus_Stats(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	first := bus.Subscribe("test", func(Event) error { return nil })
+	second := bus.SubscribeWithOptions("hotkey.*", func(Event) error { return nil }, WithDropOldest())
+
+	stats := bus.Stats()
+	require.Len(t, stats, 2)
+
+	byID := make(map[string]SubscriberStats)
+	for _, s := range stats {
+		byID[s.ID] = s
+	}
+	assert.Equal(t, "test", byID[first].Topic)
+	assert.Equal(t, BackpressureDropNewest, byID[first].Policy)
+	assert.Equal(t, "hotkey.*", byID[second].Topic)
+	assert.Equal(t, BackpressureDropOldest, byID[second].Policy)
+
+	bus.Unsubscribe(first)
+	_, ok := bus.SubscriberStats(first)
+	assert.False(t, ok)
+}
@@ -3,7 +3,7 @@
  *
  * EventBus 是发布-订阅模式的核心实现，支持：
  * - 类型安全的订阅和发布
- * - 通配符订阅
+ * - 通配符和分层主题订阅（hotkey.*、monitor.**、clipboard|keyboard）
  * - 异步事件处理
  * - 中间件链
  * - 优雅关闭
@@ -47,6 +47,17 @@ type EventFilter func(event Event) bool
  */
 type Middleware func(EventHandler) EventHandler
 
+/**
+ * Publisher 事件发布者接口
+ *
+ * 只需要发布能力的组件（如监控器）依赖此接口，
+ * 便于在事件进入总线前插入过滤等逻辑。EventBus 实现了此接口。
+ */
+type Publisher interface {
+	// Publish 发布事件
+	Publish(eventType string, event Event) error
+}
+
 /**
  * Subscriber 订阅者信息
  */
@@ -54,6 +65,9 @@ type Subscriber struct {
 	// ID 订阅者唯一标识
 	ID string
 
+	// Name 订阅者名称，重启后保持不变，默认与 ID 相同
+	Name string
+
 	// Handler 事件处理函数
 	Handler EventHandler
 
@@ -66,8 +80,42 @@ type Subscriber struct {
 	// Chan 订阅者专用通道（用于异步交付）
 	Chan chan Event
 
+	// Policy 缓冲区满时的背压策略
+	Policy BackpressurePolicy
+
+	// BlockTimeout 阻塞策略的最长等待时间
+	BlockTimeout time.Duration
+
+	// CoalesceKey 合并策略的键函数
+	CoalesceKey func(Event) string
+
+	// Retry 处理失败的重试策略，零值表示不重试
+	Retry RetryPolicy
+
 	// mu 保护 Chan 的发送和关闭
 	mu sync.RWMutex
+
+	// closed 通道是否已关闭，由 mu 保护
+	closed bool
+
+	// topic 订阅主题
+	topic string
+
+	// named 是否通过 WithName 设置了名称（否则 Name 为随机的 ID）
+	named bool
+
+	// bufferSize 缓冲区大小，0 表示使用事件总线的默认值
+	bufferSize int
+
+	// backlog 合并策略的积压队列
+	backlog coalesceBacklog
+
+	// delivered、dropped、coalesced、retried、deadLettered 交付统计
+	delivered    atomic.Uint64
+	dropped      atomic.Uint64
+	coalesced    atomic.Uint64
+	retried      atomic.Uint64
+	deadLettered atomic.Uint64
 }
 
 /**
@@ -76,9 +124,12 @@ type Subscriber struct {
  * 核心的发布-订阅系统实现
  */
 type EventBus struct {
-	// subscribers 订阅者映射：事件类型 -> 订阅者列表
+	// subscribers 订阅者映射：订阅主题 -> 订阅者列表
 	subscribers map[string][]*Subscriber
 
+	// patterns 模式订阅的前缀树，与 subscribers 一起由 mutex 保护
+	patterns *topicTrie
+
 	// mutex 保护 subscribers 的读写锁
 	mutex sync.RWMutex
 
@@ -99,6 +150,15 @@ type EventBus struct {
 
 	// asyncBufferSize 异步事件缓冲区大小
 	asyncBufferSize int
+
+	// deadLetters 死信存储（可选）
+	deadLetters DeadLetterStore
+
+	// responders 请求主题 -> 应答者订阅 ID，由 mutex 保护
+	responders map[string]string
+
+	// observer 观察者（可选），用于采集指标
+	observer Observer
 }
 
 /**
@@ -113,6 +173,8 @@ type EventBus struct {
 func NewEventBus(opts ...Option) *EventBus {
 	bus := &EventBus{
 		subscribers:     make(map[string][]*Subscriber),
+		patterns:        newTopicTrie(),
+		responders:      make(map[string]string),
 		stopChan:        make(chan struct{}),
 		middleware:      make([]Middleware, 0),
 		asyncEnabled:    true,
@@ -154,7 +216,8 @@ func WithAsyncDisabled() Option {
  * Subscribe 订阅事件
  *
  * Parameters:
- *   - eventType: 事件类型，使用 "*" 订阅所有事件
+ *   - eventType: 事件类型，使用 "*" 订阅所有事件；
+ *     支持分层通配符和多选，如 hotkey.*、monitor.**、clipboard|keyboard
  *   - handler: 事件处理函数
  *
  * Returns:
@@ -179,31 +242,42 @@ func (bus *EventBus) SubscribeWithFilter(
 	eventType string,
 	handler EventHandler,
 	filter EventFilter,
+) string {
+	return bus.SubscribeWithOptions(eventType, handler, WithFilter(filter))
+}
+
+/**
+ * SubscribeWithOptions 带选项订阅事件
+ *
+ * 可以为每个订阅者单独设置过滤器、缓冲区大小和背压策略，
+ * 默认策略为缓冲区满时丢弃新事件
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *   - handler: 事件处理函数
+ *   - opts: 订阅选项
+ *
+ * Returns:
+ *   - string: 订阅者 ID
+ *
+ * Example:
+ *   bus.SubscribeWithOptions("*", forward, WithDropOldest(), WithBufferSize(100))
+ */
+func (bus *EventBus) SubscribeWithOptions(
+	eventType string,
+	handler EventHandler,
+	opts ...SubscribeOption,
 ) string {
 	subscriber := &Subscriber{
 		ID:      generateSubscriberID(),
 		Handler: handler,
-		Filter:  filter,
-		Once:    false,
-		Chan:    make(chan Event, bus.asyncBufferSize),
+		Policy:  BackpressureDropNewest,
 	}
-
-	bus.mutex.Lock()
-	defer bus.mutex.Unlock()
-
-	bus.subscribers[eventType] = append(bus.subscribers[eventType], subscriber)
-
-	logger.Debug("订阅事件",
-		zap.String("event_type", eventType),
-		zap.String("subscriber_id", subscriber.ID),
-	)
-
-	// 启动异步处理
-	if bus.asyncEnabled {
-		bus.wg.Add(1)
-		go bus.processSubscriber(subscriber)
+	for _, opt := range opts {
+		opt(subscriber)
 	}
 
+	bus.addSubscriber(eventType, subscriber)
 	return subscriber.ID
 }
 
@@ -223,22 +297,52 @@ func (bus *EventBus) SubscribeOnce(eventType string, handler EventHandler) strin
 	subscriber := &Subscriber{
 		ID:      generateSubscriberID(),
 		Handler: handler,
-		Filter:  nil,
 		Once:    true,
-		Chan:    make(chan Event, bus.asyncBufferSize),
+		Policy:  BackpressureDropNewest,
+	}
+
+	bus.addSubscriber(eventType, subscriber)
+	return subscriber.ID
+}
+
+/**
+ * addSubscriber 注册订阅者并启动异步处理
+ *
+ * Parameters:
+ *   - eventType: 订阅主题
+ *   - subscriber: 订阅者
+ */
+func (bus *EventBus) addSubscriber(eventType string, subscriber *Subscriber) {
+	bufferSize := subscriber.bufferSize
+	if bufferSize <= 0 {
+		bufferSize = bus.asyncBufferSize
+	}
+	subscriber.Chan = make(chan Event, bufferSize)
+	subscriber.topic = eventType
+	subscriber.named = subscriber.Name != ""
+	if subscriber.Name == "" {
+		subscriber.Name = subscriber.ID
 	}
 
 	bus.mutex.Lock()
 	defer bus.mutex.Unlock()
 
 	bus.subscribers[eventType] = append(bus.subscribers[eventType], subscriber)
+	if IsTopicPattern(eventType) {
+		bus.patterns.insert(eventType, subscriber)
+	}
 
+	logger.Debug("订阅事件",
+		zap.String("event_type", eventType),
+		zap.String("subscriber_id", subscriber.ID),
+		zap.String("policy", string(subscriber.Policy)),
+	)
+
+	// 启动异步处理
 	if bus.asyncEnabled {
 		bus.wg.Add(1)
 		go bus.processSubscriber(subscriber)
 	}
-
-	return subscriber.ID
 }
 
 /**
@@ -257,6 +361,12 @@ func (bus *EventBus) Unsubscribe(subscriberID string) {
 			if sub.ID == subscriberID {
 				// 从列表中移除
 				bus.subscribers[eventType] = append(subscribers[:i], subscribers[i+1:]...)
+				if IsTopicPattern(eventType) {
+					bus.patterns.remove(eventType, subscriberID)
+				}
+				if bus.responders[eventType] == subscriberID {
+					delete(bus.responders, eventType)
+				}
 
 				logger.Debug("取消订阅",
 					zap.String("event_type", eventType),
@@ -265,6 +375,7 @@ func (bus *EventBus) Unsubscribe(subscriberID string) {
 
 				// 关闭通道（加锁保护）
 				sub.mu.Lock()
+				sub.closed = true
 				close(sub.Chan)
 				sub.mu.Unlock()
 
@@ -279,7 +390,10 @@ func (bus *EventBus) Unsubscribe(subscriberID string) {
 /**
  * Publish 同步发布事件
  *
- * 会等待所有订阅者处理完成
+ * 会等待所有订阅者处理完成。
+ * 通过 SubscribeCaused 订阅的处理函数（或使用 EventBus.CausedBy）发布后续事件时
+ * 记录 CausationID 和 CorrelationID；
+ * 没有因果关系的事件是新因果链的根
  *
  * Parameters:
  *   - eventType: 事件类型
@@ -296,6 +410,13 @@ func (bus *EventBus) Publish(eventType string, event Event) error {
 		return fmt.Errorf("event bus is stopped")
 	}
 
+	// 补齐关联 ID
+	stampCorrelation(&event)
+
+	if bus.observer != nil {
+		bus.observer.ObservePublish(eventType, event)
+	}
+
 	logger.Debug("发布事件",
 		zap.String("event_type", eventType),
 		zap.String("event_id", event.ID),
@@ -323,18 +444,8 @@ func (bus *EventBus) Publish(eventType string, event Event) error {
 
 		subscriberCount++
 
-		// 异步发送（加锁保护）
-		subscriber.mu.RLock()
-		select {
-		case subscriber.Chan <- event:
-		default:
-			// 缓冲区满，丢弃事件
-			logger.Warn("事件缓冲区满，丢弃事件",
-				zap.String("subscriber_id", subscriber.ID),
-				zap.String("event_type", eventType),
-			)
-		}
-		subscriber.mu.RUnlock()
+		// 按订阅者的背压策略发送
+		bus.enqueue(subscriber, event)
 	}
 
 	logger.Debug("事件已发送",
@@ -437,13 +548,11 @@ func (bus *EventBus) processSubscriber(subscriber *Subscriber) {
 			}
 
 			// 处理事件
-			handler := bus.applyMiddleware(subscriber.Handler)
-			if err := handler(event); err != nil {
-				logger.Error("事件处理错误",
-					zap.String("subscriber_id", subscriber.ID),
-					zap.String("event_type", string(event.Type)),
-					zap.Error(err),
-				)
+			bus.handleEvent(subscriber, event)
+
+			// 缓冲区清空后交付合并策略积压的事件
+			for _, backlogged := range subscriber.takeBacklog() {
+				bus.handleEvent(subscriber, backlogged)
 			}
 
 			// 如果是一次性订阅，处理后取消
@@ -465,7 +574,7 @@ func (bus *EventBus) processSubscriber(subscriber *Subscriber) {
 /**
  * getSubscribers 获取事件类型的所有订阅者
  *
- * 包括通配符订阅者
+ * 包括精确订阅者、"*" 订阅者和前缀树中匹配的模式订阅者
  *
  * Parameters:
  *   - eventType: 事件类型
@@ -476,8 +585,8 @@ func (bus *EventBus) processSubscriber(subscriber *Subscriber) {
 func (bus *EventBus) getSubscribers(eventType string) []*Subscriber {
 	subscribers := make([]*Subscriber, 0)
 
-	// 添加特定类型的订阅者
-	if subs, ok := bus.subscribers[eventType]; ok {
+	// 添加特定类型的订阅者（模式主题的订阅者只通过前缀树匹配，避免重复）
+	if subs, ok := bus.subscribers[eventType]; ok && !IsTopicPattern(eventType) {
 		subscribers = append(subscribers, subs...)
 	}
 
@@ -486,6 +595,9 @@ func (bus *EventBus) getSubscribers(eventType string) []*Subscriber {
 		subscribers = append(subscribers, wildcardSubs...)
 	}
 
+	// 添加模式订阅者
+	subscribers = append(subscribers, bus.patterns.match(eventType)...)
+
 	return subscribers
 }
 
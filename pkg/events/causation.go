@@ -0,0 +1,140 @@
+package events
+
+/**
+ * CausedBy 把事件标记为由 parent 引起
+ *
+ * CausationID 指向直接原因，CorrelationID 沿用整条因果链的根事件 ID
+ *
+ * Parameters:
+ *   - parent: 引起本事件的事件
+ *
+ * Returns:
+ *   - *Event: 返回自身，支持链式调用
+ */
+func (e *Event) CausedBy(parent Event) *Event {
+	e.CausationID = parent.ID
+	e.CorrelationID = parent.CorrelationID
+	if e.CorrelationID == "" {
+		e.CorrelationID = parent.ID
+	}
+	return e
+}
+
+/**
+ * IsRoot 判断事件是否是因果链的根事件
+ *
+ * Returns:
+ *   - bool: 没有 CausationID 时为 true
+ */
+func (e *Event) IsRoot() bool {
+	return e.CausationID == ""
+}
+
+/**
+ * CausedPublisher 发布由同一个事件引起的事件
+ *
+ * 处理函数用它代替 EventBus 发布事件，发布的事件记录为由正在处理的事件引起。
+ * 可以传给处理函数启动的协程，因果关系不依赖发布所在的协程
+ */
+type CausedPublisher struct {
+	bus    *EventBus
+	parent Event
+}
+
+/**
+ * CausedBy 获取发布由 parent 引起的事件的发布者
+ *
+ * 用法：
+ *
+ *	bus.Subscribe("keyboard", func(event Event) error {
+ *		return bus.CausedBy(event).Publish("hotkey", *NewEvent("hotkey", nil))
+ *	})
+ *
+ * Parameters:
+ *   - parent: 引起后续事件的事件，通常是处理函数正在处理的事件
+ *
+ * Returns:
+ *   - *CausedPublisher: 发布者
+ */
+func (bus *EventBus) CausedBy(parent Event) *CausedPublisher {
+	return &CausedPublisher{bus: bus, parent: parent}
+}
+
+/**
+ * Publish 同步发布由 parent 引起的事件
+ *
+ * 已设置 CausationID 的事件保持不变
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *   - event: 事件对象
+ *
+ * Returns:
+ *   - error: 发布过程中的错误
+ */
+func (p *CausedPublisher) Publish(eventType string, event Event) error {
+	p.stamp(&event)
+	return p.bus.Publish(eventType, event)
+}
+
+/**
+ * PublishAsync 异步发布由 parent 引起的事件
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *   - event: 事件对象
+ */
+func (p *CausedPublisher) PublishAsync(eventType string, event Event) {
+	p.stamp(&event)
+	p.bus.PublishAsync(eventType, event)
+}
+
+/**
+ * CausalHandler 带因果发布者的事件处理函数
+ *
+ * publisher 发布的事件自动记录为由 event 引起
+ */
+type CausalHandler func(event Event, publisher *CausedPublisher) error
+
+/**
+ * SubscribeCaused 订阅事件，处理函数自动获得由当前事件引起的发布者
+ *
+ * 处理函数在处理事件时发布后续事件，应使用 SubscribeCaused 代替 SubscribeWithOptions，
+ * 不需要手动调用 CausedBy
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *   - handler: 带因果发布者的事件处理函数
+ *   - opts: 订阅选项
+ *
+ * Returns:
+ *   - string: 订阅者 ID
+ */
+func (bus *EventBus) SubscribeCaused(eventType string, handler CausalHandler, opts ...SubscribeOption) string {
+	return bus.SubscribeWithOptions(eventType, func(event Event) error {
+		return handler(event, bus.CausedBy(event))
+	}, opts...)
+}
+
+/**
+ * stamp 为没有因果关系的事件设置 parent 为原因
+ */
+func (p *CausedPublisher) stamp(event *Event) {
+	if event.CausationID == "" {
+		event.CausedBy(p.parent)
+	}
+}
+
+/**
+ * stampCorrelation 为即将发布的事件补齐 CorrelationID
+ *
+ * 没有设置 CorrelationID 的事件是新因果链的根，CorrelationID 为自身 ID
+ *
+ * Parameters:
+ *   - event: 即将发布的事件
+ */
+func stampCorrelation(event *Event) {
+	if event.CorrelationID == "" {
+		event.CorrelationID = event.ID
+	}
+}
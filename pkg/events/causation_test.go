@@ -0,0 +1,177 @@
+package events
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// eventRecorder 记录收到的事件
+type eventRecorder struct {
+	mu     sync.Mutex
+	events map[EventType]Event
+}
+
+func newEventRecorder() *eventRecorder {
+	return &eventRecorder{events: make(map[EventType]Event)}
+}
+
+func (r *eventRecorder) handle(event Event) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events[event.Type] = event
+	return nil
+}
+
+func (r *eventRecorder) get(eventType EventType) (Event, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	event, ok := r.events[eventType]
+	return event, ok
+}
+
+// TestCausation_PropagatesFromHandler 测试处理函数通过 CausedBy 发布的事件继承因果关系
+func TestCausation_PropagatesFromHandler(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	// keyboard -> hotkey -> ai（在处理函数启动的协程中异步发布）
+	bus.Subscribe("keyboard", func(event Event) error {
+		return bus.CausedBy(event).Publish("hotkey", *NewEvent("hotkey", nil))
+	})
+	bus.Subscribe("hotkey", func(event Event) error {
+		publisher := bus.CausedBy(event)
+		go publisher.PublishAsync("ai", *NewEvent("ai", nil))
+		return nil
+	})
+
+	recorder := newEventRecorder()
+	bus.Subscribe("*", recorder.handle)
+
+	root := NewEvent("keyboard", nil)
+	require.NoError(t, bus.Publish("keyboard", *root))
+
+	require.Eventually(t, func() bool {
+		_, ok := recorder.get("ai")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	keyboard, _ := recorder.get("keyboard")
+	hotkey, _ := recorder.get("hotkey")
+	ai, _ := recorder.get("ai")
+
+	assert.True(t, keyboard.IsRoot())
+	assert.Equal(t, root.ID, keyboard.CorrelationID)
+
+	assert.Equal(t, root.ID, hotkey.CausationID)
+	assert.Equal(t, root.ID, hotkey.CorrelationID)
+
+	assert.Equal(t, hotkey.ID, ai.CausationID)
+	assert.Equal(t, root.ID, ai.CorrelationID)
+}
+
+// TestCausation_SubscribeCaused 测试 SubscribeCaused 订阅的处理函数自动记录因果关系
+func TestCausation_SubscribeCaused(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	bus.SubscribeCaused("clipboard", func(event Event, publisher *CausedPublisher) error {
+		return publisher.Publish("pattern", *NewEvent("pattern", nil))
+	})
+	recorder := newEventRecorder()
+	bus.Subscribe("pattern", recorder.handle)
+
+	root := NewEvent("clipboard", nil)
+	require.NoError(t, bus.Publish("clipboard", *root))
+	require.Eventually(t, func() bool {
+		_, ok := recorder.get("pattern")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	pattern, _ := recorder.get("pattern")
+	assert.Equal(t, root.ID, pattern.CausationID)
+	assert.Equal(t, root.ID, pattern.CorrelationID)
+}
+
+// TestCausation_ExplicitCause 测试显式设置的因果关系不被覆盖
+func TestCausation_ExplicitCause(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	recorder := newEventRecorder()
+	bus.Subscribe("*", recorder.handle)
+
+	parent := NewEvent("clipboard", nil)
+	parent.CorrelationID = "session-1"
+	child := NewEvent("pattern", nil).CausedBy(*parent)
+
+	// 不在处理函数中发布，且已有 CausationID
+	require.NoError(t, bus.Publish("pattern", *child))
+
+	require.Eventually(t, func() bool {
+		_, ok := recorder.get("pattern")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	got, _ := recorder.get("pattern")
+	assert.Equal(t, parent.ID, got.CausationID)
+	assert.Equal(t, "session-1", got.CorrelationID)
+}
+
+// TestCausation_PlainPublishIsRoot 测试处理函数直接通过总线发布的事件是新因果链的根
+func TestCausation_PlainPublishIsRoot(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	bus.Subscribe("keyboard", func(event Event) error {
+		return bus.Publish("status", *NewEvent("status", nil))
+	})
+	recorder := newEventRecorder()
+	bus.Subscribe("status", recorder.handle)
+
+	require.NoError(t, bus.Publish("keyboard", *NewEvent("keyboard", nil)))
+	require.Eventually(t, func() bool {
+		_, ok := recorder.get("status")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	status, _ := recorder.get("status")
+	assert.True(t, status.IsRoot())
+	assert.Equal(t, status.ID, status.CorrelationID)
+}
+
+// TestCausation_UnrelatedPublisher 测试处理函数之外的发布者不受正在处理的事件影响
+func TestCausation_UnrelatedPublisher(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	started := make(chan struct{})
+	release := make(chan struct{})
+	bus.Subscribe("slow", func(Event) error {
+		close(started)
+		<-release
+		return nil
+	})
+
+	recorder := newEventRecorder()
+	bus.Subscribe("other", recorder.handle)
+
+	require.NoError(t, bus.Publish("slow", *NewEvent("slow", nil)))
+	<-started
+
+	other := NewEvent("other", nil)
+	require.NoError(t, bus.Publish("other", *other))
+	close(release)
+
+	require.Eventually(t, func() bool {
+		_, ok := recorder.get("other")
+		return ok
+	}, time.Second, 5*time.Millisecond)
+
+	got, _ := recorder.get("other")
+	assert.True(t, got.IsRoot())
+	assert.Equal(t, other.ID, got.CorrelationID)
+}
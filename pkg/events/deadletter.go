@@ -0,0 +1,232 @@
+package events
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * DeadLetter 死信记录
+ *
+ * 重试耗尽仍处理失败的事件
+ */
+type DeadLetter struct {
+	// ID 死信唯一标识
+	ID string `json:"id"`
+
+	// Subscriber 订阅者名称（未设置名称时为订阅者 ID）
+	Subscriber string `json:"subscriber"`
+
+	// Topic 订阅主题
+	Topic string `json:"topic"`
+
+	// Event 处理失败的事件
+	Event Event `json:"event"`
+
+	// Error 最后一次处理的错误信息
+	Error string `json:"error"`
+
+	// Attempts 尝试次数
+	Attempts int `json:"attempts"`
+
+	// FailedAt 进入死信的时间
+	FailedAt time.Time `json:"failed_at"`
+}
+
+/**
+ * DeadLetterStore 死信存储接口
+ *
+ * storage.SQLiteDeadLetterStore 提供持久化实现
+ */
+type DeadLetterStore interface {
+	// Save 保存死信
+	Save(letter DeadLetter) error
+
+	// List 按进入时间倒序列出死信，limit 小于等于 0 表示不限
+	List(limit int) ([]DeadLetter, error)
+
+	// Get 获取单条死信
+	Get(id string) (*DeadLetter, error)
+
+	// Delete 删除单条死信
+	Delete(id string) error
+
+	// Purge 删除 before 之前的死信，零值表示全部删除，返回删除数量
+	Purge(before time.Time) (int64, error)
+}
+
+/**
+ * WithDeadLetterStore 设置死信存储
+ *
+ * 保存有名称且配置了重试的订阅者重试耗尽的事件，未设置时只记录错误日志
+ *
+ * Parameters:
+ *   - store: 死信存储
+ */
+func WithDeadLetterStore(store DeadLetterStore) Option {
+	return func(bus *EventBus) {
+		bus.deadLetters = store
+	}
+}
+
+/**
+ * deadLetter 记录重试耗尽的事件
+ *
+ * 只有设置了名称（WithName）和重试策略（WithRetry）的订阅者进入死信：
+ * 死信按名称重新投递，匿名订阅者重启后无法对应；未配置重试的订阅者失败时只记录日志
+ *
+ * Parameters:
+ *   - subscriber: 订阅者
+ *   - event: 处理失败的事件
+ *   - err: 最后一次错误
+ *   - attempts: 尝试次数
+ */
+func (bus *EventBus) deadLetter(subscriber *Subscriber, event Event, err error, attempts int) {
+	if !subscriber.named || subscriber.Retry.MaxAttempts <= 1 {
+		logger.Error("事件处理错误",
+			zap.String("subscriber_id", subscriber.ID),
+			zap.String("event_type", string(event.Type)),
+			zap.Error(err),
+		)
+		return
+	}
+
+	subscriber.deadLettered.Add(1)
+
+	if bus.deadLetters == nil {
+		logger.Error("事件处理错误",
+			zap.String("subscriber_id", subscriber.ID),
+			zap.String("event_type", string(event.Type)),
+			zap.Int("attempts", attempts),
+			zap.Error(err),
+		)
+		return
+	}
+
+	letter := DeadLetter{
+		ID:         generateEventID(),
+		Subscriber: subscriber.Name,
+		Topic:      subscriber.topic,
+		Event:      event,
+		Error:      err.Error(),
+		Attempts:   attempts,
+		FailedAt:   time.Now(),
+	}
+	if saveErr := bus.deadLetters.Save(letter); saveErr != nil {
+		logger.Error("保存死信失败",
+			zap.String("subscriber_id", subscriber.ID),
+			zap.String("event_id", event.ID),
+			zap.Error(saveErr),
+		)
+		return
+	}
+
+	logger.Warn("事件处理失败，已进入死信",
+		zap.String("subscriber", subscriber.Name),
+		zap.String("event_id", event.ID),
+		zap.String("dead_letter_id", letter.ID),
+		zap.Int("attempts", attempts),
+		zap.Error(err),
+	)
+}
+
+/**
+ * ListDeadLetters 列出死信
+ *
+ * Parameters:
+ *   - limit: 数量限制，小于等于 0 表示不限
+ *
+ * Returns:
+ *   - []DeadLetter: 按进入时间倒序排列的死信
+ *   - error: 未配置死信存储或查询失败时返回错误
+ */
+func (bus *EventBus) ListDeadLetters(limit int) ([]DeadLetter, error) {
+	if bus.deadLetters == nil {
+		return nil, fmt.Errorf("dead letter store not configured")
+	}
+	return bus.deadLetters.List(limit)
+}
+
+/**
+ * GetDeadLetter 查看单条死信
+ *
+ * Parameters:
+ *   - id: 死信 ID
+ *
+ * Returns:
+ *   - *DeadLetter: 死信记录
+ *   - error: 未配置死信存储或死信不存在时返回错误
+ */
+func (bus *EventBus) GetDeadLetter(id string) (*DeadLetter, error) {
+	if bus.deadLetters == nil {
+		return nil, fmt.Errorf("dead letter store not configured")
+	}
+	return bus.deadLetters.Get(id)
+}
+
+/**
+ * RequeueDeadLetter 把死信重新投递给原订阅者
+ *
+ * 按名称查找订阅者（同名订阅者都会收到），投递成功后删除死信
+ *
+ * Parameters:
+ *   - id: 死信 ID
+ *
+ * Returns:
+ *   - error: 死信不存在、订阅者不存在或投递失败时返回错误
+ */
+func (bus *EventBus) RequeueDeadLetter(id string) error {
+	letter, err := bus.GetDeadLetter(id)
+	if err != nil {
+		return err
+	}
+
+	bus.mutex.RLock()
+	var targets []*Subscriber
+	for _, subscribers := range bus.subscribers {
+		for _, sub := range subscribers {
+			if sub.Name == letter.Subscriber {
+				targets = append(targets, sub)
+			}
+		}
+	}
+	bus.mutex.RUnlock()
+
+	if len(targets) == 0 {
+		return fmt.Errorf("subscriber not found: %s", letter.Subscriber)
+	}
+
+	for _, sub := range targets {
+		if !bus.enqueue(sub, letter.Event) {
+			return fmt.Errorf("requeue dead letter %s: subscriber %s buffer full", id, sub.ID)
+		}
+	}
+
+	logger.Info("死信已重新投递",
+		zap.String("dead_letter_id", id),
+		zap.String("subscriber", letter.Subscriber),
+		zap.Int("targets", len(targets)),
+	)
+
+	return bus.deadLetters.Delete(id)
+}
+
+/**
+ * PurgeDeadLetters 清理死信
+ *
+ * Parameters:
+ *   - before: 删除此时间之前的死信，零值表示全部删除
+ *
+ * Returns:
+ *   - int64: 删除数量
+ *   - error: 未配置死信存储或删除失败时返回错误
+ */
+func (bus *EventBus) PurgeDeadLetters(before time.Time) (int64, error) {
+	if bus.deadLetters == nil {
+		return 0, fmt.Errorf("dead letter store not configured")
+	}
+	return bus.deadLetters.Purge(before)
+}
@@ -49,6 +49,9 @@ type Event struct {
 	// Type 事件类型
 	Type EventType `json:"type"`
 
+	// SchemaVersion 事件数据的结构版本，见 SchemaRegistry
+	SchemaVersion int `json:"schema_version,omitempty"`
+
 	// Timestamp 事件发生时间
 	Timestamp time.Time `json:"timestamp"`
 
@@ -60,6 +63,12 @@ type Event struct {
 
 	// Context 事件上下文信息（捕获事件时的环境）
 	Context *EventContext `json:"context,omitempty"`
+
+	// CorrelationID 因果链的根事件 ID，同一条链上的事件相同
+	CorrelationID string `json:"correlation_id,omitempty"`
+
+	// CausationID 直接引起本事件的事件 ID，根事件为空
+	CausationID string `json:"causation_id,omitempty"`
 }
 
 /**
@@ -96,11 +105,12 @@ type EventContext struct {
  */
 func NewEvent(eventType EventType, data map[string]interface{}) *Event {
 	return &Event{
-		ID:        generateEventID(),
-		Type:      eventType,
-		Timestamp: time.Now(),
-		Data:      data,
-		Metadata:  make(map[string]string),
+		ID:            generateEventID(),
+		Type:          eventType,
+		SchemaVersion: CurrentSchemaVersion(eventType),
+		Timestamp:     time.Now(),
+		Data:          data,
+		Metadata:      make(map[string]string),
 	}
 }
 
@@ -136,6 +146,29 @@ func (e *Event) WithMetadata(key, value string) *Event {
 	return e
 }
 
+/**
+ * 回放相关的元数据键
+ *
+ * 从存储回放的事件会带上这些元数据，订阅者据此区分历史事件和实时事件
+ */
+const (
+	// MetadataReplay 回放标记，值为 "true"
+	MetadataReplay = "replay"
+
+	// MetadataReplayCursor 回放事件的存储游标，可用于断点续传
+	MetadataReplayCursor = "replay_cursor"
+)
+
+/**
+ * IsReplay 判断事件是否来自存储回放
+ *
+ * Returns:
+ *   - bool: true 表示是回放事件
+ */
+func (e *Event) IsReplay() bool {
+	return e.Metadata[MetadataReplay] == "true"
+}
+
 /**
  * generateEventID 生成事件唯一 ID
  *
@@ -160,8 +193,12 @@ type KeyboardEventData struct {
  * ClipboardEventData 剪贴板事件数据
  */
 type ClipboardEventData struct {
-	Content string `json:"content"` // 剪贴板内容
-	Length  int    `json:"length"`  // 内容长度
+	Content    string         `json:"content"`              // 剪贴板内容（已脱敏）
+	Type       string         `json:"type"`                 // 内容类型（UTI）
+	Size       int64          `json:"size"`                 // 内容大小（字节）
+	Length     int            `json:"length"`               // 内容长度（脱敏后）
+	Redacted   bool           `json:"redacted,omitempty"`   // 是否脱敏
+	Redactions map[string]int `json:"redactions,omitempty"` // 各类敏感信息的命中次数
 }
 
 /**
@@ -189,10 +226,15 @@ type AppSessionEventData struct {
  * FileSystemEventData 文件系统事件数据
  */
 type FileSystemEventData struct {
-	Path      string `json:"path"`       // 文件路径
-	Operation string `json:"operation"`  // 操作类型
-	IsCreate  bool   `json:"is_create"`  // 是否创建
-	IsWrite   bool   `json:"is_write"`   // 是否写入
-	IsRemove  bool   `json:"is_remove"`  // 是否删除
-	IsRename  bool   `json:"is_rename"`  // 是否重命名
+	Path       string `json:"path"`                  // 文件路径
+	Operation  string `json:"operation"`             // 操作类型
+	Name       string `json:"name"`                  // 文件名
+	Extension  string `json:"extension"`             // 扩展名
+	IsDir      bool   `json:"is_dir"`                // 是否目录
+	IsCreate   bool   `json:"is_create"`             // 是否创建
+	IsWrite    bool   `json:"is_write"`              // 是否写入
+	IsRemove   bool   `json:"is_remove"`             // 是否删除
+	IsRename   bool   `json:"is_rename"`             // 是否重命名
+	OldPath    string `json:"old_path,omitempty"`    // 重命名前的路径
+	WriteCount int    `json:"write_count,omitempty"` // 合并的写入次数
 }
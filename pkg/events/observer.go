@@ -0,0 +1,36 @@
+package events
+
+import "time"
+
+/**
+ * AnonymousSubscriber 未设置名称的订阅者上报给观察者时使用的名称
+ *
+ * 未命名订阅者的 ID 每次订阅都不同，直接作为指标标签会无限增长
+ */
+const AnonymousSubscriber = "anonymous"
+
+/**
+ * Observer 事件总线观察者
+ *
+ * 用于采集指标，方法在发布者和订阅者的协程中同步调用，必须快速返回。
+ * ObserveHandle 的 subscriber 为订阅者名称，未命名的订阅者为 AnonymousSubscriber
+ */
+type Observer interface {
+	// ObservePublish 事件被发布
+	ObservePublish(eventType string, event Event)
+
+	// ObserveHandle 订阅者处理完一次事件（每次重试各调用一次）
+	ObserveHandle(subscriber string, event Event, duration time.Duration, err error)
+}
+
+/**
+ * WithObserver 设置事件总线观察者
+ *
+ * Parameters:
+ *   - observer: 观察者
+ */
+func WithObserver(observer Observer) Option {
+	return func(bus *EventBus) {
+		bus.observer = observer
+	}
+}
@@ -0,0 +1,456 @@
+package events
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+/**
+ * 事件数据结构版本
+ *
+ * 事件数据的字段变化时递增对应事件类型的版本，并注册升级函数，
+ * 保证存储中的旧事件读出后与当前版本的结构一致
+ */
+const (
+	// LegacySchemaVersion 引入版本号之前写入的事件
+	LegacySchemaVersion = 0
+
+	// InitialSchemaVersion 所有事件类型的初始版本
+	InitialSchemaVersion = 1
+)
+
+/**
+ * Payload 类型化的事件数据
+ *
+ * 每种监控事件都有对应的结构体，Event.Data 是它的 map 形式
+ */
+type Payload interface {
+	// EventType 数据对应的事件类型
+	EventType() EventType
+
+	// ToData 转换为 Event.Data，字段类型与监控器发布的实时事件一致
+	ToData() map[string]interface{}
+}
+
+/**
+ * NewPayloadEvent 用类型化数据创建事件
+ *
+ * Parameters:
+ *   - payload: 事件数据
+ *
+ * Returns:
+ *   - *Event: 新创建的事件
+ */
+func NewPayloadEvent(payload Payload) *Event {
+	return NewEvent(payload.EventType(), payload.ToData())
+}
+
+/**
+ * DecodePayload 把事件数据解码为类型化结构
+ *
+ * 通过 JSON 中转，同时兼容实时事件（int、time.Time）和
+ * 从存储读出的事件（float64、字符串时间）
+ *
+ * Parameters:
+ *   - event: 事件对象
+ *   - payload: 解码目标，必须是指针
+ *
+ * Returns:
+ *   - error: 事件类型不匹配或字段类型错误时返回错误
+ */
+func DecodePayload(event Event, payload Payload) error {
+	if event.Type != payload.EventType() {
+		return fmt.Errorf("decode payload: event type %s does not match %s", event.Type, payload.EventType())
+	}
+
+	raw, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("decode %s payload: %w", event.Type, err)
+	}
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return fmt.Errorf("decode %s payload: %w", event.Type, err)
+	}
+	return nil
+}
+
+/**
+ * EventType 键盘事件类型
+ */
+func (KeyboardEventData) EventType() EventType { return EventTypeKeyboard }
+
+/**
+ * ToData 转换为键盘事件数据
+ */
+func (d KeyboardEventData) ToData() map[string]interface{} {
+	return map[string]interface{}{
+		"keycode":   d.KeyCode,
+		"modifiers": d.Modifiers,
+	}
+}
+
+/**
+ * EventType 剪贴板事件类型
+ */
+func (ClipboardEventData) EventType() EventType { return EventTypeClipboard }
+
+/**
+ * ToData 转换为剪贴板事件数据
+ */
+func (d ClipboardEventData) ToData() map[string]interface{} {
+	data := map[string]interface{}{
+		"content": d.Content,
+		"type":    d.Type,
+		"size":    d.Size,
+		"length":  d.Length,
+	}
+	if d.Redacted {
+		data["redacted"] = true
+		data["redactions"] = d.Redactions
+	}
+	return data
+}
+
+/**
+ * EventType 应用切换事件类型
+ */
+func (AppSwitchEventData) EventType() EventType { return EventTypeAppSwitch }
+
+/**
+ * ToData 转换为应用切换事件数据
+ */
+func (d AppSwitchEventData) ToData() map[string]interface{} {
+	return map[string]interface{}{
+		"from":      d.From,
+		"to":        d.To,
+		"bundle_id": d.BundleID,
+		"window":    d.Window,
+	}
+}
+
+/**
+ * EventType 应用会话事件类型
+ */
+func (AppSessionEventData) EventType() EventType { return EventTypeAppSession }
+
+/**
+ * ToData 转换为应用会话事件数据
+ */
+func (d AppSessionEventData) ToData() map[string]interface{} {
+	return map[string]interface{}{
+		"app_name":  d.AppName,
+		"bundle_id": d.BundleID,
+		"start":     d.Start,
+		"end":       d.End,
+		"duration":  d.Duration,
+	}
+}
+
+/**
+ * EventType 文件系统事件类型
+ */
+func (FileSystemEventData) EventType() EventType { return EventTypeFileSystem }
+
+/**
+ * ToData 转换为文件系统事件数据
+ */
+func (d FileSystemEventData) ToData() map[string]interface{} {
+	data := map[string]interface{}{
+		"path":      d.Path,
+		"operation": d.Operation,
+		"name":      d.Name,
+		"extension": d.Extension,
+		"is_dir":    d.IsDir,
+		"is_create": d.IsCreate,
+		"is_write":  d.IsWrite,
+		"is_remove": d.IsRemove,
+		"is_rename": d.IsRename,
+	}
+	if d.OldPath != "" {
+		data["old_path"] = d.OldPath
+	}
+	if d.WriteCount > 0 {
+		data["write_count"] = d.WriteCount
+	}
+	return data
+}
+
+/**
+ * Upcaster 把事件数据从某个版本升级到下一个版本
+ *
+ * 传入的 data 是副本，可以直接修改后返回
+ */
+type Upcaster func(data map[string]interface{}) (map[string]interface{}, error)
+
+/**
+ * SchemaRegistry 事件数据结构注册表
+ *
+ * 记录每种事件类型的当前版本、类型化结构和版本升级函数
+ */
+type SchemaRegistry struct {
+	mu        sync.RWMutex
+	payloads  map[EventType]func() Payload
+	upcasters map[EventType]map[int]Upcaster
+	versions  map[EventType]int
+}
+
+/**
+ * NewSchemaRegistry 创建空的结构注册表
+ *
+ * Returns:
+ *   - *SchemaRegistry: 注册表实例
+ */
+func NewSchemaRegistry() *SchemaRegistry {
+	return &SchemaRegistry{
+		payloads:  make(map[EventType]func() Payload),
+		upcasters: make(map[EventType]map[int]Upcaster),
+		versions:  make(map[EventType]int),
+	}
+}
+
+/**
+ * RegisterPayload 注册事件类型的类型化结构
+ *
+ * 升级后的事件会按此结构重新编码，把 JSON 读出的 float64 等类型还原为实时事件的类型
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *   - factory: 创建空结构指针的函数
+ */
+func (r *SchemaRegistry) RegisterPayload(eventType EventType, factory func() Payload) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.payloads[eventType] = factory
+}
+
+/**
+ * RegisterUpcaster 注册版本升级函数
+ *
+ * 事件类型的当前版本随之提升到 fromVersion+1（如果更高）
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *   - fromVersion: 升级前的版本
+ *   - upcaster: 升级到 fromVersion+1 的函数
+ */
+func (r *SchemaRegistry) RegisterUpcaster(eventType EventType, fromVersion int, upcaster Upcaster) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.upcasters[eventType] == nil {
+		r.upcasters[eventType] = make(map[int]Upcaster)
+	}
+	r.upcasters[eventType][fromVersion] = upcaster
+	if fromVersion+1 > r.versions[eventType] {
+		r.versions[eventType] = fromVersion + 1
+	}
+}
+
+/**
+ * CurrentVersion 获取事件类型的当前版本
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *
+ * Returns:
+ *   - int: 当前版本，未注册升级函数的类型为 InitialSchemaVersion
+ */
+func (r *SchemaRegistry) CurrentVersion(eventType EventType) int {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if version, ok := r.versions[eventType]; ok && version > InitialSchemaVersion {
+		return version
+	}
+	return InitialSchemaVersion
+}
+
+/**
+ * Upgrade 把事件数据升级到当前版本
+ *
+ * 依次执行升级函数，再按类型化结构重新编码。
+ * 从 LegacySchemaVersion 升级且没有注册升级函数时视为结构未变。
+ * 不修改原来的 Data map
+ *
+ * Parameters:
+ *   - event: 事件对象，成功后 Data 和 SchemaVersion 被替换
+ *
+ * Returns:
+ *   - error: 版本高于当前版本、缺少升级函数或解码失败时返回错误，事件保持不变
+ */
+func (r *SchemaRegistry) Upgrade(event *Event) error {
+	current := r.CurrentVersion(event.Type)
+	if event.SchemaVersion > current {
+		return fmt.Errorf("schema version %d of %s is newer than supported version %d", event.SchemaVersion, event.Type, current)
+	}
+
+	r.mu.RLock()
+	upcasters := r.upcasters[event.Type]
+	factory := r.payloads[event.Type]
+	r.mu.RUnlock()
+
+	data := make(map[string]interface{}, len(event.Data))
+	for key, value := range event.Data {
+		data[key] = value
+	}
+
+	for version := event.SchemaVersion; version < current; version++ {
+		upcaster, ok := upcasters[version]
+		if !ok {
+			if version == LegacySchemaVersion {
+				continue
+			}
+			return fmt.Errorf("no upcaster for %s schema version %d", event.Type, version)
+		}
+
+		upgraded, err := upcaster(data)
+		if err != nil {
+			return fmt.Errorf("upcast %s from version %d: %w", event.Type, version, err)
+		}
+		data = upgraded
+	}
+
+	if factory != nil {
+		payload := factory()
+		if err := DecodePayload(Event{Type: event.Type, Data: data}, payload); err != nil {
+			return err
+		}
+		// 保留结构之外的字段，只替换已知字段
+		for key, value := range payload.ToData() {
+			data[key] = value
+		}
+	}
+
+	event.Data = data
+	event.SchemaVersion = current
+	return nil
+}
+
+/**
+ * DefaultSchemaRegistry 默认结构注册表
+ *
+ * 注册了所有监控事件的类型化结构和旧数据升级函数，NewEvent 用它标记版本
+ */
+var DefaultSchemaRegistry = newDefaultSchemaRegistry()
+
+/**
+ * CurrentSchemaVersion 获取事件类型在默认注册表中的当前版本
+ *
+ * Parameters:
+ *   - eventType: 事件类型
+ *
+ * Returns:
+ *   - int: 当前版本
+ */
+func CurrentSchemaVersion(eventType EventType) int {
+	return DefaultSchemaRegistry.CurrentVersion(eventType)
+}
+
+/**
+ * UpgradeEvent 使用默认注册表把事件升级到当前版本
+ *
+ * Parameters:
+ *   - event: 事件对象
+ *
+ * Returns:
+ *   - error: 升级失败时返回错误
+ */
+func UpgradeEvent(event *Event) error {
+	return DefaultSchemaRegistry.Upgrade(event)
+}
+
+/**
+ * newDefaultSchemaRegistry 创建包含内置结构的注册表
+ */
+func newDefaultSchemaRegistry() *SchemaRegistry {
+	r := NewSchemaRegistry()
+
+	r.RegisterPayload(EventTypeKeyboard, func() Payload { return &KeyboardEventData{} })
+	r.RegisterPayload(EventTypeClipboard, func() Payload { return &ClipboardEventData{} })
+	r.RegisterPayload(EventTypeAppSwitch, func() Payload { return &AppSwitchEventData{} })
+	r.RegisterPayload(EventTypeAppSession, func() Payload { return &AppSessionEventData{} })
+	r.RegisterPayload(EventTypeFileSystem, func() Payload { return &FileSystemEventData{} })
+
+	r.RegisterUpcaster(EventTypeClipboard, LegacySchemaVersion, upcastLegacyClipboard)
+	r.RegisterUpcaster(EventTypeAppSession, LegacySchemaVersion, upcastLegacyAppSession)
+	r.RegisterUpcaster(EventTypeFileSystem, LegacySchemaVersion, upcastLegacyFileSystem)
+
+	return r
+}
+
+/**
+ * upcastLegacyClipboard 早期剪贴板事件只有 content 和 length，补齐类型和大小
+ */
+func upcastLegacyClipboard(data map[string]interface{}) (map[string]interface{}, error) {
+	content, _ := data["content"].(string)
+	if _, ok := data["type"]; !ok {
+		data["type"] = "public.utf8-plain-text"
+	}
+	if _, ok := data["length"]; !ok {
+		data["length"] = len(content)
+	}
+	if _, ok := data["size"]; !ok {
+		data["size"] = data["length"]
+	}
+	return data, nil
+}
+
+/**
+ * upcastLegacyAppSession 早期应用会话事件可能缺少 duration，按起止时间计算
+ */
+func upcastLegacyAppSession(data map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := data["duration"]; ok {
+		return data, nil
+	}
+
+	start, startErr := parseLegacyTime(data["start"])
+	end, endErr := parseLegacyTime(data["end"])
+	if startErr == nil && endErr == nil && end.After(start) {
+		data["duration"] = end.Sub(start).Seconds()
+	} else {
+		data["duration"] = float64(0)
+	}
+	return data, nil
+}
+
+/**
+ * upcastLegacyFileSystem 早期文件系统事件只有 is_* 标志，补齐操作类型和文件名
+ */
+func upcastLegacyFileSystem(data map[string]interface{}) (map[string]interface{}, error) {
+	if _, ok := data["operation"]; !ok {
+		operation := ""
+		for _, op := range []string{"create", "write", "remove", "rename"} {
+			if flag, _ := data["is_"+op].(bool); flag {
+				operation = op
+				break
+			}
+		}
+		data["operation"] = operation
+	}
+
+	if path, ok := data["path"].(string); ok {
+		if _, exists := data["name"]; !exists {
+			data["name"] = filepath.Base(path)
+		}
+		if _, exists := data["extension"]; !exists {
+			data["extension"] = filepath.Ext(path)
+		}
+	}
+	return data, nil
+}
+
+/**
+ * parseLegacyTime 解析实时事件（time.Time）或 JSON（RFC3339 字符串）中的时间
+ */
+func parseLegacyTime(value interface{}) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		return time.Parse(time.RFC3339Nano, v)
+	default:
+		return time.Time{}, fmt.Errorf("unsupported time value: %T", value)
+	}
+}
@@ -0,0 +1,125 @@
+package events
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestPayload_RoundTrip 测试类型化数据编码后经过 JSON 仍能解码
+func TestPayload_RoundTrip(t *testing.T) {
+	start := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+	payloads := []Payload{
+		KeyboardEventData{KeyCode: 122, Modifiers: 1 << 20},
+		ClipboardEventData{Content: "[EMAIL]", Type: "public.utf8-plain-text", Size: 16, Length: 16, Redacted: true, Redactions: map[string]int{"email": 1}},
+		AppSwitchEventData{From: "Finder", To: "Xcode", BundleID: "com.apple.dt.Xcode", Window: "main.go"},
+		AppSessionEventData{AppName: "Xcode", BundleID: "com.apple.dt.Xcode", Start: start, End: start.Add(time.Minute), Duration: 60},
+		FileSystemEventData{Path: "/tmp/a.go", Operation: "write", Name: "a.go", Extension: ".go", IsWrite: true, WriteCount: 3},
+	}
+
+	for _, payload := range payloads {
+		t.Run(string(payload.EventType()), func(t *testing.T) {
+			event := NewPayloadEvent(payload)
+			assert.Equal(t, payload.EventType(), event.Type)
+			assert.Equal(t, CurrentSchemaVersion(event.Type), event.SchemaVersion)
+
+			// 模拟存储：JSON 序列化后数字变为 float64、时间变为字符串
+			raw, err := json.Marshal(event)
+			require.NoError(t, err)
+			var stored Event
+			require.NoError(t, json.Unmarshal(raw, &stored))
+
+			for _, source := range []Event{*event, stored} {
+				decoded := DefaultSchemaRegistry.payloads[event.Type]()
+				require.NoError(t, DecodePayload(source, decoded))
+				assert.Equal(t, payload.ToData(), decoded.ToData())
+			}
+		})
+	}
+}
+
+// TestDecodePayload_TypeMismatch 测试事件类型不匹配时返回错误
+func TestDecodePayload_TypeMismatch(t *testing.T) {
+	event := NewEvent(EventTypeClipboard, map[string]interface{}{"content": "x"})
+
+	var data KeyboardEventData
+	assert.Error(t, DecodePayload(*event, &data))
+
+	event = NewEvent(EventTypeKeyboard, map[string]interface{}{"keycode": "a"})
+	assert.Error(t, DecodePayload(*event, &data))
+}
+
+// TestSchemaRegistry_UpgradeChain 测试按版本依次执行升级函数
+func TestSchemaRegistry_UpgradeChain(t *testing.T) {
+	registry := NewSchemaRegistry()
+	registry.RegisterUpcaster("custom", 1, func(data map[string]interface{}) (map[string]interface{}, error) {
+		data["name"] = data["title"]
+		delete(data, "title")
+		return data, nil
+	})
+	registry.RegisterUpcaster("custom", 2, func(data map[string]interface{}) (map[string]interface{}, error) {
+		data["tags"] = []string{}
+		return data, nil
+	})
+	assert.Equal(t, 3, registry.CurrentVersion("custom"))
+	assert.Equal(t, InitialSchemaVersion, registry.CurrentVersion("other"))
+
+	original := map[string]interface{}{"title": "report"}
+	event := &Event{Type: "custom", Data: original}
+
+	// 旧数据没有版本号，从 LegacySchemaVersion 开始升级
+	require.NoError(t, registry.Upgrade(event))
+	assert.Equal(t, 3, event.SchemaVersion)
+	assert.Equal(t, map[string]interface{}{"name": "report", "tags": []string{}}, event.Data)
+	assert.Equal(t, map[string]interface{}{"title": "report"}, original, "不修改原来的数据")
+
+	// 已是当前版本时不再升级
+	require.NoError(t, registry.Upgrade(event))
+	assert.Equal(t, "report", event.Data["name"])
+
+	// 来自更新版本的事件无法降级
+	newer := &Event{Type: "custom", SchemaVersion: 4, Data: map[string]interface{}{}}
+	assert.Error(t, registry.Upgrade(newer))
+	assert.Equal(t, 4, newer.SchemaVersion)
+}
+
+// TestSchemaRegistry_MissingUpcaster 测试升级链缺少中间版本时返回错误
+func TestSchemaRegistry_MissingUpcaster(t *testing.T) {
+	registry := NewSchemaRegistry()
+	registry.RegisterUpcaster("custom", 2, func(data map[string]interface{}) (map[string]interface{}, error) {
+		return data, nil
+	})
+
+	event := &Event{Type: "custom", SchemaVersion: 1, Data: map[string]interface{}{}}
+	assert.Error(t, registry.Upgrade(event))
+	assert.Equal(t, 1, event.SchemaVersion)
+}
+
+// TestUpgradeEvent_Legacy 测试内置的旧数据升级
+func TestUpgradeEvent_Legacy(t *testing.T) {
+	start := time.Date(2026, 1, 2, 9, 0, 0, 0, time.UTC)
+
+	session := &Event{Type: EventTypeAppSession, Data: map[string]interface{}{
+		"app_name": "Xcode",
+		"start":    start.Format(time.RFC3339Nano),
+		"end":      start.Add(90 * time.Second).Format(time.RFC3339Nano),
+	}}
+	require.NoError(t, UpgradeEvent(session))
+	assert.Equal(t, 90.0, session.Data["duration"])
+	assert.Equal(t, start, session.Data["start"])
+
+	file := &Event{Type: EventTypeFileSystem, Data: map[string]interface{}{
+		"path":      "/tmp/notes.md",
+		"is_rename": true,
+		"custom":    "kept",
+	}}
+	require.NoError(t, UpgradeEvent(file))
+	assert.Equal(t, "rename", file.Data["operation"])
+	assert.Equal(t, "notes.md", file.Data["name"])
+	assert.Equal(t, ".md", file.Data["extension"])
+	assert.Equal(t, "kept", file.Data["custom"], "结构之外的字段保留")
+	assert.Equal(t, InitialSchemaVersion, file.SchemaVersion)
+}
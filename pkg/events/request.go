@@ -0,0 +1,206 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * 请求-应答相关的元数据键
+ */
+const (
+	// MetadataReplyTo 请求事件的应答主题
+	MetadataReplyTo = "reply_to"
+
+	// MetadataReplyError 应答者处理失败时的错误信息
+	MetadataReplyError = "reply_error"
+)
+
+/**
+ * ReplyTopicPrefix 应答主题前缀
+ *
+ * 每个请求使用独立的应答主题 _reply.<uuid>
+ */
+const ReplyTopicPrefix = "_reply."
+
+/**
+ * ReplySubscriberName 请求方等待应答的临时订阅者名称
+ *
+ * 所有请求共用同一个名称，避免每次请求产生新的订阅者名称
+ */
+const ReplySubscriberName = "request_reply"
+
+/**
+ * DefaultRequestTimeout 请求的默认超时时间
+ *
+ * ctx 没有设置截止时间时使用
+ */
+const DefaultRequestTimeout = 5 * time.Second
+
+var (
+	// ErrNoResponder 请求主题没有应答者
+	ErrNoResponder = errors.New("no responder for topic")
+
+	// ErrResponderExists 请求主题已有应答者
+	ErrResponderExists = errors.New("responder already registered for topic")
+
+	// ErrRequestTimeout 等待应答超时
+	ErrRequestTimeout = errors.New("request timed out")
+)
+
+/**
+ * RequestHandler 请求处理函数
+ *
+ * 返回的事件作为应答发送给请求方，返回错误时请求方收到该错误
+ */
+type RequestHandler func(request Event) (Event, error)
+
+/**
+ * Respond 注册请求主题的应答者
+ *
+ * 每个主题只能有一个应答者，保证请求只有一个回复。
+ * 应答者只处理带应答主题的请求事件，同一主题上的普通事件会被忽略。
+ * 通过 Unsubscribe 注销应答者
+ *
+ * Parameters:
+ *   - topic: 请求主题，不能是主题模式
+ *   - handler: 请求处理函数
+ *
+ * Returns:
+ *   - string: 订阅者 ID
+ *   - error: 主题是模式或已有应答者时返回错误
+ */
+func (bus *EventBus) Respond(topic string, handler RequestHandler) (string, error) {
+	if topic == "" || topic == "*" || IsTopicPattern(topic) {
+		return "", fmt.Errorf("invalid request topic: %q", topic)
+	}
+
+	subscriber := &Subscriber{
+		ID:      generateSubscriberID(),
+		Handler: bus.replyHandler(topic, handler),
+		Policy:  BackpressureDropNewest,
+		Name:    "responder:" + topic,
+	}
+
+	bus.mutex.Lock()
+	if _, exists := bus.responders[topic]; exists {
+		bus.mutex.Unlock()
+		return "", fmt.Errorf("%w: %s", ErrResponderExists, topic)
+	}
+	bus.responders[topic] = subscriber.ID
+	bus.mutex.Unlock()
+
+	bus.addSubscriber(topic, subscriber)
+	return subscriber.ID, nil
+}
+
+/**
+ * Request 发送请求并等待应答
+ *
+ * 请求事件带上独立的应答主题后发布到 topic，由该主题唯一的应答者处理。
+ * 其他订阅者（如 "*"）照常收到请求事件
+ *
+ * Parameters:
+ *   - ctx: 上下文，用于取消和超时，没有截止时间时使用 DefaultRequestTimeout
+ *   - topic: 请求主题
+ *   - event: 请求事件
+ *
+ * Returns:
+ *   - Event: 应答事件
+ *   - error: 没有应答者、超时、取消或应答者处理失败时返回错误
+ */
+func (bus *EventBus) Request(ctx context.Context, topic string, event Event) (Event, error) {
+	bus.mutex.RLock()
+	_, ok := bus.responders[topic]
+	bus.mutex.RUnlock()
+	if !ok {
+		return Event{}, fmt.Errorf("%w: %s", ErrNoResponder, topic)
+	}
+
+	if _, hasDeadline := ctx.Deadline(); !hasDeadline {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, DefaultRequestTimeout)
+		defer cancel()
+	}
+
+	replyTopic := ReplyTopicPrefix + generateEventID()
+	replies := make(chan Event, 1)
+	replySub := bus.SubscribeWithOptions(replyTopic, func(reply Event) error {
+		select {
+		case replies <- reply:
+		default:
+		}
+		return nil
+	}, WithBufferSize(1), WithName(ReplySubscriberName))
+	defer bus.Unsubscribe(replySub)
+
+	// 复制元数据，避免修改调用方的 map
+	metadata := make(map[string]string, len(event.Metadata)+1)
+	for key, value := range event.Metadata {
+		metadata[key] = value
+	}
+	metadata[MetadataReplyTo] = replyTopic
+	event.Metadata = metadata
+
+	if err := bus.Publish(topic, event); err != nil {
+		return Event{}, err
+	}
+
+	select {
+	case reply := <-replies:
+		if message := reply.Metadata[MetadataReplyError]; message != "" {
+			return reply, fmt.Errorf("request %s failed: %s", topic, message)
+		}
+		return reply, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return Event{}, fmt.Errorf("%w: %s", ErrRequestTimeout, topic)
+		}
+		return Event{}, ctx.Err()
+	}
+}
+
+/**
+ * replyHandler 把请求处理函数包装为订阅处理函数
+ *
+ * Parameters:
+ *   - topic: 请求主题
+ *   - handler: 请求处理函数
+ *
+ * Returns:
+ *   - EventHandler: 处理请求并发布应答的处理函数
+ */
+func (bus *EventBus) replyHandler(topic string, handler RequestHandler) EventHandler {
+	return func(request Event) error {
+		replyTo := request.Metadata[MetadataReplyTo]
+		if replyTo == "" {
+			return nil
+		}
+
+		reply, err := handler(request)
+		if err != nil {
+			logger.Warn("请求处理失败",
+				zap.String("topic", topic),
+				zap.String("request_id", request.ID),
+				zap.Error(err),
+			)
+			reply = *NewEvent(EventType(replyTo), nil)
+			reply.WithMetadata(MetadataReplyError, err.Error())
+		}
+
+		if reply.ID == "" {
+			reply.ID = generateEventID()
+		}
+		if reply.Timestamp.IsZero() {
+			reply.Timestamp = time.Now()
+		}
+		reply.CausedBy(request)
+
+		return bus.Publish(replyTo, reply)
+	}
+}
@@ -0,0 +1,109 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequest_Reply 测试请求得到应答
+func TestRequest_Reply(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	_, err := bus.Respond("engine.status", func(request Event) (Event, error) {
+		return *NewEvent("engine.status.reply", map[string]interface{}{
+			"running": true,
+			"asked":   request.Data["from"],
+		}), nil
+	})
+	require.NoError(t, err)
+
+	request := NewEvent("engine.status", map[string]interface{}{"from": "hotkey"})
+	reply, err := bus.Request(context.Background(), "engine.status", *request)
+	require.NoError(t, err)
+	assert.Equal(t, true, reply.Data["running"])
+	assert.Equal(t, "hotkey", reply.Data["asked"])
+	assert.Equal(t, request.ID, reply.CausationID)
+	assert.Empty(t, request.Metadata[MetadataReplyTo], "不修改调用方的事件")
+
+	// 应答订阅在请求结束后取消
+	for _, stats := range bus.Stats() {
+		assert.NotContains(t, stats.Topic, ReplyTopicPrefix)
+	}
+}
+
+// TestRequest_SingleResponder 测试每个主题只能有一个应答者
+func TestRequest_SingleResponder(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	handler := func(Event) (Event, error) { return *NewEvent("reply", nil), nil }
+
+	id, err := bus.Respond("query", handler)
+	require.NoError(t, err)
+
+	_, err = bus.Respond("query", handler)
+	assert.ErrorIs(t, err, ErrResponderExists)
+
+	_, err = bus.Respond("query.*", handler)
+	assert.Error(t, err, "不能用主题模式注册应答者")
+
+	// 注销后可以重新注册
+	bus.Unsubscribe(id)
+	_, err = bus.Request(context.Background(), "query", *NewEvent("query", nil))
+	assert.ErrorIs(t, err, ErrNoResponder)
+
+	_, err = bus.Respond("query", handler)
+	assert.NoError(t, err)
+}
+
+// TestRequest_Timeout 测试应答超时
+func TestRequest_Timeout(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	release := make(chan struct{})
+	defer close(release)
+	_, err := bus.Respond("slow", func(Event) (Event, error) {
+		<-release
+		return *NewEvent("reply", nil), nil
+	})
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err = bus.Request(ctx, "slow", *NewEvent("slow", nil))
+	assert.ErrorIs(t, err, ErrRequestTimeout)
+	assert.Less(t, time.Since(start), time.Second)
+
+	// 主动取消返回 context.Canceled
+	ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	_, err = bus.Request(ctx, "slow", *NewEvent("slow", nil))
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+// TestRequest_HandlerError 测试应答者处理失败时请求方收到错误
+func TestRequest_HandlerError(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	_, err := bus.Respond("failing", func(Event) (Event, error) {
+		return Event{}, errors.New("engine not running")
+	})
+	require.NoError(t, err)
+
+	// 同一主题上的普通事件不会触发应答
+	require.NoError(t, bus.Publish("failing", *NewEvent("failing", nil)))
+
+	_, err = bus.Request(context.Background(), "failing", *NewEvent("failing", nil))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "engine not running")
+}
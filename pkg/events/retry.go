@@ -0,0 +1,171 @@
+package events
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/chenyang-zz/flowmind/internal/infrastructure/logger"
+	"go.uber.org/zap"
+)
+
+/**
+ * RetryPolicy 处理失败的重试策略
+ *
+ * 第 n 次重试前等待 InitialBackoff * Multiplier^(n-1)，不超过 MaxBackoff，
+ * 再叠加 ±Jitter 比例的随机抖动，避免多个订阅者同时重试
+ */
+type RetryPolicy struct {
+	// MaxAttempts 最大尝试次数（包括第一次），小于等于 1 表示不重试
+	MaxAttempts int
+
+	// InitialBackoff 第一次重试前的等待时间
+	InitialBackoff time.Duration
+
+	// MaxBackoff 等待时间上限
+	MaxBackoff time.Duration
+
+	// Multiplier 退避倍数，小于 1 时按 2 处理
+	Multiplier float64
+
+	// Jitter 随机抖动比例，取值 0-1
+	Jitter float64
+}
+
+/**
+ * DefaultRetryPolicy 默认重试策略
+ *
+ * 最多尝试 4 次，等待 100ms、200ms、400ms（±20% 抖动）
+ *
+ * Returns:
+ *   - RetryPolicy: 重试策略
+ */
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+		Jitter:         0.2,
+	}
+}
+
+/**
+ * Backoff 计算第 attempt 次失败后的等待时间
+ *
+ * Parameters:
+ *   - attempt: 已失败的次数，从 1 开始
+ *
+ * Returns:
+ *   - time.Duration: 等待时间
+ */
+func (p RetryPolicy) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+
+	multiplier := p.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	backoff := float64(p.InitialBackoff) * math.Pow(multiplier, float64(attempt-1))
+	if p.MaxBackoff > 0 && backoff > float64(p.MaxBackoff) {
+		backoff = float64(p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		jitter := math.Min(p.Jitter, 1)
+		backoff *= 1 + jitter*(2*rand.Float64()-1)
+	}
+
+	return time.Duration(backoff)
+}
+
+/**
+ * WithRetry 处理函数返回错误时按策略重试
+ *
+ * 重试在订阅者自己的处理协程中进行，期间后续事件排队等待，保证顺序。
+ * 同时设置了 WithName 时，重试耗尽后事件进入死信存储（见 WithDeadLetterStore）
+ *
+ * Parameters:
+ *   - policy: 重试策略
+ */
+func WithRetry(policy RetryPolicy) SubscribeOption {
+	return func(s *Subscriber) {
+		s.Retry = policy
+	}
+}
+
+/**
+ * WithName 设置订阅者名称
+ *
+ * 名称在重启后保持不变，死信记录按名称关联订阅者，
+ * 重新投递死信时按名称查找订阅者
+ *
+ * Parameters:
+ *   - name: 订阅者名称
+ */
+func WithName(name string) SubscribeOption {
+	return func(s *Subscriber) {
+		s.Name = name
+	}
+}
+
+/**
+ * handleEvent 调用订阅者的处理函数，失败时按重试策略重试
+ *
+ * Parameters:
+ *   - subscriber: 订阅者对象
+ *   - event: 事件对象
+ */
+func (bus *EventBus) handleEvent(subscriber *Subscriber, event Event) {
+	subscriber.delivered.Add(1)
+
+	handler := bus.applyMiddleware(subscriber.Handler)
+	for attempt := 1; ; attempt++ {
+		start := time.Now()
+		err := handler(event)
+		if bus.observer != nil {
+			bus.observer.ObserveHandle(subscriber.observedName(), event, time.Since(start), err)
+		}
+		if err == nil {
+			return
+		}
+
+		if attempt >= subscriber.Retry.MaxAttempts {
+			bus.deadLetter(subscriber, event, err, attempt)
+			return
+		}
+
+		backoff := subscriber.Retry.Backoff(attempt)
+		logger.Warn("事件处理失败，准备重试",
+			zap.String("subscriber_id", subscriber.ID),
+			zap.String("event_type", string(event.Type)),
+			zap.Int("attempt", attempt),
+			zap.Duration("backoff", backoff),
+			zap.Error(err),
+		)
+		subscriber.retried.Add(1)
+
+		// 总线停止时不再等待，直接进入死信，避免事件丢失
+		timer := time.NewTimer(backoff)
+		select {
+		case <-timer.C:
+		case <-bus.stopChan:
+			timer.Stop()
+			bus.deadLetter(subscriber, event, err, attempt)
+			return
+		}
+	}
+}
+
+/**
+ * observedName 上报给观察者的订阅者名称，未命名的订阅者统一为 AnonymousSubscriber
+ */
+func (s *Subscriber) observedName() string {
+	if !s.named {
+		return AnonymousSubscriber
+	}
+	return s.Name
+}
@@ -0,0 +1,224 @@
+package events
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryDeadLetterStore 内存死信存储
+type memoryDeadLetterStore struct {
+	mu      sync.Mutex
+	letters map[string]DeadLetter
+}
+
+func newMemoryDeadLetterStore() *memoryDeadLetterStore {
+	return &memoryDeadLetterStore{letters: make(map[string]DeadLetter)}
+}
+
+func (s *memoryDeadLetterStore) Save(letter DeadLetter) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.letters[letter.ID] = letter
+	return nil
+}
+
+func (s *memoryDeadLetterStore) List(limit int) ([]DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letters := make([]DeadLetter, 0, len(s.letters))
+	for _, letter := range s.letters {
+		letters = append(letters, letter)
+	}
+	sort.Slice(letters, func(i, j int) bool { return letters[i].FailedAt.After(letters[j].FailedAt) })
+	if limit > 0 && len(letters) > limit {
+		letters = letters[:limit]
+	}
+	return letters, nil
+}
+
+func (s *memoryDeadLetterStore) Get(id string) (*DeadLetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	letter, ok := s.letters[id]
+	if !ok {
+		return nil, fmt.Errorf("not found: %s", id)
+	}
+	return &letter, nil
+}
+
+func (s *memoryDeadLetterStore) Delete(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.letters, id)
+	return nil
+}
+
+func (s *memoryDeadLetterStore) Purge(before time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted int64
+	for id, letter := range s.letters {
+		if before.IsZero() || letter.FailedAt.Before(before) {
+			delete(s.letters, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// fastRetry 测试用的快速重试策略
+func fastRetry(attempts int) RetryPolicy {
+	return RetryPolicy{MaxAttempts: attempts, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+// TestRetryPolicy_Backoff 测试指数退避和抖动
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: 300 * time.Millisecond, Multiplier: 2}
+
+	assert.Equal(t, 100*time.Millisecond, policy.Backoff(1))
+	assert.Equal(t, 200*time.Millisecond, policy.Backoff(2))
+	assert.Equal(t, 300*time.Millisecond, policy.Backoff(3), "不超过上限")
+
+	policy.Jitter = 0.5
+	for i := 0; i < 100; i++ {
+		backoff := policy.Backoff(1)
+		assert.GreaterOrEqual(t, backoff, 50*time.Millisecond)
+		assert.LessOrEqual(t, backoff, 150*time.Millisecond)
+	}
+}
+
+// TestRetry_SucceedsAfterFailures 测试失败后重试成功
+func TestRetry_SucceedsAfterFailures(t *testing.T) {
+	store := newMemoryDeadLetterStore()
+	bus := NewEventBus(WithDeadLetterStore(store))
+	defer bus.Stop(time.Second)
+
+	var calls atomic.Int32
+	id := bus.SubscribeWithOptions("test", func(Event) error {
+		if calls.Add(1) < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	}, WithRetry(fastRetry(3)))
+
+	require.NoError(t, bus.Publish("test", *NewEvent("test", nil)))
+
+	require.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, 5*time.Millisecond)
+
+	stats, _ := bus.SubscriberStats(id)
+	assert.Equal(t, uint64(2), stats.Retried)
+	assert.Equal(t, uint64(0), stats.DeadLettered)
+
+	letters, err := bus.ListDeadLetters(0)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+}
+
+// TestRetry_DeadLetterAndRequeue 测试重试耗尽进入死信，并重新投递
+func TestRetry_DeadLetterAndRequeue(t *testing.T) {
+	store := newMemoryDeadLetterStore()
+	bus := NewEventBus(WithDeadLetterStore(store))
+	defer bus.Stop(time.Second)
+
+	var healthy atomic.Bool
+	var succeeded atomic.Int32
+	id := bus.SubscribeWithOptions("test", func(Event) error {
+		if !healthy.Load() {
+			return errors.New("storage unavailable")
+		}
+		succeeded.Add(1)
+		return nil
+	}, WithName("worker"), WithRetry(fastRetry(2)))
+
+	event := NewEvent("test", map[string]interface{}{"seq": 1})
+	require.NoError(t, bus.Publish("test", *event))
+
+	var letters []DeadLetter
+	require.Eventually(t, func() bool {
+		letters, _ = bus.ListDeadLetters(10)
+		return len(letters) == 1
+	}, time.Second, 5*time.Millisecond)
+
+	letter, err := bus.GetDeadLetter(letters[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, "worker", letter.Subscriber)
+	assert.Equal(t, "test", letter.Topic)
+	assert.Equal(t, event.ID, letter.Event.ID)
+	assert.Equal(t, 2, letter.Attempts)
+	assert.Equal(t, "storage unavailable", letter.Error)
+
+	stats, _ := bus.SubscriberStats(id)
+	assert.Equal(t, "worker", stats.Name)
+	assert.Equal(t, uint64(1), stats.DeadLettered)
+
+	// 恢复后重新投递
+	healthy.Store(true)
+	require.NoError(t, bus.RequeueDeadLetter(letter.ID))
+	require.Eventually(t, func() bool { return succeeded.Load() == 1 }, time.Second, 5*time.Millisecond)
+
+	letters, err = bus.ListDeadLetters(0)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+}
+
+// TestDeadLetter_OnlyNamedWithRetry 测试只有命名且配置了重试的订阅者进入死信
+func TestDeadLetter_OnlyNamedWithRetry(t *testing.T) {
+	store := newMemoryDeadLetterStore()
+	bus := NewEventBus(WithDeadLetterStore(store))
+	defer bus.Stop(time.Second)
+
+	var calls atomic.Int32
+	failing := func(Event) error {
+		calls.Add(1)
+		return errors.New("failed")
+	}
+	anonymous := bus.SubscribeWithOptions("test", failing, WithRetry(fastRetry(2)))
+	noRetry := bus.SubscribeWithOptions("test", failing, WithName("no-retry"))
+
+	require.NoError(t, bus.Publish("test", *NewEvent("test", nil)))
+	require.Eventually(t, func() bool { return calls.Load() == 3 }, time.Second, 5*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+
+	letters, err := bus.ListDeadLetters(0)
+	require.NoError(t, err)
+	assert.Empty(t, letters)
+	for _, id := range []string{anonymous, noRetry} {
+		stats, _ := bus.SubscriberStats(id)
+		assert.Equal(t, uint64(0), stats.DeadLettered)
+	}
+}
+
+// TestDeadLetter_RequeueUnknownSubscriber 测试订阅者不存在时不能重新投递
+func TestDeadLetter_RequeueUnknownSubscriber(t *testing.T) {
+	store := newMemoryDeadLetterStore()
+	bus := NewEventBus(WithDeadLetterStore(store))
+	defer bus.Stop(time.Second)
+
+	require.NoError(t, store.Save(DeadLetter{ID: "dl-1", Subscriber: "gone", Event: *NewEvent("test", nil), FailedAt: time.Now()}))
+
+	assert.Error(t, bus.RequeueDeadLetter("dl-1"))
+	_, err := bus.GetDeadLetter("dl-1")
+	assert.NoError(t, err, "投递失败时保留死信")
+
+	deleted, err := bus.PurgeDeadLetters(time.Time{})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), deleted)
+}
+
+// TestDeadLetter_NotConfigured 测试未配置死信存储
+func TestDeadLetter_NotConfigured(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(time.Second)
+
+	_, err := bus.ListDeadLetters(0)
+	assert.Error(t, err)
+	assert.Error(t, bus.RequeueDeadLetter("any"))
+}
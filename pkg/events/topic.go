@@ -0,0 +1,244 @@
+package events
+
+import "strings"
+
+/**
+ * 主题匹配语法
+ *
+ * 事件类型按 "." 分层，例如 hotkey.toggle_ai。订阅时可以使用：
+ * - "*"：单独使用时订阅所有事件；作为某一层时匹配恰好一层，例如 hotkey.*
+ * - "**"：匹配零层或多层，例如 monitor.** 匹配 monitor、monitor.status、monitor.a.b
+ * - "|"：多个主题任选其一，例如 clipboard|keyboard、clipboard|hotkey.*
+ *
+ * 不含这些符号的事件类型仍按精确匹配处理
+ */
+const (
+	// TopicSeparator 主题层级分隔符
+	TopicSeparator = "."
+
+	// TopicWildcardOne 匹配恰好一层
+	TopicWildcardOne = "*"
+
+	// TopicWildcardMulti 匹配零层或多层
+	TopicWildcardMulti = "**"
+
+	// TopicAlternation 多个主题任选其一
+	TopicAlternation = "|"
+)
+
+/**
+ * IsTopicPattern 判断订阅主题是否需要模式匹配
+ *
+ * 单独的 "*" 是历史上的全量订阅，按精确键处理，不算模式
+ *
+ * Parameters:
+ *   - topic: 订阅主题
+ *
+ * Returns:
+ *   - bool: true 表示包含通配符或多选
+ */
+func IsTopicPattern(topic string) bool {
+	if topic == TopicWildcardOne {
+		return false
+	}
+	return strings.Contains(topic, TopicWildcardOne) || strings.Contains(topic, TopicAlternation)
+}
+
+/**
+ * MatchTopic 判断事件类型是否匹配订阅主题
+ *
+ * Parameters:
+ *   - pattern: 订阅主题，支持 *、** 和 |
+ *   - topic: 事件类型
+ *
+ * Returns:
+ *   - bool: true 表示匹配
+ */
+func MatchTopic(pattern, topic string) bool {
+	if pattern == TopicWildcardOne {
+		return true
+	}
+	for _, alternative := range strings.Split(pattern, TopicAlternation) {
+		if matchSegments(splitTopic(alternative), splitTopic(topic)) {
+			return true
+		}
+	}
+	return false
+}
+
+/**
+ * matchSegments 逐层匹配
+ */
+func matchSegments(pattern, topic []string) bool {
+	if len(pattern) == 0 {
+		return len(topic) == 0
+	}
+
+	switch pattern[0] {
+	case TopicWildcardMulti:
+		for i := 0; i <= len(topic); i++ {
+			if matchSegments(pattern[1:], topic[i:]) {
+				return true
+			}
+		}
+		return false
+	case TopicWildcardOne:
+		return len(topic) > 0 && matchSegments(pattern[1:], topic[1:])
+	default:
+		return len(topic) > 0 && pattern[0] == topic[0] && matchSegments(pattern[1:], topic[1:])
+	}
+}
+
+/**
+ * splitTopic 按层级拆分主题
+ */
+func splitTopic(topic string) []string {
+	return strings.Split(strings.TrimSpace(topic), TopicSeparator)
+}
+
+/**
+ * topicNode 主题前缀树节点
+ */
+type topicNode struct {
+	// children 下一层节点：层级名称（或通配符）-> 节点
+	children map[string]*topicNode
+
+	// subscribers 在此节点结束的订阅者
+	subscribers []*Subscriber
+}
+
+/**
+ * topicTrie 模式订阅的前缀树
+ *
+ * 每个节点对应一层主题，通配符作为普通子节点存储。
+ * 匹配时只沿着与事件类型相关的分支走，复杂度与订阅数量无关，
+ * 只与主题层数和命中的通配符分支有关。
+ * 不是并发安全的，由 EventBus 的锁保护。
+ */
+type topicTrie struct {
+	root *topicNode
+}
+
+/**
+ * newTopicTrie 创建主题前缀树
+ */
+func newTopicTrie() *topicTrie {
+	return &topicTrie{root: &topicNode{}}
+}
+
+/**
+ * insert 插入模式订阅
+ *
+ * 模式中的每个多选分支都会单独插入
+ *
+ * Parameters:
+ *   - pattern: 订阅主题
+ *   - subscriber: 订阅者
+ */
+func (t *topicTrie) insert(pattern string, subscriber *Subscriber) {
+	for _, alternative := range strings.Split(pattern, TopicAlternation) {
+		node := t.root
+		for _, segment := range splitTopic(alternative) {
+			if node.children == nil {
+				node.children = make(map[string]*topicNode)
+			}
+			child, ok := node.children[segment]
+			if !ok {
+				child = &topicNode{}
+				node.children[segment] = child
+			}
+			node = child
+		}
+		node.subscribers = append(node.subscribers, subscriber)
+	}
+}
+
+/**
+ * remove 移除模式订阅，并清理空节点
+ *
+ * Parameters:
+ *   - pattern: 订阅时使用的主题
+ *   - subscriberID: 订阅者 ID
+ */
+func (t *topicTrie) remove(pattern string, subscriberID string) {
+	for _, alternative := range strings.Split(pattern, TopicAlternation) {
+		removeFromNode(t.root, splitTopic(alternative), subscriberID)
+	}
+}
+
+/**
+ * removeFromNode 递归移除订阅者
+ *
+ * Returns: bool - true 表示节点已为空，可以从父节点删除
+ */
+func removeFromNode(node *topicNode, segments []string, subscriberID string) bool {
+	if len(segments) == 0 {
+		for i, sub := range node.subscribers {
+			if sub.ID == subscriberID {
+				node.subscribers = append(node.subscribers[:i], node.subscribers[i+1:]...)
+				break
+			}
+		}
+	} else if child, ok := node.children[segments[0]]; ok {
+		if removeFromNode(child, segments[1:], subscriberID) {
+			delete(node.children, segments[0])
+		}
+	}
+
+	return len(node.subscribers) == 0 && len(node.children) == 0
+}
+
+/**
+ * match 查找匹配事件类型的订阅者
+ *
+ * 同一订阅者的多个分支同时命中时只返回一次
+ *
+ * Parameters:
+ *   - topic: 事件类型
+ *
+ * Returns:
+ *   - []*Subscriber: 匹配的订阅者
+ */
+func (t *topicTrie) match(topic string) []*Subscriber {
+	var matched []*Subscriber
+	matchNode(t.root, splitTopic(topic), &matched)
+
+	if len(matched) < 2 {
+		return matched
+	}
+
+	seen := make(map[*Subscriber]struct{}, len(matched))
+	unique := matched[:0]
+	for _, sub := range matched {
+		if _, ok := seen[sub]; ok {
+			continue
+		}
+		seen[sub] = struct{}{}
+		unique = append(unique, sub)
+	}
+	return unique
+}
+
+/**
+ * matchNode 递归匹配
+ */
+func matchNode(node *topicNode, segments []string, matched *[]*Subscriber) {
+	// ** 可以吞掉剩余的任意层（包括零层）
+	if multi, ok := node.children[TopicWildcardMulti]; ok {
+		for i := 0; i <= len(segments); i++ {
+			matchNode(multi, segments[i:], matched)
+		}
+	}
+
+	if len(segments) == 0 {
+		*matched = append(*matched, node.subscribers...)
+		return
+	}
+
+	if child, ok := node.children[segments[0]]; ok {
+		matchNode(child, segments[1:], matched)
+	}
+	if one, ok := node.children[TopicWildcardOne]; ok {
+		matchNode(one, segments[1:], matched)
+	}
+}
@@ -0,0 +1,148 @@
+package events
+
+import (
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMatchTopic 测试主题模式匹配
+func TestMatchTopic(t *testing.T) {
+	tests := []struct {
+		pattern string
+		topic   string
+		want    bool
+	}{
+		{"keyboard", "keyboard", true},
+		{"keyboard", "clipboard", false},
+		{"*", "hotkey.toggle_ai", true},
+		{"hotkey.*", "hotkey.toggle_ai", true},
+		{"hotkey.*", "hotkey", false},
+		{"hotkey.*", "hotkey.a.b", false},
+		{"*.toggle_ai", "hotkey.toggle_ai", true},
+		{"monitor.**", "monitor", true},
+		{"monitor.**", "monitor.status", true},
+		{"monitor.**", "monitor.a.b", true},
+		{"monitor.**", "monitoring", false},
+		{"**", "anything.at.all", true},
+		{"a.**.z", "a.z", true},
+		{"a.**.z", "a.b.c.z", true},
+		{"a.**.z", "a.b.c", false},
+		{"clipboard|keyboard", "keyboard", true},
+		{"clipboard|keyboard", "app_switch", false},
+		{"clipboard|hotkey.*", "hotkey.show_status", true},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, MatchTopic(tt.pattern, tt.topic), "%s ~ %s", tt.pattern, tt.topic)
+	}
+}
+
+// TestTopicTrie_MatchesMatchTopic 测试前缀树匹配结果与 MatchTopic 一致
+func TestTopicTrie_MatchesMatchTopic(t *testing.T) {
+	patterns := []string{
+		"hotkey.*", "monitor.**", "clipboard|keyboard", "a.**.z", "*.toggle_ai", "**",
+		"clipboard|clipboard", "a.*.**",
+	}
+	topics := []string{
+		"hotkey.toggle_ai", "hotkey", "monitor", "monitor.status.started", "keyboard",
+		"clipboard", "a.z", "a.b.z", "a.b", "x.toggle_ai", "app_switch",
+	}
+
+	trie := newTopicTrie()
+	byID := make(map[string]string)
+	for i, pattern := range patterns {
+		sub := &Subscriber{ID: string(rune('A' + i))}
+		byID[sub.ID] = pattern
+		trie.insert(pattern, sub)
+	}
+
+	for _, topic := range topics {
+		var got []string
+		for _, sub := range trie.match(topic) {
+			got = append(got, sub.ID)
+		}
+		sort.Strings(got)
+
+		var want []string
+		for id, pattern := range byID {
+			if MatchTopic(pattern, topic) {
+				want = append(want, id)
+			}
+		}
+		sort.Strings(want)
+
+		assert.Equal(t, want, got, "topic %s", topic)
+	}
+}
+
+// TestTopicTrie_Remove 测试移除模式订阅并清理空节点
+func TestTopicTrie_Remove(t *testing.T) {
+	trie := newTopicTrie()
+	first := &Subscriber{ID: "first"}
+	second := &Subscriber{ID: "second"}
+	trie.insert("hotkey.*|monitor.**", first)
+	trie.insert("hotkey.*", second)
+
+	trie.remove("hotkey.*|monitor.**", first.ID)
+
+	assert.Equal(t, []*Subscriber{second}, trie.match("hotkey.toggle_ai"))
+	assert.Empty(t, trie.match("monitor.status"))
+	assert.NotContains(t, trie.root.children, "monitor")
+
+	trie.remove("hotkey.*", second.ID)
+	assert.Empty(t, trie.root.children)
+}
+
+// TestSubscribePattern 测试事件总线的模式订阅
+func TestSubscribePattern(t *testing.T) {
+	bus := NewEventBus()
+	defer bus.Stop(5 * time.Second)
+
+	var mu sync.Mutex
+	received := make(map[string][]string)
+	subscribe := func(pattern string) string {
+		return bus.Subscribe(pattern, func(event Event) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received[pattern] = append(received[pattern], string(event.Type))
+			return nil
+		})
+	}
+
+	subscribe("hotkey.*")
+	subscribe("monitor.**")
+	subscribe("clipboard|keyboard")
+	subscribe("keyboard")
+	altID := subscribe("app_switch|hotkey.**")
+
+	for _, topic := range []string{"hotkey.toggle_ai", "monitor", "monitor.status", "keyboard", "clipboard", "app_switch"} {
+		require.NoError(t, bus.Publish(topic, *NewEvent(EventType(topic), nil)))
+	}
+
+	snapshot := func(pattern string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), received[pattern]...)
+	}
+
+	require.Eventually(t, func() bool {
+		return len(snapshot("app_switch|hotkey.**")) == 2 && len(snapshot("clipboard|keyboard")) == 2
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, []string{"hotkey.toggle_ai"}, snapshot("hotkey.*"))
+	assert.ElementsMatch(t, []string{"monitor", "monitor.status"}, snapshot("monitor.**"))
+	assert.ElementsMatch(t, []string{"keyboard", "clipboard"}, snapshot("clipboard|keyboard"))
+	assert.Equal(t, []string{"keyboard"}, snapshot("keyboard"))
+	assert.ElementsMatch(t, []string{"hotkey.toggle_ai", "app_switch"}, snapshot("app_switch|hotkey.**"))
+
+	// 取消模式订阅后不再收到事件
+	bus.Unsubscribe(altID)
+	require.NoError(t, bus.Publish("app_switch", *NewEvent(EventTypeAppSwitch, nil)))
+	time.Sleep(50 * time.Millisecond)
+	assert.Len(t, snapshot("app_switch|hotkey.**"), 2)
+}